@@ -28,6 +28,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -87,10 +88,75 @@ func main() {
 	tokenRepo := repository.NewRedisTokenRepository(redisClient)
 	roleRepo := repository.NewRoleRepository(db)
 	permissionRepo := repository.NewPermissionRepository(db)
+	oauthClientRepo := repository.NewOAuthClientRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	invitationRepo := repository.NewInvitationRepository(db)
+	fileRepo := repository.NewFileRepository(db)
+	attributeRepo := repository.NewUserAttributeRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationBroker := repository.NewRedisNotificationBroker(redisClient, cfg.Notification.Channel)
+	chatRepo := repository.NewChatRepository(db)
+	chatPresenceRepo := repository.NewRedisChatPresenceRepository(redisClient)
+	chatBroker := repository.NewRedisChatBroker(redisClient, cfg.Chat.Channel)
+	jobRunRepo := repository.NewJobRunRepository(db)
+	jobLocker := repository.NewRedisJobLocker(redisClient, cfg.Jobs.LockPrefix)
+	ipReputationRepo := repository.NewRedisIPReputationRepository(redisClient)
+	txManager := repository.NewTransactionManager(db)
+
+	// Inisialisasi file storage
+	fileStorage, err := setupFileStorage(cfg.Storage)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	// Inisialisasi transport email
+	mailer, err := setupMailer(cfg.Mail)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	mailQueue := repository.NewRedisMailQueue(redisClient, cfg.Mail.QueueKey)
 
 	// Inisialisasi service
-	authService := service.NewAuthService(userRepo, tokenRepo, cfg)
-	roleService := service.NewRoleService(roleRepo, permissionRepo, userRepo)
+	emailService, err := service.NewEmailService(mailer, mailQueue, cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to load email templates: %v", err)
+	}
+	auditService := service.NewAuditService(auditRepo, cfg)
+	webhookService := service.NewWebhookService(webhookRepo, auditService, cfg)
+
+	// Inisialisasi sink relay outbox domain event
+	eventSinks, err := setupEventSinks(cfg.Events, redisClient, webhookService)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize event sinks: %v", err)
+	}
+	eventBus := service.NewEventBus(outboxRepo, eventSinks, cfg)
+	notificationService := service.NewNotificationService(notificationRepo, notificationBroker, cfg)
+	loginRiskService, err := service.NewLoginRiskService(userRepo, ipReputationRepo, cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize login risk engine: %v", err)
+	}
+
+	authService := service.NewAuthService(userRepo, tokenRepo, invitationRepo, attributeRepo, fileStorage, chatBroker, txManager, auditService, eventBus, notificationService, emailService, loginRiskService, cfg)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, userRepo, txManager, auditService, eventBus)
+	oauthService := service.NewOAuthService(oauthClientRepo, roleRepo, tokenRepo, authService, roleService, auditService, cfg)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, authService, roleService, auditService, cfg)
+	userImportService := service.NewUserImportService(userRepo, authService, roleService, cfg)
+	userBulkService := service.NewUserBulkService(userRepo, tokenRepo, auditRepo, authService, roleService, txManager, eventBus, cfg)
+	invitationService := service.NewInvitationService(invitationRepo, userRepo, roleService, emailService, cfg)
+	profileService := service.NewProfileService(userRepo, tokenRepo, emailService, cfg)
+	avatarService := service.NewAvatarService(userRepo, tokenRepo, fileStorage, cfg)
+	storageService := service.NewStorageService(fileStorage, cfg)
+	fileService := service.NewFileService(fileRepo, userRepo, roleService, fileStorage, cfg)
+	userAttributeService := service.NewUserAttributeService(attributeRepo, userRepo, tokenRepo, cfg)
+	chatService := service.NewChatService(chatRepo, chatPresenceRepo, chatBroker, userRepo, tokenRepo, cfg)
+	loginHistoryService := service.NewLoginHistoryService(userRepo, fileStorage, cfg)
+	jobService, err := service.NewJobService(jobRunRepo, jobLocker, userRepo, tokenRepo, invitationRepo, authService, loginHistoryService, loginRiskService, auditService, cfg)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize job scheduler: %v", err)
+	}
 
 	// Inisialisasi default roles dan permissions
 	ctx := context.Background()
@@ -102,9 +168,24 @@ func main() {
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(authService)
 	roleHandler := handler.NewRoleHandler(authService, roleService)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	userImportHandler := handler.NewUserImportHandler(userImportService)
+	userBulkHandler := handler.NewUserBulkHandler(userBulkService)
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+	profileHandler := handler.NewProfileHandler(profileService)
+	avatarHandler := handler.NewAvatarHandler(avatarService)
+	storageHandler := handler.NewStorageHandler(storageService)
+	fileHandler := handler.NewFileHandler(fileService)
+	userAttributeHandler := handler.NewUserAttributeHandler(userAttributeService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	chatHandler := handler.NewChatHandler(chatService)
+	jobHandler := handler.NewJobHandler(jobService)
 
 	// Inisialisasi middleware
-	authMiddleware := middleware.AuthMiddleware(authService)
+	authMiddleware := middleware.AuthMiddleware(authService, apiKeyService)
 
 	// Setup router
 	router := setupRouter(cfg)
@@ -113,6 +194,21 @@ func main() {
 	authHandler.RegisterRoutes(router, authMiddleware)
 	userHandler.RegisterRoutes(router, authMiddleware)
 	roleHandler.RegisterRoutes(router, authMiddleware)
+	oauthHandler.RegisterRoutes(router, authMiddleware)
+	apiKeyHandler.RegisterRoutes(router, authMiddleware)
+	userImportHandler.RegisterRoutes(router, authMiddleware)
+	userBulkHandler.RegisterRoutes(router, authMiddleware)
+	invitationHandler.RegisterRoutes(router, authMiddleware)
+	profileHandler.RegisterRoutes(router, authMiddleware)
+	avatarHandler.RegisterRoutes(router, authMiddleware)
+	storageHandler.RegisterRoutes(router, authMiddleware)
+	fileHandler.RegisterRoutes(router, authMiddleware)
+	userAttributeHandler.RegisterRoutes(router, authMiddleware)
+	auditHandler.RegisterRoutes(router, authMiddleware)
+	webhookHandler.RegisterRoutes(router, authMiddleware)
+	notificationHandler.RegisterRoutes(router, authMiddleware)
+	chatHandler.RegisterRoutes(router, authMiddleware)
+	jobHandler.RegisterRoutes(router, authMiddleware)
 
 	// Jalankan server
 	server := &http.Server{
@@ -128,11 +224,55 @@ func main() {
 		}
 	}()
 
+	// Jalankan checkpoint hash chain audit log secara berkala
+	checkpointCtx, stopCheckpoints := context.WithCancel(context.Background())
+	go runAuditCheckpoints(checkpointCtx, auditService, cfg.Audit.CheckpointInterval)
+
+	// Jalankan dispatcher pengiriman webhook
+	dispatcherCtx, stopDispatcher := context.WithCancel(context.Background())
+	go runWebhookDispatcher(dispatcherCtx, webhookService, cfg.Webhook.PollInterval)
+
+	// Jalankan relay outbox domain event
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	go runOutboxRelay(relayCtx, eventBus, cfg.Events.RelayInterval)
+
+	// Jalankan fan-out notifikasi real-time dari Redis pub/sub ke stream SSE
+	notificationCtx, stopNotifications := context.WithCancel(context.Background())
+	go notificationService.Run(notificationCtx)
+	go runNotificationPurge(notificationCtx, notificationService)
+
+	// Jalankan fan-out event chat dari Redis pub/sub ke koneksi WebSocket
+	chatCtx, stopChat := context.WithCancel(context.Background())
+	go chatService.Run(chatCtx)
+
+	// Jalankan worker pengiriman email dari antrean Redis
+	mailCtx, stopMail := context.WithCancel(context.Background())
+	mailDone := make(chan struct{})
+	go func() {
+		emailService.Run(mailCtx)
+		close(mailDone)
+	}()
+
+	// Jalankan scheduler job pemeliharaan
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		jobService.Run(jobsCtx)
+		close(jobsDone)
+	}()
+
 	// Tunggu sinyal untuk shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logrus.Info("Shutting down server...")
+	stopCheckpoints()
+	stopRelay()
+	stopDispatcher()
+	// Tutup stream SSE agar shutdown server tidak menunggu koneksi yang tidak pernah selesai
+	stopNotifications()
+	// Koneksi WebSocket tidak ditunggu oleh shutdown server, tutup sesi chat secara eksplisit
+	stopChat()
 
 	// Berikan waktu untuk menyelesaikan request yang sedang berjalan
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -143,6 +283,14 @@ func main() {
 		logrus.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Tunggu email yang sedang dikirim; email lain tetap tersimpan di antrean Redis
+	stopMail()
+	<-mailDone
+
+	// Job yang sedang berjalan dibatalkan dan hasilnya dicatat sebelum proses berhenti
+	stopJobs()
+	<-jobsDone
+
 	logrus.Info("Server exiting")
 }
 
@@ -200,7 +348,25 @@ func setupDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		&model.Role{},
 		&model.Permission{},
 		&model.LoginHistory{},
-		&model.UserActivity{},
+		&model.AuditLog{},
+		&model.AuditChainHead{},
+		&model.AuditCheckpoint{},
+		&model.Invitation{},
+		&model.OAuthClient{},
+		&model.APIKey{},
+		&model.File{},
+		&model.Folder{},
+		&model.StorageQuota{},
+		&model.AttributeDefinition{},
+		&model.UserAttributeValue{},
+		&model.WebhookSubscription{},
+		&model.WebhookDelivery{},
+		&model.WebhookDeliveryAttempt{},
+		&model.OutboxEvent{},
+		&model.Notification{},
+		&model.ChatConversation{},
+		&model.ChatMessage{},
+		&model.JobRun{},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %v", err)
@@ -230,6 +396,192 @@ func setupRedis(cfg config.RedisConfig) (*redis.Client, error) {
 	return client, nil
 }
 
+// setupFileStorage menginisialisasi file storage sesuai driver yang dikonfigurasi
+func setupFileStorage(cfg config.StorageConfig) (repository.FileStorage, error) {
+	switch cfg.Driver {
+	case "local":
+		return repository.NewLocalFileStorage(cfg.LocalPath, cfg.PublicURL, cfg.SigningKey)
+	case "s3":
+		return repository.NewS3FileStorage(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3UsePathStyle)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
+
+// setupMailer menginisialisasi transport email sesuai driver yang dikonfigurasi
+func setupMailer(cfg config.MailConfig) (repository.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return repository.NewSMTPMailer(repository.SMTPMailerConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			TLSMode:  cfg.SMTPTLS,
+			From:     cfg.From,
+			Timeout:  cfg.SMTPTimeout,
+		})
+	case "file":
+		return repository.NewFileMailer(cfg.FileDir, cfg.From)
+	case "log":
+		return repository.NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+// setupEventSinks menginisialisasi sink relay outbox sesuai daftar yang dikonfigurasi
+func setupEventSinks(cfg config.EventsConfig, redisClient *redis.Client, webhookService service.WebhookService) ([]repository.EventSink, error) {
+	sinks := make([]repository.EventSink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case "webhook":
+			sinks = append(sinks, service.NewWebhookEventSink(webhookService))
+		case "redis":
+			sinks = append(sinks, repository.NewRedisStreamEventSink(redisClient, cfg.RedisStream, cfg.RedisStreamMaxLen))
+		case "nats":
+			sink, err := repository.NewNATSEventSink(cfg.NATSURL, cfg.NATSSubjectPrefix, cfg.NATSTimeout)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unsupported event sink: %s", name)
+		}
+	}
+	return sinks, nil
+}
+
+// runAuditCheckpoints membuat checkpoint hash chain audit log setiap interval sampai ctx
+// dibatalkan. Interval 0 menonaktifkan checkpoint otomatis.
+func runAuditCheckpoints(ctx context.Context, auditService service.AuditService, interval time.Duration) {
+	if interval <= 0 {
+		logrus.Info("Audit log checkpoints disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Checkpoint pertama dibuat saat start agar restart tidak memperlebar jarak antar checkpoint
+	createCheckpoint := func() {
+		checkpoint, err := auditService.CreateCheckpoint(ctx)
+		if err != nil {
+			logrus.Errorf("Failed to create audit log checkpoint: %v", err)
+			return
+		}
+		if checkpoint != nil {
+			logrus.Infof("Audit log checkpoint created at sequence %d", checkpoint.Sequence)
+		}
+	}
+	createCheckpoint()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			createCheckpoint()
+		}
+	}
+}
+
+// runWebhookDispatcher mengirim webhook yang sudah jatuh tempo setiap interval sampai ctx
+// dibatalkan. Selama masih ada antrean penuh, batch berikutnya langsung diproses.
+func runWebhookDispatcher(ctx context.Context, webhookService service.WebhookService, interval time.Duration) {
+	if interval <= 0 {
+		logrus.Info("Webhook dispatcher disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				processed, err := webhookService.ProcessDueDeliveries(ctx)
+				if err != nil {
+					logrus.Errorf("Failed to process webhook deliveries: %v", err)
+					break
+				}
+				if processed == 0 || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// runOutboxRelay mengirim event outbox yang jatuh tempo ke sink setiap interval sampai ctx
+// dibatalkan, dan menghapus event terkirim yang melewati masa retensi setiap jam.
+// Interval 0 menonaktifkan relay pada instance ini.
+func runOutboxRelay(ctx context.Context, eventBus service.EventBus, interval time.Duration) {
+	if interval <= 0 {
+		logrus.Info("Outbox relay disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	purgeTicker := time.NewTicker(time.Hour)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				relayed, err := eventBus.RelayDueEvents(ctx)
+				if err != nil {
+					logrus.Errorf("Failed to relay outbox events: %v", err)
+					break
+				}
+				if relayed == 0 || ctx.Err() != nil {
+					break
+				}
+			}
+		case <-purgeTicker.C:
+			purged, err := eventBus.PurgePublishedEvents(ctx)
+			if err != nil {
+				logrus.Errorf("Failed to purge published outbox events: %v", err)
+				continue
+			}
+			if purged > 0 {
+				logrus.Infof("Purged %d published outbox events", purged)
+			}
+		}
+	}
+}
+
+// runNotificationPurge menghapus notifikasi yang melewati masa retensi setiap jam sampai ctx dibatalkan
+func runNotificationPurge(ctx context.Context, notificationService service.NotificationService) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := notificationService.PurgeExpiredNotifications(ctx)
+			if err != nil {
+				logrus.Errorf("Failed to purge expired notifications: %v", err)
+				continue
+			}
+			if purged > 0 {
+				logrus.Infof("Purged %d expired notifications", purged)
+			}
+		}
+	}
+}
+
 // setupRouter mengatur router Gin
 func setupRouter(cfg *config.Config) *gin.Engine {
 	router := gin.New()
@@ -238,11 +590,17 @@ func setupRouter(cfg *config.Config) *gin.Engine {
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
 
+	// Hanya proxy tepercaya yang boleh menentukan IP klien melalui X-Forwarded-For
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logrus.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+	router.Use(middleware.CDNGeoHeadersMiddleware(cfg.Server.TrustCDNGeoHeaders, cfg.Server.TrustedProxyNetworks()))
+
 	// CORS configuration
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.Server.CorsAllowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -10,17 +14,29 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/hkdf"
 )
 
 // Config menyimpan semua konfigurasi aplikasi
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Google   GoogleConfig
-	Security SecurityConfig
-	Logging  LoggingConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Google       GoogleConfig
+	OAuth        OAuthConfig
+	Security     SecurityConfig
+	Storage      StorageConfig
+	Audit        AuditConfig
+	Webhook      WebhookConfig
+	Events       EventsConfig
+	Notification NotificationConfig
+	Chat         ChatConfig
+	Mail         MailConfig
+	Jobs         JobsConfig
+	LoginHistory LoginHistoryConfig
+	LoginRisk    LoginRiskConfig
+	Logging      LoggingConfig
 }
 
 // ServerConfig menyimpan konfigurasi server
@@ -29,6 +45,12 @@ type ServerConfig struct {
 	Environment      string
 	CorsAllowOrigins []string
 	SwaggerEnabled   bool
+	// TrustedProxies adalah IP atau CIDR reverse proxy/CDN yang boleh menentukan IP klien
+	// melalui X-Forwarded-For. Kosong berarti IP klien selalu diambil dari koneksi.
+	TrustedProxies []string
+	// TrustCDNGeoHeaders mengizinkan header lokasi Cloudflare (CF-IPCountry dan lainnya)
+	// jika request datang dari salah satu TrustedProxies
+	TrustCDNGeoHeaders bool
 }
 
 // DatabaseConfig menyimpan konfigurasi database MySQL
@@ -65,13 +87,172 @@ type GoogleConfig struct {
 	RedirectURL  string
 }
 
+// OAuthConfig menyimpan konfigurasi OAuth 2.0 authorization server
+type OAuthConfig struct {
+	ClientTokenExpiry     time.Duration
+	DeviceClientIDs       []string // client publik yang boleh menggunakan device authorization grant
+	DeviceCodeExpiry      time.Duration
+	DevicePollInterval    time.Duration
+	DeviceVerificationURI string
+	TokenExchangeExpiry   time.Duration
+}
+
 // SecurityConfig menyimpan konfigurasi keamanan
 type SecurityConfig struct {
-	RateLimitRequests int
-	RateLimitDuration time.Duration
-	PasswordMinLength int
-	MaxLoginAttempts  int
-	LockoutDuration   time.Duration
+	RateLimitRequests    int
+	RateLimitDuration    time.Duration
+	PasswordMinLength    int
+	MaxLoginAttempts     int
+	LockoutDuration      time.Duration
+	APIKeyMaxPerUser     int
+	InviteExpiry         time.Duration
+	DeletedUserRetention time.Duration // masa tunggu sebelum user yang dihapus dapat di-purge
+	UserImportMaxRows    int           // jumlah baris maksimum per bulk import
+	BulkActionMaxUsers   int           // jumlah user maksimum per bulk action
+	EmailChangeExpiry    time.Duration // masa berlaku link konfirmasi perubahan email
+	EmailChangeURL       string        // halaman frontend yang menerima token konfirmasi perubahan email
+	InvitationURL        string        // halaman frontend yang menerima token undangan
+	PasswordSetupURL     string        // halaman frontend untuk mengatur password dengan token dari admin
+}
+
+// StorageConfig menyimpan konfigurasi penyimpanan file (avatar dan file user)
+type StorageConfig struct {
+	Driver          string        // local atau s3
+	LocalPath       string        // direktori penyimpanan untuk driver local
+	PublicURL       string        // base URL service, dipakai untuk membuat link file
+	SigningKey      string        // kunci HMAC untuk signed URL driver local
+	SignedURLExpiry time.Duration // masa berlaku signed URL
+	S3Endpoint      string        // endpoint S3-compatible, misalnya MinIO
+	S3Region        string
+	S3Bucket        string
+	S3AccessKey     string
+	S3SecretKey     string
+	S3UsePathStyle  bool  // gunakan path-style URL (bucket di path), diperlukan oleh MinIO
+	AvatarMaxSize   int64 // ukuran maksimum file avatar dalam byte
+	AvatarSize      int   // lebar dan tinggi avatar setelah di-resize dalam pixel
+	FileMaxSize     int64 // ukuran maksimum satu file yang diunggah dalam byte
+	DefaultQuota    int64 // kuota storage default per user dalam byte
+	MaxUploadFiles  int   // jumlah file maksimum dalam satu upload
+}
+
+// AuditConfig menyimpan konfigurasi audit log
+type AuditConfig struct {
+	CheckpointKey      string        // kunci HMAC untuk menandatangani checkpoint hash chain
+	CheckpointInterval time.Duration // jarak antar checkpoint otomatis, 0 untuk menonaktifkan
+}
+
+// WebhookConfig menyimpan konfigurasi pengiriman webhook
+type WebhookConfig struct {
+	MaxAttempts    int           // jumlah percobaan sebelum delivery masuk status dead
+	RetryBaseDelay time.Duration // jeda sebelum percobaan kedua, berlipat dua setiap percobaan
+	RetryMaxDelay  time.Duration // batas atas jeda antar percobaan
+	Timeout        time.Duration // timeout satu request ke endpoint webhook
+	PollInterval   time.Duration // jarak pengecekan delivery yang jatuh tempo
+	BatchSize      int           // jumlah delivery maksimum yang dikirim per pengecekan
+	RequireHTTPS   bool          // menolak URL webhook dengan skema http
+	AllowPrivate   bool          // mengizinkan target loopback, private, dan link-local (hanya untuk development)
+}
+
+// EventsConfig menyimpan konfigurasi outbox dan relay domain event
+type EventsConfig struct {
+	Sinks             []string      // sink tujuan relay: webhook, redis, nats
+	RelayInterval     time.Duration // jarak pengecekan outbox, 0 untuk menonaktifkan relay
+	BatchSize         int           // jumlah event maksimum yang di-relay per pengecekan
+	RetryBaseDelay    time.Duration // jeda sebelum percobaan relay kedua, berlipat dua setiap percobaan
+	RetryMaxDelay     time.Duration // batas atas jeda antar percobaan relay
+	Retention         time.Duration // lama event yang sudah terkirim disimpan di outbox
+	RedisStream       string        // nama Redis Stream untuk sink redis
+	RedisStreamMaxLen int64         // panjang maksimum Redis Stream (perkiraan)
+	NATSURL           string        // alamat server NATS untuk sink nats
+	NATSSubjectPrefix string        // prefix subject NATS, subject akhir <prefix>.<tipe event>
+	NATSTimeout       time.Duration // timeout koneksi dan publish ke NATS
+}
+
+// NotificationConfig menyimpan konfigurasi notifikasi user dan stream real-time
+type NotificationConfig struct {
+	Channel           string        // channel Redis pub/sub untuk fan-out notifikasi antar instance
+	HeartbeatInterval time.Duration // jarak komentar keep-alive pada stream SSE
+	StreamBuffer      int           // jumlah pesan yang ditampung per koneksi stream sebelum dibuang
+	Retention         time.Duration // lama notifikasi disimpan, 0 untuk menyimpan selamanya
+}
+
+// ChatConfig menyimpan konfigurasi chat 1:1 dan koneksi WebSocket
+type ChatConfig struct {
+	Channel           string        // channel Redis pub/sub untuk fan-out event chat antar instance
+	MaxMessageLength  int           // panjang maksimum isi pesan dalam karakter
+	RateLimitMessages int           // jumlah pesan maksimum per user dalam RateLimitWindow
+	RateLimitWindow   time.Duration // jendela rate limit pengiriman pesan
+	PingInterval      time.Duration // jarak ping WebSocket sekaligus pembaruan presence
+	PresenceTTL       time.Duration // lama koneksi dianggap online tanpa pembaruan presence
+	SendBuffer        int           // jumlah event yang ditampung per koneksi sebelum koneksi ditutup
+}
+
+// MailConfig menyimpan konfigurasi pengiriman email
+type MailConfig struct {
+	Driver         string        // smtp, file, atau log
+	From           string        // alamat pengirim, misalnya "Auth Service <no-reply@example.com>"
+	AppName        string        // nama aplikasi yang ditampilkan pada template email
+	DefaultLocale  string        // bahasa template jika bahasa user tidak tersedia
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUsername   string        // kosong untuk server tanpa autentikasi seperti MailHog
+	SMTPPassword   string
+	SMTPTLS        string        // none, starttls, atau tls
+	SMTPTimeout    time.Duration // timeout koneksi dan pengiriman satu email
+	FileDir        string        // direktori file .eml untuk driver file
+	QueueKey       string        // prefix key Redis antrean email
+	Workers        int           // jumlah worker pengiriman per instance
+	MaxAttempts    int           // jumlah percobaan sebelum email masuk dead list
+	RetryBaseDelay time.Duration // jeda sebelum percobaan kedua, berlipat dua setiap percobaan
+	RetryMaxDelay  time.Duration // batas atas jeda antar percobaan
+}
+
+// JobsConfig menyimpan konfigurasi scheduler job pemeliharaan. Jadwal memakai ekspresi cron
+// lima field dalam UTC atau "@every <durasi>"; "off" menonaktifkan jadwal otomatis job tersebut.
+type JobsConfig struct {
+	Enabled                 bool          // menjalankan job terjadwal di instance ini
+	LockPrefix              string        // prefix key Redis untuk lock dan slot jadwal job
+	Timeout                 time.Duration // batas waktu satu run job sekaligus masa berlaku lock
+	ExpiredLockoutsSchedule string
+	OAuthStateSchedule      string
+	LoginHistorySchedule    string
+	UnverifiedUsersSchedule string
+	InvitationsSchedule     string
+	DeletedUsersSchedule    string
+	RunHistorySchedule      string
+	TorExitListSchedule     string
+	UnverifiedUserRetention time.Duration // lama akun lokal yang belum terverifikasi dan belum pernah login disimpan
+	InvitationRetention     time.Duration // lama undangan kedaluwarsa atau dicabut disimpan
+	RunHistoryRetention     time.Duration // lama riwayat run job disimpan
+}
+
+// LoginHistoryConfig menyimpan konfigurasi retensi dan arsip riwayat login yang dijalankan
+// oleh job login_history_cleanup
+type LoginHistoryConfig struct {
+	SuccessRetention time.Duration // lama riwayat login berhasil disimpan, 0 untuk menyimpan selamanya
+	FailureRetention time.Duration // lama riwayat login gagal disimpan, 0 untuk menyimpan selamanya
+	BatchSize        int           // jumlah baris yang diarsipkan dan dihapus per transaksi
+	BatchPause       time.Duration // jeda antar batch agar penghapusan tidak membebani database
+	ArchiveEnabled   bool          // arsipkan baris ke file NDJSON terkompresi sebelum dihapus
+	ArchivePrefix    string        // prefix key object arsip di file storage
+}
+
+// LoginRiskConfig menyimpan konfigurasi penilaian risiko login. Skor setiap faktor dijumlahkan
+// (maksimal 100) lalu dibandingkan dengan ambang; ambang 0 menonaktifkan tindakan tersebut.
+type LoginRiskConfig struct {
+	NewDeviceScore        int
+	NewCountryScore       int
+	ImpossibleTravelScore int
+	TorExitNodeScore      int
+	DeniedIPScore         int
+	NotifyThreshold       int           // skor minimum untuk memberi tahu user
+	StepUpThreshold       int           // skor minimum untuk meminta verifikasi kode dari email
+	MaxTravelSpeed        float64       // kecepatan perjalanan maksimum yang wajar dalam km/jam
+	MinTravelDistance     float64       // jarak minimum dalam km sebelum kecepatan perjalanan dinilai
+	IPDenylist            []string      // alamat IP atau CIDR yang selalu dianggap berisiko
+	TorExitListURL        string        // daftar exit node TOR (satu IP per baris), kosong untuk menonaktifkan
+	StepUpExpiry          time.Duration // masa berlaku kode verifikasi step-up
+	StepUpMaxAttempts     int           // jumlah percobaan kode sebelum challenge dibatalkan
 }
 
 // LoggingConfig menyimpan konfigurasi logging
@@ -96,6 +277,14 @@ func LoadConfig() (*Config, error) {
 	serverEnv := getEnv("ENVIRONMENT", "development")
 	corsAllowOrigins := strings.Split(getEnv("CORS_ALLOW_ORIGINS", "http://localhost:3000"), ",")
 	swaggerEnabled, _ := strconv.ParseBool(getEnv("SWAGGER_ENABLED", "true"))
+	trustedProxies, err := parseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		return nil, err
+	}
+	trustCDNGeoHeaders, _ := strconv.ParseBool(getEnv("TRUST_CDN_GEO_HEADERS", "false"))
+	if trustCDNGeoHeaders && len(trustedProxies) == 0 {
+		return nil, fmt.Errorf("TRUST_CDN_GEO_HEADERS requires TRUSTED_PROXIES")
+	}
 
 	// Konfigurasi database
 	dbHost := getEnv("DB_HOST", "localhost")
@@ -123,12 +312,166 @@ func LoadConfig() (*Config, error) {
 	googleClientSecret := getEnv("GOOGLE_CLIENT_SECRET", "")
 	googleRedirectURL := getEnv("GOOGLE_REDIRECT_URL", "http://localhost:8080/api/v1/auth/google/callback")
 
+	// Konfigurasi OAuth authorization server
+	oauthClientTokenExpiry, _ := time.ParseDuration(getEnv("OAUTH_CLIENT_TOKEN_EXPIRY", "1h"))
+	oauthDeviceClientIDs := strings.Split(getEnv("OAUTH_DEVICE_CLIENT_IDS", "auth-cli"), ",")
+	oauthDeviceCodeExpiry, _ := time.ParseDuration(getEnv("OAUTH_DEVICE_CODE_EXPIRY", "10m"))
+	oauthDevicePollInterval, _ := time.ParseDuration(getEnv("OAUTH_DEVICE_POLL_INTERVAL", "5s"))
+	oauthDeviceVerificationURI := getEnv("OAUTH_DEVICE_VERIFICATION_URI", "http://localhost:3000/device")
+	oauthTokenExchangeExpiry, _ := time.ParseDuration(getEnv("OAUTH_TOKEN_EXCHANGE_EXPIRY", "5m"))
+
 	// Konfigurasi keamanan
 	rateLimitRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_REQUESTS", "100"))
 	rateLimitDuration, _ := time.ParseDuration(getEnv("RATE_LIMIT_DURATION", "1m"))
 	passwordMinLength, _ := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
 	maxLoginAttempts, _ := strconv.Atoi(getEnv("MAX_LOGIN_ATTEMPTS", "5"))
 	lockoutDuration, _ := time.ParseDuration(getEnv("LOCKOUT_DURATION", "15m"))
+	apiKeyMaxPerUser, _ := strconv.Atoi(getEnv("API_KEY_MAX_PER_USER", "25"))
+	inviteExpiry, _ := time.ParseDuration(getEnv("USER_INVITE_EXPIRY", "72h"))
+	deletedUserRetention, _ := time.ParseDuration(getEnv("DELETED_USER_RETENTION", "720h"))
+	userImportMaxRows, _ := strconv.Atoi(getEnv("USER_IMPORT_MAX_ROWS", "1000"))
+	bulkActionMaxUsers, _ := strconv.Atoi(getEnv("BULK_ACTION_MAX_USERS", "1000"))
+	emailChangeExpiry, _ := time.ParseDuration(getEnv("EMAIL_CHANGE_EXPIRY", "24h"))
+	emailChangeURL := getEnv("EMAIL_CHANGE_URL", "http://localhost:3000/confirm-email-change")
+	invitationURL := getEnv("INVITATION_URL", "http://localhost:3000/accept-invitation")
+	passwordSetupURL := getEnv("PASSWORD_SETUP_URL", "http://localhost:3000/set-password")
+
+	// Konfigurasi penyimpanan file
+	storageDriver := getEnv("STORAGE_DRIVER", "local")
+	storageLocalPath := getEnv("STORAGE_LOCAL_PATH", "./storage")
+	storagePublicURL := strings.TrimRight(getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080"), "/")
+	storageSigningKey := getEnv("STORAGE_SIGNING_KEY", "")
+	if storageSigningKey == jwtSecretKey {
+		return nil, fmt.Errorf("STORAGE_SIGNING_KEY must differ from JWT_SECRET_KEY")
+	}
+	if storageSigningKey == "" {
+		// Kunci tidak dipakai ulang; kunci terpisah diturunkan dari JWT secret dengan HKDF
+		log.Println("Warning: STORAGE_SIGNING_KEY is not set, deriving the storage signing key from JWT_SECRET_KEY")
+		storageSigningKey, err = deriveKey(jwtSecretKey, "auth-service storage signed url")
+		if err != nil {
+			return nil, err
+		}
+	}
+	storageSignedURLExpiry, _ := time.ParseDuration(getEnv("STORAGE_SIGNED_URL_EXPIRY", "15m"))
+	s3Endpoint := getEnv("S3_ENDPOINT", "http://localhost:9000")
+	s3Region := getEnv("S3_REGION", "us-east-1")
+	s3Bucket := getEnv("S3_BUCKET", "auth-service")
+	s3AccessKey := getEnv("S3_ACCESS_KEY", "")
+	s3SecretKey := getEnv("S3_SECRET_KEY", "")
+	s3UsePathStyle, _ := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "true"))
+	avatarMaxSize, _ := strconv.ParseInt(getEnv("AVATAR_MAX_SIZE", "5242880"), 10, 64)
+	avatarSize, _ := strconv.Atoi(getEnv("AVATAR_SIZE", "256"))
+	fileMaxSize, _ := strconv.ParseInt(getEnv("FILE_MAX_SIZE", "52428800"), 10, 64)
+	storageDefaultQuota, _ := strconv.ParseInt(getEnv("STORAGE_DEFAULT_QUOTA", "1073741824"), 10, 64)
+	fileMaxUploadFiles, _ := strconv.Atoi(getEnv("FILE_MAX_UPLOAD_FILES", "10"))
+
+	// Konfigurasi audit log
+	auditCheckpointKey := getEnv("AUDIT_CHECKPOINT_KEY", "")
+	auditCheckpointInterval, _ := time.ParseDuration(getEnv("AUDIT_CHECKPOINT_INTERVAL", "1h"))
+	// Checkpoint harus ditandatangani kunci terpisah; pemegang kunci JWT tidak boleh dapat memalsukannya
+	if auditCheckpointInterval > 0 {
+		if auditCheckpointKey == "" {
+			return nil, fmt.Errorf("AUDIT_CHECKPOINT_KEY is required when AUDIT_CHECKPOINT_INTERVAL is enabled")
+		}
+		if auditCheckpointKey == jwtSecretKey {
+			return nil, fmt.Errorf("AUDIT_CHECKPOINT_KEY must differ from JWT_SECRET_KEY")
+		}
+	}
+
+	// Konfigurasi webhook
+	webhookMaxAttempts, _ := strconv.Atoi(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	webhookRetryBaseDelay, _ := time.ParseDuration(getEnv("WEBHOOK_RETRY_BASE_DELAY", "30s"))
+	webhookRetryMaxDelay, _ := time.ParseDuration(getEnv("WEBHOOK_RETRY_MAX_DELAY", "6h"))
+	webhookTimeout, _ := time.ParseDuration(getEnv("WEBHOOK_TIMEOUT", "10s"))
+	webhookPollInterval, _ := time.ParseDuration(getEnv("WEBHOOK_POLL_INTERVAL", "5s"))
+	webhookBatchSize, _ := strconv.Atoi(getEnv("WEBHOOK_BATCH_SIZE", "50"))
+	webhookRequireHTTPS, _ := strconv.ParseBool(getEnv("WEBHOOK_REQUIRE_HTTPS", "true"))
+	webhookAllowPrivate, _ := strconv.ParseBool(getEnv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "false"))
+
+	// Konfigurasi outbox dan relay domain event
+	eventSinks := strings.Split(getEnv("EVENT_SINKS", "webhook"), ",")
+	eventRelayInterval, _ := time.ParseDuration(getEnv("OUTBOX_RELAY_INTERVAL", "1s"))
+	eventBatchSize, _ := strconv.Atoi(getEnv("OUTBOX_BATCH_SIZE", "100"))
+	eventRetryBaseDelay, _ := time.ParseDuration(getEnv("OUTBOX_RETRY_BASE_DELAY", "5s"))
+	eventRetryMaxDelay, _ := time.ParseDuration(getEnv("OUTBOX_RETRY_MAX_DELAY", "10m"))
+	eventRetention, _ := time.ParseDuration(getEnv("OUTBOX_RETENTION", "168h"))
+	eventRedisStream := getEnv("EVENT_REDIS_STREAM", "auth:events")
+	eventRedisStreamMaxLen, _ := strconv.ParseInt(getEnv("EVENT_REDIS_STREAM_MAXLEN", "100000"), 10, 64)
+	eventNATSURL := getEnv("EVENT_NATS_URL", "nats://localhost:4222")
+	eventNATSSubjectPrefix := getEnv("EVENT_NATS_SUBJECT_PREFIX", "auth.events")
+	eventNATSTimeout, _ := time.ParseDuration(getEnv("EVENT_NATS_TIMEOUT", "5s"))
+
+	// Konfigurasi notifikasi
+	notificationChannel := getEnv("NOTIFICATION_CHANNEL", "auth:notifications")
+	notificationHeartbeatInterval, _ := time.ParseDuration(getEnv("NOTIFICATION_HEARTBEAT_INTERVAL", "25s"))
+	notificationStreamBuffer, _ := strconv.Atoi(getEnv("NOTIFICATION_STREAM_BUFFER", "32"))
+	notificationRetention, _ := time.ParseDuration(getEnv("NOTIFICATION_RETENTION", "2160h"))
+
+	// Konfigurasi chat
+	chatChannel := getEnv("CHAT_CHANNEL", "auth:chat")
+	chatMaxMessageLength, _ := strconv.Atoi(getEnv("CHAT_MAX_MESSAGE_LENGTH", "4000"))
+	chatRateLimitMessages, _ := strconv.Atoi(getEnv("CHAT_RATE_LIMIT_MESSAGES", "30"))
+	chatRateLimitWindow, _ := time.ParseDuration(getEnv("CHAT_RATE_LIMIT_WINDOW", "10s"))
+	chatPingInterval, _ := time.ParseDuration(getEnv("CHAT_PING_INTERVAL", "25s"))
+	chatPresenceTTL, _ := time.ParseDuration(getEnv("CHAT_PRESENCE_TTL", "60s"))
+	chatSendBuffer, _ := strconv.Atoi(getEnv("CHAT_SEND_BUFFER", "64"))
+
+	// Konfigurasi email
+	mailDriver := getEnv("MAIL_DRIVER", "log")
+	mailFrom := getEnv("MAIL_FROM", "Auth Service <no-reply@localhost>")
+	mailAppName := getEnv("MAIL_APP_NAME", "Auth Service")
+	mailDefaultLocale := getEnv("MAIL_DEFAULT_LOCALE", "en")
+	mailSMTPHost := getEnv("MAIL_SMTP_HOST", "localhost")
+	mailSMTPPort := getEnv("MAIL_SMTP_PORT", "1025")
+	mailSMTPUsername := getEnv("MAIL_SMTP_USERNAME", "")
+	mailSMTPPassword := getEnv("MAIL_SMTP_PASSWORD", "")
+	mailSMTPTLS := getEnv("MAIL_SMTP_TLS", "none")
+	mailSMTPTimeout, _ := time.ParseDuration(getEnv("MAIL_SMTP_TIMEOUT", "10s"))
+	mailFileDir := getEnv("MAIL_FILE_DIR", "./storage/mail")
+	mailQueueKey := getEnv("MAIL_QUEUE_KEY", "auth:mail")
+	mailWorkers, _ := strconv.Atoi(getEnv("MAIL_WORKERS", "2"))
+	mailMaxAttempts, _ := strconv.Atoi(getEnv("MAIL_MAX_ATTEMPTS", "6"))
+	mailRetryBaseDelay, _ := time.ParseDuration(getEnv("MAIL_RETRY_BASE_DELAY", "30s"))
+	mailRetryMaxDelay, _ := time.ParseDuration(getEnv("MAIL_RETRY_MAX_DELAY", "30m"))
+
+	// Konfigurasi scheduler job pemeliharaan
+	jobsEnabled, _ := strconv.ParseBool(getEnv("JOBS_ENABLED", "true"))
+	jobsLockPrefix := getEnv("JOBS_LOCK_PREFIX", "auth:jobs")
+	jobsTimeout, _ := time.ParseDuration(getEnv("JOBS_TIMEOUT", "30m"))
+	jobExpiredLockoutsSchedule := getEnv("JOB_EXPIRED_LOCKOUTS_SCHEDULE", "*/5 * * * *")
+	jobOAuthStateSchedule := getEnv("JOB_OAUTH_STATE_SCHEDULE", "15 * * * *")
+	jobLoginHistorySchedule := getEnv("JOB_LOGIN_HISTORY_SCHEDULE", "30 2 * * *")
+	jobUnverifiedUsersSchedule := getEnv("JOB_UNVERIFIED_USERS_SCHEDULE", "0 3 * * *")
+	jobInvitationsSchedule := getEnv("JOB_INVITATIONS_SCHEDULE", "30 3 * * *")
+	jobDeletedUsersSchedule := getEnv("JOB_DELETED_USERS_SCHEDULE", "0 4 * * *")
+	jobRunHistorySchedule := getEnv("JOB_RUN_HISTORY_SCHEDULE", "30 4 * * *")
+	jobTorExitListSchedule := getEnv("JOB_TOR_EXIT_LIST_SCHEDULE", "0 * * * *")
+	unverifiedUserRetention, _ := time.ParseDuration(getEnv("UNVERIFIED_USER_RETENTION", "720h"))
+	invitationRetention, _ := time.ParseDuration(getEnv("INVITATION_RETENTION", "720h"))
+	jobRunHistoryRetention, _ := time.ParseDuration(getEnv("JOB_RUN_HISTORY_RETENTION", "720h"))
+
+	// Konfigurasi retensi dan arsip riwayat login
+	loginHistorySuccessRetention, _ := time.ParseDuration(getEnv("LOGIN_HISTORY_SUCCESS_RETENTION", "2160h"))
+	loginHistoryFailureRetention, _ := time.ParseDuration(getEnv("LOGIN_HISTORY_FAILURE_RETENTION", "720h"))
+	loginHistoryBatchSize, _ := strconv.Atoi(getEnv("LOGIN_HISTORY_BATCH_SIZE", "1000"))
+	loginHistoryBatchPause, _ := time.ParseDuration(getEnv("LOGIN_HISTORY_BATCH_PAUSE", "100ms"))
+	loginHistoryArchiveEnabled, _ := strconv.ParseBool(getEnv("LOGIN_HISTORY_ARCHIVE_ENABLED", "false"))
+	loginHistoryArchivePrefix := getEnv("LOGIN_HISTORY_ARCHIVE_PREFIX", "archives/login-history")
+
+	// Konfigurasi penilaian risiko login
+	loginRiskNewDeviceScore, _ := strconv.Atoi(getEnv("LOGIN_RISK_NEW_DEVICE_SCORE", "30"))
+	loginRiskNewCountryScore, _ := strconv.Atoi(getEnv("LOGIN_RISK_NEW_COUNTRY_SCORE", "30"))
+	loginRiskImpossibleTravelScore, _ := strconv.Atoi(getEnv("LOGIN_RISK_IMPOSSIBLE_TRAVEL_SCORE", "60"))
+	loginRiskTorExitNodeScore, _ := strconv.Atoi(getEnv("LOGIN_RISK_TOR_SCORE", "50"))
+	loginRiskDeniedIPScore, _ := strconv.Atoi(getEnv("LOGIN_RISK_DENIED_IP_SCORE", "80"))
+	loginRiskNotifyThreshold, _ := strconv.Atoi(getEnv("LOGIN_RISK_NOTIFY_THRESHOLD", "30"))
+	loginRiskStepUpThreshold, _ := strconv.Atoi(getEnv("LOGIN_RISK_STEP_UP_THRESHOLD", "60"))
+	loginRiskMaxTravelSpeed, _ := strconv.ParseFloat(getEnv("LOGIN_RISK_MAX_TRAVEL_SPEED", "1000"), 64)
+	loginRiskMinTravelDistance, _ := strconv.ParseFloat(getEnv("LOGIN_RISK_MIN_TRAVEL_DISTANCE", "500"), 64)
+	loginRiskIPDenylist := strings.Split(getEnv("LOGIN_RISK_IP_DENYLIST", ""), ",")
+	loginRiskTorExitListURL := getEnv("LOGIN_RISK_TOR_EXIT_LIST_URL", "https://check.torproject.org/torbulkexitlist")
+	loginRiskStepUpExpiry, _ := time.ParseDuration(getEnv("LOGIN_RISK_STEP_UP_EXPIRY", "10m"))
+	loginRiskStepUpMaxAttempts, _ := strconv.Atoi(getEnv("LOGIN_RISK_STEP_UP_MAX_ATTEMPTS", "5"))
 
 	// Konfigurasi logging
 	logLevel := getEnv("LOG_LEVEL", "info")
@@ -136,10 +479,12 @@ func LoadConfig() (*Config, error) {
 
 	return &Config{
 		Server: ServerConfig{
-			Port:             serverPort,
-			Environment:      serverEnv,
-			CorsAllowOrigins: corsAllowOrigins,
-			SwaggerEnabled:   swaggerEnabled,
+			Port:               serverPort,
+			Environment:        serverEnv,
+			CorsAllowOrigins:   corsAllowOrigins,
+			SwaggerEnabled:     swaggerEnabled,
+			TrustedProxies:     trustedProxies,
+			TrustCDNGeoHeaders: trustCDNGeoHeaders,
 		},
 		Database: DatabaseConfig{
 			Host:            dbHost,
@@ -167,12 +512,146 @@ func LoadConfig() (*Config, error) {
 			ClientSecret: googleClientSecret,
 			RedirectURL:  googleRedirectURL,
 		},
+		OAuth: OAuthConfig{
+			ClientTokenExpiry:     oauthClientTokenExpiry,
+			DeviceClientIDs:       oauthDeviceClientIDs,
+			DeviceCodeExpiry:      oauthDeviceCodeExpiry,
+			DevicePollInterval:    oauthDevicePollInterval,
+			DeviceVerificationURI: oauthDeviceVerificationURI,
+			TokenExchangeExpiry:   oauthTokenExchangeExpiry,
+		},
 		Security: SecurityConfig{
-			RateLimitRequests: rateLimitRequests,
-			RateLimitDuration: rateLimitDuration,
-			PasswordMinLength: passwordMinLength,
-			MaxLoginAttempts:  maxLoginAttempts,
-			LockoutDuration:   lockoutDuration,
+			RateLimitRequests:    rateLimitRequests,
+			RateLimitDuration:    rateLimitDuration,
+			PasswordMinLength:    passwordMinLength,
+			MaxLoginAttempts:     maxLoginAttempts,
+			LockoutDuration:      lockoutDuration,
+			APIKeyMaxPerUser:     apiKeyMaxPerUser,
+			InviteExpiry:         inviteExpiry,
+			DeletedUserRetention: deletedUserRetention,
+			UserImportMaxRows:    userImportMaxRows,
+			BulkActionMaxUsers:   bulkActionMaxUsers,
+			EmailChangeExpiry:    emailChangeExpiry,
+			EmailChangeURL:       emailChangeURL,
+			InvitationURL:        invitationURL,
+			PasswordSetupURL:     passwordSetupURL,
+		},
+		Storage: StorageConfig{
+			Driver:          storageDriver,
+			LocalPath:       storageLocalPath,
+			PublicURL:       storagePublicURL,
+			SigningKey:      storageSigningKey,
+			SignedURLExpiry: storageSignedURLExpiry,
+			S3Endpoint:      s3Endpoint,
+			S3Region:        s3Region,
+			S3Bucket:        s3Bucket,
+			S3AccessKey:     s3AccessKey,
+			S3SecretKey:     s3SecretKey,
+			S3UsePathStyle:  s3UsePathStyle,
+			AvatarMaxSize:   avatarMaxSize,
+			AvatarSize:      avatarSize,
+			FileMaxSize:     fileMaxSize,
+			DefaultQuota:    storageDefaultQuota,
+			MaxUploadFiles:  fileMaxUploadFiles,
+		},
+		Audit: AuditConfig{
+			CheckpointKey:      auditCheckpointKey,
+			CheckpointInterval: auditCheckpointInterval,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:    webhookMaxAttempts,
+			RetryBaseDelay: webhookRetryBaseDelay,
+			RetryMaxDelay:  webhookRetryMaxDelay,
+			Timeout:        webhookTimeout,
+			PollInterval:   webhookPollInterval,
+			BatchSize:      webhookBatchSize,
+			RequireHTTPS:   webhookRequireHTTPS,
+			AllowPrivate:   webhookAllowPrivate,
+		},
+		Events: EventsConfig{
+			Sinks:             eventSinks,
+			RelayInterval:     eventRelayInterval,
+			BatchSize:         eventBatchSize,
+			RetryBaseDelay:    eventRetryBaseDelay,
+			RetryMaxDelay:     eventRetryMaxDelay,
+			Retention:         eventRetention,
+			RedisStream:       eventRedisStream,
+			RedisStreamMaxLen: eventRedisStreamMaxLen,
+			NATSURL:           eventNATSURL,
+			NATSSubjectPrefix: eventNATSSubjectPrefix,
+			NATSTimeout:       eventNATSTimeout,
+		},
+		Notification: NotificationConfig{
+			Channel:           notificationChannel,
+			HeartbeatInterval: notificationHeartbeatInterval,
+			StreamBuffer:      notificationStreamBuffer,
+			Retention:         notificationRetention,
+		},
+		Chat: ChatConfig{
+			Channel:           chatChannel,
+			MaxMessageLength:  chatMaxMessageLength,
+			RateLimitMessages: chatRateLimitMessages,
+			RateLimitWindow:   chatRateLimitWindow,
+			PingInterval:      chatPingInterval,
+			PresenceTTL:       chatPresenceTTL,
+			SendBuffer:        chatSendBuffer,
+		},
+		Mail: MailConfig{
+			Driver:         mailDriver,
+			From:           mailFrom,
+			AppName:        mailAppName,
+			DefaultLocale:  mailDefaultLocale,
+			SMTPHost:       mailSMTPHost,
+			SMTPPort:       mailSMTPPort,
+			SMTPUsername:   mailSMTPUsername,
+			SMTPPassword:   mailSMTPPassword,
+			SMTPTLS:        mailSMTPTLS,
+			SMTPTimeout:    mailSMTPTimeout,
+			FileDir:        mailFileDir,
+			QueueKey:       mailQueueKey,
+			Workers:        mailWorkers,
+			MaxAttempts:    mailMaxAttempts,
+			RetryBaseDelay: mailRetryBaseDelay,
+			RetryMaxDelay:  mailRetryMaxDelay,
+		},
+		Jobs: JobsConfig{
+			Enabled:                 jobsEnabled,
+			LockPrefix:              jobsLockPrefix,
+			Timeout:                 jobsTimeout,
+			ExpiredLockoutsSchedule: jobExpiredLockoutsSchedule,
+			OAuthStateSchedule:      jobOAuthStateSchedule,
+			LoginHistorySchedule:    jobLoginHistorySchedule,
+			UnverifiedUsersSchedule: jobUnverifiedUsersSchedule,
+			InvitationsSchedule:     jobInvitationsSchedule,
+			DeletedUsersSchedule:    jobDeletedUsersSchedule,
+			RunHistorySchedule:      jobRunHistorySchedule,
+			TorExitListSchedule:     jobTorExitListSchedule,
+			UnverifiedUserRetention: unverifiedUserRetention,
+			InvitationRetention:     invitationRetention,
+			RunHistoryRetention:     jobRunHistoryRetention,
+		},
+		LoginHistory: LoginHistoryConfig{
+			SuccessRetention: loginHistorySuccessRetention,
+			FailureRetention: loginHistoryFailureRetention,
+			BatchSize:        loginHistoryBatchSize,
+			BatchPause:       loginHistoryBatchPause,
+			ArchiveEnabled:   loginHistoryArchiveEnabled,
+			ArchivePrefix:    loginHistoryArchivePrefix,
+		},
+		LoginRisk: LoginRiskConfig{
+			NewDeviceScore:        loginRiskNewDeviceScore,
+			NewCountryScore:       loginRiskNewCountryScore,
+			ImpossibleTravelScore: loginRiskImpossibleTravelScore,
+			TorExitNodeScore:      loginRiskTorExitNodeScore,
+			DeniedIPScore:         loginRiskDeniedIPScore,
+			NotifyThreshold:       loginRiskNotifyThreshold,
+			StepUpThreshold:       loginRiskStepUpThreshold,
+			MaxTravelSpeed:        loginRiskMaxTravelSpeed,
+			MinTravelDistance:     loginRiskMinTravelDistance,
+			IPDenylist:            loginRiskIPDenylist,
+			TorExitListURL:        loginRiskTorExitListURL,
+			StepUpExpiry:          loginRiskStepUpExpiry,
+			StepUpMaxAttempts:     loginRiskStepUpMaxAttempts,
 		},
 		Logging: LoggingConfig{
 			Level:  logLevel,
@@ -192,6 +671,45 @@ func (c *RedisConfig) GetRedisAddr() string {
 	return fmt.Sprintf("%s:%s", c.Host, c.Port)
 }
 
+// TrustedProxyNetworks mengembalikan TrustedProxies sebagai jaringan IP
+func (c *ServerConfig) TrustedProxyNetworks() []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(c.TrustedProxies))
+	for _, entry := range c.TrustedProxies {
+		if network, err := parseNetwork(entry); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// parseTrustedProxies memvalidasi daftar IP atau CIDR proxy tepercaya yang dipisahkan koma
+func parseTrustedProxies(value string) ([]string, error) {
+	var proxies []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := parseNetwork(entry); err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q", entry)
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
+
+// parseNetwork mengurai CIDR, atau satu alamat IP sebagai jaringan berisi alamat itu saja
+func parseNetwork(entry string) (*net.IPNet, error) {
+	if ip := net.ParseIP(entry); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, network, err := net.ParseCIDR(entry)
+	return network, err
+}
+
 // Helper untuk mendapatkan nilai environment variable dengan default value
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -199,4 +717,14 @@ func getEnv(key, defaultValue string) string {
 		return defaultValue
 	}
 	return value
-}
\ No newline at end of file
+}
+
+// deriveKey menurunkan kunci HMAC terpisah dari secret dengan HKDF-SHA256 dan label tetap,
+// sehingga satu secret tidak dipakai langsung untuk beberapa keperluan
+func deriveKey(secret, label string) (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte(label)), key); err != nil {
+		return "", fmt.Errorf("failed to derive key for %s: %w", label, err)
+	}
+	return hex.EncodeToString(key), nil
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler menangani request personal access token (API key)
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+	validator     *validator.Validate
+}
+
+// NewAPIKeyHandler membuat instance baru APIKeyHandler
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     validator.New(),
+	}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List the current user's API keys
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.APIKeyResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		response := model.Error500("Failed to get API keys")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(keys, "API keys retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create a new API key scoped to a subset of the current user's permissions. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAPIKeyRequest true "Create API key request"
+// @Success 201 {object} model.APIKeyCreatedResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+
+	created, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		h.writeError(c, err, "Failed to create API key")
+		return
+	}
+
+	response := model.Success201(created, "API key created successfully. Store the key now, it will not be shown again")
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetAPIKey godoc
+// @Summary Get API key
+// @Description Get details of one of the current user's API keys
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} model.APIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid API key ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	key, err := h.apiKeyService.GetAPIKey(c.Request.Context(), userID.(uuid.UUID), keyID)
+	if err != nil {
+		h.writeError(c, err, "Failed to get API key")
+		return
+	}
+
+	response := model.Success200(key, "API key retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateAPIKey godoc
+// @Summary Update API key
+// @Description Rename an API key or change its scopes
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body model.UpdateAPIKeyRequest true "Update API key request"
+// @Success 200 {object} model.APIKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys/{id} [put]
+func (h *APIKeyHandler) UpdateAPIKey(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid API key ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Parse request body
+	var req model.UpdateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	if req.Name != nil {
+		name := utils.SanitizeInput(strings.TrimSpace(*req.Name))
+		req.Name = &name
+	}
+
+	key, err := h.apiKeyService.UpdateAPIKey(c.Request.Context(), userID.(uuid.UUID), keyID, &req)
+	if err != nil {
+		h.writeError(c, err, "Failed to update API key")
+		return
+	}
+
+	response := model.Success200(key, "API key updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke one of the current user's API keys
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid API key ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), userID.(uuid.UUID), keyID, auditActorFromContext(c)); err != nil {
+		h.writeError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	response := model.Success200(nil, "API key revoked successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// writeError memetakan error service ke response standar
+func (h *APIKeyHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrAPIKeyNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("API key not found"))
+	case service.ErrInvalidAPIKey:
+		c.JSON(http.StatusBadRequest, model.Error400("API key has been revoked"))
+	case service.ErrInvalidScope:
+		c.JSON(http.StatusBadRequest, model.Error400("Scopes must be a non-empty subset of your permissions"))
+	case service.ErrInvalidAPIKeyExpiry:
+		c.JSON(http.StatusBadRequest, model.Error400("API key expiry must be in the future"))
+	case service.ErrAPIKeyLimitReached:
+		c.JSON(http.StatusConflict, model.Error409("Maximum number of active API keys reached"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500(fallback))
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk APIKeyHandler
+func (h *APIKeyHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// API key hanya dapat dikelola dari sesi login user, bukan dengan API key lain
+	apiKeys := router.Group("/api/v1/auth/api-keys")
+	apiKeys.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware())
+	{
+		apiKeys.GET("", h.ListAPIKeys)         // GET /api/v1/auth/api-keys
+		apiKeys.POST("", h.CreateAPIKey)       // POST /api/v1/auth/api-keys
+		apiKeys.GET("/:id", h.GetAPIKey)       // GET /api/v1/auth/api-keys/:id
+		apiKeys.PUT("/:id", h.UpdateAPIKey)    // PUT /api/v1/auth/api-keys/:id
+		apiKeys.DELETE("/:id", h.RevokeAPIKey) // DELETE /api/v1/auth/api-keys/:id
+	}
+}
@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// AuditHandler menangani request query dan export audit log
+type AuditHandler struct {
+	auditService service.AuditService
+	validator    *validator.Validate
+}
+
+// NewAuditHandler membuat instance baru AuditHandler
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		validator:    validator.New(),
+	}
+}
+
+// GetAuditLogs godoc
+// @Summary List audit log
+// @Description Query the append-only audit log of security and admin events, newest first
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param user_id query string false "Filter by affected user ID"
+// @Param actor_id query string false "Filter by actor user ID"
+// @Param actor_type query string false "Filter by actor type" Enums(user, client, system)
+// @Param action query string false "Filter by action, use a trailing * for a prefix match (e.g. user.*)"
+// @Param resource query string false "Filter by resource (user, session, token, api_key, role, permission)"
+// @Param resource_id query string false "Filter by resource ID"
+// @Param ip_address query string false "Filter by actor IP address"
+// @Param from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Created at or before (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} model.AuditLogsListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit [get]
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.auditService.GetAuditLogs(c.Request.Context(), filter)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get audit log", filter.Page, filter.Limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Entries, "Audit log retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportAuditLogs godoc
+// @Summary Export audit log
+// @Description Export all audit log entries matching the same filters as the list endpoint in chronological order as CSV or NDJSON
+// @Tags audit
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Param user_id query string false "Filter by affected user ID"
+// @Param actor_id query string false "Filter by actor user ID"
+// @Param actor_type query string false "Filter by actor type" Enums(user, client, system)
+// @Param action query string false "Filter by action, use a trailing * for a prefix match (e.g. user.*)"
+// @Param resource query string false "Filter by resource"
+// @Param resource_id query string false "Filter by resource ID"
+// @Param ip_address query string false "Filter by actor IP address"
+// @Param from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Created at or before (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit/export [get]
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", model.AuditExportFormatCSV))
+	if format != model.AuditExportFormatCSV && format != model.AuditExportFormatJSON {
+		response := model.Error400("Unsupported export format, use csv or ndjson")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Filter sama dengan GET /api/v1/audit
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	contentType := "text/csv; charset=utf-8"
+	if format == model.AuditExportFormatJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	// Header response baru dikirim saat batch pertama ditulis, sehingga error sebelum itu
+	// masih dapat dikembalikan sebagai JSON
+	started := false
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+
+	err = h.auditService.ExportAuditLogs(c.Request.Context(), filter, func(entries []model.AuditLog) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+			c.Status(http.StatusOK)
+			if format == model.AuditExportFormatCSV {
+				if err := csvWriter.Write(auditExportCSVHeader); err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := range entries {
+			if format == model.AuditExportFormatCSV {
+				if err := csvWriter.Write(auditExportCSVRecord(&entries[i])); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := encoder.Encode(&entries[i]); err != nil {
+				return err
+			}
+		}
+
+		if format == model.AuditExportFormatCSV {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err != nil {
+		if started {
+			// Response sudah terkirim sebagian, koneksi diputus agar klien tahu export tidak lengkap
+			log.Printf("Audit log export aborted: %v", err)
+			c.Abort()
+			return
+		}
+		response := model.Error500("Failed to export audit log")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	// Tidak ada entry yang cocok, tetap kirim file kosong (dengan header kolom untuk CSV)
+	if !started {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+		c.Status(http.StatusOK)
+		if format == model.AuditExportFormatCSV {
+			csvWriter.Write(auditExportCSVHeader)
+			csvWriter.Flush()
+		}
+	}
+}
+
+// VerifyAuditChain godoc
+// @Summary Verify audit log integrity
+// @Description Walk the audit log hash chain from the first entry, check every link and signed checkpoint, and report the first broken link
+// @Tags audit
+// @Produce json
+// @Success 200 {object} model.AuditVerifyResult
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	result, err := h.auditService.VerifyChain(c.Request.Context())
+	if err != nil {
+		response := model.Error500("Failed to verify audit log")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	message := "Audit log chain is intact"
+	if !result.Valid {
+		message = "Audit log chain is broken"
+	}
+	response := model.Success200(result, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAuditCheckpoints godoc
+// @Summary List audit log checkpoints
+// @Description Get all signed checkpoints of the audit log hash chain
+// @Tags audit
+// @Produce json
+// @Success 200 {array} model.AuditCheckpoint
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /audit/checkpoints [get]
+func (h *AuditHandler) GetAuditCheckpoints(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	checkpoints, err := h.auditService.GetCheckpoints(c.Request.Context())
+	if err != nil {
+		response := model.Error500("Failed to get audit checkpoints")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(checkpoints, "Audit checkpoints retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// parseAuditFilter membaca filter audit log dari query parameter
+func parseAuditFilter(c *gin.Context) (*model.AuditFilter, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := &model.AuditFilter{
+		ActorType:  strings.TrimSpace(c.Query("actor_type")),
+		Action:     strings.TrimSpace(c.Query("action")),
+		Resource:   strings.TrimSpace(c.Query("resource")),
+		ResourceID: strings.TrimSpace(c.Query("resource_id")),
+		IPAddress:  strings.TrimSpace(c.Query("ip_address")),
+		Page:       page,
+		Limit:      limit,
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid user_id")
+		}
+		filter.UserID = &id
+	}
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid actor_id")
+		}
+		filter.ActorID = &id
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from", false); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeQuery(c, "to", true); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// auditExportCSVHeader adalah kolom pada file export audit log CSV
+var auditExportCSVHeader = []string{"id", "created_at", "action", "resource", "resource_id", "user_id", "actor_type", "actor_id", "actor", "ip_address", "user_agent", "details"}
+
+// auditExportCSVRecord mengubah audit log menjadi satu baris CSV sesuai auditExportCSVHeader
+func auditExportCSVRecord(entry *model.AuditLog) []string {
+	userID := ""
+	if entry.UserID != nil {
+		userID = entry.UserID.String()
+	}
+	actorID := ""
+	if entry.ActorID != nil {
+		actorID = entry.ActorID.String()
+	}
+
+	return []string{
+		entry.ID.String(),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		entry.Action,
+		entry.Resource,
+		entry.ResourceID,
+		userID,
+		entry.ActorType,
+		actorID,
+		entry.Actor,
+		entry.IPAddress,
+		entry.UserAgent,
+		entry.Details,
+	}
+}
+
+// auditActorFromContext membuat AuditActor dari data autentikasi di konteks request
+func auditActorFromContext(c *gin.Context) *model.AuditActor {
+	actor := &model.AuditActor{
+		Type:      model.AuditActorUser,
+		IP:        utils.GetClientIP(c),
+		UserAgent: utils.GetUserAgent(c),
+	}
+
+	if subjectType, _ := c.Get("subject_type"); subjectType == utils.SubjectTypeClient {
+		actor.Type = model.AuditActorClient
+		if clientID, ok := c.Get("client_id"); ok {
+			actor.Name, _ = clientID.(string)
+		}
+		return actor
+	}
+
+	if userID, ok := c.Get("user_id"); ok {
+		if id, ok := userID.(uuid.UUID); ok {
+			actor.ID = &id
+		}
+	}
+	if email, ok := c.Get("user_email"); ok {
+		actor.Name, _ = email.(string)
+	}
+
+	return actor
+}
+
+// RegisterRoutes mendaftarkan rute untuk AuditHandler
+func (h *AuditHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	audit := router.Group("/api/v1/audit")
+	audit.Use(authMiddleware)
+	{
+		audit.GET("", middleware.RequireScope("audit:read"), h.GetAuditLogs)                    // GET /api/v1/audit
+		audit.GET("/export", middleware.RequireScope("audit:read"), h.ExportAuditLogs)          // GET /api/v1/audit/export
+		audit.GET("/verify", middleware.RequireScope("audit:read"), h.VerifyAuditChain)         // GET /api/v1/audit/verify
+		audit.GET("/checkpoints", middleware.RequireScope("audit:read"), h.GetAuditCheckpoints) // GET /api/v1/audit/checkpoints
+	}
+}
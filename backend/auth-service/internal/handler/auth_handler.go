@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/auth-service/internal/middleware"
 	"github.com/auth-service/internal/model"
 	"github.com/auth-service/internal/service"
 	"github.com/auth-service/internal/utils"
@@ -80,12 +84,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Dapatkan informasi klien
-	clientInfo := &service.ClientInfo{
-		IP:        utils.GetClientIP(c),
-		UserAgent: utils.GetUserAgent(c),
-		Country:   c.Request.Header.Get("CF-IPCountry"), // Cloudflare header, bisa disesuaikan
-		City:      "",                                   // Bisa diisi dari layanan geolokasi
-	}
+	clientInfo := clientInfoFromRequest(c)
 
 	// Process registration with pointer to request
 	user, err := h.authService.Register(c.Request.Context(), &req, clientInfo)
@@ -120,6 +119,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Produce json
 // @Param request body model.LoginRequest true "Login request"
 // @Success 200 {object} model.TokenResponse
+// @Success 202 {object} model.LoginChallengeResponse "Risky login, verification code sent to email"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	// Set header keamanan
@@ -144,16 +144,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	req.Email = strings.TrimSpace(req.Email)
 
 	// Dapatkan informasi klien
-	clientInfo := &service.ClientInfo{
-		IP:        utils.GetClientIP(c),
-		UserAgent: utils.GetUserAgent(c),
-		Country:   c.Request.Header.Get("CF-IPCountry"), // Cloudflare header, bisa disesuaikan
-		City:      "",                                   // Bisa diisi dari layanan geolokasi
-	}
+	clientInfo := clientInfoFromRequest(c)
 
 	// Proses login
 	tokenResponse, err := h.authService.Login(c.Request.Context(), &req, clientInfo)
 	if err != nil {
+		// Login berisiko menunggu kode verifikasi yang dikirim ke email
+		var stepUp *service.StepUpRequiredError
+		if errors.As(err, &stepUp) {
+			response := model.NewSuccessResponse(http.StatusAccepted, stepUp.Challenge, "Verification code sent to your email")
+			c.JSON(http.StatusAccepted, response)
+			return
+		}
+
 		var response model.StandardResponse
 		switch err {
 		case service.ErrInvalidCredentials:
@@ -165,6 +168,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		case service.ErrUserInactive:
 			response = model.Error403("User account is inactive")
 			c.JSON(http.StatusForbidden, response)
+		case service.ErrPasswordResetRequired:
+			response = model.Error403("Password reset required. Use the password reset token to set a new password")
+			c.JSON(http.StatusForbidden, response)
 		case service.ErrRateLimitExceeded:
 			response = model.Error429("Rate limit exceeded. Please try again later")
 			c.JSON(http.StatusTooManyRequests, response)
@@ -183,6 +189,76 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// VerifyLogin godoc
+// @Summary Verify risky login
+// @Description Complete a login that requires step-up verification with the code sent to the user's email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyLoginRequest true "Verify login request"
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/login/verify [post]
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Parse request body
+	var req model.VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.ChallengeToken = strings.TrimSpace(req.ChallengeToken)
+	req.Code = strings.TrimSpace(req.Code)
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Dapatkan informasi klien
+	clientInfo := clientInfoFromRequest(c)
+
+	tokenResponse, err := h.authService.VerifyLoginChallenge(c.Request.Context(), &req, clientInfo)
+	if err != nil {
+		var response model.StandardResponse
+		switch err {
+		case service.ErrInvalidLoginChallenge:
+			response = model.Error401("Invalid or expired login challenge")
+			c.JSON(http.StatusUnauthorized, response)
+		case service.ErrInvalidVerifyCode:
+			response = model.Error401("Invalid verification code")
+			c.JSON(http.StatusUnauthorized, response)
+		case service.ErrAccountLocked:
+			response = model.Error403("Account is locked due to too many failed login attempts")
+			c.JSON(http.StatusForbidden, response)
+		case service.ErrUserInactive:
+			response = model.Error403("User account is inactive")
+			c.JSON(http.StatusForbidden, response)
+		default:
+			response = model.Error500("Failed to verify login")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	// Set cookies untuk access token dan refresh token
+	utils.SetCookie(c, "access_token", tokenResponse.AccessToken, 60*60*24, "/", c.Request.TLS != nil, true)     // 1 day
+	utils.SetCookie(c, "refresh_token", tokenResponse.RefreshToken, 60*60*24*7, "/", c.Request.TLS != nil, true) // 7 days
+
+	response := model.Success200(tokenResponse, "Login successful")
+	c.JSON(http.StatusOK, response)
+}
+
 // RefreshToken godoc
 // @Summary Refresh access token
 // @Description Get new access token using refresh token
@@ -285,7 +361,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	}
 
 	// Proses logout
-	err := h.authService.Logout(c.Request.Context(), userID.(uuid.UUID), req.RefreshToken)
+	err := h.authService.Logout(c.Request.Context(), userID.(uuid.UUID), req.RefreshToken, auditActorFromContext(c))
 	if err != nil {
 		// Log error untuk debugging
 		log.Printf("Logout failed for user %s: %v", userID.(uuid.UUID).String(), err)
@@ -337,6 +413,243 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// AcceptInvite godoc
+// @Summary Accept invite
+// @Description Set the password using the one-time token emailed when an admin creates a user with send_invite or forces a password reset. /auth/invite/accept is a deprecated alias; self-service invitations use /auth/invitations/accept.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.AcceptInviteRequest true "Accept invite request"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/invite/accept [post]
+// @Router /auth/password/setup [post]
+func (h *AuthHandler) AcceptInvite(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Parse request body
+	var req model.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi password
+	if !utils.IsStrongPassword(req.Password, 8) {
+		response := model.Error400("Password must be at least 8 characters and include uppercase, lowercase, number, and special character")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	user, err := h.authService.AcceptInvite(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidInviteToken:
+			response := model.Error400("Invalid or expired invite token")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrPasswordTooWeak:
+			response := model.Error400("Password is too weak")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to accept invite")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(user, "Invite accepted successfully. You can now log in")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetInvitation godoc
+// @Summary Verify invitation
+// @Description Validate an invitation token and return the invited email, name and role
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token query string true "Invitation token"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/invitations/verify [get]
+func (h *AuthHandler) GetInvitation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	token := c.Query("token")
+	if token == "" {
+		response := model.Error400("Invitation token is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	invitation, err := h.authService.GetInvitation(c.Request.Context(), token)
+	if err != nil {
+		respondInvitationError(c, err)
+		return
+	}
+
+	response := model.Success200(invitation, "Invitation is valid")
+	c.JSON(http.StatusOK, response)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept invitation
+// @Description Create the invited account with a password. The account gets the role assigned in the invitation.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.AcceptInvitationRequest true "Accept invitation request"
+// @Success 201 {object} model.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/invitations/accept [post]
+func (h *AuthHandler) AcceptInvitation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Parse request body
+	var req model.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+
+	// Validasi password
+	if !utils.IsStrongPassword(req.Password, 8) {
+		response := model.Error400("Password must be at least 8 characters and include uppercase, lowercase, number, and special character")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Dapatkan informasi klien
+	clientInfo := clientInfoFromRequest(c)
+
+	user, err := h.authService.AcceptInvitation(c.Request.Context(), &req, clientInfo)
+	if err != nil {
+		respondInvitationError(c, err)
+		return
+	}
+
+	response := model.Success201(user, "Invitation accepted successfully. You can now log in")
+	c.JSON(http.StatusCreated, response)
+}
+
+// GoogleInvitationLogin godoc
+// @Summary Accept invitation with Google
+// @Description Validate the invitation and redirect to Google. The callback creates the invited account linked to the Google account, whose email must match the invitation.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token query string true "Invitation token"
+// @Param redirect_url query string false "URL to redirect after successful login"
+// @Success 307 {string} string "Redirect to Google"
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/invitations/google [get]
+func (h *AuthHandler) GoogleInvitationLogin(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	token := c.Query("token")
+	if token == "" {
+		response := model.Error400("Invitation token is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	url, err := h.authService.GetGoogleAuthURLForInvitation(c.Request.Context(), token, c.Query("redirect_url"))
+	if err != nil {
+		respondInvitationError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// clientInfoFromRequest mengumpulkan informasi klien untuk riwayat login dan penilaian risiko.
+// Lokasi diambil dari header visitor location Cloudflare (CF-IPCountry, CF-IPCity,
+// CF-IPLatitude, CF-IPLongitude) dan kosong jika tidak tersedia. Header tersebut sudah
+// dihapus oleh CDNGeoHeadersMiddleware jika request tidak datang dari proxy tepercaya.
+func clientInfoFromRequest(c *gin.Context) *service.ClientInfo {
+	clientInfo := &service.ClientInfo{
+		IP:        utils.GetClientIP(c),
+		UserAgent: utils.GetUserAgent(c),
+		Country:   strings.TrimSpace(c.Request.Header.Get("CF-IPCountry")),
+		City:      strings.TrimSpace(c.Request.Header.Get("CF-IPCity")),
+	}
+
+	latitude, latErr := strconv.ParseFloat(strings.TrimSpace(c.Request.Header.Get("CF-IPLatitude")), 64)
+	longitude, lonErr := strconv.ParseFloat(strings.TrimSpace(c.Request.Header.Get("CF-IPLongitude")), 64)
+	if latErr == nil && lonErr == nil && latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180 {
+		clientInfo.Latitude = &latitude
+		clientInfo.Longitude = &longitude
+	}
+
+	return clientInfo
+}
+
+// respondInvitationError memetakan error penerimaan undangan ke respons HTTP
+func respondInvitationError(c *gin.Context, err error) {
+	var response model.StandardResponse
+	switch err {
+	case service.ErrInvalidInvitation:
+		response = model.Error400("Invalid, expired or revoked invitation")
+		c.JSON(http.StatusBadRequest, response)
+	case service.ErrInvitationAccepted:
+		response = model.Error409("Invitation has already been accepted")
+		c.JSON(http.StatusConflict, response)
+	case service.ErrUserAlreadyExists:
+		response = model.Error409("Email already registered")
+		c.JSON(http.StatusConflict, response)
+	case service.ErrInviteEmailMismatch:
+		response = model.Error403("Google account email does not match the invitation")
+		c.JSON(http.StatusForbidden, response)
+	case service.ErrNameRequired:
+		response = model.Error400("Name is required")
+		c.JSON(http.StatusBadRequest, response)
+	case service.ErrPasswordTooWeak:
+		response = model.Error400("Password is too weak")
+		c.JSON(http.StatusBadRequest, response)
+	case service.ErrRateLimitExceeded:
+		response = model.Error429("Rate limit exceeded. Please try again later")
+		c.JSON(http.StatusTooManyRequests, response)
+	case service.ErrGoogleAuthFailed:
+		response = model.Error400("Google authentication failed")
+		c.JSON(http.StatusBadRequest, response)
+	case service.ErrUserInactive:
+		response = model.Error403("User account is inactive")
+		c.JSON(http.StatusForbidden, response)
+	default:
+		response = model.Error500("Failed to accept invitation")
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
 // GoogleLogin godoc
 // @Summary Login with Google
 // @Description Redirect to Google OAuth login page
@@ -369,9 +682,11 @@ func (h *AuthHandler) GoogleLogin(c *gin.Context) {
 // @Param code query string true "Authorization code from Google"
 // @Param state query string false "State parameter for CSRF protection"
 // @Success 200 {object} model.TokenResponse
-// @Success 307 {string} string "Redirect to frontend with token"
+// @Success 202 {object} model.LoginChallengeResponse "Risky login, verification code sent to email"
+// @Success 307 {string} string "Redirect to frontend with token or step-up challenge"
 // @Failure 400 {object} map[string]string
 // @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /auth/google/callback [get]
 func (h *AuthHandler) GoogleCallback(c *gin.Context) {
@@ -392,12 +707,7 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 	}
 
 	// Dapatkan informasi klien
-	clientInfo := &service.ClientInfo{
-		IP:        utils.GetClientIP(c),
-		UserAgent: utils.GetUserAgent(c),
-		Country:   c.Request.Header.Get("CF-IPCountry"), // Cloudflare header, bisa disesuaikan
-		City:      "",                                   // Bisa diisi dari layanan geolokasi
-	}
+	clientInfo := clientInfoFromRequest(c)
 
 	// Proses callback
 	// Tambahkan state ke clientInfo untuk validasi
@@ -408,11 +718,45 @@ func (h *AuthHandler) GoogleCallback(c *gin.Context) {
 	// Log untuk debugging
 	fmt.Printf("Calling HandleGoogleCallback with code length: %d\n", len(code))
 
-	tokenResponse, err := h.authService.HandleGoogleCallback(c.Request.Context(), code, clientInfo)
+	// Login Google yang dimulai dari undangan membuat akun dari undangan tersebut
+	invitationToken := h.authService.GetInvitationTokenFromState(state)
+
+	var tokenResponse *model.TokenResponse
+	var err error
+	if invitationToken != "" {
+		tokenResponse, err = h.authService.HandleGoogleInvitationCallback(c.Request.Context(), code, invitationToken, clientInfo)
+	} else {
+		tokenResponse, err = h.authService.HandleGoogleCallback(c.Request.Context(), code, clientInfo)
+	}
 	if err != nil {
 		// Log error untuk debugging
 		fmt.Printf("Google callback error: %v\n", err)
 
+		if invitationToken != "" {
+			respondInvitationError(c, err)
+			return
+		}
+
+		// Login berisiko menunggu kode verifikasi; frontend menampilkan form kode lalu
+		// memanggil /auth/login/verify dengan challenge token
+		var stepUp *service.StepUpRequiredError
+		if errors.As(err, &stepUp) {
+			if redirectURL := h.authService.GetRedirectURLFromState(state); redirectURL != "" {
+				separator := "?"
+				if strings.Contains(redirectURL, "?") {
+					separator = "&"
+				}
+				finalURL := fmt.Sprintf("%s%sstep_up_required=true&challenge_token=%s&expires_at=%s",
+					redirectURL, separator, url.QueryEscape(stepUp.Challenge.ChallengeToken), url.QueryEscape(stepUp.Challenge.ExpiresAt.Format(time.RFC3339)))
+				c.Redirect(http.StatusTemporaryRedirect, finalURL)
+				return
+			}
+
+			response := model.NewSuccessResponse(http.StatusAccepted, stepUp.Challenge, "Verification code sent to your email")
+			c.JSON(http.StatusAccepted, response)
+			return
+		}
+
 		var response model.StandardResponse
 		switch err {
 		case service.ErrGoogleAuthFailed:
@@ -496,6 +840,16 @@ func (h *AuthHandler) GetLoginHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// deprecatedAlias menandai rute lama yang masih dilayani sebagai deprecated (RFC 8594) dan
+// menunjuk rute penggantinya melalui header Link
+func deprecatedAlias(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
+
 // RegisterRoutes mendaftarkan semua rute autentikasi
 func (h *AuthHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
 	// Public routes (tidak memerlukan autentikasi)
@@ -503,14 +857,20 @@ func (h *AuthHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.Hand
 	{
 		public.POST("/register", h.Register)
 		public.POST("/login", h.Login)
+		public.POST("/login/verify", h.VerifyLogin)
 		public.POST("/refresh", h.RefreshToken)
+		public.POST("/password/setup", h.AcceptInvite)                                                // token dari user yang dibuat admin dan reset password paksa
+		public.POST("/invite/accept", deprecatedAlias("/api/v1/auth/password/setup"), h.AcceptInvite) // deprecated, gunakan /password/setup
 		public.GET("/google/login", h.GoogleLogin)
 		public.GET("/google/callback", h.GoogleCallback)
+		public.GET("/invitations/verify", h.GetInvitation)
+		public.POST("/invitations/accept", h.AcceptInvitation)
+		public.GET("/invitations/google", h.GoogleInvitationLogin)
 	}
 
 	// Protected routes (memerlukan autentikasi)
 	protected := router.Group("/api/v1/auth")
-	protected.Use(authMiddleware)
+	protected.Use(authMiddleware, middleware.UserOnlyMiddleware())
 	{
 		protected.GET("/me", h.GetMe)
 		protected.POST("/logout", h.Logout)
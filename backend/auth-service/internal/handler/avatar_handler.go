@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxAvatarRequestSize membatasi ukuran body request upload avatar (20 MB).
+// Batas ukuran file avatar yang sebenarnya diatur oleh AVATAR_MAX_SIZE.
+const maxAvatarRequestSize = 20 << 20
+
+// AvatarHandler menangani request upload dan penyajian avatar user
+type AvatarHandler struct {
+	avatarService service.AvatarService
+}
+
+// NewAvatarHandler membuat instance baru AvatarHandler
+func NewAvatarHandler(avatarService service.AvatarService) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+	}
+}
+
+// UploadAvatar godoc
+// @Summary Upload avatar
+// @Description Upload a JPEG, PNG or GIF avatar for the currently authenticated user. The type is detected from the file content and the image is cropped and resized to a fixed square size.
+// @Tags auth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Avatar image"
+// @Success 200 {object} model.AvatarResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 415 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/upload-avatar [post]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAvatarRequestSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "Avatar file is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+			return
+		}
+		response := model.Error400("File is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response := model.Error400("Failed to read file")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.avatarService.UploadAvatar(c.Request.Context(), userID.(uuid.UUID), file)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrAvatarTooLarge:
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "Avatar file is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+		case service.ErrUnsupportedImage:
+			response := model.NewErrorResponse(http.StatusUnsupportedMediaType, "Unsupported image type, use JPEG, PNG or GIF")
+			c.JSON(http.StatusUnsupportedMediaType, response)
+		case service.ErrInvalidImage:
+			response := model.Error400("Invalid image or image dimensions too large")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrRateLimitExceeded:
+			response := model.Error429("Too many avatar uploads. Please try again later")
+			c.JSON(http.StatusTooManyRequests, response)
+		default:
+			response := model.Error500("Failed to upload avatar")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(result, "Avatar uploaded successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteAvatar godoc
+// @Summary Delete avatar
+// @Description Delete the uploaded avatar of the currently authenticated user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.UserResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	user, err := h.avatarService.DeleteAvatar(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrAvatarNotFound:
+			response := model.Error404("No uploaded avatar")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to delete avatar")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(user, "Avatar deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAvatar godoc
+// @Summary Get avatar
+// @Description Redirect to a short-lived signed URL of the uploaded avatar of a user. This is the stable URL stored as profile_picture.
+// @Tags auth
+// @Param id path string true "User ID"
+// @Success 302
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /avatars/{id} [get]
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Parse user ID dari URL
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	signedURL, err := h.avatarService.GetAvatarURL(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case service.ErrAvatarNotFound:
+			response := model.Error404("Avatar not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to get avatar")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	// Redirect boleh di-cache sebentar, jauh sebelum signed URL kedaluwarsa
+	c.Header("Cache-Control", "private, max-age=60")
+	c.Redirect(http.StatusFound, signedURL)
+}
+
+// RegisterRoutes mendaftarkan rute untuk AvatarHandler
+func (h *AvatarHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// Public routes, dipakai langsung sebagai src gambar
+	router.GET("/api/v1/avatars/:id", h.GetAvatar)
+
+	// Protected routes, API key tidak dapat mengubah avatar
+	protected := router.Group("/api/v1/auth")
+	protected.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware())
+	{
+		protected.POST("/upload-avatar", h.UploadAvatar)
+		protected.DELETE("/avatar", h.DeleteAvatar)
+	}
+}
@@ -0,0 +1,598 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// chatMaxFrameBytes membatasi ukuran frame WebSocket dari client
+const chatMaxFrameBytes = 64 << 10
+
+// chatReplyBuffer adalah jumlah event error yang dapat menunggu dikirim ke client
+const chatReplyBuffer = 16
+
+// ChatHandler menangani request percakapan chat dan koneksi WebSocket chat
+type ChatHandler struct {
+	chatService service.ChatService
+	validator   *validator.Validate
+}
+
+// NewChatHandler membuat instance baru ChatHandler
+func NewChatHandler(chatService service.ChatService) *ChatHandler {
+	return &ChatHandler{
+		chatService: chatService,
+		validator:   validator.New(),
+	}
+}
+
+// GetConversations godoc
+// @Summary List chat conversations
+// @Description Get the current user's conversations, most recently active first, with the other participant, last message and unread count
+// @Tags chat
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} model.ChatConversationsListResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations [get]
+func (h *ChatHandler) GetConversations(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.chatService.ListConversations(c.Request.Context(), userID.(uuid.UUID), page, limit)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get conversations", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Conversations, "Conversations retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// StartConversation godoc
+// @Summary Start chat conversation
+// @Description Get the 1:1 conversation with another active user, creating it if it does not exist yet
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param request body model.StartConversationRequest true "Conversation peer"
+// @Success 200 {object} model.ChatConversationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations [post]
+func (h *ChatHandler) StartConversation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	var req model.StartConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	conversation, err := h.chatService.StartConversation(c.Request.Context(), userID.(uuid.UUID), req.UserID)
+	if err != nil {
+		h.writeChatError(c, err, "Failed to start conversation")
+		return
+	}
+
+	response := model.Success200(conversation, "Conversation retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetConversation godoc
+// @Summary Get chat conversation
+// @Description Get one of the current user's conversations
+// @Tags chat
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} model.ChatConversationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations/{id} [get]
+func (h *ChatHandler) GetConversation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid conversation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	conversation, err := h.chatService.GetConversation(c.Request.Context(), userID.(uuid.UUID), id)
+	if err != nil {
+		h.writeChatError(c, err, "Failed to get conversation")
+		return
+	}
+
+	response := model.Success200(conversation, "Conversation retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMessages godoc
+// @Summary List chat messages
+// @Description Get the message history of a conversation, newest first. Pass next_cursor of the previous page as before to load older messages.
+// @Tags chat
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param before query string false "Cursor from next_cursor of the previous page"
+// @Param limit query int false "Messages per page" default(50)
+// @Success 200 {object} model.ChatMessagesPageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations/{id}/messages [get]
+func (h *ChatHandler) GetMessages(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid conversation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	page, err := h.chatService.GetMessages(c.Request.Context(), userID.(uuid.UUID), id, strings.TrimSpace(c.Query("before")), limit)
+	if err != nil {
+		h.writeChatError(c, err, "Failed to get messages")
+		return
+	}
+
+	response := model.Success200(page, "Messages retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// SendMessage godoc
+// @Summary Send chat message
+// @Description Send a message to a conversation. The message is also delivered to both participants over WebSocket. Resending with the same client_message_id returns the stored message instead of creating a duplicate.
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param request body model.SendChatMessageRequest true "Message"
+// @Success 201 {object} model.ChatMessage
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations/{id}/messages [post]
+func (h *ChatHandler) SendMessage(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid conversation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req model.SendChatMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	message, err := h.chatService.SendMessage(c.Request.Context(), userID.(uuid.UUID), id, &req)
+	if err != nil {
+		h.writeChatError(c, err, "Failed to send message")
+		return
+	}
+
+	response := model.Success201(message, "Message sent successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// MarkDelivered godoc
+// @Summary Mark chat messages as delivered
+// @Description Mark every message to the current user in the conversation up to message_id as delivered
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param request body model.ChatReceiptRequest true "Last delivered message"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations/{id}/delivered [post]
+func (h *ChatHandler) MarkDelivered(c *gin.Context) {
+	h.markReceipt(c, h.chatService.MarkDelivered, "Messages marked as delivered")
+}
+
+// MarkRead godoc
+// @Summary Mark chat messages as read
+// @Description Mark every message to the current user in the conversation up to message_id as read
+// @Tags chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param request body model.ChatReceiptRequest true "Last read message"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/conversations/{id}/read [post]
+func (h *ChatHandler) MarkRead(c *gin.Context) {
+	h.markReceipt(c, h.chatService.MarkRead, "Messages marked as read")
+}
+
+// markReceipt menangani request delivery dan read receipt
+func (h *ChatHandler) markReceipt(c *gin.Context, mark func(ctx context.Context, userID, conversationID, messageID uuid.UUID) error, message string) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid conversation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	var req model.ChatReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := mark(c.Request.Context(), userID.(uuid.UUID), id, req.MessageID); err != nil {
+		h.writeChatError(c, err, "Failed to update messages")
+		return
+	}
+
+	response := model.Success200(nil, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchContacts godoc
+// @Summary Search chat contacts
+// @Description Search active users by name or email to start a conversation with, including their presence
+// @Tags chat
+// @Produce json
+// @Param search query string false "Search by name or email"
+// @Success 200 {array} model.ChatParticipant
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/contacts [get]
+func (h *ChatHandler) SearchContacts(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	contacts, err := h.chatService.SearchContacts(c.Request.Context(), userID.(uuid.UUID), strings.TrimSpace(c.Query("search")))
+	if err != nil {
+		response := model.Error500("Failed to search contacts")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(contacts, "Contacts retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// Connect godoc
+// @Summary Open chat WebSocket
+// @Description Upgrade to a WebSocket carrying JSON chat events. Authenticates like every other endpoint, so browsers can rely on the access_token cookie; browser origins must be listed in CORS_ALLOW_ORIGINS. The server sends "ready" first, then "message.new", "message.delivered", "message.read", "typing", "presence" and "error" events, and "sessions.revoked" right before closing the connection when the user's sessions are revoked. Clients send "message.send", "message.delivered", "message.read" and "typing" frames.
+// @Tags chat
+// @Success 101 {object} model.ChatEvent
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /chat/ws [get]
+func (h *ChatHandler) Connect(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	server := websocket.Server{
+		// Client non-browser tidak mengirim Origin dan tidak dapat membawa cookie user
+		// dari halaman lain, sehingga hanya origin browser yang diperiksa
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return nil
+			}
+			if !h.chatService.IsAllowedOrigin(origin) {
+				return fmt.Errorf("origin %q is not allowed", origin)
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			h.serveChat(ws, userID.(uuid.UUID))
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveChat menjalankan sesi chat pada koneksi WebSocket. Frame client dibaca pada goroutine
+// terpisah, sedangkan semua penulisan ke koneksi dilakukan oleh loop ini.
+func (h *ChatHandler) serveChat(ws *websocket.Conn, userID uuid.UUID) {
+	ctx := ws.Request().Context()
+	ws.MaxPayloadBytes = chatMaxFrameBytes
+	defer ws.Close()
+
+	session, err := h.chatService.Connect(ctx, userID)
+	if err != nil {
+		_ = websocket.JSON.Send(ws, newChatErrorEvent(nil, http.StatusServiceUnavailable, "Chat is not available"))
+		return
+	}
+	// Sesi ditutup dengan context baru karena context request sudah selesai saat client terputus
+	defer h.chatService.Disconnect(context.Background(), session)
+
+	ready, _ := model.NewChatEvent(model.ChatEventReady, &model.ChatReady{SessionID: session.ID, UserID: userID})
+	if err := websocket.JSON.Send(ws, ready); err != nil {
+		return
+	}
+
+	replies := make(chan *model.ChatEvent, chatReplyBuffer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.readChatFrames(ctx, ws, session, replies)
+	}()
+
+	ping := time.NewTicker(h.chatService.PingInterval())
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-session.Events:
+			if !ok {
+				return
+			}
+			// Daftar penerima hanya untuk fan-out antar instance
+			if err := websocket.JSON.Send(ws, &model.ChatEvent{Type: event.Type, Data: event.Data}); err != nil {
+				return
+			}
+		case reply := <-replies:
+			if err := websocket.JSON.Send(ws, reply); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := sendChatPing(ws); err != nil {
+				return
+			}
+			h.chatService.Touch(ctx, session)
+		}
+	}
+}
+
+// readChatFrames membaca frame client sampai koneksi ditutup. Frame yang ditolak dibalas
+// dengan event error tanpa menutup koneksi.
+func (h *ChatHandler) readChatFrames(ctx context.Context, ws *websocket.Conn, session *service.ChatSession, replies chan<- *model.ChatEvent) {
+	for {
+		var frame model.ChatClientEvent
+		err := websocket.JSON.Receive(ws, &frame)
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case err == nil:
+			if err := h.chatService.HandleClientEvent(ctx, session, &frame); err != nil {
+				status, message := chatErrorStatus(err, "Failed to process event")
+				sendChatReply(replies, newChatErrorEvent(&frame, status, message))
+			}
+		case errors.Is(err, websocket.ErrFrameTooLarge):
+			sendChatReply(replies, newChatErrorEvent(nil, http.StatusRequestEntityTooLarge, "Frame is too large"))
+		case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+			sendChatReply(replies, newChatErrorEvent(nil, http.StatusBadRequest, "Invalid frame format"))
+		default:
+			return
+		}
+	}
+}
+
+// sendChatReply mengantrikan event balasan tanpa menunggu. Balasan dibuang jika antrean
+// penuh karena client mengirim frame lebih cepat daripada yang dapat dibalas.
+func sendChatReply(replies chan<- *model.ChatEvent, event *model.ChatEvent) {
+	select {
+	case replies <- event:
+	default:
+	}
+}
+
+// sendChatPing mengirim frame ping agar koneksi yang mati terdeteksi dan proxy tidak
+// menutup koneksi yang sedang menganggur
+func sendChatPing(ws *websocket.Conn) error {
+	payloadType := ws.PayloadType
+	ws.PayloadType = websocket.PingFrame
+	_, err := ws.Write(nil)
+	ws.PayloadType = payloadType
+	return err
+}
+
+// newChatErrorEvent membuat event error untuk frame client yang ditolak
+func newChatErrorEvent(frame *model.ChatClientEvent, status int, message string) *model.ChatEvent {
+	data := &model.ChatError{Status: status, Message: message}
+	if frame != nil {
+		data.Event = frame.Type
+		data.ClientMessageID = frame.ClientMessageID
+		if frame.ConversationID != uuid.Nil {
+			conversationID := frame.ConversationID
+			data.ConversationID = &conversationID
+		}
+	}
+	event, _ := model.NewChatEvent(model.ChatEventError, data)
+	return event
+}
+
+// chatErrorStatus memetakan error service chat ke status dan pesan HTTP
+func chatErrorStatus(err error, fallback string) (int, string) {
+	switch err {
+	case service.ErrChatConversationNotFound:
+		return http.StatusNotFound, "Conversation not found"
+	case service.ErrChatMessageNotFound:
+		return http.StatusNotFound, "Message not found"
+	case service.ErrChatInvalidPeer:
+		return http.StatusBadRequest, "Cannot start a conversation with this user"
+	case service.ErrChatMessageEmpty:
+		return http.StatusBadRequest, "Message body is required"
+	case service.ErrChatMessageTooLong:
+		return http.StatusBadRequest, "Message body is too long"
+	case service.ErrChatInvalidMessageType:
+		return http.StatusBadRequest, "Message type must be text, image or file"
+	case service.ErrChatInvalidClientID:
+		return http.StatusBadRequest, "Client message ID must be at most 64 characters"
+	case service.ErrChatUnknownEvent:
+		return http.StatusBadRequest, "Unknown event type"
+	case service.ErrInvalidCursor:
+		return http.StatusBadRequest, "Invalid cursor"
+	case service.ErrRateLimitExceeded:
+		return http.StatusTooManyRequests, "Too many messages, please slow down"
+	default:
+		return http.StatusInternalServerError, fallback
+	}
+}
+
+// writeChatError memetakan error service chat ke response HTTP
+func (h *ChatHandler) writeChatError(c *gin.Context, err error, fallback string) {
+	status, message := chatErrorStatus(err, fallback)
+	c.JSON(status, model.NewErrorResponse(status, message))
+}
+
+// RegisterRoutes mendaftarkan rute untuk ChatHandler
+func (h *ChatHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// Chat antar user, tidak tersedia untuk service account maupun API key
+	chat := router.Group("/api/v1/chat")
+	chat.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware())
+	{
+		chat.GET("/ws", h.Connect)                                 // GET /api/v1/chat/ws
+		chat.GET("/contacts", h.SearchContacts)                    // GET /api/v1/chat/contacts
+		chat.GET("/conversations", h.GetConversations)             // GET /api/v1/chat/conversations
+		chat.POST("/conversations", h.StartConversation)           // POST /api/v1/chat/conversations
+		chat.GET("/conversations/:id", h.GetConversation)          // GET /api/v1/chat/conversations/:id
+		chat.GET("/conversations/:id/messages", h.GetMessages)     // GET /api/v1/chat/conversations/:id/messages
+		chat.POST("/conversations/:id/messages", h.SendMessage)    // POST /api/v1/chat/conversations/:id/messages
+		chat.POST("/conversations/:id/delivered", h.MarkDelivered) // POST /api/v1/chat/conversations/:id/delivered
+		chat.POST("/conversations/:id/read", h.MarkRead)           // POST /api/v1/chat/conversations/:id/read
+	}
+}
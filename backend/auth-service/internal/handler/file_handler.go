@@ -0,0 +1,1110 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// maxFileRequestSize membatasi ukuran body request upload file (512 MB).
+// Batas ukuran per file dan jumlah file diatur oleh FILE_MAX_SIZE dan FILE_MAX_UPLOAD_FILES.
+const maxFileRequestSize = 512 << 20
+
+// fileUploadMemory adalah bagian form multipart yang disimpan di memori; sisanya ditulis ke file sementara
+const fileUploadMemory = 8 << 20
+
+// FileHandler menangani request file, folder, dan statistik storage user
+type FileHandler struct {
+	fileService service.FileService
+	validator   *validator.Validate
+}
+
+// NewFileHandler membuat instance baru FileHandler
+func NewFileHandler(fileService service.FileService) *FileHandler {
+	return &FileHandler{
+		fileService: fileService,
+		validator:   validator.New(),
+	}
+}
+
+// UploadFile godoc
+// @Summary Upload file
+// @Description Upload a single file, optionally into a folder path. Missing folders are created.
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File"
+// @Param folder formData string false "Folder path, e.g. /documents/2024"
+// @Success 201 {object} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /upload [post]
+func (h *FileHandler) UploadFile(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	form, ok := parseUploadForm(c)
+	if !ok {
+		return
+	}
+	defer form.RemoveAll()
+
+	fileHeaders := form.File["file"]
+	if len(fileHeaders) != 1 {
+		response := model.Error400("Exactly one file is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	files, ok := h.uploadFiles(c, userID.(uuid.UUID), form, fileHeaders)
+	if !ok {
+		return
+	}
+
+	response := model.Success201(files[0], "File uploaded successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// UploadMultipleFiles godoc
+// @Summary Upload multiple files
+// @Description Upload several files at once (form fields files[0], files[1], ... or files), optionally into a folder path. The upload is all-or-nothing.
+// @Tags files
+// @Accept multipart/form-data
+// @Produce json
+// @Param files formData file true "Files"
+// @Param folder formData string false "Folder path, e.g. /documents/2024"
+// @Success 201 {array} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /upload-multiple [post]
+func (h *FileHandler) UploadMultipleFiles(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	form, ok := parseUploadForm(c)
+	if !ok {
+		return
+	}
+	defer form.RemoveAll()
+
+	// Frontend mengirim files[0], files[1], ...; field "files" berulang juga diterima
+	var fileHeaders []*multipart.FileHeader
+	fileHeaders = append(fileHeaders, form.File["files"]...)
+	for i := 0; ; i++ {
+		headers, found := form.File[fmt.Sprintf("files[%d]", i)]
+		if !found {
+			break
+		}
+		fileHeaders = append(fileHeaders, headers...)
+	}
+
+	if len(fileHeaders) == 0 {
+		response := model.Error400("At least one file is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	files, ok := h.uploadFiles(c, userID.(uuid.UUID), form, fileHeaders)
+	if !ok {
+		return
+	}
+
+	response := model.Success201(files, "Files uploaded successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// parseUploadForm membaca form multipart dengan batas ukuran body request
+func parseUploadForm(c *gin.Context) (*multipart.Form, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileRequestSize)
+
+	if err := c.Request.ParseMultipartForm(fileUploadMemory); err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "Upload is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+			return nil, false
+		}
+		response := model.Error400("Invalid multipart form")
+		c.JSON(http.StatusBadRequest, response)
+		return nil, false
+	}
+
+	return c.Request.MultipartForm, true
+}
+
+// uploadFiles membuka file dari form lalu menyimpannya melalui FileService
+func (h *FileHandler) uploadFiles(c *gin.Context, userID uuid.UUID, form *multipart.Form, fileHeaders []*multipart.FileHeader) ([]model.FileResponse, bool) {
+	var folder string
+	if values := form.Value["folder"]; len(values) > 0 {
+		folder = values[0]
+	}
+
+	uploads := make([]service.FileUpload, 0, len(fileHeaders))
+	for _, fileHeader := range fileHeaders {
+		file, err := fileHeader.Open()
+		if err != nil {
+			response := model.Error400("Failed to read file")
+			c.JSON(http.StatusBadRequest, response)
+			return nil, false
+		}
+		defer file.Close()
+
+		uploads = append(uploads, service.FileUpload{
+			Name:    fileHeader.Filename,
+			Size:    fileHeader.Size,
+			Content: file,
+		})
+	}
+
+	files, err := h.fileService.UploadFiles(c.Request.Context(), userID, folder, uploads)
+	if err != nil {
+		switch err {
+		case service.ErrFileAccessDenied:
+			response := model.Error403("You do not have permission to upload files")
+			c.JSON(http.StatusForbidden, response)
+		case service.ErrTooManyFiles:
+			response := model.Error400("Too many files in one upload")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrFileTooLarge:
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "File is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+		case service.ErrStorageQuotaExceeded:
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "Storage quota exceeded")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+		case service.ErrInvalidFileName:
+			response := model.Error400("Invalid file name")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrInvalidFolderPath:
+			response := model.Error400("Invalid folder path")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to upload files")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return nil, false
+	}
+
+	return files, true
+}
+
+// GetFiles godoc
+// @Summary List files
+// @Description List files of the currently authenticated user with filters and pagination
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param folder query string false "Folder path, / for root; all folders when empty"
+// @Param mimeType query string false "MIME type, e.g. application/pdf or image/*"
+// @Param search query string false "Search by file name"
+// @Param dateFrom query string false "Uploaded at or after (RFC3339 or YYYY-MM-DD)"
+// @Param dateTo query string false "Uploaded at or before (RFC3339 or YYYY-MM-DD)"
+// @Param sizeMin query int false "Minimum size in bytes"
+// @Param sizeMax query int false "Maximum size in bytes"
+// @Param sortBy query string false "Sort column" Enums(name, size, createdAt) default(createdAt)
+// @Param sortOrder query string false "Sort order" Enums(asc, desc) default(desc)
+// @Success 200 {object} model.FilesListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files [get]
+func (h *FileHandler) GetFiles(c *gin.Context) {
+	h.listFiles(c, c.Query("search"))
+}
+
+// SearchFiles godoc
+// @Summary Search files
+// @Description Search files of the currently authenticated user by name
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param folder query string false "Folder path, / for root; all folders when empty"
+// @Param mimeType query string false "MIME type, e.g. application/pdf or image/*"
+// @Param dateFrom query string false "Uploaded at or after (RFC3339 or YYYY-MM-DD)"
+// @Param dateTo query string false "Uploaded at or before (RFC3339 or YYYY-MM-DD)"
+// @Param sizeMin query int false "Minimum size in bytes"
+// @Param sizeMax query int false "Maximum size in bytes"
+// @Success 200 {object} model.FilesListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/search [get]
+func (h *FileHandler) SearchFiles(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		utils.SetSecureHeaders(c)
+		response := model.Error400("Search query is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	h.listFiles(c, query)
+}
+
+// listFiles menangani daftar dan pencarian file
+func (h *FileHandler) listFiles(c *gin.Context, search string) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse query parameters
+	filter, err := parseFileFilter(c, search)
+	if err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	filesResponse, err := h.fileService.GetFiles(c.Request.Context(), userID.(uuid.UUID), filter)
+	if err != nil {
+		switch err {
+		case service.ErrFileAccessDenied:
+			response := model.Error403("You do not have permission to read files")
+			c.JSON(http.StatusForbidden, response)
+		case service.ErrInvalidFolderPath:
+			response := model.PaginatedError400("Invalid folder path", filter.Page, filter.Limit)
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.PaginatedError500("Failed to get files", filter.Page, filter.Limit)
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	// Buat response dengan pagination
+	response := model.PaginatedSuccess200(filesResponse.Files, "Files retrieved successfully", filesResponse.Page, filesResponse.Limit, filesResponse.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// parseFileFilter membaca filter daftar file dari query parameter
+func parseFileFilter(c *gin.Context, search string) (*model.FileFilter, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := &model.FileFilter{
+		Folder:    strings.TrimSpace(c.Query("folder")),
+		MimeType:  strings.ToLower(strings.TrimSpace(c.Query("mimeType"))),
+		Search:    strings.TrimSpace(search),
+		SortBy:    strings.TrimSpace(c.Query("sortBy")),
+		SortOrder: strings.ToLower(strings.TrimSpace(c.Query("sortOrder"))),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	var err error
+	if filter.DateFrom, err = parseTimeQuery(c, "dateFrom", false); err != nil {
+		return filter, err
+	}
+	if filter.DateTo, err = parseTimeQuery(c, "dateTo", true); err != nil {
+		return filter, err
+	}
+	if filter.SizeMin, err = parseInt64Query(c, "sizeMin"); err != nil {
+		return filter, err
+	}
+	if filter.SizeMax, err = parseInt64Query(c, "sizeMax"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+// parseInt64Query membaca query parameter bilangan bulat opsional
+func parseInt64Query(c *gin.Context, key string) (*int64, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be an integer", key)
+	}
+
+	return &value, nil
+}
+
+// GetFile godoc
+// @Summary Get file
+// @Description Get file details, including a short-lived signed URL
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id} [get]
+func (h *FileHandler) GetFile(c *gin.Context) {
+	h.getFile(c, "File retrieved successfully")
+}
+
+// GetFileMetadata godoc
+// @Summary Get file metadata
+// @Description Get file metadata such as checksum, image dimensions and download statistics
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id}/metadata [get]
+func (h *FileHandler) GetFileMetadata(c *gin.Context) {
+	h.getFile(c, "File metadata retrieved successfully")
+}
+
+// getFile menangani detail dan metadata file
+func (h *FileHandler) getFile(c *gin.Context, message string) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.fileService.GetFile(c.Request.Context(), userID, fileID)
+	if err != nil {
+		respondFileError(c, err, "Failed to get file")
+		return
+	}
+
+	response := model.Success200(file, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetFileURL godoc
+// @Summary Get file URL
+// @Description Get a short-lived signed URL to download the file directly from storage
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} model.FileURLResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id}/url [get]
+func (h *FileHandler) GetFileURL(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	fileURL, err := h.fileService.GetFileURL(c.Request.Context(), userID, fileID)
+	if err != nil {
+		respondFileError(c, err, "Failed to get file URL")
+		return
+	}
+
+	response := model.Success200(fileURL, "File URL generated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadFile godoc
+// @Summary Download file
+// @Description Download the file content as an attachment
+// @Tags files
+// @Produce octet-stream
+// @Param id path string true "File ID"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id}/download [get]
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	file, content, err := h.fileService.DownloadFile(c.Request.Context(), userID, fileID)
+	if err != nil {
+		respondFileError(c, err, "Failed to download file")
+		return
+	}
+	defer content.Close()
+
+	// File selalu dikirim sebagai attachment agar isinya tidak dirender di origin service
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Length", strconv.FormatInt(file.Size, 10))
+	c.Header("Cache-Control", "private, no-store")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, content)
+}
+
+// RenameFile godoc
+// @Summary Rename file
+// @Description Change the display name of a file
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Param request body model.RenameRequest true "New name"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id}/rename [put]
+func (h *FileHandler) RenameFile(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	var req model.RenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	file, err := h.fileService.RenameFile(c.Request.Context(), userID, fileID, req.Name)
+	if err != nil {
+		respondFileError(c, err, "Failed to rename file")
+		return
+	}
+
+	response := model.Success200(file, "File renamed successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// MoveFile godoc
+// @Summary Move file
+// @Description Move a file to another folder path (/ for root). Missing folders are created.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Param request body model.MoveFileRequest true "Target folder"
+// @Success 200 {object} model.FileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id}/move [put]
+func (h *FileHandler) MoveFile(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	var req model.MoveFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	file, err := h.fileService.MoveFile(c.Request.Context(), userID, fileID, req.Folder)
+	if err != nil {
+		respondFileError(c, err, "Failed to move file")
+		return
+	}
+
+	response := model.Success200(file, "File moved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteFile godoc
+// @Summary Delete file
+// @Description Delete a file and release its storage quota
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/{id} [delete]
+func (h *FileHandler) DeleteFile(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, fileID, ok := fileRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.fileService.DeleteFiles(c.Request.Context(), userID, []uuid.UUID{fileID}); err != nil {
+		respondFileError(c, err, "Failed to delete file")
+		return
+	}
+
+	response := model.Success200(nil, "File deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteMultipleFiles godoc
+// @Summary Delete multiple files
+// @Description Delete several files at once. Nothing is deleted if any of the files is not found.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body model.DeleteFilesRequest true "File IDs"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /files/delete-multiple [post]
+func (h *FileHandler) DeleteMultipleFiles(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	var req model.DeleteFilesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.fileService.DeleteFiles(c.Request.Context(), userID.(uuid.UUID), req.FileIDs); err != nil {
+		respondFileError(c, err, "Failed to delete files")
+		return
+	}
+
+	response := model.Success200(nil, "Files deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateFolder godoc
+// @Summary Create folder
+// @Description Create a folder under a parent folder path. Missing parent folders are created.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param request body model.CreateFolderRequest true "Folder data"
+// @Success 201 {object} model.FolderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders [post]
+func (h *FileHandler) CreateFolder(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	var req model.CreateFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	folder, err := h.fileService.CreateFolder(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		respondFileError(c, err, "Failed to create folder")
+		return
+	}
+
+	response := model.Success201(folder, "Folder created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetFolders godoc
+// @Summary List folders
+// @Description List the direct subfolders of a parent folder path with file count and size
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param parent query string false "Parent folder path, root when empty"
+// @Success 200 {array} model.FolderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders [get]
+func (h *FileHandler) GetFolders(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	folders, err := h.fileService.GetFolders(c.Request.Context(), userID.(uuid.UUID), c.Query("parent"))
+	if err != nil {
+		respondFileError(c, err, "Failed to get folders")
+		return
+	}
+
+	response := model.Success200(folders, "Folders retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RenameFolder godoc
+// @Summary Rename folder
+// @Description Rename a folder. Paths of all subfolders are updated.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param request body model.RenameRequest true "New name"
+// @Success 200 {object} model.FolderResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id}/rename [put]
+func (h *FileHandler) RenameFolder(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, folderID, ok := folderRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	var req model.RenameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	folder, err := h.fileService.RenameFolder(c.Request.Context(), userID, folderID, req.Name)
+	if err != nil {
+		respondFileError(c, err, "Failed to rename folder")
+		return
+	}
+
+	response := model.Success200(folder, "Folder renamed successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteFolder godoc
+// @Summary Delete folder
+// @Description Delete a folder. A folder that still contains files or subfolders is only deleted with force=true, which deletes all of its content.
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param force query bool false "Delete the folder with all of its content" default(false)
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /folders/{id} [delete]
+func (h *FileHandler) DeleteFolder(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, folderID, ok := folderRequestIDs(c)
+	if !ok {
+		return
+	}
+
+	force, err := parseBoolQuery(c, "force")
+	if err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.fileService.DeleteFolder(c.Request.Context(), userID, folderID, force != nil && *force); err != nil {
+		respondFileError(c, err, "Failed to delete folder")
+		return
+	}
+
+	response := model.Success200(nil, "Folder deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetStorageStats godoc
+// @Summary Get storage statistics
+// @Description Get the storage quota, usage, and file and folder counts of the currently authenticated user
+// @Tags files
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.StorageStatsResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /storage/stats [get]
+func (h *FileHandler) GetStorageStats(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	stats, err := h.fileService.GetStorageStats(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		respondFileError(c, err, "Failed to get storage statistics")
+		return
+	}
+
+	response := model.Success200(stats, "Storage statistics retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetStorageQuota godoc
+// @Summary Get user storage quota
+// @Description Get the storage quota and usage of a user (admin only)
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Success 200 {object} model.StorageQuotaResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /storage/quotas/{userId} [get]
+func (h *FileHandler) GetStorageQuota(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, ok := quotaRequestUserID(c)
+	if !ok {
+		return
+	}
+
+	quota, err := h.fileService.GetStorageQuota(c.Request.Context(), userID)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to get storage quota")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(quota, "Storage quota retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateStorageQuota godoc
+// @Summary Update user storage quota
+// @Description Set a custom storage quota for a user, or reset it to the default with quota_bytes null (admin only)
+// @Tags files
+// @Accept json
+// @Produce json
+// @Param userId path string true "User ID"
+// @Param request body model.UpdateStorageQuotaRequest true "Quota in bytes"
+// @Success 200 {object} model.StorageQuotaResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /storage/quotas/{userId} [put]
+func (h *FileHandler) UpdateStorageQuota(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userID, ok := quotaRequestUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateStorageQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	quota, err := h.fileService.UpdateStorageQuota(c.Request.Context(), userID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to update storage quota")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(quota, "Storage quota updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// fileRequestIDs membaca user ID dari konteks dan file ID dari URL
+func fileRequestIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	return requestIDs(c, "Invalid file ID")
+}
+
+// folderRequestIDs membaca user ID dari konteks dan folder ID dari URL
+func folderRequestIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	return requestIDs(c, "Invalid folder ID")
+}
+
+// requestIDs membaca user ID dari konteks (diisi oleh middleware auth) dan ID dari parameter :id
+func requestIDs(c *gin.Context, invalidMessage string) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400(invalidMessage)
+		c.JSON(http.StatusBadRequest, response)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID.(uuid.UUID), id, true
+}
+
+// quotaRequestUserID memeriksa role admin dan membaca user ID dari URL
+func quotaRequestUserID(c *gin.Context) (uuid.UUID, bool) {
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// respondFileError memetakan error FileService ke response HTTP
+func respondFileError(c *gin.Context, err error, defaultMessage string) {
+	switch err {
+	case service.ErrFileAccessDenied:
+		response := model.Error403("You do not have permission to perform this action")
+		c.JSON(http.StatusForbidden, response)
+	case service.ErrFileNotFound:
+		response := model.Error404("File not found")
+		c.JSON(http.StatusNotFound, response)
+	case service.ErrFolderNotFound:
+		response := model.Error404("Folder not found")
+		c.JSON(http.StatusNotFound, response)
+	case service.ErrFolderExists:
+		response := model.Error409("Folder already exists")
+		c.JSON(http.StatusConflict, response)
+	case service.ErrFolderNotEmpty:
+		response := model.Error409("Folder is not empty, use force=true to delete it with its content")
+		c.JSON(http.StatusConflict, response)
+	case service.ErrInvalidFileName:
+		response := model.Error400("Invalid file name")
+		c.JSON(http.StatusBadRequest, response)
+	case service.ErrInvalidFolderPath:
+		response := model.Error400("Invalid folder name or path")
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		response := model.Error500(defaultMessage)
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk FileHandler
+func (h *FileHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	files := router.Group("/api/v1")
+	files.Use(authMiddleware, middleware.UserOnlyMiddleware()) // Semua endpoint memerlukan autentikasi
+	{
+		files.POST("/upload", middleware.RequireScope("files:create"), h.UploadFile)                         // POST /api/v1/upload
+		files.POST("/upload-multiple", middleware.RequireScope("files:create"), h.UploadMultipleFiles)       // POST /api/v1/upload-multiple
+		files.GET("/files", middleware.RequireScope("files:read"), h.GetFiles)                               // GET /api/v1/files
+		files.GET("/files/search", middleware.RequireScope("files:read"), h.SearchFiles)                     // GET /api/v1/files/search
+		files.POST("/files/delete-multiple", middleware.RequireScope("files:delete"), h.DeleteMultipleFiles) // POST /api/v1/files/delete-multiple
+		files.GET("/files/:id", middleware.RequireScope("files:read"), h.GetFile)                            // GET /api/v1/files/:id
+		files.GET("/files/:id/metadata", middleware.RequireScope("files:read"), h.GetFileMetadata)           // GET /api/v1/files/:id/metadata
+		files.GET("/files/:id/url", middleware.RequireScope("files:read"), h.GetFileURL)                     // GET /api/v1/files/:id/url
+		files.GET("/files/:id/download", middleware.RequireScope("files:read"), h.DownloadFile)              // GET /api/v1/files/:id/download
+		files.PUT("/files/:id/rename", middleware.RequireScope("files:update"), h.RenameFile)                // PUT /api/v1/files/:id/rename
+		files.PUT("/files/:id/move", middleware.RequireScope("files:update"), h.MoveFile)                    // PUT /api/v1/files/:id/move
+		files.DELETE("/files/:id", middleware.RequireScope("files:delete"), h.DeleteFile)                    // DELETE /api/v1/files/:id
+		files.POST("/folders", middleware.RequireScope("files:create"), h.CreateFolder)                      // POST /api/v1/folders
+		files.GET("/folders", middleware.RequireScope("files:read"), h.GetFolders)                           // GET /api/v1/folders
+		files.PUT("/folders/:id/rename", middleware.RequireScope("files:update"), h.RenameFolder)            // PUT /api/v1/folders/:id/rename
+		files.DELETE("/folders/:id", middleware.RequireScope("files:delete"), h.DeleteFolder)                // DELETE /api/v1/folders/:id
+		files.GET("/storage/stats", middleware.RequireScope("files:read"), h.GetStorageStats)                // GET /api/v1/storage/stats
+		files.GET("/storage/quotas/:userId", middleware.RequireScope("files:manage"), h.GetStorageQuota)     // GET /api/v1/storage/quotas/:userId
+		files.PUT("/storage/quotas/:userId", middleware.RequireScope("files:manage"), h.UpdateStorageQuota)  // PUT /api/v1/storage/quotas/:userId
+	}
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// InvitationHandler menangani request pengelolaan undangan user oleh admin
+type InvitationHandler struct {
+	invitationService service.InvitationService
+	validator         *validator.Validate
+}
+
+// NewInvitationHandler membuat instance baru InvitationHandler
+func NewInvitationHandler(invitationService service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+		validator:         validator.New(),
+	}
+}
+
+// CreateInvitation godoc
+// @Summary Create invitation
+// @Description Invite someone by email with a pre-assigned role. The invitation link is emailed; the signed token is returned only once and only when MAIL_DRIVER is "log".
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body model.CreateInvitationRequest true "Create invitation request"
+// @Success 201 {object} model.CreateInvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Email = strings.TrimSpace(req.Email)
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+
+	result, err := h.invitationService.CreateInvitation(c.Request.Context(), &req, auditActorFromContext(c).ID)
+	if err != nil {
+		switch err {
+		case service.ErrUserAlreadyExists:
+			response := model.Error409("Email already registered")
+			c.JSON(http.StatusConflict, response)
+		case service.ErrInvitationExists:
+			response := model.Error409("A pending invitation already exists for this email")
+			c.JSON(http.StatusConflict, response)
+		case service.ErrRoleNotFound:
+			response := model.Error404("Role not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrInvalidRole:
+			response := model.Error400("role and role_id refer to different roles")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to create invitation")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success201(result, "Invitation created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetInvitations godoc
+// @Summary List invitations
+// @Description Get invitations with pagination, optionally filtered by status and email or name
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending, accepted, revoked, expired)
+// @Param search query string false "Search by email or name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} model.InvitationsListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /invitations [get]
+func (h *InvitationHandler) GetInvitations(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	filter := &model.InvitationFilter{
+		Status: strings.TrimSpace(c.Query("status")),
+		Search: utils.SanitizeInput(strings.TrimSpace(c.Query("search"))),
+		Page:   page,
+		Limit:  limit,
+	}
+
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400("Invalid status filter", page, limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.invitationService.GetInvitations(c.Request.Context(), filter)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get invitations", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Invitations, "Invitations retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetInvitation godoc
+// @Summary Get invitation
+// @Description Get invitation details by ID
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /invitations/{id} [get]
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse invitation ID dari URL
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid invitation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	invitation, err := h.invitationService.GetInvitationByID(c.Request.Context(), invitationID)
+	if err != nil {
+		switch err {
+		case service.ErrInvitationNotFound:
+			response := model.Error404("Invitation not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to get invitation")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(invitation, "Invitation retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke invitation
+// @Description Revoke a pending invitation so its token can no longer be used
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /invitations/{id}/revoke [post]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse invitation ID dari URL
+	invitationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid invitation ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	invitation, err := h.invitationService.RevokeInvitation(c.Request.Context(), invitationID)
+	if err != nil {
+		switch err {
+		case service.ErrInvitationNotFound:
+			response := model.Error404("Invitation not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrInvitationNotPending:
+			response := model.Error409("Only pending invitations can be revoked")
+			c.JSON(http.StatusConflict, response)
+		default:
+			response := model.Error500("Failed to revoke invitation")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(invitation, "Invitation revoked successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes mendaftarkan rute untuk InvitationHandler
+func (h *InvitationHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	invitations := router.Group("/api/v1/invitations")
+	invitations.Use(authMiddleware)
+	{
+		invitations.POST("", middleware.RequireScope("users:create"), h.CreateInvitation)            // POST /api/v1/invitations
+		invitations.GET("", middleware.RequireScope("users:list"), h.GetInvitations)                 // GET /api/v1/invitations
+		invitations.GET("/:id", middleware.RequireScope("users:read"), h.GetInvitation)              // GET /api/v1/invitations/:id
+		invitations.POST("/:id/revoke", middleware.RequireScope("users:create"), h.RevokeInvitation) // POST /api/v1/invitations/:id/revoke
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// JobHandler menangani request daftar job pemeliharaan, riwayat run dan pemicuan manual
+type JobHandler struct {
+	jobService service.JobService
+	validator  *validator.Validate
+}
+
+// NewJobHandler membuat instance baru JobHandler
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		validator:  validator.New(),
+	}
+}
+
+// GetJobs godoc
+// @Summary List maintenance jobs
+// @Description Get all background maintenance jobs with their schedule, next run and last run
+// @Tags jobs
+// @Produce json
+// @Success 200 {array} model.JobResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /jobs [get]
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Request.Context())
+	if err != nil {
+		response := model.Error500("Failed to get jobs")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(jobs, "Jobs retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetRuns godoc
+// @Summary List job runs
+// @Description Get the run history of all maintenance jobs, newest first
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param job_name query string false "Filter by job name"
+// @Param status query string false "Filter by status" Enums(running, succeeded, failed)
+// @Success 200 {object} model.JobRunsListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /jobs/runs [get]
+func (h *JobHandler) GetRuns(c *gin.Context) {
+	h.listRuns(c, strings.TrimSpace(c.Query("job_name")))
+}
+
+// GetJobRuns godoc
+// @Summary List runs of a job
+// @Description Get the run history of one maintenance job, newest first
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param name path string true "Job name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Filter by status" Enums(running, succeeded, failed)
+// @Success 200 {object} model.JobRunsListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /jobs/{name}/runs [get]
+func (h *JobHandler) GetJobRuns(c *gin.Context) {
+	h.listRuns(c, c.Param("name"))
+}
+
+// listRuns menangani daftar riwayat run, opsional dibatasi pada satu job
+func (h *JobHandler) listRuns(c *gin.Context, jobName string) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := &model.JobRunFilter{
+		JobName: jobName,
+		Status:  strings.TrimSpace(c.Query("status")),
+		Page:    page,
+		Limit:   limit,
+	}
+
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), page, limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.jobService.GetJobRuns(c.Request.Context(), filter)
+	if err != nil {
+		if err == service.ErrJobNotFound {
+			h.writeJobError(c, err, "Failed to get job runs")
+			return
+		}
+		response := model.PaginatedError500("Failed to get job runs", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Runs, "Job runs retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// TriggerJob godoc
+// @Summary Run job now
+// @Description Start a maintenance job in the background outside its schedule. Fails if the job is already running on any instance.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param name path string true "Job name"
+// @Success 202 {object} model.JobRun
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /jobs/{name}/run [post]
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	run, err := h.jobService.TriggerJob(c.Request.Context(), c.Param("name"), auditActorFromContext(c))
+	if err != nil {
+		h.writeJobError(c, err, "Failed to start job")
+		return
+	}
+
+	response := model.NewSuccessResponse(http.StatusAccepted, run, "Job started")
+	c.JSON(http.StatusAccepted, response)
+}
+
+// writeJobError memetakan error service ke response standar untuk endpoint job
+func (h *JobHandler) writeJobError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrJobNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Job not found"))
+	case service.ErrJobRunning:
+		c.JSON(http.StatusConflict, model.Error409("Job is already running"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500(fallback))
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk JobHandler
+func (h *JobHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	jobs := router.Group("/api/v1/jobs")
+	jobs.Use(authMiddleware, middleware.RequireScope("jobs:manage"))
+	{
+		jobs.GET("", h.GetJobs)               // GET /api/v1/jobs
+		jobs.GET("/runs", h.GetRuns)          // GET /api/v1/jobs/runs
+		jobs.GET("/:name/runs", h.GetJobRuns) // GET /api/v1/jobs/:name/runs
+		jobs.POST("/:name/run", h.TriggerJob) // POST /api/v1/jobs/:name/run
+	}
+}
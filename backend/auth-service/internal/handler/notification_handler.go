@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// NotificationHandler menangani request notifikasi user dan stream notifikasi real-time
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	validator           *validator.Validate
+}
+
+// NewNotificationHandler membuat instance baru NotificationHandler
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		validator:           validator.New(),
+	}
+}
+
+// GetNotifications godoc
+// @Summary List notifications
+// @Description Get the current user's notifications, newest first
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param unread query bool false "Only unread notifications"
+// @Param category query string false "Filter by category" Enums(security, account, system)
+// @Success 200 {object} model.NotificationsListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	filter := &model.NotificationFilter{
+		UnreadOnly: unreadOnly,
+		Category:   strings.TrimSpace(c.Query("category")),
+		Page:       page,
+		Limit:      limit,
+	}
+
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), page, limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.notificationService.GetNotifications(c.Request.Context(), userID.(uuid.UUID), filter)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get notifications", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Notifications, "Notifications retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUnreadCount godoc
+// @Summary Count unread notifications
+// @Description Get the number of unread notifications of the current user
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} model.UnreadNotificationCountResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	count, err := h.notificationService.GetUnreadCount(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		response := model.Error500("Failed to count unread notifications")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(model.UnreadNotificationCountResponse{UnreadCount: count}, "Unread notification count retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkAsRead godoc
+// @Summary Mark notification as read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid notification ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.notificationService.MarkAsRead(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		h.writeNotificationError(c, err, "Failed to mark notification as read")
+		return
+	}
+
+	response := model.Success200(nil, "Notification marked as read")
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkAllAsRead godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllAsRead(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		response := model.Error500("Failed to mark notifications as read")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(map[string]int64{"updated": updated}, "Notifications marked as read")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteNotification godoc
+// @Summary Delete notification
+// @Description Delete one of the current user's notifications
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid notification ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.notificationService.DeleteNotification(c.Request.Context(), userID.(uuid.UUID), id); err != nil {
+		h.writeNotificationError(c, err, "Failed to delete notification")
+		return
+	}
+
+	response := model.Success200(nil, "Notification deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// StreamNotifications godoc
+// @Summary Stream notifications
+// @Description Open a Server-Sent Events stream of the current user's notifications. Authenticates like every other endpoint, so browsers using EventSource can rely on the access_token cookie. A "ready" event with the unread count is sent first, followed by "notification", "read", "read_all" and "deleted" events, and "sessions.revoked" right before closing the stream when the user's sessions are revoked. Comment lines are sent periodically as keep-alive.
+// @Tags notifications
+// @Produce text/event-stream
+// @Success 200 {object} model.NotificationMessage
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /notifications/stream [get]
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Berlangganan sebelum menghitung notifikasi belum dibaca agar tidak ada pesan yang terlewat
+	messages, unsubscribe := h.notificationService.Subscribe(userID.(uuid.UUID))
+	defer unsubscribe()
+
+	count, err := h.notificationService.GetUnreadCount(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		response := model.Error500("Failed to open notification stream")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Matikan buffering pada reverse proxy seperti nginx
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(model.NotificationStreamReady, model.UnreadNotificationCountResponse{UnreadCount: count})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.notificationService.HeartbeatInterval())
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case message, ok := <-messages:
+			if !ok {
+				return false
+			}
+			c.SSEvent(message.Type, message)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// writeNotificationError memetakan error service notifikasi ke response HTTP
+func (h *NotificationHandler) writeNotificationError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrNotificationNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Notification not found"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500(fallback))
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk NotificationHandler
+func (h *NotificationHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// Notifikasi milik user, tidak tersedia untuk service account maupun API key
+	notifications := router.Group("/api/v1/notifications")
+	notifications.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware())
+	{
+		notifications.GET("", h.GetNotifications)            // GET /api/v1/notifications
+		notifications.GET("/unread-count", h.GetUnreadCount) // GET /api/v1/notifications/unread-count
+		notifications.GET("/stream", h.StreamNotifications)  // GET /api/v1/notifications/stream
+		notifications.POST("/read-all", h.MarkAllAsRead)     // POST /api/v1/notifications/read-all
+		notifications.POST("/:id/read", h.MarkAsRead)        // POST /api/v1/notifications/:id/read
+		notifications.DELETE("/:id", h.DeleteNotification)   // DELETE /api/v1/notifications/:id
+	}
+}
@@ -0,0 +1,770 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// OAuthHandler menangani endpoint OAuth 2.0 dan manajemen service account
+type OAuthHandler struct {
+	oauthService service.OAuthService
+	validator    *validator.Validate
+}
+
+// NewOAuthHandler membuat instance baru OAuthHandler
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService: oauthService,
+		validator:    validator.New(),
+	}
+}
+
+// Token godoc
+// @Summary OAuth 2.0 token endpoint
+// @Description Issue an access token. Supported grant types: client_credentials, urn:ietf:params:oauth:grant-type:device_code, urn:ietf:params:oauth:grant-type:token-exchange
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Grant type"
+// @Param client_id formData string false "Client ID (if not using HTTP Basic auth)"
+// @Param client_secret formData string false "Client secret (if not using HTTP Basic auth)"
+// @Param scope formData string false "Space-delimited list of requested scopes"
+// @Param device_code formData string false "Device code (device_code grant only)"
+// @Param subject_token formData string false "Subject access token (token-exchange grant only)"
+// @Param subject_token_type formData string false "urn:ietf:params:oauth:token-type:access_token (token-exchange grant only)"
+// @Param audience formData []string false "Target audience (token-exchange grant only)"
+// @Param requested_token_type formData string false "Requested token type (token-exchange grant only)"
+// @Success 200 {object} model.OAuthTokenResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Failure 500 {object} model.OAuthErrorResponse
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	// Set header keamanan dan larang caching response token (RFC 6749 section 5.1)
+	utils.SetSecureHeaders(c)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	// Parse request body
+	var req model.OAuthTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "Invalid request format"))
+		return
+	}
+
+	if req.GrantType == "" {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "grant_type is required"))
+		return
+	}
+
+	switch req.GrantType {
+	case model.GrantTypeClientCredentials:
+		client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+		if !ok {
+			return
+		}
+
+		tokenResponse, err := h.oauthService.ClientCredentialsGrant(c.Request.Context(), client, req.Scope)
+		if err != nil {
+			h.writeOAuthError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tokenResponse)
+	case model.GrantTypeTokenExchange:
+		client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+		if !ok {
+			return
+		}
+
+		tokenResponse, err := h.oauthService.TokenExchangeGrant(c.Request.Context(), client, &req)
+		if err != nil {
+			h.writeOAuthError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tokenResponse)
+	case model.GrantTypeDeviceCode:
+		// Client device adalah client publik sehingga hanya diidentifikasi dengan client_id
+		clientInfo := clientInfoFromRequest(c)
+
+		tokenResponse, err := h.oauthService.DeviceCodeGrant(c.Request.Context(), req.ClientID, req.DeviceCode, clientInfo)
+		if err != nil {
+			h.writeOAuthError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, tokenResponse)
+	default:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("unsupported_grant_type", "Grant type is not supported"))
+	}
+}
+
+// DeviceAuthorization godoc
+// @Summary OAuth 2.0 device authorization endpoint
+// @Description Start the device authorization grant (RFC 8628) and obtain a device code and user code
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param client_id formData string true "Client ID"
+// @Param scope formData string false "Space-delimited list of requested scopes"
+// @Success 200 {object} model.OAuthDeviceAuthorizationResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Failure 500 {object} model.OAuthErrorResponse
+// @Router /oauth/device_authorization [post]
+func (h *OAuthHandler) DeviceAuthorization(c *gin.Context) {
+	// Set header keamanan dan larang caching response
+	utils.SetSecureHeaders(c)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	// Parse request body
+	var req model.OAuthDeviceAuthorizationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "Invalid request format"))
+		return
+	}
+
+	if req.ClientID == "" {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "client_id is required"))
+		return
+	}
+
+	deviceResponse, err := h.oauthService.DeviceAuthorization(c.Request.Context(), req.ClientID, req.Scope)
+	if err != nil {
+		h.writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deviceResponse)
+}
+
+// Introspect godoc
+// @Summary OAuth 2.0 token introspection
+// @Description Check whether an access token or refresh token is active (RFC 7662). Requires client authentication.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID (if not using HTTP Basic auth)"
+// @Param client_secret formData string false "Client secret (if not using HTTP Basic auth)"
+// @Success 200 {object} model.OAuthIntrospectionResponse
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Failure 500 {object} model.OAuthErrorResponse
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	// Set header keamanan dan larang caching response
+	utils.SetSecureHeaders(c)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	// Parse request body
+	var req model.OAuthTokenActionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "Invalid request format"))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "token is required"))
+		return
+	}
+
+	introspection, err := h.oauthService.IntrospectToken(c.Request.Context(), client, req.Token, req.TokenTypeHint)
+	if err != nil {
+		h.writeOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, introspection)
+}
+
+// Revoke godoc
+// @Summary OAuth 2.0 token revocation
+// @Description Revoke an access token or refresh token (RFC 7009). Requires client authentication. Invalid or already revoked tokens also return 200.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to revoke"
+// @Param token_type_hint formData string false "access_token or refresh_token"
+// @Param client_id formData string false "Client ID (if not using HTTP Basic auth)"
+// @Param client_secret formData string false "Client secret (if not using HTTP Basic auth)"
+// @Success 200
+// @Failure 400 {object} model.OAuthErrorResponse
+// @Failure 401 {object} model.OAuthErrorResponse
+// @Failure 500 {object} model.OAuthErrorResponse
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	// Parse request body
+	var req model.OAuthTokenActionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "Invalid request format"))
+		return
+	}
+
+	client, ok := h.authenticateClient(c, req.ClientID, req.ClientSecret)
+	if !ok {
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "token is required"))
+		return
+	}
+
+	// Pencabutan dicatat atas nama client yang mengautentikasi request
+	actor := &model.AuditActor{
+		Type:      model.AuditActorClient,
+		Name:      client.ClientID,
+		IP:        utils.GetClientIP(c),
+		UserAgent: utils.GetUserAgent(c),
+	}
+
+	if err := h.oauthService.RevokeToken(c.Request.Context(), client, req.Token, req.TokenTypeHint, actor); err != nil {
+		h.writeOAuthError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// authenticateClient mengautentikasi client melalui HTTP Basic atau parameter form (RFC 6749 section 2.3.1)
+func (h *OAuthHandler) authenticateClient(c *gin.Context, formClientID, formClientSecret string) (*model.OAuthClient, bool) {
+	clientID, clientSecret, usedBasic := c.Request.BasicAuth()
+	if usedBasic {
+		// Kredensial pada header Basic dienkode form-urlencoded
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = formClientID, formClientSecret
+	}
+
+	client, err := h.oauthService.AuthenticateClient(c.Request.Context(), clientID, clientSecret)
+	if err != nil {
+		if err == service.ErrInvalidClient && usedBasic {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		h.writeOAuthError(c, err)
+		return nil, false
+	}
+
+	return client, true
+}
+
+// writeOAuthError memetakan error service ke response error OAuth
+func (h *OAuthHandler) writeOAuthError(c *gin.Context, err error) {
+	var stepUp *service.StepUpRequiredError
+	if errors.As(err, &stepUp) {
+		c.JSON(http.StatusBadRequest, model.OAuthStepUpErrorResponse{
+			OAuthErrorResponse:     model.NewOAuthErrorResponse("step_up_required", "Verification code sent to the user's email"),
+			LoginChallengeResponse: stepUp.Challenge,
+		})
+		return
+	}
+
+	switch err {
+	case service.ErrInvalidClient:
+		c.JSON(http.StatusUnauthorized, model.NewOAuthErrorResponse("invalid_client", "Client authentication failed"))
+	case service.ErrInvalidScope:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_scope", "Requested scope exceeds the scope granted to the client"))
+	case service.ErrUnauthorizedClient:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("unauthorized_client", "Token was not issued to this client"))
+	case service.ErrInvalidGrant:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_grant", "The provided grant is invalid or expired"))
+	case service.ErrInvalidRequest:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_request", "Missing or unsupported token exchange parameters"))
+	case service.ErrInvalidTarget:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("invalid_target", "Requested audience is not allowed for this client"))
+	case service.ErrAuthorizationPending:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("authorization_pending", "The user has not yet approved the device"))
+	case service.ErrSlowDown:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("slow_down", "Polling too frequently, increase the interval by 5 seconds"))
+	case service.ErrAccessDenied:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("access_denied", "The authorization request was denied"))
+	case service.ErrExpiredDeviceCode:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("expired_token", "Device code has expired"))
+	case service.ErrUnsupportedGrantType:
+		c.JSON(http.StatusBadRequest, model.NewOAuthErrorResponse("unsupported_grant_type", "Grant type is not supported"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.NewOAuthErrorResponse("server_error", "Failed to process request"))
+	}
+}
+
+// GetDeviceVerification godoc
+// @Summary Get pending device authorization
+// @Description Look up a pending device authorization by user code so the user can confirm it
+// @Tags oauth-device
+// @Accept json
+// @Produce json
+// @Param user_code query string true "User code shown on the device"
+// @Success 200 {object} model.DeviceVerificationResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/device [get]
+func (h *OAuthHandler) GetDeviceVerification(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	userCode := strings.TrimSpace(c.Query("user_code"))
+	if userCode == "" {
+		response := model.Error400("user_code is required")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	verification, err := h.oauthService.GetDeviceVerification(c.Request.Context(), userCode)
+	if err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	response := model.Success200(verification, "Device authorization retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// VerifyDevice godoc
+// @Summary Approve or deny a device
+// @Description Approve or deny a pending device authorization as the current user
+// @Tags oauth-device
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyDeviceRequest true "Verify device request"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/device [post]
+func (h *OAuthHandler) VerifyDevice(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse request body
+	var req model.VerifyDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.oauthService.VerifyDevice(c.Request.Context(), userID.(uuid.UUID), req.UserCode, *req.Approve); err != nil {
+		h.writeDeviceError(c, err)
+		return
+	}
+
+	message := "Device denied successfully"
+	if *req.Approve {
+		message = "Device approved successfully"
+	}
+
+	response := model.Success200(nil, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// writeDeviceError memetakan error verifikasi perangkat ke response standar
+func (h *OAuthHandler) writeDeviceError(c *gin.Context, err error) {
+	switch err {
+	case service.ErrDeviceCodeNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Invalid or expired user code"))
+	case service.ErrDeviceAlreadyHandled:
+		c.JSON(http.StatusConflict, model.Error409("Device authorization has already been approved or denied"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500("Failed to process device authorization"))
+	}
+}
+
+// GetAllClients godoc
+// @Summary Get all service accounts
+// @Description Get all OAuth service account clients with pagination and search
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param search query string false "Search by name or client ID"
+// @Success 200 {object} model.OAuthClientsListResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients [get]
+func (h *OAuthHandler) GetAllClients(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	search := strings.TrimSpace(c.Query("search"))
+
+	// Sanitasi input search
+	if search != "" {
+		search = utils.SanitizeInput(search)
+	}
+
+	clientsResponse, err := h.oauthService.GetAllClients(c.Request.Context(), page, limit, search)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get service accounts", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(clientsResponse.Clients, "Service accounts retrieved successfully", clientsResponse.Page, clientsResponse.Limit, clientsResponse.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetClient godoc
+// @Summary Get service account by ID
+// @Description Get OAuth service account details by ID
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} model.OAuthClientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients/{id} [get]
+func (h *OAuthHandler) GetClient(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid client ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	clientResponse, err := h.oauthService.GetClientByID(c.Request.Context(), id)
+	if err != nil {
+		h.writeClientError(c, err, "Failed to get service account")
+		return
+	}
+
+	response := model.Success200(clientResponse, "Service account retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateClient godoc
+// @Summary Create service account
+// @Description Create a new OAuth service account. The client secret is only returned once.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param request body model.CreateOAuthClientRequest true "Create service account request"
+// @Success 201 {object} model.OAuthClientCredentialsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients [post]
+func (h *OAuthHandler) CreateClient(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+	req.Description = utils.SanitizeInput(strings.TrimSpace(req.Description))
+
+	userID, _ := c.Get("user_id")
+	credentials, err := h.oauthService.CreateClient(c.Request.Context(), &req, userID.(uuid.UUID))
+	if err != nil {
+		h.writeClientError(c, err, "Failed to create service account")
+		return
+	}
+
+	response := model.Success201(credentials, "Service account created successfully. Store the client secret now, it will not be shown again")
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateClient godoc
+// @Summary Update service account
+// @Description Update an OAuth service account. Changing role, scopes or deactivating revokes issued tokens.
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param request body model.UpdateOAuthClientRequest true "Update service account request"
+// @Success 200 {object} model.OAuthClientResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients/{id} [put]
+func (h *OAuthHandler) UpdateClient(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid client ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Parse request body
+	var req model.UpdateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	if req.Name != nil {
+		name := utils.SanitizeInput(strings.TrimSpace(*req.Name))
+		req.Name = &name
+	}
+	if req.Description != nil {
+		description := utils.SanitizeInput(strings.TrimSpace(*req.Description))
+		req.Description = &description
+	}
+
+	clientResponse, err := h.oauthService.UpdateClient(c.Request.Context(), id, &req)
+	if err != nil {
+		h.writeClientError(c, err, "Failed to update service account")
+		return
+	}
+
+	response := model.Success200(clientResponse, "Service account updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteClient godoc
+// @Summary Delete service account
+// @Description Delete an OAuth service account and revoke its tokens
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients/{id} [delete]
+func (h *OAuthHandler) DeleteClient(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid client ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.oauthService.DeleteClient(c.Request.Context(), id); err != nil {
+		h.writeClientError(c, err, "Failed to delete service account")
+		return
+	}
+
+	response := model.Success200(nil, "Service account deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RotateClientSecret godoc
+// @Summary Rotate service account secret
+// @Description Generate a new client secret and revoke tokens issued with the old one
+// @Tags oauth-clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} model.OAuthClientCredentialsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /oauth/clients/{id}/rotate-secret [post]
+func (h *OAuthHandler) RotateClientSecret(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid client ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	credentials, err := h.oauthService.RotateClientSecret(c.Request.Context(), id)
+	if err != nil {
+		h.writeClientError(c, err, "Failed to rotate client secret")
+		return
+	}
+
+	response := model.Success200(credentials, "Client secret rotated successfully. Store the client secret now, it will not be shown again")
+	c.JSON(http.StatusOK, response)
+}
+
+// writeClientError memetakan error service ke response standar untuk endpoint manajemen client
+func (h *OAuthHandler) writeClientError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrOAuthClientNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Service account not found"))
+	case service.ErrRoleNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Role not found"))
+	case service.ErrInvalidRole:
+		c.JSON(http.StatusBadRequest, model.Error400("Role is inactive"))
+	case service.ErrInvalidScope:
+		c.JSON(http.StatusBadRequest, model.Error400("Scopes must be a subset of the role permissions"))
+	case service.ErrInvalidAudience:
+		c.JSON(http.StatusBadRequest, model.Error400("Audiences must not contain whitespace"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500(fallback))
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk OAuthHandler
+func (h *OAuthHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// Endpoint protokol OAuth 2.0 (autentikasi menggunakan kredensial client)
+	oauth := router.Group("/oauth")
+	{
+		oauth.POST("/token", h.Token)                              // POST /oauth/token
+		oauth.POST("/introspect", h.Introspect)                    // POST /oauth/introspect
+		oauth.POST("/revoke", h.Revoke)                            // POST /oauth/revoke
+		oauth.POST("/device_authorization", h.DeviceAuthorization) // POST /oauth/device_authorization
+	}
+
+	// Verifikasi perangkat oleh user yang sedang login (RFC 8628 section 3.3). Hanya sesi login
+	// penuh yang dapat menyetujui perangkat agar token terbatas tidak dapat menerbitkan sesi baru.
+	device := router.Group("/api/v1/oauth/device")
+	device.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware(), middleware.FullSessionOnlyMiddleware())
+	{
+		device.GET("", h.GetDeviceVerification) // GET /api/v1/oauth/device
+		device.POST("", h.VerifyDevice)         // POST /api/v1/oauth/device
+	}
+
+	// Manajemen service account (hanya untuk admin). API key dan token dengan scope terbatas
+	// ditolak karena service account dapat diberi role admin beserta secret-nya.
+	clients := router.Group("/api/v1/oauth/clients")
+	clients.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware(), middleware.RequireScope("clients:manage"))
+	{
+		clients.GET("", h.GetAllClients)                         // GET /api/v1/oauth/clients
+		clients.POST("", h.CreateClient)                         // POST /api/v1/oauth/clients
+		clients.GET("/:id", h.GetClient)                         // GET /api/v1/oauth/clients/:id
+		clients.PUT("/:id", h.UpdateClient)                      // PUT /api/v1/oauth/clients/:id
+		clients.DELETE("/:id", h.DeleteClient)                   // DELETE /api/v1/oauth/clients/:id
+		clients.POST("/:id/rotate-secret", h.RotateClientSecret) // POST /api/v1/oauth/clients/:id/rotate-secret
+	}
+}
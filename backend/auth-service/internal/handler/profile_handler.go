@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// ProfileHandler menangani request pengelolaan profil oleh user sendiri
+type ProfileHandler struct {
+	profileService service.ProfileService
+	validator      *validator.Validate
+}
+
+// NewProfileHandler membuat instance baru ProfileHandler
+func NewProfileHandler(profileService service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		validator:      validator.New(),
+	}
+}
+
+// UpdateMe godoc
+// @Summary Update current user profile
+// @Description Update the profile of the currently authenticated user. Only the fields that are sent are changed. A new email is applied only after the confirmation links sent to the old and the new address have both been opened.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateProfileRequest true "Update profile request"
+// @Success 200 {object} model.ProfileResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me [put]
+func (h *ProfileHandler) UpdateMe(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse request body
+	var req model.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	if req.Name != nil {
+		name := utils.SanitizeInput(strings.TrimSpace(*req.Name))
+		req.Name = &name
+	}
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		req.Email = &email
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(c.Request.Context(), userID.(uuid.UUID), &req)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrUserAlreadyExists:
+			response := model.Error409("Email already registered")
+			c.JSON(http.StatusConflict, response)
+		case service.ErrRateLimitExceeded:
+			response := model.Error429("Too many email change requests. Please try again later")
+			c.JSON(http.StatusTooManyRequests, response)
+		default:
+			response := model.Error500("Failed to update profile")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	message := "Profile updated successfully"
+	if profile.PendingEmailChange != nil && req.Email != nil && strings.EqualFold(profile.PendingEmailChange.NewEmail, *req.Email) {
+		message = "Profile updated successfully. Confirm the email change from both your current and new email address"
+	}
+
+	response := model.Success200(profile, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmEmailChange godoc
+// @Summary Confirm email change
+// @Description Confirm an email change with the token from the link sent to the old or the new address. The email is changed once both links have been opened.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ConfirmEmailChangeRequest true "Confirm email change request"
+// @Success 200 {object} model.EmailChangeStatus
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /auth/email-change/confirm [post]
+func (h *ProfileHandler) ConfirmEmailChange(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Parse request body
+	var req model.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	status, err := h.profileService.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidEmailChangeToken:
+			response := model.Error400("Invalid or expired email change token")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrUserAlreadyExists:
+			response := model.Error409("Email already registered")
+			c.JSON(http.StatusConflict, response)
+		default:
+			response := model.Error500("Failed to confirm email change")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	message := "Email change confirmed. Waiting for confirmation from the other address"
+	if status.Completed {
+		message = "Email changed successfully"
+	}
+
+	response := model.Success200(status, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelEmailChange godoc
+// @Summary Cancel email change
+// @Description Cancel the pending email change of the currently authenticated user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me/email-change [delete]
+func (h *ProfileHandler) CancelEmailChange(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	err := h.profileService.CancelEmailChange(c.Request.Context(), userID.(uuid.UUID))
+	if err != nil {
+		switch err {
+		case service.ErrEmailChangeNotFound:
+			response := model.Error404("No pending email change")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to cancel email change")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(nil, "Email change cancelled successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes mendaftarkan rute untuk ProfileHandler
+func (h *ProfileHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	// Public routes (link konfirmasi dapat dibuka tanpa login)
+	public := router.Group("/api/v1/auth")
+	{
+		public.POST("/email-change/confirm", h.ConfirmEmailChange)
+	}
+
+	// Protected routes, API key tidak dapat mengubah profil atau email
+	protected := router.Group("/api/v1/auth")
+	protected.Use(authMiddleware, middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware())
+	{
+		protected.PUT("/me", h.UpdateMe)
+		protected.DELETE("/me/email-change", h.CancelEmailChange)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/auth-service/internal/middleware"
 	"github.com/auth-service/internal/model"
 	"github.com/auth-service/internal/service"
 	"github.com/auth-service/internal/utils"
@@ -173,7 +174,7 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 	req.Description = utils.SanitizeInput(strings.TrimSpace(req.Description))
 
 	// Create role
-	roleResponse, err := h.roleService.CreateRole(c.Request.Context(), &req)
+	roleResponse, err := h.roleService.CreateRole(c.Request.Context(), &req, auditActorFromContext(c))
 	if err != nil {
 		switch err {
 		case service.ErrRoleAlreadyExists:
@@ -253,7 +254,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 	}
 
 	// Update role
-	roleResponse, err := h.roleService.UpdateRole(c.Request.Context(), roleID, &req)
+	roleResponse, err := h.roleService.UpdateRole(c.Request.Context(), roleID, &req, auditActorFromContext(c))
 	if err != nil {
 		switch err {
 		case service.ErrRoleNotFound:
@@ -307,7 +308,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 	}
 
 	// Delete role
-	err = h.roleService.DeleteRole(c.Request.Context(), roleID)
+	err = h.roleService.DeleteRole(c.Request.Context(), roleID, auditActorFromContext(c))
 	if err != nil {
 		switch err {
 		case service.ErrRoleNotFound:
@@ -427,7 +428,7 @@ func (h *RoleHandler) CreatePermission(c *gin.Context) {
 	req.Action = utils.SanitizeInput(strings.TrimSpace(req.Action))
 
 	// Create permission
-	permissionResponse, err := h.roleService.CreatePermission(c.Request.Context(), &req)
+	permissionResponse, err := h.roleService.CreatePermission(c.Request.Context(), &req, auditActorFromContext(c))
 	if err != nil {
 		switch err {
 		case service.ErrPermissionAlreadyExists:
@@ -480,17 +481,17 @@ func (h *RoleHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.Hand
 	roles := router.Group("/api/v1/roles")
 	roles.Use(authMiddleware) // Semua endpoint memerlukan autentikasi
 	{
-		roles.GET("", h.GetAllRoles)       // GET /api/v1/roles
-		roles.POST("", h.CreateRole)       // POST /api/v1/roles
-		roles.GET("/:id", h.GetRoleByID)   // GET /api/v1/roles/:id
-		roles.PUT("/:id", h.UpdateRole)    // PUT /api/v1/roles/:id
-		roles.DELETE("/:id", h.DeleteRole) // DELETE /api/v1/roles/:id
+		roles.GET("", middleware.RequireScope("roles:list"), h.GetAllRoles)         // GET /api/v1/roles
+		roles.POST("", middleware.RequireScope("roles:create"), h.CreateRole)       // POST /api/v1/roles
+		roles.GET("/:id", middleware.RequireScope("roles:read"), h.GetRoleByID)     // GET /api/v1/roles/:id
+		roles.PUT("/:id", middleware.RequireScope("roles:update"), h.UpdateRole)    // PUT /api/v1/roles/:id
+		roles.DELETE("/:id", middleware.RequireScope("roles:delete"), h.DeleteRole) // DELETE /api/v1/roles/:id
 	}
 
 	permissions := router.Group("/api/v1/permissions")
 	permissions.Use(authMiddleware) // Semua endpoint memerlukan autentikasi
 	{
-		permissions.GET("", h.GetAllPermissions) // GET /api/v1/permissions
-		permissions.POST("", h.CreatePermission) // POST /api/v1/permissions
+		permissions.GET("", middleware.RequireScope("permissions:list"), h.GetAllPermissions)   // GET /api/v1/permissions
+		permissions.POST("", middleware.RequireScope("permissions:create"), h.CreatePermission) // POST /api/v1/permissions
 	}
 }
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// inlineContentTypes adalah tipe file yang aman ditampilkan langsung di browser.
+// Tipe lain (misalnya HTML atau SVG) selalu diunduh agar tidak dieksekusi di origin service.
+var inlineContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// StorageHandler menyajikan object dari file storage lokal melalui signed URL
+type StorageHandler struct {
+	storageService service.StorageService
+}
+
+// NewStorageHandler membuat instance baru StorageHandler
+func NewStorageHandler(storageService service.StorageService) *StorageHandler {
+	return &StorageHandler{
+		storageService: storageService,
+	}
+}
+
+// GetObject godoc
+// @Summary Get stored object
+// @Description Serve an object from local file storage. Only reachable through signed URLs issued by the service.
+// @Tags storage
+// @Param key path string true "Object key"
+// @Param expires query int true "Expiry as unix timestamp"
+// @Param signature query string true "URL signature"
+// @Success 200
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /objects/{key} [get]
+func (h *StorageHandler) GetObject(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		response := model.Error403("Invalid or expired link")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	object, err := h.storageService.OpenSignedObject(c.Request.Context(), key, expires, c.Query("signature"))
+	if err != nil {
+		switch err {
+		case service.ErrInvalidSignedURL:
+			response := model.Error403("Invalid or expired link")
+			c.JSON(http.StatusForbidden, response)
+		case service.ErrObjectNotFound:
+			response := model.Error404("File not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to read file")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+	defer object.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !inlineContentTypes[mediaType] {
+		contentType = "application/octet-stream"
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	}
+
+	// Response boleh di-cache selama signed URL masih berlaku
+	maxAge := expires - time.Now().Unix()
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	c.Header("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+	c.Header("Content-Type", contentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, object)
+}
+
+// RegisterRoutes mendaftarkan rute untuk StorageHandler. Endpoint bersifat publik
+// karena akses dibatasi oleh signature pada URL.
+func (h *StorageHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	router.GET("/api/v1/objects/*key", h.GetObject)
+}
@@ -0,0 +1,530 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// UserAttributeHandler menangani request definisi dan nilai custom attribute user
+type UserAttributeHandler struct {
+	attributeService service.UserAttributeService
+	validator        *validator.Validate
+}
+
+// NewUserAttributeHandler membuat instance baru UserAttributeHandler
+func NewUserAttributeHandler(attributeService service.UserAttributeService) *UserAttributeHandler {
+	return &UserAttributeHandler{
+		attributeService: attributeService,
+		validator:        validator.New(),
+	}
+}
+
+// GetDefinitions godoc
+// @Summary List attribute definitions
+// @Description Get all custom user attribute definitions
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.AttributeDefinitionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /user-attributes [get]
+func (h *UserAttributeHandler) GetDefinitions(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	definitions, err := h.attributeService.GetDefinitions(c.Request.Context(), true)
+	if err != nil {
+		response := model.Error500("Failed to get attribute definitions")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(definitions, "Attribute definitions retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateDefinition godoc
+// @Summary Create attribute definition
+// @Description Define a new custom user attribute with its type, validation rules, visibility and optional JWT claim mapping
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAttributeDefinitionRequest true "Create attribute definition request"
+// @Success 201 {object} model.AttributeDefinitionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /user-attributes [post]
+func (h *UserAttributeHandler) CreateDefinition(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateAttributeDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Name = strings.TrimSpace(req.Name)
+	req.DisplayName = utils.SanitizeInput(strings.TrimSpace(req.DisplayName))
+	req.Description = utils.SanitizeInput(strings.TrimSpace(req.Description))
+	req.JWTClaim = strings.TrimSpace(req.JWTClaim)
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	definition, err := h.attributeService.CreateDefinition(c.Request.Context(), &req)
+	if err != nil {
+		switch err {
+		case service.ErrInvalidAttributeDefinition:
+			response := model.Error400("Invalid attribute definition")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrAttributeDefinitionExists:
+			response := model.Error409("Attribute or JWT claim already exists")
+			c.JSON(http.StatusConflict, response)
+		default:
+			response := model.Error500("Failed to create attribute definition")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success201(definition, "Attribute definition created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateDefinition godoc
+// @Summary Update attribute definition
+// @Description Update a custom user attribute definition. Name and type cannot be changed.
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "Attribute definition ID"
+// @Param request body model.UpdateAttributeDefinitionRequest true "Update attribute definition request"
+// @Success 200 {object} model.AttributeDefinitionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /user-attributes/{id} [put]
+func (h *UserAttributeHandler) UpdateDefinition(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse definition ID dari URL
+	definitionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid attribute definition ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Parse request body
+	var req model.UpdateAttributeDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	if req.DisplayName != nil {
+		displayName := utils.SanitizeInput(strings.TrimSpace(*req.DisplayName))
+		req.DisplayName = &displayName
+	}
+	if req.Description != nil {
+		description := utils.SanitizeInput(strings.TrimSpace(*req.Description))
+		req.Description = &description
+	}
+	if req.JWTClaim != nil {
+		claim := strings.TrimSpace(*req.JWTClaim)
+		req.JWTClaim = &claim
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	definition, err := h.attributeService.UpdateDefinition(c.Request.Context(), definitionID, &req)
+	if err != nil {
+		switch err {
+		case service.ErrAttributeDefinitionNotFound:
+			response := model.Error404("Attribute definition not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrInvalidAttributeDefinition:
+			response := model.Error400("Invalid attribute definition")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrAttributeDefinitionExists:
+			response := model.Error409("JWT claim already used by another attribute")
+			c.JSON(http.StatusConflict, response)
+		default:
+			response := model.Error500("Failed to update attribute definition")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(definition, "Attribute definition updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDefinition godoc
+// @Summary Delete attribute definition
+// @Description Delete a custom user attribute definition together with all stored values
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "Attribute definition ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /user-attributes/{id} [delete]
+func (h *UserAttributeHandler) DeleteDefinition(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse definition ID dari URL
+	definitionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid attribute definition ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.attributeService.DeleteDefinition(c.Request.Context(), definitionID); err != nil {
+		switch err {
+		case service.ErrAttributeDefinitionNotFound:
+			response := model.Error404("Attribute definition not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to delete attribute definition")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(nil, "Attribute definition deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserAttributes godoc
+// @Summary Get user attributes
+// @Description Get all custom attribute values of a user
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/attributes [get]
+func (h *UserAttributeHandler) GetUserAttributes(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	h.getAttributes(c, userID, true)
+}
+
+// SetUserAttributes godoc
+// @Summary Set user attributes
+// @Description Set custom attribute values of a user. Attributes that are not sent are unchanged; null removes a value.
+// @Tags user-attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body model.SetUserAttributesRequest true "Set user attributes request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/attributes [put]
+func (h *UserAttributeHandler) SetUserAttributes(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	h.setAttributes(c, userID, true)
+}
+
+// GetMyAttributeDefinitions godoc
+// @Summary List my attribute definitions
+// @Description Get the custom attribute definitions visible to the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {array} model.AttributeDefinitionResponse
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me/attribute-definitions [get]
+func (h *UserAttributeHandler) GetMyAttributeDefinitions(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	if _, exists := c.Get("user_id"); !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	definitions, err := h.attributeService.GetDefinitions(c.Request.Context(), false)
+	if err != nil {
+		response := model.Error500("Failed to get attribute definitions")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(definitions, "Attribute definitions retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMyAttributes godoc
+// @Summary Get my attributes
+// @Description Get the custom attribute values of the current user. Admin-only attributes are not included.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me/attributes [get]
+func (h *UserAttributeHandler) GetMyAttributes(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	h.getAttributes(c, userID.(uuid.UUID), false)
+}
+
+// SetMyAttributes godoc
+// @Summary Set my attributes
+// @Description Set custom attribute values of the current user. Only attributes with self_edit visibility can be changed; null removes a value.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.SetUserAttributesRequest true "Set user attributes request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /auth/me/attributes [put]
+func (h *UserAttributeHandler) SetMyAttributes(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Dapatkan user ID dari konteks (diisi oleh middleware auth)
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	h.setAttributes(c, userID.(uuid.UUID), false)
+}
+
+// getAttributes mengirim nilai attribute user sebagai response
+func (h *UserAttributeHandler) getAttributes(c *gin.Context, userID uuid.UUID, admin bool) {
+	attributes, err := h.attributeService.GetUserAttributes(c.Request.Context(), userID, admin)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to get user attributes")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(attributes, "User attributes retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// setAttributes membaca request body, menyimpan nilai attribute user, dan mengirim hasilnya
+func (h *UserAttributeHandler) setAttributes(c *gin.Context, userID uuid.UUID, admin bool) {
+	// Parse request body
+	var req model.SetUserAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	attributes, err := h.attributeService.SetUserAttributes(c.Request.Context(), userID, req.Attributes, admin)
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrUnknownAttribute:
+			response := model.Error400("Unknown attribute")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrInvalidAttributeValue:
+			response := model.Error400("Invalid attribute value")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrAttributeRequired:
+			response := model.Error400("Required attribute is missing")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrAttributeNotEditable:
+			response := model.Error403("Attribute can only be changed by an admin")
+			c.JSON(http.StatusForbidden, response)
+		default:
+			response := model.Error500("Failed to update user attributes")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(attributes, "User attributes updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes mendaftarkan rute untuk UserAttributeHandler
+func (h *UserAttributeHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	definitions := router.Group("/api/v1/user-attributes")
+	definitions.Use(authMiddleware)
+	{
+		definitions.GET("", middleware.RequireScope("users:list"), h.GetDefinitions)            // GET /api/v1/user-attributes
+		definitions.POST("", middleware.RequireScope("users:manage"), h.CreateDefinition)       // POST /api/v1/user-attributes
+		definitions.PUT("/:id", middleware.RequireScope("users:manage"), h.UpdateDefinition)    // PUT /api/v1/user-attributes/:id
+		definitions.DELETE("/:id", middleware.RequireScope("users:manage"), h.DeleteDefinition) // DELETE /api/v1/user-attributes/:id
+	}
+
+	users := router.Group("/api/v1/users")
+	users.Use(authMiddleware)
+	{
+		users.GET("/:id/attributes", middleware.RequireScope("users:read"), h.GetUserAttributes)   // GET /api/v1/users/:id/attributes
+		users.PUT("/:id/attributes", middleware.RequireScope("users:update"), h.SetUserAttributes) // PUT /api/v1/users/:id/attributes
+	}
+
+	// Attribute milik user sendiri; API key hanya dapat membaca
+	me := router.Group("/api/v1/auth/me")
+	me.Use(authMiddleware)
+	{
+		me.GET("/attributes", h.GetMyAttributes)                                                                       // GET /api/v1/auth/me/attributes
+		me.GET("/attribute-definitions", h.GetMyAttributeDefinitions)                                                  // GET /api/v1/auth/me/attribute-definitions
+		me.PUT("/attributes", middleware.UserOnlyMiddleware(), middleware.RejectAPIKeyMiddleware(), h.SetMyAttributes) // PUT /api/v1/auth/me/attributes
+	}
+}
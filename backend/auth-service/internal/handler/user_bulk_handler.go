@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// UserBulkHandler menangani request bulk action user
+type UserBulkHandler struct {
+	bulkService service.UserBulkService
+	validator   *validator.Validate
+}
+
+// NewUserBulkHandler membuat instance baru UserBulkHandler
+func NewUserBulkHandler(bulkService service.UserBulkService) *UserBulkHandler {
+	return &UserBulkHandler{
+		bulkService: bulkService,
+		validator:   validator.New(),
+	}
+}
+
+// BulkAction godoc
+// @Summary Bulk user action
+// @Description Deactivate, activate, change role, force a password reset or revoke sessions for many users, selected by user_ids or by the same filters as GET /users. Users are processed in batches and every affected user gets an audit event.
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param request body model.BulkUserActionRequest true "Bulk action request"
+// @Success 200 {object} model.BulkUserActionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/bulk [post]
+func (h *UserBulkHandler) BulkAction(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.BulkUserActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if req.Filter != nil {
+		if err := h.validator.Struct(req.Filter); err != nil {
+			response := model.Error400(err.Error())
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		req.Filter.Search = utils.SanitizeInput(req.Filter.Search)
+	}
+
+	result, err := h.bulkService.ExecuteBulkAction(c.Request.Context(), &req, auditActorFromContext(c))
+	if err != nil {
+		switch err {
+		case service.ErrBulkNoTarget:
+			response := model.Error400("Either user_ids or filter must be provided")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrBulkAmbiguous:
+			response := model.Error400("user_ids and filter cannot be combined")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrBulkTooManyUsers:
+			response := model.Error400("Bulk action exceeds the maximum number of users, narrow the filter")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrBulkRoleRequired:
+			response := model.Error400("role or role_id is required for change_role")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrBulkInvalidRoleRef:
+			response := model.Error400("role and role_id refer to different roles")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrRoleNotFound:
+			response := model.Error404("Role not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to run bulk action")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(result, "Bulk action completed")
+	c.JSON(http.StatusOK, response)
+}
+
+// RegisterRoutes mendaftarkan rute untuk UserBulkHandler
+func (h *UserBulkHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	users := router.Group("/api/v1/users")
+	users.Use(authMiddleware)
+	{
+		users.POST("/bulk", middleware.RequireScope("users:update"), h.BulkAction) // POST /api/v1/users/bulk
+	}
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/auth-service/internal/middleware"
 	"github.com/auth-service/internal/model"
 	"github.com/auth-service/internal/service"
 	"github.com/auth-service/internal/utils"
@@ -29,13 +32,27 @@ func NewUserHandler(authService service.AuthService) *UserHandler {
 
 // GetAllUsers godoc
 // @Summary Get all users
-// @Description Get all users with pagination and search
+// @Description Get all users with search, filters, sorting and offset or cursor pagination
 // @Tags user-management
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number" default(1)
+// @Param page query int false "Page number (offset pagination)" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param search query string false "Search by email or name"
+// @Param role query string false "Filter by legacy role name"
+// @Param role_id query string false "Filter by role ID"
+// @Param active query bool false "Filter by active status"
+// @Param verified query bool false "Filter by verified status"
+// @Param locked query bool false "Filter by lockout status"
+// @Param provider query string false "Filter by auth provider (local, google)"
+// @Param created_from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created at or before (RFC3339 or YYYY-MM-DD)"
+// @Param last_login_from query string false "Last login at or after (RFC3339 or YYYY-MM-DD)"
+// @Param last_login_to query string false "Last login at or before (RFC3339 or YYYY-MM-DD)"
+// @Param sort_by query string false "Sort column" Enums(created_at, email, name, last_login) default(created_at)
+// @Param sort_order query string false "Sort order" Enums(asc, desc) default(desc)
+// @Param cursor query string false "Cursor from next_cursor; pass an empty value to start cursor pagination"
+// @Param attr.name query string false "Filter by custom attribute value, e.g. attr.department=sales (exact match)"
 // @Success 200 {object} model.UsersListResponse
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -56,28 +73,148 @@ func (h *UserHandler) GetAllUsers(c *gin.Context) {
 	}
 
 	// Parse query parameters
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	search := strings.TrimSpace(c.Query("search"))
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
 
-	// Sanitasi input search
-	if search != "" {
-		search = utils.SanitizeInput(search)
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), filter.Page, filter.Limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
 	}
 
 	// Dapatkan daftar user
-	usersResponse, err := h.authService.GetAllUsers(c.Request.Context(), page, limit, search)
+	usersResponse, err := h.authService.GetAllUsers(c.Request.Context(), filter)
 	if err != nil {
-		response := model.PaginatedError500("Failed to get users", page, limit)
-		c.JSON(http.StatusInternalServerError, response)
+		switch err {
+		case service.ErrInvalidCursor:
+			response := model.PaginatedError400("Invalid cursor", filter.Page, filter.Limit)
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrUnknownAttribute:
+			response := model.PaginatedError400("Unknown attribute filter", filter.Page, filter.Limit)
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrInvalidAttributeValue:
+			response := model.PaginatedError400("Invalid attribute filter value", filter.Page, filter.Limit)
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.PaginatedError500("Failed to get users", filter.Page, filter.Limit)
+			c.JSON(http.StatusInternalServerError, response)
+		}
 		return
 	}
 
 	// Buat response dengan pagination
-	response := model.PaginatedSuccess200(usersResponse.Users, "Users retrieved successfully", page, limit, usersResponse.Total)
+	response := model.PaginatedSuccess200(usersResponse.Users, "Users retrieved successfully", usersResponse.Page, usersResponse.Limit, usersResponse.Total)
+	response.NextCursor = usersResponse.NextCursor
 	c.JSON(http.StatusOK, response)
 }
 
+// parseUserFilter membaca filter daftar user dari query parameter
+func parseUserFilter(c *gin.Context) (*model.UserFilter, error) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	filter := &model.UserFilter{
+		Search:    utils.SanitizeInput(strings.TrimSpace(c.Query("search"))),
+		Role:      strings.TrimSpace(c.Query("role")),
+		Provider:  strings.TrimSpace(c.Query("provider")),
+		SortBy:    strings.TrimSpace(c.Query("sort_by")),
+		SortOrder: strings.ToLower(strings.TrimSpace(c.Query("sort_order"))),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	// Cursor pagination aktif jika parameter cursor ada, meskipun kosong (halaman pertama)
+	filter.Cursor, filter.UseCursor = c.GetQuery("cursor")
+
+	if roleID := c.Query("role_id"); roleID != "" {
+		id, err := uuid.Parse(roleID)
+		if err != nil {
+			return filter, fmt.Errorf("invalid role_id")
+		}
+		filter.RoleID = &id
+	}
+
+	var err error
+	if filter.Active, err = parseBoolQuery(c, "active"); err != nil {
+		return filter, err
+	}
+	if filter.Verified, err = parseBoolQuery(c, "verified"); err != nil {
+		return filter, err
+	}
+	if filter.Locked, err = parseBoolQuery(c, "locked"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseTimeQuery(c, "created_from", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeQuery(c, "created_to", true); err != nil {
+		return filter, err
+	}
+	if filter.LastLoginFrom, err = parseTimeQuery(c, "last_login_from", false); err != nil {
+		return filter, err
+	}
+	if filter.LastLoginTo, err = parseTimeQuery(c, "last_login_to", true); err != nil {
+		return filter, err
+	}
+
+	// Filter custom attribute: attr.<nama>=<nilai>
+	for key, values := range c.Request.URL.Query() {
+		name := strings.TrimPrefix(key, "attr.")
+		if name == key || len(values) == 0 {
+			continue
+		}
+		if filter.AttributeQuery == nil {
+			filter.AttributeQuery = make(map[string]string)
+		}
+		filter.AttributeQuery[name] = strings.TrimSpace(values[0])
+	}
+
+	return filter, nil
+}
+
+// parseBoolQuery membaca query parameter boolean opsional
+func parseBoolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: must be true or false", key)
+	}
+
+	return &value, nil
+}
+
+// parseTimeQuery membaca query parameter waktu opsional dalam format RFC3339 atau YYYY-MM-DD.
+// Tanggal tanpa jam pada batas akhir mencakup seluruh hari tersebut.
+func parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: use RFC3339 or YYYY-MM-DD", key)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return &t, nil
+}
+
 // UpdateUser godoc
 // @Summary Update user
 // @Description Update user information
@@ -136,7 +273,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	// Update user
-	userResponse, err := h.authService.UpdateUser(c.Request.Context(), userID, &req)
+	userResponse, err := h.authService.UpdateUser(c.Request.Context(), userID, &req, auditActorFromContext(c))
 	if err != nil {
 		switch err {
 		case service.ErrUserNotFound:
@@ -156,6 +293,458 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// CreateUser godoc
+// @Summary Create user
+// @Description Create a new user as admin, either with an initial password or with a one-time invite token to set the password
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param request body model.CreateUserRequest true "Create user request"
+// @Success 201 {object} model.CreateUserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Email = utils.SanitizeInput(strings.TrimSpace(req.Email))
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+
+	createdUser, err := h.authService.CreateUser(c.Request.Context(), &req, auditActorFromContext(c))
+	if err != nil {
+		switch err {
+		case service.ErrUserAlreadyExists:
+			response := model.Error409("User with this email already exists")
+			c.JSON(http.StatusConflict, response)
+		case service.ErrPasswordRequired:
+			response := model.Error400("Password is required when no invite is sent")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrPasswordTooWeak:
+			response := model.Error400("Password is too weak")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to create user")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success201(createdUser, "User created successfully")
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetUser godoc
+// @Summary Get user
+// @Description Get user details by ID
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin atau user yang sama
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		response := model.Error401("Unauthorized")
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Cek apakah admin atau user yang sama
+	currentUserID, _ := c.Get("user_id")
+	if userRole != "admin" && currentUserID != userID {
+		response := model.Error403("Access denied")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Admin mendapatkan semua custom attribute, user sendiri hanya yang terlihat olehnya
+	var userResponse *model.UserResponse
+	if userRole == "admin" {
+		userResponse, err = h.authService.GetUserDetail(c.Request.Context(), userID)
+	} else {
+		userResponse, err = h.authService.GetUserByID(c.Request.Context(), userID)
+	}
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to get user")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(userResponse, "User retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteUser godoc
+// @Summary Delete user
+// @Description Soft delete a user and revoke all of their sessions
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id} [delete]
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Admin tidak boleh menghapus akunnya sendiri
+	if currentUserID, _ := c.Get("user_id"); currentUserID == userID {
+		response := model.Error400("You cannot delete your own account")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.authService.DeleteUser(c.Request.Context(), userID, auditActorFromContext(c)); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to delete user")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(nil, "User deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// ToggleUserStatus godoc
+// @Summary Toggle user status
+// @Description Activate or deactivate a user. Deactivating revokes all of the user's sessions
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/toggle-status [patch]
+func (h *UserHandler) ToggleUserStatus(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Admin tidak boleh menonaktifkan akunnya sendiri
+	if currentUserID, _ := c.Get("user_id"); currentUserID == userID {
+		response := model.Error400("You cannot change the status of your own account")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userResponse, err := h.authService.ToggleUserStatus(c.Request.Context(), userID, auditActorFromContext(c))
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("User not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to update user status")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	message := "User deactivated successfully"
+	if userResponse.Active {
+		message = "User activated successfully"
+	}
+
+	response := model.Success200(userResponse, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDeletedUsers godoc
+// @Summary Get deleted users
+// @Description Get soft-deleted users (trash) with pagination and search
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param search query string false "Search by email or name"
+// @Success 200 {object} model.DeletedUsersListResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/deleted [get]
+func (h *UserHandler) GetDeletedUsers(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	search := strings.TrimSpace(c.Query("search"))
+
+	// Sanitasi input search
+	if search != "" {
+		search = utils.SanitizeInput(search)
+	}
+
+	usersResponse, err := h.authService.GetDeletedUsers(c.Request.Context(), page, limit, search)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get deleted users", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(usersResponse.Users, "Deleted users retrieved successfully", usersResponse.Page, usersResponse.Limit, usersResponse.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// RestoreUser godoc
+// @Summary Restore user
+// @Description Restore a soft-deleted user from the trash
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	userResponse, err := h.authService.RestoreUser(c.Request.Context(), userID, auditActorFromContext(c))
+	if err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("Deleted user not found")
+			c.JSON(http.StatusNotFound, response)
+		default:
+			response := model.Error500("Failed to restore user")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(userResponse, "User restored successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// PurgeUser godoc
+// @Summary Purge user
+// @Description Permanently delete a soft-deleted user and their login history once the retention period has elapsed
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/purge [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse user ID dari URL
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		response := model.Error400("Invalid user ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.authService.PurgeUser(c.Request.Context(), userID, auditActorFromContext(c)); err != nil {
+		switch err {
+		case service.ErrUserNotFound:
+			response := model.Error404("Deleted user not found")
+			c.JSON(http.StatusNotFound, response)
+		case service.ErrRetentionNotElapsed:
+			response := model.Error409("User cannot be purged before the retention period has elapsed")
+			c.JSON(http.StatusConflict, response)
+		default:
+			response := model.Error500("Failed to purge user")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	response := model.Success200(nil, "User purged successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// PurgeExpiredUsers godoc
+// @Summary Purge expired users
+// @Description Permanently delete all soft-deleted users whose retention period has elapsed
+// @Tags user-management
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.PurgeUsersResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/deleted/purge [post]
+func (h *UserHandler) PurgeExpiredUsers(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	purged, err := h.authService.PurgeExpiredUsers(c.Request.Context(), auditActorFromContext(c))
+	if err != nil {
+		response := model.Error500("Failed to purge users")
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.Success200(model.PurgeUsersResponse{Purged: purged}, "Expired users purged successfully")
+	c.JSON(http.StatusOK, response)
+}
+
 // GetUserStats godoc
 // @Summary Get user statistics
 // @Description Get user statistics for admin dashboard
@@ -266,9 +855,17 @@ func (h *UserHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.Hand
 	users := router.Group("/api/v1/users")
 	users.Use(authMiddleware) // Semua endpoint memerlukan autentikasi
 	{
-		users.GET("", h.GetAllUsers)                  // GET /api/v1/users
-		users.PUT("/:id", h.UpdateUser)               // PUT /api/v1/users/:id
-		users.GET("/stats", h.GetUserStats)           // GET /api/v1/users/stats
-		users.GET("/:id/activity", h.GetUserActivity) // GET /api/v1/users/:id/activity
+		users.GET("", middleware.RequireScope("users:list"), h.GetAllUsers)                            // GET /api/v1/users
+		users.POST("", middleware.RequireScope("users:create"), h.CreateUser)                          // POST /api/v1/users
+		users.GET("/:id", middleware.RequireScope("users:read"), h.GetUser)                            // GET /api/v1/users/:id
+		users.PUT("/:id", middleware.RequireScope("users:update"), h.UpdateUser)                       // PUT /api/v1/users/:id
+		users.DELETE("/:id", middleware.RequireScope("users:delete"), h.DeleteUser)                    // DELETE /api/v1/users/:id
+		users.PATCH("/:id/toggle-status", middleware.RequireScope("users:update"), h.ToggleUserStatus) // PATCH /api/v1/users/:id/toggle-status
+		users.GET("/deleted", middleware.RequireScope("users:list"), h.GetDeletedUsers)                // GET /api/v1/users/deleted
+		users.POST("/deleted/purge", middleware.RequireScope("users:delete"), h.PurgeExpiredUsers)     // POST /api/v1/users/deleted/purge
+		users.POST("/:id/restore", middleware.RequireScope("users:update"), h.RestoreUser)             // POST /api/v1/users/:id/restore
+		users.DELETE("/:id/purge", middleware.RequireScope("users:delete"), h.PurgeUser)               // DELETE /api/v1/users/:id/purge
+		users.GET("/stats", middleware.RequireScope("users:list"), h.GetUserStats)                     // GET /api/v1/users/stats
+		users.GET("/:id/activity", middleware.RequireScope("users:read"), h.GetUserActivity)           // GET /api/v1/users/:id/activity
 	}
 }
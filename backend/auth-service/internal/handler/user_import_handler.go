@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxImportFileSize membatasi ukuran file bulk import (5 MB)
+const maxImportFileSize = 5 << 20
+
+// UserImportHandler menangani request bulk import dan export user
+type UserImportHandler struct {
+	importService service.UserImportService
+	validator     *validator.Validate
+}
+
+// NewUserImportHandler membuat instance baru UserImportHandler
+func NewUserImportHandler(importService service.UserImportService) *UserImportHandler {
+	return &UserImportHandler{
+		importService: importService,
+		validator:     validator.New(),
+	}
+}
+
+// ImportUsers godoc
+// @Summary Bulk import users
+// @Description Import users from a CSV (header: email,name,password,role,verified,send_invite) or JSON array file. Every row is validated and reported individually; with dry_run nothing is created.
+// @Tags user-management
+// @Accept multipart/form-data
+// @Accept json
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "CSV or JSON file (when using multipart/form-data)"
+// @Param format query string false "File format, detected from the file name or Content-Type if omitted" Enums(csv, json)
+// @Param dry_run query bool false "Validate only, do not create users" default(false)
+// @Success 200 {object} model.ImportUsersResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/import [post]
+func (h *UserImportHandler) ImportUsers(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		response := model.Error400("Invalid dry_run: must be true or false")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize)
+
+	// File dapat dikirim sebagai multipart/form-data atau langsung sebagai body request
+	var reader io.Reader = c.Request.Body
+	format := strings.ToLower(c.Query("format"))
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response := model.Error400("File is required")
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			response := model.Error400("Failed to read file")
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		defer file.Close()
+
+		reader = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+	} else if format == "" {
+		format = model.UserImportFormatJSON
+		if strings.Contains(c.ContentType(), "csv") {
+			format = model.UserImportFormatCSV
+		}
+	}
+
+	var rows []model.ImportUserRow
+	switch format {
+	case model.UserImportFormatCSV:
+		rows, err = parseImportCSV(reader)
+	case model.UserImportFormatJSON:
+		rows, err = parseImportJSON(reader)
+	default:
+		response := model.Error400("Unsupported import format, use csv or json")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			response := model.NewErrorResponse(http.StatusRequestEntityTooLarge, "Import file is too large")
+			c.JSON(http.StatusRequestEntityTooLarge, response)
+			return
+		}
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.importService.ImportUsers(c.Request.Context(), rows, dryRun, auditActorFromContext(c))
+	if err != nil {
+		switch err {
+		case service.ErrImportEmpty:
+			response := model.Error400("Import file contains no rows")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrImportTooManyRows:
+			response := model.Error400("Import file exceeds the maximum number of rows")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to import users")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	message := "Users imported successfully"
+	if dryRun {
+		message = "Import validated successfully, no users were created"
+	}
+
+	response := model.Success200(result, message)
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportUsers godoc
+// @Summary Export users
+// @Description Stream all users matching the same filters as GET /users as CSV or NDJSON
+// @Tags user-management
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "Export format" Enums(csv, ndjson) default(csv)
+// @Param search query string false "Search by email or name"
+// @Param role query string false "Filter by legacy role name"
+// @Param role_id query string false "Filter by role ID"
+// @Param active query bool false "Filter by active status"
+// @Param verified query bool false "Filter by verified status"
+// @Param locked query bool false "Filter by lockout status"
+// @Param provider query string false "Filter by auth provider"
+// @Param created_from query string false "Created at or after (RFC3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Created at or before (RFC3339 or YYYY-MM-DD)"
+// @Param last_login_from query string false "Last login at or after (RFC3339 or YYYY-MM-DD)"
+// @Param last_login_to query string false "Last login at or before (RFC3339 or YYYY-MM-DD)"
+// @Param sort_by query string false "Sort column" Enums(created_at, email, name, last_login)
+// @Param sort_order query string false "Sort order" Enums(asc, desc)
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/export [get]
+func (h *UserImportHandler) ExportUsers(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", model.UserExportFormatCSV))
+	if format != model.UserExportFormatCSV && format != model.UserExportFormatJSON {
+		response := model.Error400("Unsupported export format, use csv or ndjson")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Filter sama dengan GET /api/v1/users
+	filter, err := parseUserFilter(c)
+	if err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	filename := fmt.Sprintf("users-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	contentType := "text/csv; charset=utf-8"
+	if format == model.UserExportFormatJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	// Header response baru dikirim saat batch pertama ditulis, sehingga error sebelum itu
+	// masih dapat dikembalikan sebagai JSON
+	started := false
+	csvWriter := csv.NewWriter(c.Writer)
+	encoder := json.NewEncoder(c.Writer)
+
+	err = h.importService.ExportUsers(c.Request.Context(), filter, func(users []model.UserResponse) error {
+		if !started {
+			started = true
+			c.Header("Content-Type", contentType)
+			c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+			c.Status(http.StatusOK)
+			if format == model.UserExportFormatCSV {
+				if err := csvWriter.Write(userExportCSVHeader); err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := range users {
+			if format == model.UserExportFormatCSV {
+				if err := csvWriter.Write(userExportCSVRecord(&users[i])); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := encoder.Encode(&users[i]); err != nil {
+				return err
+			}
+		}
+
+		if format == model.UserExportFormatCSV {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+
+	if err != nil {
+		if started {
+			// Response sudah terkirim sebagian, koneksi diputus agar klien tahu export tidak lengkap
+			log.Printf("User export aborted: %v", err)
+			c.Abort()
+			return
+		}
+		switch err {
+		case service.ErrUnknownAttribute:
+			response := model.Error400("Unknown attribute filter")
+			c.JSON(http.StatusBadRequest, response)
+		case service.ErrInvalidAttributeValue:
+			response := model.Error400("Invalid attribute filter value")
+			c.JSON(http.StatusBadRequest, response)
+		default:
+			response := model.Error500("Failed to export users")
+			c.JSON(http.StatusInternalServerError, response)
+		}
+		return
+	}
+
+	// Tidak ada user yang cocok, tetap kirim file kosong (dengan header kolom untuk CSV)
+	if !started {
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+		c.Status(http.StatusOK)
+		if format == model.UserExportFormatCSV {
+			csvWriter.Write(userExportCSVHeader)
+			csvWriter.Flush()
+		}
+	}
+}
+
+// userExportCSVHeader adalah kolom pada file export CSV
+var userExportCSVHeader = []string{"id", "email", "name", "provider", "role", "role_id", "verified", "active", "last_login", "created_at", "updated_at"}
+
+// userExportCSVRecord mengubah user menjadi satu baris CSV sesuai userExportCSVHeader
+func userExportCSVRecord(user *model.UserResponse) []string {
+	roleID := ""
+	if user.RoleID != nil {
+		roleID = user.RoleID.String()
+	}
+	lastLogin := ""
+	if !user.LastLogin.IsZero() {
+		lastLogin = user.LastLogin.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		user.ID.String(),
+		user.Email,
+		user.Name,
+		user.Provider,
+		user.Role,
+		roleID,
+		strconv.FormatBool(user.Verified),
+		strconv.FormatBool(user.Active),
+		lastLogin,
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// parseImportCSV membaca baris import dari CSV dengan baris header.
+// Kolom email dan name wajib ada, kolom lain opsional dan urutannya bebas.
+func parseImportCSV(r io.Reader) ([]model.ImportUserRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Hapus BOM UTF-8 yang sering ditambahkan oleh spreadsheet
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"email", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain the %q column", required)
+		}
+	}
+
+	var rows []model.ImportUserRow
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := model.ImportUserRow{
+			Email:    value("email"),
+			Name:     value("name"),
+			Password: value("password"),
+			Role:     value("role"),
+		}
+		if row.Verified, err = parseCSVBool(value("verified")); err != nil {
+			return nil, fmt.Errorf("row %d: invalid verified value", line)
+		}
+		if row.SendInvite, err = parseCSVBool(value("send_invite")); err != nil {
+			return nil, fmt.Errorf("row %d: invalid send_invite value", line)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseCSVBool membaca nilai boolean CSV, nilai kosong dianggap false
+func parseCSVBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseImportJSON membaca baris import dari array JSON
+func parseImportJSON(r io.Reader) ([]model.ImportUserRow, error) {
+	var rows []model.ImportUserRow
+	if err := json.NewDecoder(r).Decode(&rows); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		if strings.Contains(err.Error(), "request body too large") {
+			return nil, err
+		}
+		return nil, fmt.Errorf("invalid JSON: expected an array of users")
+	}
+
+	return rows, nil
+}
+
+// RegisterRoutes mendaftarkan rute untuk UserImportHandler
+func (h *UserImportHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	users := router.Group("/api/v1/users")
+	users.Use(authMiddleware)
+	{
+		users.POST("/import", middleware.RequireScope("users:create"), h.ImportUsers) // POST /api/v1/users/import
+		users.GET("/export", middleware.RequireScope("users:list"), h.ExportUsers)    // GET /api/v1/users/export
+	}
+}
@@ -0,0 +1,623 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/service"
+	"github.com/auth-service/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// WebhookHandler menangani request pengelolaan subscription dan log pengiriman webhook
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	validator      *validator.Validate
+}
+
+// NewWebhookHandler membuat instance baru WebhookHandler
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validator:      validator.New(),
+	}
+}
+
+// GetEventTypes godoc
+// @Summary List webhook event types
+// @Description Get the identity event types that can be subscribed to. Use "*" to subscribe to all events.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/events [get]
+func (h *WebhookHandler) GetEventTypes(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	response := model.Success200(model.WebhookEventTypes, "Webhook event types retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAllSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description Get all webhook subscriptions with pagination and search
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param search query string false "Search by name or URL"
+// @Success 200 {object} model.WebhookSubscriptionsListResponse
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAllSubscriptions(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	search := strings.TrimSpace(c.Query("search"))
+
+	// Sanitasi input search
+	if search != "" {
+		search = utils.SanitizeInput(search)
+	}
+
+	result, err := h.webhookService.GetAllSubscriptions(c.Request.Context(), page, limit, search)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get webhook subscriptions", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Subscriptions, "Webhook subscriptions retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSubscription godoc
+// @Summary Get webhook subscription by ID
+// @Description Get webhook subscription details by ID
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} model.WebhookSubscriptionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid subscription ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscriptionByID(c.Request.Context(), id)
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to get webhook subscription")
+		return
+	}
+
+	response := model.Success200(subscription, "Webhook subscription retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateSubscription godoc
+// @Summary Create webhook subscription
+// @Description Subscribe an endpoint to identity events. The signing secret is only returned once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body model.CreateWebhookSubscriptionRequest true "Create webhook subscription request"
+// @Success 201 {object} model.WebhookSubscriptionSecretResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse request body
+	var req model.CreateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	req.Name = utils.SanitizeInput(strings.TrimSpace(req.Name))
+	req.Description = utils.SanitizeInput(strings.TrimSpace(req.Description))
+	req.URL = strings.TrimSpace(req.URL)
+
+	result, err := h.webhookService.CreateSubscription(c.Request.Context(), &req, auditActorFromContext(c))
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to create webhook subscription")
+		return
+	}
+
+	response := model.Success201(result, "Webhook subscription created successfully. Store the signing secret now, it will not be shown again")
+	c.JSON(http.StatusCreated, response)
+}
+
+// UpdateSubscription godoc
+// @Summary Update webhook subscription
+// @Description Update a webhook subscription. Fields that are not sent are left unchanged.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body model.UpdateWebhookSubscriptionRequest true "Update webhook subscription request"
+// @Success 200 {object} model.WebhookSubscriptionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid subscription ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Parse request body
+	var req model.UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response := model.Error400("Invalid request format")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Validasi request
+	if err := h.validator.Struct(req); err != nil {
+		response := model.Error400(err.Error())
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	// Sanitasi input
+	if req.Name != nil {
+		name := utils.SanitizeInput(strings.TrimSpace(*req.Name))
+		req.Name = &name
+	}
+	if req.Description != nil {
+		description := utils.SanitizeInput(strings.TrimSpace(*req.Description))
+		req.Description = &description
+	}
+	if req.URL != nil {
+		url := strings.TrimSpace(*req.URL)
+		req.URL = &url
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(c.Request.Context(), id, &req, auditActorFromContext(c))
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to update webhook subscription")
+		return
+	}
+
+	response := model.Success200(subscription, "Webhook subscription updated successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteSubscription godoc
+// @Summary Delete webhook subscription
+// @Description Delete a webhook subscription. Pending deliveries are closed as dead, delivery logs are kept.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid subscription ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), id, auditActorFromContext(c)); err != nil {
+		h.writeWebhookError(c, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	response := model.Success200(nil, "Webhook subscription deleted successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// RotateSubscriptionSecret godoc
+// @Summary Rotate webhook signing secret
+// @Description Generate a new signing secret for a webhook subscription. The old secret stops working immediately.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} model.WebhookSubscriptionSecretResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateSubscriptionSecret(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid subscription ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.webhookService.RotateSubscriptionSecret(c.Request.Context(), id, auditActorFromContext(c))
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to rotate webhook signing secret")
+		return
+	}
+
+	response := model.Success200(result, "Webhook signing secret rotated successfully. Store the signing secret now, it will not be shown again")
+	c.JSON(http.StatusOK, response)
+}
+
+// SendTestEvent godoc
+// @Summary Send test webhook
+// @Description Queue a webhook.ping event to a subscription to check the endpoint and signature verification
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id}/test [post]
+func (h *WebhookHandler) SendTestEvent(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid subscription ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	delivery, err := h.webhookService.SendTestEvent(c.Request.Context(), id)
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to queue test webhook")
+		return
+	}
+
+	response := model.NewSuccessResponse(http.StatusAccepted, delivery, "Test webhook queued for delivery")
+	c.JSON(http.StatusAccepted, response)
+}
+
+// GetDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get webhook delivery logs, newest first
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param subscription_id query string false "Filter by subscription ID"
+// @Param event_type query string false "Filter by event type"
+// @Param status query string false "Filter by status" Enums(pending, failed, succeeded, dead)
+// @Success 200 {object} model.WebhookDeliveriesListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	h.listDeliveries(c, c.Query("subscription_id"))
+}
+
+// GetSubscriptionDeliveries godoc
+// @Summary List deliveries of a webhook subscription
+// @Description Get delivery logs of one webhook subscription, newest first
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param event_type query string false "Filter by event type"
+// @Param status query string false "Filter by status" Enums(pending, failed, succeeded, dead)
+// @Success 200 {object} model.WebhookDeliveriesListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetSubscriptionDeliveries(c *gin.Context) {
+	h.listDeliveries(c, c.Param("id"))
+}
+
+// listDeliveries menangani daftar log pengiriman, opsional dibatasi pada satu subscription
+func (h *WebhookHandler) listDeliveries(c *gin.Context, subscriptionID string) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	// Parse query parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := &model.WebhookDeliveryFilter{
+		EventType: strings.TrimSpace(c.Query("event_type")),
+		Status:    strings.TrimSpace(c.Query("status")),
+		Page:      page,
+		Limit:     limit,
+	}
+	if subscriptionID != "" {
+		id, err := uuid.Parse(subscriptionID)
+		if err != nil {
+			response := model.PaginatedError400("Invalid subscription ID", page, limit)
+			c.JSON(http.StatusBadRequest, response)
+			return
+		}
+		filter.SubscriptionID = &id
+	}
+
+	// Validasi filter
+	if err := h.validator.Struct(filter); err != nil {
+		response := model.PaginatedError400(err.Error(), page, limit)
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	result, err := h.webhookService.GetDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		response := model.PaginatedError500("Failed to get webhook deliveries", page, limit)
+		c.JSON(http.StatusInternalServerError, response)
+		return
+	}
+
+	response := model.PaginatedSuccess200(result.Deliveries, "Webhook deliveries retrieved successfully", result.Page, result.Limit, result.Total)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetDelivery godoc
+// @Summary Get webhook delivery
+// @Description Get a webhook delivery with its payload and the log of every attempt
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid delivery ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to get webhook delivery")
+		return
+	}
+
+	response := model.Success200(delivery, "Webhook delivery retrieved successfully")
+	c.JSON(http.StatusOK, response)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Queue a finished (succeeded or dead) delivery again with a full retry budget
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 202 {object} model.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Security BearerAuth
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	// Set header keamanan
+	utils.SetSecureHeaders(c)
+
+	// Cek role admin
+	userRole, exists := c.Get("user_role")
+	if !exists || userRole != "admin" {
+		response := model.Error403("Admin access required")
+		c.JSON(http.StatusForbidden, response)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response := model.Error400("Invalid delivery ID")
+		c.JSON(http.StatusBadRequest, response)
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		h.writeWebhookError(c, err, "Failed to redeliver webhook")
+		return
+	}
+
+	response := model.NewSuccessResponse(http.StatusAccepted, delivery, "Webhook delivery queued again")
+	c.JSON(http.StatusAccepted, response)
+}
+
+// writeWebhookError memetakan error service ke response standar untuk endpoint webhook
+func (h *WebhookHandler) writeWebhookError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case service.ErrWebhookSubscriptionNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Webhook subscription not found"))
+	case service.ErrWebhookDeliveryNotFound:
+		c.JSON(http.StatusNotFound, model.Error404("Webhook delivery not found"))
+	case service.ErrInvalidWebhookEvent:
+		c.JSON(http.StatusBadRequest, model.Error400("Unknown event type, see GET /api/v1/webhooks/events"))
+	case service.ErrInvalidWebhookURL:
+		c.JSON(http.StatusBadRequest, model.Error400("Webhook URL must be an absolute https URL without credentials"))
+	case service.ErrWebhookURLNotAllowed:
+		c.JSON(http.StatusBadRequest, model.Error400("Webhook URL must not target loopback, private or link-local addresses"))
+	case service.ErrWebhookDeliveryInProgress:
+		c.JSON(http.StatusConflict, model.Error409("Delivery is still being retried"))
+	default:
+		c.JSON(http.StatusInternalServerError, model.Error500(fallback))
+	}
+}
+
+// RegisterRoutes mendaftarkan rute untuk WebhookHandler
+func (h *WebhookHandler) RegisterRoutes(router *gin.Engine, authMiddleware gin.HandlerFunc) {
+	webhooks := router.Group("/api/v1/webhooks")
+	webhooks.Use(authMiddleware, middleware.RequireScope("webhooks:manage"))
+	{
+		webhooks.GET("", h.GetAllSubscriptions)                         // GET /api/v1/webhooks
+		webhooks.POST("", h.CreateSubscription)                         // POST /api/v1/webhooks
+		webhooks.GET("/events", h.GetEventTypes)                        // GET /api/v1/webhooks/events
+		webhooks.GET("/deliveries", h.GetDeliveries)                    // GET /api/v1/webhooks/deliveries
+		webhooks.GET("/deliveries/:id", h.GetDelivery)                  // GET /api/v1/webhooks/deliveries/:id
+		webhooks.POST("/deliveries/:id/redeliver", h.Redeliver)         // POST /api/v1/webhooks/deliveries/:id/redeliver
+		webhooks.GET("/:id", h.GetSubscription)                         // GET /api/v1/webhooks/:id
+		webhooks.PUT("/:id", h.UpdateSubscription)                      // PUT /api/v1/webhooks/:id
+		webhooks.DELETE("/:id", h.DeleteSubscription)                   // DELETE /api/v1/webhooks/:id
+		webhooks.POST("/:id/rotate-secret", h.RotateSubscriptionSecret) // POST /api/v1/webhooks/:id/rotate-secret
+		webhooks.POST("/:id/test", h.SendTestEvent)                     // POST /api/v1/webhooks/:id/test
+		webhooks.GET("/:id/deliveries", h.GetSubscriptionDeliveries)    // GET /api/v1/webhooks/:id/deliveries
+	}
+}
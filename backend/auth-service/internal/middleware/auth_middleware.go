@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"net/http"
+	"reflect"
+	"runtime"
 	"strings"
 
 	"github.com/auth-service/internal/model"
@@ -10,8 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware adalah middleware untuk memvalidasi JWT token
-func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+// AuthMiddleware adalah middleware untuk memvalidasi JWT token atau API key
+func AuthMiddleware(authService service.AuthService, apiKeyService service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Set header keamanan
 		utils.SetSecureHeaders(c)
@@ -20,24 +22,60 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		tokenString, err := utils.ExtractTokenFromHeader(authHeader)
 		if err != nil || tokenString == "" {
-			// Coba dapatkan dari cookie
-			tokenCookie, err := c.Cookie("access_token")
-			if err != nil || tokenCookie == "" {
-				response := model.Error401("Authorization token is required")
-				c.AbortWithStatusJSON(http.StatusUnauthorized, response)
-				return
+			// Coba dapatkan dari header X-API-Key atau cookie
+			if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+				tokenString = apiKey
+			} else {
+				tokenCookie, err := c.Cookie("access_token")
+				if err != nil || tokenCookie == "" {
+					response := model.Error401("Authorization token is required")
+					c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+					return
+				}
+				tokenString = tokenCookie
 			}
-			tokenString = tokenCookie
+		}
+
+		// API key dikenali dari prefix-nya
+		if strings.HasPrefix(tokenString, model.APIKeyPrefix) {
+			authenticateAPIKey(c, apiKeyService, tokenString)
+			return
 		}
 
 		// Validasi token
 		claims, err := authService.ValidateToken(c.Request.Context(), tokenString)
+		if err == service.ErrInternalServerError {
+			response := model.Error500("Failed to validate token")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, response)
+			return
+		}
 		if err != nil {
 			response := model.Error401("Invalid or expired token")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
 			return
 		}
 
+		// Token hasil token exchange untuk layanan lain tidak boleh digunakan di layanan ini
+		if len(claims.Audience) > 0 && !claims.VerifyAudience(utils.TokenIssuer, true) {
+			response := model.Error401("Token is not intended for this service")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+			return
+		}
+
+		// Token service account tidak terkait dengan user, sehingga tidak ada user_id di konteks
+		if claims.IsClient() {
+			c.Set("subject_type", utils.SubjectTypeClient)
+			c.Set("auth_method", "client_credentials")
+			c.Set("client_id", claims.ClientID)
+			c.Set("user_role", claims.Role)
+			c.Set("scope", claims.Scope)
+			if !allowScopedRoute(c) {
+				return
+			}
+			c.Next()
+			return
+		}
+
 		// Get user ID from claims
 		userID := claims.UserID
 
@@ -55,6 +93,134 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 		c.Set("user_id", userID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
+		c.Set("subject_type", utils.SubjectTypeUser)
+		c.Set("auth_method", "jwt")
+
+		// Token hasil token exchange mencatat client yang bertindak atas nama user
+		if claims.Act != nil {
+			c.Set("actor_client_id", claims.Act.ClientID)
+		}
+
+		// Token hasil token exchange dibatasi scope-nya
+		if claims.Scope != "" {
+			c.Set("scope", claims.Scope)
+			if !allowScopedRoute(c) {
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey memvalidasi API key dan mengisi konteks dengan data pemiliknya
+func authenticateAPIKey(c *gin.Context, apiKeyService service.APIKeyService, rawKey string) {
+	principal, err := apiKeyService.ValidateAPIKey(c.Request.Context(), rawKey, utils.GetClientIP(c))
+	if err != nil {
+		if err == service.ErrUserInactive {
+			response := model.Error403("User account is inactive or not found")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+		response := model.Error401("Invalid or expired API key")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	c.Set("user_id", principal.User.ID)
+	c.Set("user_email", principal.User.Email)
+	c.Set("user_role", principal.User.Role)
+	c.Set("subject_type", utils.SubjectTypeUser)
+	c.Set("auth_method", "api_key")
+	c.Set("api_key_id", principal.KeyID)
+	c.Set("scope", strings.Join(principal.Scopes, " "))
+	if !allowScopedRoute(c) {
+		return
+	}
+
+	c.Next()
+}
+
+// requireScopeHandlerName adalah nama fungsi handler yang dibuat RequireScope di rantai handler gin
+var requireScopeHandlerName = runtime.FuncForPC(reflect.ValueOf(RequireScope("")).Pointer()).Name()
+
+// allowScopedRoute menolak token ber-scope (API key, service account, dan token hasil exchange)
+// pada rute yang tidak mendeklarasikan scope dengan RequireScope, sehingga rute yang lupa
+// memasang RequireScope tidak memberikan akses penuh user kepada token tersebut
+func allowScopedRoute(c *gin.Context) bool {
+	for _, name := range c.HandlerNames() {
+		if name == requireScopeHandlerName {
+			return true
+		}
+	}
+
+	response := model.Error403("Token scope does not grant access to this endpoint")
+	c.AbortWithStatusJSON(http.StatusForbidden, response)
+	return false
+}
+
+// RequireScope membatasi akses token ber-scope (API key dan service account) ke scope tertentu.
+// Sesi login biasa tidak memiliki scope di konteks dan tetap diatur oleh pemeriksaan role.
+// Token ber-scope hanya diterima pada rute yang memasang RequireScope (lihat allowScopedRoute).
+func RequireScope(scope string) gin.HandlerFunc {
+	resource, _, _ := strings.Cut(scope, ":")
+	return func(c *gin.Context) {
+		granted, exists := c.Get("scope")
+		if !exists {
+			c.Next()
+			return
+		}
+
+		for _, s := range strings.Fields(granted.(string)) {
+			if s == scope || s == resource+":manage" {
+				c.Next()
+				return
+			}
+		}
+
+		response := model.Error403("Token does not have the required scope: " + scope)
+		c.AbortWithStatusJSON(http.StatusForbidden, response)
+	}
+}
+
+// RejectAPIKeyMiddleware menolak request yang diautentikasi dengan API key,
+// misalnya untuk mencegah API key membuat API key baru
+func RejectAPIKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authMethod, _ := c.Get("auth_method"); authMethod == "api_key" {
+			response := model.Error403("This endpoint is not available when authenticating with an API key")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// FullSessionOnlyMiddleware menolak token ber-scope dan token hasil token exchange, misalnya
+// pada persetujuan device yang menerbitkan sesi baru atas nama user
+func FullSessionOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, scoped := c.Get("scope")
+		_, delegated := c.Get("actor_client_id")
+		if scoped || delegated {
+			response := model.Error403("This endpoint requires a full login session")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// UserOnlyMiddleware menolak request dari service account pada endpoint yang khusus untuk user
+func UserOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subjectType, _ := c.Get("subject_type"); subjectType == utils.SubjectTypeClient {
+			response := model.Error403("This endpoint is not available to service accounts")
+			c.AbortWithStatusJSON(http.StatusForbidden, response)
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cdnGeoHeaders adalah header visitor location Cloudflare yang dipakai untuk penilaian risiko login
+var cdnGeoHeaders = []string{"CF-IPCountry", "CF-IPCity", "CF-IPLatitude", "CF-IPLongitude"}
+
+// CDNGeoHeadersMiddleware menghapus header lokasi CDN dari request kecuali fitur diaktifkan dan
+// koneksi berasal dari proxy tepercaya, sehingga klien tidak dapat memalsukan lokasinya
+func CDNGeoHeadersMiddleware(enabled bool, trustedProxies []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !enabled || !remoteAddrTrusted(c.Request.RemoteAddr, trustedProxies) {
+			for _, header := range cdnGeoHeaders {
+				c.Request.Header.Del(header)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// remoteAddrTrusted memeriksa apakah alamat koneksi berada di salah satu jaringan tepercaya
+func remoteAddrTrusted(remoteAddr string, networks []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix adalah prefix untuk semua API key agar mudah dikenali (misalnya oleh secret scanner)
+const APIKeyPrefix = "ak_"
+
+// APIKey merepresentasikan personal access token milik user
+type APIKey struct {
+	ID         uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	UserID     uuid.UUID      `gorm:"type:char(36);index" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100)" json:"name"`
+	Prefix     string         `gorm:"type:varchar(16)" json:"prefix"` // beberapa karakter awal key untuk identifikasi
+	KeyHash    string         `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	Scopes     string         `gorm:"type:varchar(1000)" json:"scopes"` // daftar scope dipisah spasi
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	LastUsedIP string         `gorm:"type:varchar(50)" json:"last_used_ip"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan API key baru
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsUsable mengembalikan true jika API key belum dicabut dan belum kedaluwarsa
+func (k *APIKey) IsUsable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// APIKeyResponse adalah struktur untuk respons API key tanpa nilai rahasia
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// APIKeyCreatedResponse berisi API key dalam bentuk plaintext.
+// Hanya dikembalikan sekali saat key dibuat.
+type APIKeyCreatedResponse struct {
+	APIKey APIKeyResponse `json:"api_key"`
+	Key    string         `json:"key"`
+}
+
+// ToAPIKeyResponse mengkonversi APIKey ke APIKeyResponse
+func (k *APIKey) ToAPIKeyResponse() APIKeyResponse {
+	scopes := strings.Fields(k.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		LastUsedIP: k.LastUsedIP,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+		UpdatedAt:  k.UpdatedAt,
+	}
+}
+
+// CreateAPIKeyRequest adalah struktur untuk request pembuatan API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"` // opsional, kosong = tidak kedaluwarsa
+}
+
+// UpdateAPIKeyRequest adalah struktur untuk request update API key
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Scopes []string `json:"scopes" validate:"omitempty,min=1"`
+}
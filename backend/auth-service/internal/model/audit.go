@@ -0,0 +1,295 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jenis aktor pada audit log
+const (
+	AuditActorUser   = "user"   // user yang login (JWT atau API key)
+	AuditActorClient = "client" // service account (client credentials)
+	AuditActorSystem = "system" // proses internal tanpa aktor
+)
+
+// Aksi audit untuk pengelolaan user oleh admin
+const (
+	AuditActionUserCreated         = "user.created"
+	AuditActionUserUpdated         = "user.updated"
+	AuditActionUserDeleted         = "user.deleted"
+	AuditActionUserRestored        = "user.restored"
+	AuditActionUserPurged          = "user.purged"
+	AuditActionUserActivated       = "user.activated"
+	AuditActionUserDeactivated     = "user.deactivated"
+	AuditActionUserRoleChanged     = "user.role_changed"
+	AuditActionUserPasswordReset   = "user.password_reset_forced"
+	AuditActionUserSessionsRevoked = "user.sessions_revoked"
+)
+
+// Aksi audit untuk autentikasi dan pencabutan token
+const (
+	AuditActionLoginSucceeded = "auth.login_succeeded"
+	AuditActionLoginFailed    = "auth.login_failed"
+	AuditActionLogout         = "auth.logout"
+	AuditActionTokenRevoked   = "token.revoked"
+	AuditActionAPIKeyRevoked  = "api_key.revoked"
+)
+
+// Aksi audit untuk pengelolaan role dan permission
+const (
+	AuditActionRoleCreated            = "role.created"
+	AuditActionRoleUpdated            = "role.updated"
+	AuditActionRoleDeleted            = "role.deleted"
+	AuditActionRolePermissionsChanged = "role.permissions_changed"
+	AuditActionPermissionCreated      = "permission.created"
+	AuditActionPermissionUpdated      = "permission.updated"
+	AuditActionPermissionDeleted      = "permission.deleted"
+)
+
+// Aksi audit untuk pengelolaan subscription webhook
+const (
+	AuditActionWebhookCreated       = "webhook.created"
+	AuditActionWebhookUpdated       = "webhook.updated"
+	AuditActionWebhookDeleted       = "webhook.deleted"
+	AuditActionWebhookSecretRotated = "webhook.secret_rotated"
+)
+
+// Aksi audit untuk job pemeliharaan
+const (
+	AuditActionJobTriggered = "job.triggered"
+)
+
+// Resource pada audit log
+const (
+	AuditResourceUser       = "user"
+	AuditResourceSession    = "session"
+	AuditResourceToken      = "token"
+	AuditResourceAPIKey     = "api_key"
+	AuditResourceRole       = "role"
+	AuditResourcePermission = "permission"
+	AuditResourceWebhook    = "webhook"
+	AuditResourceJob        = "job"
+)
+
+// Format export audit log
+const (
+	AuditExportFormatCSV  = "csv"
+	AuditExportFormatJSON = "ndjson"
+)
+
+// AuditGenesisHash adalah prev_hash untuk entry pertama pada hash chain audit log
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditLog adalah catatan audit append-only yang disimpan di tabel user_activities.
+// UserID adalah user yang terdampak (kosong untuk aksi pada role atau permission),
+// sedangkan Actor* adalah pihak yang melakukan aksi. Setiap entry terhubung ke entry
+// sebelumnya melalui PrevHash sehingga perubahan setelah ditulis dapat dideteksi.
+type AuditLog struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	Sequence   *uint64    `gorm:"uniqueIndex" json:"sequence"` // posisi pada hash chain, NULL untuk entry sebelum hash chain diaktifkan
+	UserID     *uuid.UUID `gorm:"type:char(36);index" json:"user_id"`
+	ActorID    *uuid.UUID `gorm:"type:char(36);index" json:"actor_id"`
+	ActorType  string     `gorm:"type:varchar(20)" json:"actor_type"`
+	Actor      string     `gorm:"type:varchar(255)" json:"actor"` // email user atau client ID service account
+	Action     string     `gorm:"type:varchar(100);index" json:"action"`
+	Resource   string     `gorm:"type:varchar(100);index" json:"resource"`
+	ResourceID string     `gorm:"type:varchar(100)" json:"resource_id"`
+	Details    string     `gorm:"type:json" json:"details"`
+	IPAddress  string     `gorm:"type:varchar(50)" json:"ip_address"`
+	UserAgent  string     `gorm:"type:varchar(255)" json:"user_agent"`
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+	PrevHash   string     `gorm:"type:char(64)" json:"prev_hash"`
+	Hash       string     `gorm:"type:char(64)" json:"hash"`
+}
+
+// TableName mengembalikan nama tabel untuk AuditLog
+func (AuditLog) TableName() string {
+	return "user_activities"
+}
+
+// ComputeHash menghitung hash SHA-256 (hex) dari isi entry dan PrevHash. Details
+// dinormalisasi lebih dulu karena MySQL menyimpan kolom JSON dalam bentuk kanonis
+// miliknya sendiri, dan waktu dihitung dalam detik unix agar tidak bergantung pada
+// presisi kolom maupun zona waktu koneksi.
+func (a *AuditLog) ComputeHash() string {
+	var sequence uint64
+	if a.Sequence != nil {
+		sequence = *a.Sequence
+	}
+	userID := ""
+	if a.UserID != nil {
+		userID = a.UserID.String()
+	}
+	actorID := ""
+	if a.ActorID != nil {
+		actorID = a.ActorID.String()
+	}
+
+	payload, _ := json.Marshal([]interface{}{
+		sequence,
+		a.PrevHash,
+		a.ID.String(),
+		userID,
+		actorID,
+		a.ActorType,
+		a.Actor,
+		a.Action,
+		a.Resource,
+		a.ResourceID,
+		canonicalAuditDetails(a.Details),
+		a.IPAddress,
+		a.UserAgent,
+		a.CreatedAt.Unix(),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalAuditDetails menyusun ulang JSON details dengan key terurut tanpa spasi
+func canonicalAuditDetails(details string) string {
+	if details == "" {
+		return "{}"
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(details), &value); err != nil {
+		return details
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return details
+	}
+	return string(canonical)
+}
+
+// AuditChainHead menyimpan ujung hash chain audit log dalam satu baris. Baris ini
+// dikunci selama penulisan agar sequence dan prev_hash tidak bentrok antar request.
+type AuditChainHead struct {
+	ID        uint   `gorm:"primary_key"`
+	Sequence  uint64 `gorm:"not null;default:0"`
+	Hash      string `gorm:"type:char(64)"`
+	UpdatedAt time.Time
+}
+
+// TableName mengembalikan nama tabel untuk AuditChainHead
+func (AuditChainHead) TableName() string {
+	return "audit_chain_head"
+}
+
+// AuditCheckpoint adalah snapshot ujung hash chain yang ditandatangani secara berkala,
+// sehingga penulisan ulang seluruh chain setelah checkpoint tetap dapat dideteksi
+type AuditCheckpoint struct {
+	ID        uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Sequence  uint64    `gorm:"index" json:"sequence"`
+	Hash      string    `gorm:"type:char(64)" json:"hash"`
+	Signature string    `gorm:"type:char(64)" json:"signature"` // HMAC-SHA256 atas sequence, hash, dan waktu checkpoint
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan checkpoint baru
+func (c *AuditCheckpoint) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan audit log baru
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	// Kolom details bertipe JSON sehingga tidak boleh berisi string kosong
+	if a.Details == "" {
+		a.Details = "{}"
+	}
+	return nil
+}
+
+// AuditActor menggambarkan pihak yang melakukan aksi beserta asal request-nya
+type AuditActor struct {
+	ID        *uuid.UUID
+	Type      string
+	Name      string
+	IP        string
+	UserAgent string
+}
+
+// NewAuditLog membuat audit log untuk aksi yang dilakukan actor. Actor nil berarti
+// aksi dijalankan oleh sistem.
+func NewAuditLog(actor *AuditActor, userID *uuid.UUID, action, resource, resourceID string, details map[string]interface{}) AuditLog {
+	entry := AuditLog{
+		UserID:     userID,
+		Action:     action,
+		Resource:   resource,
+		ResourceID: resourceID,
+		ActorType:  AuditActorSystem,
+	}
+	if len(details) > 0 {
+		detailsJSON, _ := json.Marshal(details)
+		entry.Details = string(detailsJSON)
+	}
+	if actor != nil {
+		entry.ActorID = actor.ID
+		entry.ActorType = actor.Type
+		entry.Actor = actor.Name
+		entry.IPAddress = actor.IP
+		entry.UserAgent = actor.UserAgent
+	}
+	return entry
+}
+
+// AuditFilter adalah filter untuk query dan export audit log
+type AuditFilter struct {
+	UserID     *uuid.UUID `json:"user_id"`
+	ActorID    *uuid.UUID `json:"actor_id"`
+	ActorType  string     `json:"actor_type" validate:"omitempty,oneof=user client system"`
+	Action     string     `json:"action" validate:"max=100"` // akhiran ".*" untuk mencocokkan prefix, misalnya "user.*"
+	Resource   string     `json:"resource" validate:"max=100"`
+	ResourceID string     `json:"resource_id" validate:"max=100"`
+	IPAddress  string     `json:"ip_address" validate:"max=50"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+	Page       int        `json:"page" validate:"min=1"`
+	Limit      int        `json:"limit" validate:"min=1,max=100"`
+}
+
+// AuditLogsListResponse adalah struktur respons daftar audit log dengan pagination
+type AuditLogsListResponse struct {
+	Entries    []AuditLog `json:"entries"`
+	Total      int64      `json:"total"`
+	Page       int        `json:"page"`
+	Limit      int        `json:"limit"`
+	TotalPages int        `json:"total_pages"`
+}
+
+// AuditChainBreak menjelaskan link pertama yang rusak pada hash chain
+type AuditChainBreak struct {
+	Sequence uint64     `json:"sequence"`
+	EntryID  *uuid.UUID `json:"entry_id,omitempty"`
+	Reason   string     `json:"reason"`
+}
+
+// AuditVerifyResult adalah hasil verifikasi hash chain dan checkpoint audit log
+type AuditVerifyResult struct {
+	Valid              bool                    `json:"valid"`
+	EntriesChecked     int64                   `json:"entries_checked"`
+	CheckpointsChecked int64                   `json:"checkpoints_checked"`
+	LastSequence       uint64                  `json:"last_sequence"`
+	LastHash           string                  `json:"last_hash"`
+	LegacyEntries      int64                   `json:"legacy_entries"` // entry tanpa hash yang ditulis sebelum hash chain diaktifkan
+	FirstBroken        *AuditChainBreak        `json:"first_broken,omitempty"`
+	LatestCheckpoint   *AuditCheckpointSummary `json:"latest_checkpoint,omitempty"`
+	CheckpointGap      string                  `json:"checkpoint_gap,omitempty"` // alasan jika checkpoint terakhir lebih tua dari interval checkpoint
+	VerifiedAt         time.Time               `json:"verified_at"`
+}
+
+// AuditCheckpointSummary berisi sequence dan waktu checkpoint terakhir
+type AuditCheckpointSummary struct {
+	Sequence  uint64    `json:"sequence"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// AvatarResponse adalah response setelah avatar diunggah. AvatarURL adalah URL
+// tetap milik service yang mengarahkan ke signed URL avatar yang masih berlaku.
+type AvatarResponse struct {
+	AvatarURL string       `json:"avatarUrl"`
+	User      UserResponse `json:"user"`
+}
@@ -0,0 +1,224 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipe pesan chat, sama dengan tipe pada chat store frontend
+const (
+	ChatMessageTypeText  = "text"
+	ChatMessageTypeImage = "image"
+	ChatMessageTypeFile  = "file"
+)
+
+// Tipe event WebSocket chat dari client ke server
+const (
+	ChatClientSendMessage = "message.send"      // kirim pesan baru
+	ChatClientDelivered   = "message.delivered" // pesan sampai di perangkat penerima
+	ChatClientRead        = "message.read"      // pesan sudah dibaca penerima
+	ChatClientTyping      = "typing"            // indikator sedang mengetik
+)
+
+// Tipe event WebSocket chat dari server ke client
+const (
+	ChatEventReady     = "ready"             // dikirim sekali saat koneksi terbuka
+	ChatEventMessage   = "message.new"       // pesan baru pada percakapan, juga ke pengirim sebagai konfirmasi
+	ChatEventDelivered = "message.delivered" // receipt pesan sampai di penerima
+	ChatEventRead      = "message.read"      // receipt pesan dibaca penerima
+	ChatEventTyping    = "typing"            // peserta lain sedang atau berhenti mengetik
+	ChatEventPresence  = "presence"          // peserta lain online atau offline
+	ChatEventError     = "error"             // event dari client ditolak
+	// ChatEventSessionsRevoked dikirim sebelum koneksi ditutup karena sesi user dicabut
+	ChatEventSessionsRevoked = "sessions.revoked"
+)
+
+// ChatConversation adalah percakapan 1:1. UserAID selalu lebih kecil dari UserBID
+// sehingga satu pasangan user hanya memiliki satu percakapan.
+type ChatConversation struct {
+	ID            uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	UserAID       uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_chat_conversations_pair,priority:1" json:"user_a_id"`
+	UserBID       uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_chat_conversations_pair,priority:2;index" json:"user_b_id"`
+	LastMessageID *uuid.UUID `gorm:"type:char(36)" json:"last_message_id"`
+	LastMessageAt *time.Time `gorm:"index" json:"last_message_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName mengembalikan nama tabel untuk ChatConversation
+func (ChatConversation) TableName() string {
+	return "chat_conversations"
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan percakapan baru
+func (c *ChatConversation) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// HasParticipant memeriksa apakah user adalah peserta percakapan
+func (c *ChatConversation) HasParticipant(userID uuid.UUID) bool {
+	return c.UserAID == userID || c.UserBID == userID
+}
+
+// PeerOf mengembalikan peserta lain dari sudut pandang user
+func (c *ChatConversation) PeerOf(userID uuid.UUID) uuid.UUID {
+	if c.UserAID == userID {
+		return c.UserBID
+	}
+	return c.UserAID
+}
+
+// ChatMessage adalah pesan pada percakapan 1:1 beserta status delivery dan read receipt
+type ChatMessage struct {
+	ID              uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	ConversationID  uuid.UUID  `gorm:"type:char(36);not null;index:idx_chat_messages_conversation,priority:1" json:"conversation_id"`
+	SenderID        uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_chat_messages_client,priority:1" json:"sender_id"`
+	RecipientID     uuid.UUID  `gorm:"type:char(36);not null;index:idx_chat_messages_recipient,priority:1" json:"recipient_id"`
+	ClientMessageID *string    `gorm:"type:varchar(64);uniqueIndex:idx_chat_messages_client,priority:2" json:"client_message_id,omitempty"` // ID dari client untuk mencegah pesan ganda saat kirim ulang
+	Type            string     `gorm:"type:varchar(20);not null" json:"type"`
+	Body            string     `gorm:"type:text" json:"body"`
+	DeliveredAt     *time.Time `json:"delivered_at"`
+	ReadAt          *time.Time `gorm:"index:idx_chat_messages_recipient,priority:2" json:"read_at"`
+	CreatedAt       time.Time  `gorm:"index:idx_chat_messages_conversation,priority:2" json:"created_at"`
+}
+
+// TableName mengembalikan nama tabel untuk ChatMessage
+func (ChatMessage) TableName() string {
+	return "chat_messages"
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan pesan baru
+func (m *ChatMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChatParticipant adalah data publik peserta chat beserta presence-nya
+type ChatParticipant struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	ProfilePicture string     `json:"profile_picture,omitempty"`
+	Online         bool       `json:"online"`
+	LastSeen       *time.Time `json:"last_seen,omitempty"`
+}
+
+// ChatConversationResponse adalah struktur untuk response percakapan dari sudut pandang user
+type ChatConversationResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	Participant   ChatParticipant `json:"participant"`
+	LastMessage   *ChatMessage    `json:"last_message"`
+	UnreadCount   int64           `json:"unread_count"`
+	LastMessageAt *time.Time      `json:"last_message_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ChatConversationsListResponse adalah struktur untuk response daftar percakapan
+type ChatConversationsListResponse struct {
+	Conversations []ChatConversationResponse `json:"conversations"`
+	Total         int64                      `json:"total"`
+	Page          int                        `json:"page"`
+	Limit         int                        `json:"limit"`
+	TotalPages    int                        `json:"total_pages"`
+}
+
+// ChatMessagesPageResponse adalah satu halaman riwayat pesan, terbaru lebih dulu
+type ChatMessagesPageResponse struct {
+	Messages   []ChatMessage `json:"messages"`
+	NextCursor string        `json:"next_cursor,omitempty"` // ID pesan terlama, dipakai sebagai parameter before halaman berikutnya
+	HasMore    bool          `json:"has_more"`
+}
+
+// StartConversationRequest adalah struktur untuk request memulai percakapan
+type StartConversationRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+// SendChatMessageRequest adalah struktur untuk request mengirim pesan
+type SendChatMessageRequest struct {
+	Type            string `json:"type" validate:"omitempty,oneof=text image file"`
+	Body            string `json:"body" validate:"required"`
+	ClientMessageID string `json:"client_message_id" validate:"omitempty,max=64"`
+}
+
+// ChatReceiptRequest adalah struktur untuk request delivery atau read receipt. Semua pesan
+// untuk user pada percakapan sampai dengan MessageID ikut ditandai.
+type ChatReceiptRequest struct {
+	MessageID uuid.UUID `json:"message_id" validate:"required"`
+}
+
+// ChatReceipt adalah data event receipt yang dikirim ke kedua peserta
+type ChatReceipt struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	UserID         uuid.UUID `json:"user_id"`    // penerima pesan yang mengirim receipt
+	MessageID      uuid.UUID `json:"message_id"` // pesan terakhir yang ditandai
+	At             time.Time `json:"at"`
+}
+
+// ChatTyping adalah data event indikator mengetik
+type ChatTyping struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Typing         bool      `json:"typing"`
+}
+
+// ChatPresence adalah data event perubahan presence
+type ChatPresence struct {
+	UserID   uuid.UUID  `json:"user_id"`
+	Online   bool       `json:"online"`
+	LastSeen *time.Time `json:"last_seen,omitempty"`
+}
+
+// ChatReady adalah data event ready yang dikirim saat koneksi WebSocket terbuka
+type ChatReady struct {
+	SessionID string    `json:"session_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// ChatError adalah data event error untuk frame client yang ditolak
+type ChatError struct {
+	Event           string     `json:"event,omitempty"` // tipe frame client yang ditolak
+	ConversationID  *uuid.UUID `json:"conversation_id,omitempty"`
+	ClientMessageID string     `json:"client_message_id,omitempty"`
+	Status          int        `json:"status"` // kode status HTTP yang setara
+	Message         string     `json:"message"`
+}
+
+// ChatClientEvent adalah frame JSON yang dikirim client melalui WebSocket
+type ChatClientEvent struct {
+	Type            string    `json:"type"`
+	ConversationID  uuid.UUID `json:"conversation_id"`
+	MessageID       uuid.UUID `json:"message_id,omitempty"`        // untuk message.delivered dan message.read
+	ClientMessageID string    `json:"client_message_id,omitempty"` // untuk message.send
+	MessageType     string    `json:"message_type,omitempty"`      // untuk message.send, default text
+	Body            string    `json:"body,omitempty"`              // untuk message.send
+	Typing          bool      `json:"typing,omitempty"`            // untuk typing
+}
+
+// ChatEvent adalah frame JSON yang dikirim server melalui WebSocket. Recipients hanya
+// dipakai untuk fan-out antar instance melalui Redis pub/sub dan tidak dikirim ke client.
+type ChatEvent struct {
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	Recipients []uuid.UUID     `json:"recipients,omitempty"`
+}
+
+// NewChatEvent membuat ChatEvent untuk daftar penerima
+func NewChatEvent(eventType string, data interface{}, recipients ...uuid.UUID) (*ChatEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatEvent{
+		Type:       eventType,
+		Data:       payload,
+		Recipients: recipients,
+	}, nil
+}
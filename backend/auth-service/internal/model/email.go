@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Nama template email transaksional. Setiap template tersedia dalam versi HTML dan teks
+// untuk setiap bahasa yang didukung.
+const (
+	EmailTemplateEmailChangeApprove = "email_change_approve" // ke alamat lama, menyetujui perubahan email
+	EmailTemplateEmailChangeConfirm = "email_change_confirm" // ke alamat baru, membuktikan kepemilikan email
+	EmailTemplateEmailChanged       = "email_changed"        // ke alamat lama, pemberitahuan email sudah berubah
+	EmailTemplateInvitation         = "invitation"           // undangan bergabung dari admin
+	EmailTemplatePasswordSetup      = "password_setup"       // link mengatur password untuk user yang dibuat admin
+	EmailTemplateNewDeviceLogin     = "new_device_login"     // peringatan login dari perangkat baru
+	EmailTemplateAccountLocked      = "account_locked"       // peringatan akun terkunci
+	EmailTemplateSuspiciousLogin    = "suspicious_login"     // peringatan login berisiko dari negara atau IP tidak biasa
+	EmailTemplateLoginVerification  = "login_verification"   // kode verifikasi step-up untuk login berisiko
+)
+
+// EmailRequest adalah permintaan mengirim email dari template
+type EmailRequest struct {
+	To       string
+	Name     string // nama penerima, dipakai pada sapaan dan header To
+	Locale   string // BCP 47 language tag penerima, kosong untuk bahasa default
+	Template string
+	Data     map[string]interface{}
+}
+
+// EmailMessage adalah email yang sudah dirender dan siap dikirim
+type EmailMessage struct {
+	ID       string `json:"id"` // dipakai sebagai Message-ID dan nama file pada mailer file
+	To       string `json:"to"`
+	ToName   string `json:"to_name,omitempty"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+}
+
+// EmailJob adalah email pada antrean pengiriman beserta status percobaannya
+type EmailJob struct {
+	ID         uuid.UUID    `json:"id"`
+	Message    EmailMessage `json:"message"`
+	Attempts   int          `json:"attempts"`
+	LastError  string       `json:"last_error,omitempty"`
+	EnqueuedAt time.Time    `json:"enqueued_at"`
+
+	// Receipt adalah payload asli job pada list processing, dipakai untuk menghapusnya
+	// setelah email terkirim, dijadwalkan ulang, atau dipindahkan ke dead list
+	Receipt string `json:"-"`
+}
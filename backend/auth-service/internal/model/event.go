@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipe domain event identitas
+const (
+	EventUserRegistered         = "user.registered"
+	EventUserLoggedIn           = "user.logged_in"
+	EventUserLocked             = "user.locked"
+	EventUserRoleChanged        = "user.role_changed"
+	EventUserDeleted            = "user.deleted"
+	EventRoleCreated            = "role.created"
+	EventRoleUpdated            = "role.updated"
+	EventRoleDeleted            = "role.deleted"
+	EventRolePermissionsChanged = "role.permissions_changed"
+)
+
+// Tipe aggregate pemilik domain event
+const (
+	EventAggregateUser = "user"
+	EventAggregateRole = "role"
+)
+
+// Status event pada outbox
+const (
+	OutboxEventPending   = "pending"   // menunggu relay pertama
+	OutboxEventFailed    = "failed"    // relay ke sebagian sink gagal, dicoba lagi pada next_attempt_at
+	OutboxEventPublished = "published" // sudah diterima semua sink
+)
+
+// DomainEvent adalah perubahan state yang dipublikasikan ke sink melalui outbox.
+// ID event dipakai sebagai idempotency key oleh consumer karena relay menjamin
+// at-least-once, sehingga event yang sama dapat diterima lebih dari sekali.
+type DomainEvent struct {
+	ID            uuid.UUID              `json:"id"`
+	Type          string                 `json:"type"`
+	AggregateType string                 `json:"aggregate_type"`
+	AggregateID   string                 `json:"aggregate_id"`
+	OccurredAt    time.Time              `json:"occurred_at"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// NewDomainEvent membuat domain event baru dengan ID unik
+func NewDomainEvent(eventType, aggregateType, aggregateID string, data map[string]interface{}) *DomainEvent {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &DomainEvent{
+		ID:            uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		OccurredAt:    time.Now().UTC(),
+		Data:          data,
+	}
+}
+
+// IdempotencyKey mengembalikan key yang sama untuk setiap pengiriman ulang event
+func (e *DomainEvent) IdempotencyKey() string {
+	return e.ID.String()
+}
+
+// ToOutboxEvent mengkonversi DomainEvent ke baris outbox
+func (e *DomainEvent) ToOutboxEvent() (*OutboxEvent, error) {
+	payload, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &OutboxEvent{
+		ID:            e.ID,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		EventType:     e.Type,
+		Payload:       string(payload),
+		Status:        OutboxEventPending,
+		NextAttemptAt: &e.OccurredAt,
+		OccurredAt:    e.OccurredAt,
+	}, nil
+}
+
+// OutboxEvent adalah domain event yang ditulis dalam transaksi yang sama dengan perubahan
+// state, lalu dikirim ke sink oleh relay
+type OutboxEvent struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	AggregateType  string     `gorm:"type:varchar(50);index:idx_outbox_aggregate,priority:1" json:"aggregate_type"`
+	AggregateID    string     `gorm:"type:varchar(100);index:idx_outbox_aggregate,priority:2" json:"aggregate_id"`
+	EventType      string     `gorm:"type:varchar(100);index" json:"event_type"`
+	Payload        string     `gorm:"type:mediumtext" json:"payload"` // data event dalam JSON
+	Status         string     `gorm:"type:varchar(20);index:idx_outbox_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	PublishedSinks string     `gorm:"type:varchar(255)" json:"published_sinks"` // sink yang sudah menerima event, dipisah spasi
+	LastError      string     `gorm:"type:varchar(1000)" json:"last_error"`
+	OccurredAt     time.Time  `json:"occurred_at"`
+	PublishedAt    *time.Time `gorm:"index" json:"published_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName mengembalikan nama tabel untuk OutboxEvent
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan event baru
+func (o *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// ToDomainEvent mengkonversi baris outbox kembali ke DomainEvent
+func (o *OutboxEvent) ToDomainEvent() (*DomainEvent, error) {
+	data := map[string]interface{}{}
+	if o.Payload != "" {
+		if err := json.Unmarshal([]byte(o.Payload), &data); err != nil {
+			return nil, err
+		}
+	}
+	return &DomainEvent{
+		ID:            o.ID,
+		Type:          o.EventType,
+		AggregateType: o.AggregateType,
+		AggregateID:   o.AggregateID,
+		OccurredAt:    o.OccurredAt.UTC(),
+		Data:          data,
+	}, nil
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// File menyimpan metadata file yang diunggah user. Isi file disimpan di file storage
+// dengan key StorageKey; database hanya menyimpan metadata.
+type File struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	OwnerID        uuid.UUID  `gorm:"type:char(36);index" json:"owner_id"`
+	FolderID       *uuid.UUID `gorm:"type:char(36);index" json:"folder_id"` // nil berarti folder root
+	Name           string     `gorm:"type:varchar(255);index" json:"name"`
+	OriginalName   string     `gorm:"type:varchar(255)" json:"original_name"`
+	MimeType       string     `gorm:"type:varchar(127);index" json:"mime_type"`
+	Size           int64      `gorm:"index" json:"size"`
+	StorageKey     string     `gorm:"type:varchar(255)" json:"-"`
+	Checksum       string     `gorm:"type:varchar(64)" json:"checksum"` // SHA-256 (hex) dari isi file
+	Width          *int       `json:"width"`
+	Height         *int       `json:"height"`
+	DownloadCount  int        `gorm:"default:0" json:"download_count"`
+	LastDownloadAt *time.Time `json:"last_download_at"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan file baru
+func (f *File) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// Folder adalah folder milik user. Path menyimpan path lengkap (misalnya /docs/2024)
+// agar file dapat difilter berdasarkan path tanpa menelusuri hierarki.
+type Folder struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	OwnerID   uuid.UUID  `gorm:"type:char(36);uniqueIndex:idx_folder_owner_path" json:"owner_id"`
+	ParentID  *uuid.UUID `gorm:"type:char(36);index" json:"parent_id"`
+	Name      string     `gorm:"type:varchar(255)" json:"name"`
+	Path      string     `gorm:"type:varchar(512);uniqueIndex:idx_folder_owner_path" json:"path"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan folder baru
+func (f *Folder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// StorageQuota menyimpan pemakaian storage user. QuotaBytes nil berarti memakai
+// kuota default dari konfigurasi.
+type StorageQuota struct {
+	UserID     uuid.UUID `gorm:"type:char(36);primary_key" json:"user_id"`
+	QuotaBytes *int64    `json:"quota_bytes"`
+	UsedBytes  int64     `gorm:"default:0" json:"used_bytes"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// FileMetadata adalah metadata tambahan file, misalnya dimensi gambar
+type FileMetadata struct {
+	Width  *int `json:"width,omitempty"`
+	Height *int `json:"height,omitempty"`
+}
+
+// FileResponse adalah struktur respons file. Nama field mengikuti FileUploadResponse di frontend.
+type FileResponse struct {
+	ID             uuid.UUID    `json:"id"`
+	Filename       string       `json:"filename"`
+	OriginalName   string       `json:"originalName"`
+	MimeType       string       `json:"mimeType"`
+	Size           int64        `json:"size"`
+	URL            string       `json:"url"`
+	Folder         string       `json:"folder"`
+	Checksum       string       `json:"checksum"`
+	Metadata       FileMetadata `json:"metadata"`
+	DownloadCount  int          `json:"downloadCount"`
+	LastDownloadAt *time.Time   `json:"lastDownloadAt,omitempty"`
+	UploadedAt     time.Time    `json:"uploadedAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+	UploadedBy     uuid.UUID    `json:"uploadedBy"`
+}
+
+// ToFileResponse mengkonversi File ke FileResponse dengan path folder dan signed URL
+func (f *File) ToFileResponse(folderPath, url string) FileResponse {
+	return FileResponse{
+		ID:             f.ID,
+		Filename:       f.Name,
+		OriginalName:   f.OriginalName,
+		MimeType:       f.MimeType,
+		Size:           f.Size,
+		URL:            url,
+		Folder:         folderPath,
+		Checksum:       f.Checksum,
+		Metadata:       FileMetadata{Width: f.Width, Height: f.Height},
+		DownloadCount:  f.DownloadCount,
+		LastDownloadAt: f.LastDownloadAt,
+		UploadedAt:     f.CreatedAt,
+		UpdatedAt:      f.UpdatedAt,
+		UploadedBy:     f.OwnerID,
+	}
+}
+
+// FolderResponse adalah struktur respons folder beserta jumlah dan ukuran file langsung di dalamnya
+type FolderResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	ParentID  *uuid.UUID `json:"parentId,omitempty"`
+	FileCount int64      `json:"fileCount"`
+	Size      int64      `json:"size"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// ToFolderResponse mengkonversi Folder ke FolderResponse
+func (f *Folder) ToFolderResponse() FolderResponse {
+	return FolderResponse{
+		ID:        f.ID,
+		Name:      f.Name,
+		Path:      f.Path,
+		ParentID:  f.ParentID,
+		CreatedAt: f.CreatedAt,
+	}
+}
+
+// FolderStats adalah jumlah dan total ukuran file langsung di dalam folder
+type FolderStats struct {
+	FolderID  uuid.UUID
+	FileCount int64
+	Size      int64
+}
+
+// FileFilter adalah filter daftar dan pencarian file milik user
+type FileFilter struct {
+	Folder    string     `json:"folder" validate:"max=512"` // path folder, "/" untuk root, kosong untuk semua folder
+	MimeType  string     `json:"mime_type" validate:"omitempty,max=127"`
+	Search    string     `json:"search" validate:"omitempty,max=255"`
+	DateFrom  *time.Time `json:"date_from"`
+	DateTo    *time.Time `json:"date_to"`
+	SizeMin   *int64     `json:"size_min" validate:"omitempty,min=0"`
+	SizeMax   *int64     `json:"size_max" validate:"omitempty,min=0"`
+	SortBy    string     `json:"-" validate:"omitempty,oneof=name size createdAt"`
+	SortOrder string     `json:"-" validate:"omitempty,oneof=asc desc"`
+	Page      int        `json:"-"`
+	Limit     int        `json:"-"`
+}
+
+// FilesListResponse adalah struktur untuk respons daftar file
+type FilesListResponse struct {
+	Files      []FileResponse `json:"files"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	Limit      int            `json:"limit"`
+	TotalPages int            `json:"total_pages"`
+}
+
+// FileURLResponse adalah signed URL file beserta waktu kedaluwarsanya
+type FileURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// DeleteFilesRequest adalah struktur untuk request hapus beberapa file
+type DeleteFilesRequest struct {
+	FileIDs []uuid.UUID `json:"fileIds" validate:"required,min=1,max=100"`
+}
+
+// RenameRequest adalah struktur untuk request rename file atau folder
+type RenameRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+// MoveFileRequest adalah struktur untuk request pindah file ke folder lain ("/" untuk root)
+type MoveFileRequest struct {
+	Folder string `json:"folder" validate:"max=512"`
+}
+
+// CreateFolderRequest adalah struktur untuk request membuat folder
+type CreateFolderRequest struct {
+	Name         string `json:"name" validate:"required,min=1,max=255"`
+	ParentFolder string `json:"parentFolder" validate:"max=512"`
+}
+
+// StorageStatsResponse adalah statistik pemakaian storage user
+type StorageStatsResponse struct {
+	TotalSize     int64 `json:"totalSize"`
+	UsedSize      int64 `json:"usedSize"`
+	AvailableSize int64 `json:"availableSize"`
+	FileCount     int64 `json:"fileCount"`
+	FolderCount   int64 `json:"folderCount"`
+}
+
+// UpdateStorageQuotaRequest adalah struktur untuk request admin mengubah kuota user.
+// QuotaBytes nil mengembalikan user ke kuota default.
+type UpdateStorageQuotaRequest struct {
+	QuotaBytes *int64 `json:"quota_bytes" validate:"omitempty,min=0"`
+}
+
+// StorageQuotaResponse adalah kuota dan pemakaian storage user
+type StorageQuotaResponse struct {
+	UserID     uuid.UUID `json:"user_id"`
+	QuotaBytes int64     `json:"quota_bytes"`
+	UsedBytes  int64     `json:"used_bytes"`
+	IsDefault  bool      `json:"is_default"`
+}
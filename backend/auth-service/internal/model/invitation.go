@@ -0,0 +1,127 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status undangan user. Status expired tidak disimpan, melainkan dihitung
+// dari undangan pending yang sudah melewati ExpiresAt.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation merepresentasikan undangan admin kepada calon user melalui email
+// dengan role yang sudah ditentukan sebelumnya
+type Invitation struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	Email          string     `gorm:"type:varchar(255);index" json:"email"`
+	Name           string     `gorm:"type:varchar(255)" json:"name"`
+	Role           string     `gorm:"type:varchar(50);default:'user'" json:"role"` // role legacy (user/admin)
+	RoleID         *uuid.UUID `gorm:"type:char(36)" json:"role_id"`
+	TokenHash      string     `gorm:"type:varchar(64);uniqueIndex" json:"-"` // hash token terakhir yang diterbitkan
+	Status         string     `gorm:"type:varchar(20);index;default:'pending'" json:"status"`
+	InvitedBy      *uuid.UUID `gorm:"type:char(36)" json:"invited_by"`
+	AcceptedUserID *uuid.UUID `gorm:"type:char(36)" json:"accepted_user_id"`
+	ExpiresAt      time.Time  `gorm:"index" json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan undangan baru
+func (i *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// CurrentStatus mengembalikan status undangan pada waktu now, termasuk expired
+func (i *Invitation) CurrentStatus(now time.Time) string {
+	if i.Status == InvitationStatusPending && !now.Before(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+// InvitationResponse adalah struktur untuk respons undangan tanpa token
+type InvitationResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	Email          string     `json:"email"`
+	Name           string     `json:"name"`
+	Role           string     `json:"role"`
+	RoleID         *uuid.UUID `json:"role_id"`
+	RoleName       string     `json:"role_name,omitempty"`
+	Status         string     `json:"status"`
+	InvitedBy      *uuid.UUID `json:"invited_by"`
+	AcceptedUserID *uuid.UUID `json:"accepted_user_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToInvitationResponse mengkonversi Invitation ke InvitationResponse
+func (i *Invitation) ToInvitationResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:             i.ID,
+		Email:          i.Email,
+		Name:           i.Name,
+		Role:           i.Role,
+		RoleID:         i.RoleID,
+		Status:         i.CurrentStatus(time.Now()),
+		InvitedBy:      i.InvitedBy,
+		AcceptedUserID: i.AcceptedUserID,
+		ExpiresAt:      i.ExpiresAt,
+		AcceptedAt:     i.AcceptedAt,
+		RevokedAt:      i.RevokedAt,
+		CreatedAt:      i.CreatedAt,
+	}
+}
+
+// CreateInvitationRequest adalah struktur untuk request pembuatan undangan oleh admin.
+// Role dapat berupa role legacy (user/admin) maupun nama role RBAC.
+type CreateInvitationRequest struct {
+	Email  string     `json:"email" validate:"required,email"`
+	Name   string     `json:"name" validate:"omitempty,max=100"`
+	Role   string     `json:"role" validate:"omitempty,max=50"`
+	RoleID *uuid.UUID `json:"role_id"`
+}
+
+// CreateInvitationResponse berisi undangan yang dibuat. Token plaintext hanya dikembalikan
+// sekali saat undangan dibuat dan hanya jika driver mail adalah "log".
+type CreateInvitationResponse struct {
+	Invitation InvitationResponse `json:"invitation"`
+	Token      string             `json:"token,omitempty"`
+}
+
+// InvitationFilter adalah struktur untuk filter daftar undangan
+type InvitationFilter struct {
+	Status string `json:"status" validate:"omitempty,oneof=pending accepted revoked expired"`
+	Search string `json:"search"`
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+}
+
+// InvitationsListResponse adalah struktur untuk response daftar undangan dengan pagination
+type InvitationsListResponse struct {
+	Invitations []InvitationResponse `json:"invitations"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	Limit       int                  `json:"limit"`
+	TotalPages  int                  `json:"total_pages"`
+}
+
+// AcceptInvitationRequest adalah struktur untuk request penerimaan undangan dengan password
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Name     string `json:"name" validate:"omitempty,min=2,max=100"` // opsional jika admin sudah mengisi nama
+	Password string `json:"password" validate:"required,min=8"`
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Nama job pemeliharaan bawaan
+const (
+	JobExpiredLockouts     = "expired_lockouts"            // membuka akun yang masa kuncinya sudah lewat
+	JobOAuthStateCleanup   = "oauth_state_cleanup"         // menghapus state OAuth dan device code yang tertinggal di Redis
+	JobLoginHistoryCleanup = "login_history_cleanup"       // mengarsipkan dan menghapus riwayat login yang melewati masa retensi
+	JobUnverifiedUsers     = "unverified_users_cleanup"    // memindahkan akun yang tidak pernah diverifikasi ke trash
+	JobExpiredInvitations  = "expired_invitations_cleanup" // menghapus undangan kedaluwarsa dan dicabut
+	JobDeletedUsersPurge   = "deleted_users_purge"         // menghapus permanen user di trash yang melewati masa retensi
+	JobRunHistoryCleanup   = "job_run_history_cleanup"     // menghapus riwayat run job yang lama
+	JobTorExitListRefresh  = "tor_exit_list_refresh"       // mengunduh ulang daftar exit node TOR untuk penilaian risiko login
+)
+
+// Status run job
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// Pemicu run job
+const (
+	JobTriggerSchedule = "schedule" // dijalankan scheduler sesuai jadwal
+	JobTriggerManual   = "manual"   // dipicu admin melalui API
+)
+
+// JobRun adalah riwayat satu kali eksekusi job
+type JobRun struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	JobName     string     `gorm:"type:varchar(100);index:idx_job_runs_job_started,priority:1" json:"job_name"`
+	TriggerType string     `gorm:"type:varchar(20)" json:"trigger"`
+	TriggeredBy *uuid.UUID `gorm:"type:char(36)" json:"triggered_by,omitempty"` // admin yang memicu run manual
+	Instance    string     `gorm:"type:varchar(255)" json:"instance"`           // host dan PID instance yang menjalankan job
+	Status      string     `gorm:"type:varchar(20);index" json:"status"`
+	Affected    int64      `json:"affected"` // jumlah data yang diproses job
+	Error       string     `gorm:"type:varchar(1000)" json:"error,omitempty"`
+	StartedAt   time.Time  `gorm:"index:idx_job_runs_job_started,priority:2;index:idx_job_runs_started_at" json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+}
+
+// JobResponse adalah informasi job beserta jadwal dan run terakhirnya
+type JobResponse struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule"`    // ekspresi cron dalam UTC, kosong jika hanya dapat dipicu manual
+	Enabled     bool       `json:"enabled"`     // dijalankan otomatis sesuai jadwal
+	Running     bool       `json:"running"`     // sedang dijalankan oleh salah satu instance
+	NextRunAt   *time.Time `json:"next_run_at"` // kosong jika job tidak dijadwalkan
+	LastRun     *JobRun    `json:"last_run"`
+}
+
+// JobRunFilter adalah filter untuk riwayat run job
+type JobRunFilter struct {
+	JobName string `json:"job_name"`
+	Status  string `json:"status" validate:"omitempty,oneof=running succeeded failed"`
+	Page    int    `json:"page" validate:"min=1"`
+	Limit   int    `json:"limit" validate:"min=1,max=100"`
+}
+
+// JobRunsListResponse adalah struktur untuk response riwayat run job
+type JobRunsListResponse struct {
+	Runs       []JobRun `json:"runs"`
+	Total      int64    `json:"total"`
+	Page       int      `json:"page"`
+	Limit      int      `json:"limit"`
+	TotalPages int      `json:"total_pages"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Faktor risiko login yang dinilai risk engine
+const (
+	RiskFactorNewDevice        = "new_device"        // perangkat belum pernah dipakai login berhasil
+	RiskFactorNewCountry       = "new_country"       // negara belum pernah dipakai login berhasil
+	RiskFactorImpossibleTravel = "impossible_travel" // jarak dari login sebelumnya tidak mungkin ditempuh
+	RiskFactorTorExitNode      = "tor_exit_node"     // IP adalah exit node TOR
+	RiskFactorDeniedIP         = "denied_ip"         // IP ada di denylist
+)
+
+// Tindakan berdasarkan skor risiko login
+const (
+	LoginRiskAllow  = "allow"   // login dilanjutkan tanpa tindakan tambahan
+	LoginRiskNotify = "notify"  // login dilanjutkan dan user diberi tahu
+	LoginRiskStepUp = "step_up" // login memerlukan verifikasi kode dari email
+)
+
+// LoginChallengeMethodEmailCode adalah metode verifikasi step-up dengan kode melalui email
+const LoginChallengeMethodEmailCode = "email_code"
+
+// LoginChallenge menyimpan login yang menunggu verifikasi step-up di Redis, beserta
+// informasi klien dan hasil penilaian risiko saat login dimulai
+type LoginChallenge struct {
+	TokenHash   string    `json:"token_hash"`
+	UserID      uuid.UUID `json:"user_id"`
+	Method      string    `json:"method"`          // metode login awal: password, google, atau device
+	Scope       string    `json:"scope,omitempty"` // scope token yang diterbitkan setelah verifikasi
+	CodeHash    string    `json:"code_hash"`
+	Attempts    int       `json:"attempts"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	Country     string    `json:"country"`
+	City        string    `json:"city"`
+	Latitude    *float64  `json:"latitude,omitempty"`
+	Longitude   *float64  `json:"longitude,omitempty"`
+	RiskScore   int       `json:"risk_score"`
+	RiskFactors []string  `json:"risk_factors"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// LoginChallengeResponse dikembalikan saat login memerlukan verifikasi step-up
+type LoginChallengeResponse struct {
+	StepUpRequired bool      `json:"step_up_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	Method         string    `json:"method"` // email_code
+	RiskFactors    []string  `json:"risk_factors"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// VerifyLoginRequest adalah struktur untuk request verifikasi step-up login
+type VerifyLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,len=6,numeric"`
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipe tampilan notifikasi, sama dengan tipe pada notifications store frontend
+const (
+	NotificationTypeInfo    = "info"
+	NotificationTypeSuccess = "success"
+	NotificationTypeWarning = "warning"
+	NotificationTypeError   = "error"
+)
+
+// Kategori notifikasi
+const (
+	NotificationCategorySecurity = "security"
+	NotificationCategoryAccount  = "account"
+	NotificationCategorySystem   = "system"
+)
+
+// Event notifikasi keamanan
+const (
+	NotificationEventNewDeviceLogin  = "security.new_device_login"
+	NotificationEventAccountLocked   = "security.account_locked"
+	NotificationEventSuspiciousLogin = "security.suspicious_login"
+)
+
+// Tipe pesan pada stream notifikasi real-time
+const (
+	NotificationStreamCreated = "notification" // notifikasi baru
+	NotificationStreamRead    = "read"         // satu notifikasi ditandai sudah dibaca
+	NotificationStreamReadAll = "read_all"     // semua notifikasi ditandai sudah dibaca
+	NotificationStreamDeleted = "deleted"      // notifikasi dihapus
+	NotificationStreamReady   = "ready"        // dikirim sekali saat stream terbuka
+	// NotificationStreamSessionsRevoked dikirim sebelum stream ditutup karena sesi user dicabut
+	NotificationStreamSessionsRevoked = "sessions.revoked"
+)
+
+// Notification adalah notifikasi yang disimpan per user
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null;index:idx_notifications_user,priority:1" json:"user_id"`
+	Type      string     `gorm:"type:varchar(20);not null" json:"type"`
+	Category  string     `gorm:"type:varchar(50);index" json:"category"`
+	Event     string     `gorm:"type:varchar(100)" json:"event"`
+	Title     string     `gorm:"type:varchar(255);not null" json:"title"`
+	Message   string     `gorm:"type:text" json:"message"`
+	Data      string     `gorm:"type:json" json:"-"`
+	ReadAt    *time.Time `gorm:"index" json:"read_at"`
+	CreatedAt time.Time  `gorm:"index:idx_notifications_user,priority:2" json:"created_at"`
+}
+
+// TableName mengembalikan nama tabel untuk Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan notifikasi baru
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// NotificationResponse adalah struktur untuk response notifikasi
+type NotificationResponse struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      string                 `json:"type"`
+	Category  string                 `json:"category"`
+	Event     string                 `json:"event,omitempty"`
+	Title     string                 `json:"title"`
+	Message   string                 `json:"message"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+	Read      bool                   `json:"read"`
+	ReadAt    *time.Time             `json:"read_at"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ToNotificationResponse mengkonversi Notification ke NotificationResponse
+func (n *Notification) ToNotificationResponse() NotificationResponse {
+	var data map[string]interface{}
+	if n.Data != "" {
+		_ = json.Unmarshal([]byte(n.Data), &data)
+	}
+
+	return NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Category:  n.Category,
+		Event:     n.Event,
+		Title:     n.Title,
+		Message:   n.Message,
+		Data:      data,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
+
+// NotificationFilter adalah filter untuk daftar notifikasi user
+type NotificationFilter struct {
+	UnreadOnly bool   `json:"unread_only"`
+	Category   string `json:"category" validate:"omitempty,oneof=security account system"`
+	Page       int    `json:"page" validate:"min=1"`
+	Limit      int    `json:"limit" validate:"min=1,max=100"`
+}
+
+// NotificationsListResponse adalah struktur untuk response daftar notifikasi
+type NotificationsListResponse struct {
+	Notifications []NotificationResponse `json:"notifications"`
+	Total         int64                  `json:"total"`
+	Page          int                    `json:"page"`
+	Limit         int                    `json:"limit"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+// UnreadNotificationCountResponse adalah struktur untuk response jumlah notifikasi belum dibaca
+type UnreadNotificationCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// NotificationMessage adalah pesan yang dipublikasikan ke Redis pub/sub dan diteruskan
+// ke stream SSE milik user pada setiap instance
+type NotificationMessage struct {
+	Type           string                `json:"type"`
+	UserID         uuid.UUID             `json:"user_id"`
+	NotificationID *uuid.UUID            `json:"notification_id,omitempty"`
+	Notification   *NotificationResponse `json:"notification,omitempty"`
+	UnreadCount    int64                 `json:"unread_count"`
+}
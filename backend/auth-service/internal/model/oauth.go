@@ -0,0 +1,154 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Grant type OAuth 2.0 yang didukung oleh endpoint /oauth/token
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// Token type identifier untuk token exchange (RFC 8693 section 3)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// Status device authorization (RFC 8628)
+const (
+	DeviceAuthorizationPending  = "pending"
+	DeviceAuthorizationApproved = "approved"
+	DeviceAuthorizationDenied   = "denied"
+	// DeviceAuthorizationIssuing menandai otorisasi yang disetujui dan sedang ditukar menjadi token
+	DeviceAuthorizationIssuing = "issuing"
+)
+
+// Nilai token_type_hint untuk endpoint introspection dan revocation (RFC 7009 section 2.1)
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// OAuthTokenRequest adalah struktur untuk request ke endpoint /oauth/token (RFC 6749)
+type OAuthTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+	Scope        string `form:"scope" json:"scope"`
+	DeviceCode   string `form:"device_code" json:"device_code"`
+
+	// Parameter token exchange (RFC 8693 section 2.1)
+	SubjectToken       string   `form:"subject_token" json:"subject_token"`
+	SubjectTokenType   string   `form:"subject_token_type" json:"subject_token_type"`
+	Audience           []string `form:"audience" json:"audience"`
+	RequestedTokenType string   `form:"requested_token_type" json:"requested_token_type"`
+}
+
+// OAuthTokenResponse adalah struktur response token sesuai RFC 6749 section 5.1
+type OAuthTokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"` // dalam detik
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"` // hanya untuk token exchange
+}
+
+// OAuthTokenActionRequest adalah struktur untuk request ke endpoint /oauth/introspect (RFC 7662)
+// dan /oauth/revoke (RFC 7009)
+type OAuthTokenActionRequest struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	ClientID      string `form:"client_id" json:"client_id"`
+	ClientSecret  string `form:"client_secret" json:"client_secret"`
+}
+
+// OAuthIntrospectionResponse adalah struktur response introspection sesuai RFC 7662 section 2.2.
+// Token yang tidak aktif hanya mengembalikan {"active": false}.
+type OAuthIntrospectionResponse struct {
+	Active    bool        `json:"active"`
+	Scope     string      `json:"scope,omitempty"`
+	ClientID  string      `json:"client_id,omitempty"`
+	Username  string      `json:"username,omitempty"`
+	TokenType string      `json:"token_type,omitempty"`
+	Exp       int64       `json:"exp,omitempty"`
+	Iat       int64       `json:"iat,omitempty"`
+	Sub       string      `json:"sub,omitempty"`
+	Aud       []string    `json:"aud,omitempty"`
+	Iss       string      `json:"iss,omitempty"`
+	Jti       string      `json:"jti,omitempty"`
+	SubType   string      `json:"sub_type,omitempty"` // "user" atau "client"
+	Role      string      `json:"role,omitempty"`
+	Act       interface{} `json:"act,omitempty"` // rantai aktor untuk token hasil token exchange
+}
+
+// OAuthDeviceAuthorizationRequest adalah struktur untuk request ke endpoint
+// /oauth/device_authorization (RFC 8628 section 3.1)
+type OAuthDeviceAuthorizationRequest struct {
+	ClientID string `form:"client_id" json:"client_id"`
+	Scope    string `form:"scope" json:"scope"`
+}
+
+// OAuthDeviceAuthorizationResponse adalah struktur response device authorization (RFC 8628 section 3.2)
+type OAuthDeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"` // dalam detik
+	Interval                int    `json:"interval"`   // jeda minimum polling dalam detik
+}
+
+// DeviceAuthorization menyimpan state device authorization yang sedang berjalan di Redis
+type DeviceAuthorization struct {
+	DeviceCodeHash string     `json:"device_code_hash"`
+	UserCode       string     `json:"user_code"`
+	ClientID       string     `json:"client_id"`
+	Scope          string     `json:"scope,omitempty"`
+	Status         string     `json:"status"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"` // user yang menyetujui atau menolak
+	Interval       int        `json:"interval"`          // interval polling awal; interval setelah slow_down disimpan terpisah
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
+
+// DeviceVerificationResponse berisi detail device authorization untuk ditampilkan
+// kepada user sebelum menyetujui perangkat
+type DeviceVerificationResponse struct {
+	UserCode  string    `json:"user_code"`
+	ClientID  string    `json:"client_id"`
+	Scope     string    `json:"scope,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// VerifyDeviceRequest adalah struktur untuk request persetujuan perangkat oleh user
+type VerifyDeviceRequest struct {
+	UserCode string `json:"user_code" validate:"required,min=8,max=16"`
+	Approve  *bool  `json:"approve" validate:"required"`
+}
+
+// OAuthErrorResponse adalah struktur response error sesuai RFC 6749 section 5.2
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthStepUpErrorResponse dikembalikan token endpoint saat penukaran device code memerlukan
+// verifikasi step-up. Client menyelesaikan login dengan mengirim kode dari email beserta
+// challenge_token ke POST /api/v1/auth/login/verify.
+type OAuthStepUpErrorResponse struct {
+	OAuthErrorResponse
+	*LoginChallengeResponse
+}
+
+// NewOAuthErrorResponse membuat response error OAuth
+func NewOAuthErrorResponse(code, description string) OAuthErrorResponse {
+	return OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthClient merepresentasikan service account (klien machine-to-machine)
+type OAuthClient struct {
+	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	ClientID    string         `gorm:"type:varchar(64);uniqueIndex" json:"client_id"`
+	SecretHash  string         `gorm:"type:varchar(255)" json:"-"`
+	Name        string         `gorm:"type:varchar(100)" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	RoleID      *uuid.UUID     `gorm:"type:char(36);index" json:"role_id"`
+	Scopes      string         `gorm:"type:varchar(1000)" json:"scopes"`    // daftar scope dipisah spasi, kosong = semua permission role
+	Audiences   string         `gorm:"type:varchar(1000)" json:"audiences"` // audience yang boleh diminta lewat token exchange, kosong = tidak diizinkan
+	Active      bool           `gorm:"default:true" json:"active"`
+	CreatedBy   *uuid.UUID     `gorm:"type:char(36)" json:"created_by"`
+	LastUsedAt  *time.Time     `json:"last_used_at"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Role *Role `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan client baru
+func (o *OAuthClient) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// OAuthClientResponse adalah struktur untuk respons API service account
+type OAuthClientResponse struct {
+	ID          uuid.UUID     `json:"id"`
+	ClientID    string        `json:"client_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	RoleID      *uuid.UUID    `json:"role_id"`
+	Role        *RoleResponse `json:"role,omitempty"`
+	Scopes      string        `json:"scopes"`
+	Audiences   string        `json:"audiences"`
+	Active      bool          `json:"active"`
+	CreatedBy   *uuid.UUID    `json:"created_by"`
+	LastUsedAt  *time.Time    `json:"last_used_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// OAuthClientCredentialsResponse berisi client secret dalam bentuk plaintext.
+// Hanya dikembalikan sekali saat client dibuat atau secret dirotasi.
+type OAuthClientCredentialsResponse struct {
+	Client       OAuthClientResponse `json:"client"`
+	ClientSecret string              `json:"client_secret"`
+}
+
+// ToOAuthClientResponse mengkonversi OAuthClient ke OAuthClientResponse
+func (o *OAuthClient) ToOAuthClientResponse() OAuthClientResponse {
+	response := OAuthClientResponse{
+		ID:          o.ID,
+		ClientID:    o.ClientID,
+		Name:        o.Name,
+		Description: o.Description,
+		RoleID:      o.RoleID,
+		Scopes:      o.Scopes,
+		Audiences:   o.Audiences,
+		Active:      o.Active,
+		CreatedBy:   o.CreatedBy,
+		LastUsedAt:  o.LastUsedAt,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+	}
+
+	if o.Role != nil {
+		roleResponse := o.Role.ToRoleResponse()
+		response.Role = &roleResponse
+	}
+
+	return response
+}
+
+// CreateOAuthClientRequest adalah struktur untuk request create service account
+type CreateOAuthClientRequest struct {
+	Name        string    `json:"name" validate:"required,min=2,max=100"`
+	Description string    `json:"description" validate:"max=500"`
+	RoleID      uuid.UUID `json:"role_id" validate:"required"`
+	Scopes      []string  `json:"scopes"`
+	Audiences   []string  `json:"audiences" validate:"dive,min=1,max=255"`
+}
+
+// UpdateOAuthClientRequest adalah struktur untuk request update service account
+type UpdateOAuthClientRequest struct {
+	Name        *string    `json:"name" validate:"omitempty,min=2,max=100"`
+	Description *string    `json:"description" validate:"omitempty,max=500"`
+	RoleID      *uuid.UUID `json:"role_id"`
+	Scopes      []string   `json:"scopes"`
+	Audiences   []string   `json:"audiences" validate:"omitempty,dive,min=1,max=255"`
+	Active      *bool      `json:"active"`
+}
+
+// OAuthClientsListResponse adalah struktur untuk response daftar service account
+type OAuthClientsListResponse struct {
+	Clients    []OAuthClientResponse `json:"clients"`
+	Total      int64                 `json:"total"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalPages int                   `json:"total_pages"`
+}
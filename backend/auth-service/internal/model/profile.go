@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UpdateProfileRequest adalah struktur untuk request update profil oleh user sendiri.
+// Field yang tidak dikirim tidak diubah. Perubahan email tidak langsung diterapkan,
+// melainkan menunggu konfirmasi dari alamat lama dan alamat baru.
+type UpdateProfileRequest struct {
+	Name           *string `json:"name" validate:"omitempty,min=2,max=100"`
+	Email          *string `json:"email" validate:"omitempty,email,max=255"`
+	ProfilePicture *string `json:"profile_picture" validate:"omitempty,url,max=255"`
+	Phone          *string `json:"phone" validate:"omitempty,e164"`
+	Locale         *string `json:"locale" validate:"omitempty,bcp47_language_tag,max=35"`
+	Timezone       *string `json:"timezone" validate:"omitempty,timezone,max=64"`
+}
+
+// ProfileResponse adalah profil user beserta perubahan email yang menunggu konfirmasi
+type ProfileResponse struct {
+	UserResponse
+	PendingEmailChange *EmailChangeStatus `json:"pending_email_change,omitempty"`
+}
+
+// EmailChange menyimpan permintaan perubahan email yang sedang menunggu konfirmasi di Redis.
+// Email baru diterapkan setelah kedua link konfirmasi (alamat lama dan baru) dibuka.
+type EmailChange struct {
+	UserID       uuid.UUID `json:"user_id"`
+	OldEmail     string    `json:"old_email"`
+	NewEmail     string    `json:"new_email"`
+	OldTokenHash string    `json:"old_token_hash"`
+	NewTokenHash string    `json:"new_token_hash"`
+	OldConfirmed bool      `json:"old_confirmed"`
+	NewConfirmed bool      `json:"new_confirmed"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// EmailChangeStatus adalah status perubahan email tanpa token konfirmasi
+type EmailChangeStatus struct {
+	NewEmail     string    `json:"new_email"`
+	OldConfirmed bool      `json:"old_confirmed"`
+	NewConfirmed bool      `json:"new_confirmed"`
+	Completed    bool      `json:"completed"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// ToEmailChangeStatus mengkonversi EmailChange ke EmailChangeStatus
+func (e *EmailChange) ToEmailChangeStatus() EmailChangeStatus {
+	return EmailChangeStatus{
+		NewEmail:     e.NewEmail,
+		OldConfirmed: e.OldConfirmed,
+		NewConfirmed: e.NewConfirmed,
+		Completed:    e.OldConfirmed && e.NewConfirmed,
+		ExpiresAt:    e.ExpiresAt,
+	}
+}
+
+// ConfirmEmailChangeRequest adalah struktur untuk request konfirmasi perubahan email
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
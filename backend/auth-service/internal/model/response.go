@@ -9,12 +9,13 @@ type StandardResponse struct {
 
 // PaginatedResponse adalah struktur standar untuk response dengan pagination
 type PaginatedResponse struct {
-	Status  int         `json:"status"`  // HTTP status code
-	Data    interface{} `json:"data"`    // data response atau null jika error
-	Message string      `json:"message"` // pesan sukses atau error
-	Page    int         `json:"page"`    // halaman saat ini
-	Size    int         `json:"size"`    // jumlah item per halaman
-	Total   int64       `json:"total"`   // total item
+	Status     int         `json:"status"`                // HTTP status code
+	Data       interface{} `json:"data"`                  // data response atau null jika error
+	Message    string      `json:"message"`               // pesan sukses atau error
+	Page       int         `json:"page"`                  // halaman saat ini
+	Size       int         `json:"size"`                  // jumlah item per halaman
+	Total      int64       `json:"total"`                 // total item
+	NextCursor string      `json:"next_cursor,omitempty"` // cursor halaman berikutnya (cursor pagination)
 }
 
 // NewSuccessResponse membuat response sukses standar
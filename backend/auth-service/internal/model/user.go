@@ -9,24 +9,29 @@ import (
 
 // User merepresentasikan model pengguna dalam sistem
 type User struct {
-	ID             uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
-	Email          string         `gorm:"type:varchar(255);uniqueIndex" json:"email"`
-	Password       string         `gorm:"type:varchar(255)" json:"-"`
-	Name           string         `gorm:"type:varchar(255)" json:"name"`
-	ProfilePicture string         `gorm:"type:varchar(255)" json:"profile_picture"`
-	Provider       string         `gorm:"type:varchar(50);default:'local'" json:"provider"` // local, google, etc.
-	ProviderID     string         `gorm:"type:varchar(255)" json:"provider_id"`
-	Role           string         `gorm:"type:varchar(50);default:'user'" json:"role"` // user, admin - legacy field
-	RoleID         *uuid.UUID     `gorm:"type:char(36);index" json:"role_id"` // New role system
-	Verified       bool           `gorm:"default:false" json:"verified"`
-	Active         bool           `gorm:"default:true" json:"active"`
-	LastLogin      *time.Time     `json:"last_login"`
-	LoginAttempts  int            `gorm:"default:0" json:"-"`
-	LockedUntil    *time.Time     `json:"-"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
-	
+	ID                    uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Email                 string         `gorm:"type:varchar(255);uniqueIndex" json:"email"`
+	Password              string         `gorm:"type:varchar(255)" json:"-"`
+	Name                  string         `gorm:"type:varchar(255);index" json:"name"`
+	ProfilePicture        string         `gorm:"type:varchar(255)" json:"profile_picture"`
+	AvatarKey             string         `gorm:"type:varchar(255)" json:"-"` // key object avatar yang diunggah di file storage
+	Phone                 string         `gorm:"type:varchar(30)" json:"phone"`
+	Locale                string         `gorm:"type:varchar(35)" json:"locale"`                   // BCP 47 language tag, misalnya id-ID
+	Timezone              string         `gorm:"type:varchar(64)" json:"timezone"`                 // nama zona waktu IANA, misalnya Asia/Jakarta
+	Provider              string         `gorm:"type:varchar(50);default:'local'" json:"provider"` // local, google, etc.
+	ProviderID            string         `gorm:"type:varchar(255)" json:"provider_id"`
+	Role                  string         `gorm:"type:varchar(50);default:'user'" json:"role"` // user, admin - legacy field
+	RoleID                *uuid.UUID     `gorm:"type:char(36);index" json:"role_id"`          // New role system
+	Verified              bool           `gorm:"default:false" json:"verified"`
+	Active                bool           `gorm:"default:true" json:"active"`
+	LastLogin             *time.Time     `gorm:"index" json:"last_login"`
+	LoginAttempts         int            `gorm:"default:0" json:"-"`
+	LockedUntil           *time.Time     `json:"-"`
+	PasswordResetRequired bool           `gorm:"default:false" json:"password_reset_required"` // user harus mengatur password baru sebelum dapat login
+	CreatedAt             time.Time      `gorm:"index" json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `gorm:"index" json:"-"`
+
 	// Relationships
 	UserRole     *Role          `gorm:"foreignKey:RoleID" json:"user_role,omitempty"`
 	LoginHistory []LoginHistory `gorm:"foreignKey:UserID" json:"login_history,omitempty"`
@@ -35,17 +40,23 @@ type User struct {
 // LoginHistory menyimpan riwayat login pengguna
 type LoginHistory struct {
 	ID           uint           `gorm:"primaryKey" json:"id"`
-	UserID       uuid.UUID      `gorm:"type:char(36);index" json:"user_id"`
+	UserID       uuid.UUID      `gorm:"type:char(36);index;index:idx_login_histories_user_device,priority:1" json:"user_id"`
 	IP           string         `gorm:"type:varchar(50)" json:"ip"`
 	UserAgent    string         `gorm:"type:varchar(255)" json:"user_agent"`
 	DeviceInfo   string         `gorm:"type:varchar(255)" json:"device_info"`
+	DeviceFingerprint string    `gorm:"type:varchar(64);index:idx_login_histories_user_device,priority:2" json:"device_fingerprint"` // hash browser dan OS (tanpa versi) serta model perangkat
 	Browser      string         `gorm:"type:varchar(100)" json:"browser"`
 	OS           string         `gorm:"type:varchar(100)" json:"os"`
 	Country      string         `gorm:"type:varchar(100)" json:"country"`
 	City         string         `gorm:"type:varchar(100)" json:"city"`
-	Success      bool           `gorm:"default:true" json:"success"`
+	Latitude     *float64       `json:"latitude,omitempty"`
+	Longitude    *float64       `json:"longitude,omitempty"`
+	Success      bool           `gorm:"default:true;index:idx_login_histories_outcome_time,priority:1" json:"success"`
 	FailureReason string         `gorm:"type:varchar(255)" json:"failure_reason"`
-	LoginTime    time.Time      `json:"login_time"`
+	RiskScore    int            `gorm:"default:0" json:"risk_score"`                      // skor risiko 0-100 dari risk engine login
+	RiskFactors  string         `gorm:"type:varchar(255)" json:"risk_factors,omitempty"` // faktor risiko dipisahkan koma
+	RiskAction   string         `gorm:"type:varchar(20)" json:"risk_action,omitempty"`   // allow, notify, atau step_up
+	LoginTime    time.Time      `gorm:"index:idx_login_histories_outcome_time,priority:2" json:"login_time"`
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
@@ -61,20 +72,25 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 
 // UserResponse adalah struktur untuk respons API user tanpa data sensitif
 type UserResponse struct {
-	ID             uuid.UUID        `json:"id"`
-	Email          string           `json:"email"`
-	Name           string           `json:"name"`
-	ProfilePicture string           `json:"profile_picture"`
-	Provider       string           `json:"provider"`
-	Role           string           `json:"role"` // Legacy field
-	RoleID         *uuid.UUID       `json:"role_id"`
-	UserRole       *RoleResponse    `json:"user_role,omitempty"`
-	Permissions    []string         `json:"permissions,omitempty"` // Flattened permissions for easy access
-	Verified       bool             `json:"verified"`
-	Active         bool             `json:"active"`
-	LastLogin      time.Time        `json:"last_login,omitempty"`
-	CreatedAt      time.Time        `json:"created_at"`
-	UpdatedAt      time.Time        `json:"updated_at"`
+	ID                    uuid.UUID              `json:"id"`
+	Email                 string                 `json:"email"`
+	Name                  string                 `json:"name"`
+	ProfilePicture        string                 `json:"profile_picture"`
+	Phone                 string                 `json:"phone"`
+	Locale                string                 `json:"locale"`
+	Timezone              string                 `json:"timezone"`
+	Provider              string                 `json:"provider"`
+	Role                  string                 `json:"role"` // Legacy field
+	RoleID                *uuid.UUID             `json:"role_id"`
+	UserRole              *RoleResponse          `json:"user_role,omitempty"`
+	Permissions           []string               `json:"permissions,omitempty"` // Flattened permissions for easy access
+	Attributes            map[string]interface{} `json:"attributes,omitempty"`  // Custom attribute sesuai visibilitasnya
+	Verified              bool                   `json:"verified"`
+	Active                bool                   `json:"active"`
+	PasswordResetRequired bool                   `json:"password_reset_required"`
+	LastLogin             time.Time              `json:"last_login,omitempty"`
+	CreatedAt             time.Time              `json:"created_at"`
+	UpdatedAt             time.Time              `json:"updated_at"`
 }
 
 // ToUserResponse mengkonversi User ke UserResponse
@@ -85,25 +101,29 @@ func (u *User) ToUserResponse() UserResponse {
 	}
 
 	response := UserResponse{
-		ID:             u.ID,
-		Email:          u.Email,
-		Name:           u.Name,
-		ProfilePicture: u.ProfilePicture,
-		Provider:       u.Provider,
-		Role:           u.Role, // Legacy field
-		RoleID:         u.RoleID,
-		Verified:       u.Verified,
-		Active:         u.Active,
-		LastLogin:      lastLogin,
-		CreatedAt:      u.CreatedAt,
-		UpdatedAt:      u.UpdatedAt,
+		ID:                    u.ID,
+		Email:                 u.Email,
+		Name:                  u.Name,
+		ProfilePicture:        u.ProfilePicture,
+		Phone:                 u.Phone,
+		Locale:                u.Locale,
+		Timezone:              u.Timezone,
+		Provider:              u.Provider,
+		Role:                  u.Role, // Legacy field
+		RoleID:                u.RoleID,
+		Verified:              u.Verified,
+		Active:                u.Active,
+		PasswordResetRequired: u.PasswordResetRequired,
+		LastLogin:             lastLogin,
+		CreatedAt:             u.CreatedAt,
+		UpdatedAt:             u.UpdatedAt,
 	}
 
 	// Add role information if available
 	if u.UserRole != nil {
 		roleResponse := u.UserRole.ToRoleResponse()
 		response.UserRole = &roleResponse
-		
+
 		// Flatten permissions for easy access
 		permissions := make([]string, len(u.UserRole.Permissions))
 		for i, p := range u.UserRole.Permissions {
@@ -202,6 +222,53 @@ type UpdateUserRequest struct {
 	RoleID *uuid.UUID `json:"role_id" validate:"omitempty"` // New role system
 }
 
+// CreateUserRequest adalah struktur untuk request pembuatan user oleh admin.
+// Password boleh dikosongkan jika user diundang untuk mengatur password sendiri.
+type CreateUserRequest struct {
+	Email      string `json:"email" validate:"required,email"`
+	Name       string `json:"name" validate:"required,min=1"`
+	Password   string `json:"password" validate:"omitempty,min=8"`
+	Role       string `json:"role" validate:"omitempty,oneof=user admin"`
+	Verified   bool   `json:"verified"`
+	SendInvite bool   `json:"send_invite"`
+
+	RoleID *uuid.UUID `json:"-"` // role RBAC yang sudah divalidasi, diisi oleh bulk import
+}
+
+// CreateUserResponse adalah struktur untuk response pembuatan user oleh admin
+type CreateUserResponse struct {
+	User            UserResponse `json:"user"`
+	InviteToken     string       `json:"invite_token,omitempty"` // hanya dikembalikan jika MAIL_DRIVER=log
+	InviteExpiresAt *time.Time   `json:"invite_expires_at,omitempty"`
+}
+
+// AcceptInviteRequest adalah struktur untuk request pengaturan password dari undangan
+type AcceptInviteRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// DeletedUserResponse adalah struktur untuk response user yang sudah dihapus (soft delete)
+type DeletedUserResponse struct {
+	UserResponse
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeableAt time.Time `json:"purgeable_at"` // waktu paling awal user dapat dihapus permanen
+}
+
+// DeletedUsersListResponse adalah struktur untuk response daftar user yang sudah dihapus
+type DeletedUsersListResponse struct {
+	Users      []DeletedUserResponse `json:"users"`
+	Total      int64                 `json:"total"`
+	Page       int                   `json:"page"`
+	Limit      int                   `json:"limit"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+// PurgeUsersResponse adalah struktur untuk response purge user yang melewati masa retensi
+type PurgeUsersResponse struct {
+	Purged int `json:"purged"`
+}
+
 // UsersListResponse adalah struktur untuk response daftar user
 type UsersListResponse struct {
 	Users      []UserResponse `json:"users"`
@@ -209,4 +276,36 @@ type UsersListResponse struct {
 	Page       int            `json:"page"`
 	Limit      int            `json:"limit"`
 	TotalPages int            `json:"total_pages"`
+	NextCursor string         `json:"next_cursor,omitempty"` // hanya diisi pada cursor pagination jika masih ada halaman berikutnya
+}
+
+// Kolom yang dapat digunakan untuk mengurutkan daftar user (semuanya memiliki index)
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortEmail     = "email"
+	UserSortName      = "name"
+	UserSortLastLogin = "last_login"
+)
+
+// UserFilter adalah kriteria pencarian, filter, pengurutan, dan pagination daftar user
+type UserFilter struct {
+	Search         string            `json:"search"`
+	Role           string            `json:"role" validate:"omitempty,max=50"`
+	RoleID         *uuid.UUID        `json:"role_id"`
+	Active         *bool             `json:"active"`
+	Verified       *bool             `json:"verified"`
+	Locked         *bool             `json:"locked"`
+	Provider       string            `json:"provider" validate:"omitempty,max=50"`
+	CreatedFrom    *time.Time        `json:"created_from"`
+	CreatedTo      *time.Time        `json:"created_to"`
+	LastLoginFrom  *time.Time        `json:"last_login_from"`
+	LastLoginTo    *time.Time        `json:"last_login_to"`
+	SortBy         string            `json:"-" validate:"omitempty,oneof=created_at email name last_login"`
+	SortOrder      string            `json:"-" validate:"omitempty,oneof=asc desc"`
+	Page           int               `json:"-"`
+	Limit          int               `json:"-"`
+	UseCursor      bool              `json:"-"` // gunakan cursor pagination alih-alih offset
+	Cursor         string            `json:"-"` // cursor dari next_cursor halaman sebelumnya, kosong untuk halaman pertama
+	AttributeQuery map[string]string `json:"-"` // filter custom attribute dari query attr.<name>=<value>
+	Attributes     []AttributeFilter `json:"-"` // AttributeQuery yang sudah dikonversi sesuai tipe definisinya
 }
\ No newline at end of file
@@ -0,0 +1,215 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipe nilai custom attribute
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeDate    = "date" // format YYYY-MM-DD
+	AttributeTypeEnum    = "enum"
+)
+
+// Visibilitas custom attribute.
+// admin: hanya terlihat dan dapat diubah oleh admin.
+// self: terlihat oleh user sendiri, hanya admin yang dapat mengubah.
+// self_edit: terlihat dan dapat diubah oleh user sendiri.
+const (
+	AttributeVisibilityAdmin    = "admin"
+	AttributeVisibilitySelf     = "self"
+	AttributeVisibilitySelfEdit = "self_edit"
+)
+
+// AttributeDefinition adalah definisi custom attribute user yang dibuat admin,
+// misalnya department, employee_id, atau cost_center
+type AttributeDefinition struct {
+	ID          uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	Name        string    `gorm:"type:varchar(64);uniqueIndex" json:"name"` // key attribute, huruf kecil dan underscore
+	DisplayName string    `gorm:"type:varchar(100)" json:"display_name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	Type        string    `gorm:"type:varchar(20)" json:"type"`
+	Required    bool      `gorm:"default:false" json:"required"`
+	Visibility  string    `gorm:"type:varchar(20);default:'admin'" json:"visibility"`
+	Pattern     string    `gorm:"type:varchar(255)" json:"pattern"`  // regex untuk tipe string
+	MinValue    *float64  `json:"min_value"`                         // nilai minimum (number) atau panjang minimum (string)
+	MaxValue    *float64  `json:"max_value"`                         // nilai maksimum (number) atau panjang maksimum (string)
+	Options     string    `gorm:"type:text" json:"-"`                // pilihan tipe enum dalam format JSON array
+	JWTClaim    string    `gorm:"type:varchar(64)" json:"jwt_claim"` // nama klaim di access token, kosong jika tidak dipetakan
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan definisi attribute baru
+func (d *AttributeDefinition) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// OptionList mengembalikan pilihan nilai attribute bertipe enum
+func (d *AttributeDefinition) OptionList() []string {
+	var options []string
+	if d.Options != "" {
+		json.Unmarshal([]byte(d.Options), &options)
+	}
+	return options
+}
+
+// SetOptionList menyimpan pilihan nilai attribute bertipe enum
+func (d *AttributeDefinition) SetOptionList(options []string) {
+	d.Options = ""
+	if len(options) > 0 {
+		encoded, _ := json.Marshal(options)
+		d.Options = string(encoded)
+	}
+}
+
+// VisibleToSelf mengembalikan true jika attribute boleh dilihat oleh user pemiliknya
+func (d *AttributeDefinition) VisibleToSelf() bool {
+	return d.Visibility == AttributeVisibilitySelf || d.Visibility == AttributeVisibilitySelfEdit
+}
+
+// ToAttributeDefinitionResponse mengkonversi AttributeDefinition ke AttributeDefinitionResponse
+func (d *AttributeDefinition) ToAttributeDefinitionResponse() AttributeDefinitionResponse {
+	return AttributeDefinitionResponse{
+		ID:          d.ID,
+		Name:        d.Name,
+		DisplayName: d.DisplayName,
+		Description: d.Description,
+		Type:        d.Type,
+		Required:    d.Required,
+		Visibility:  d.Visibility,
+		Pattern:     d.Pattern,
+		MinValue:    d.MinValue,
+		MaxValue:    d.MaxValue,
+		Options:     d.OptionList(),
+		JWTClaim:    d.JWTClaim,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+// UserAttributeValue menyimpan nilai custom attribute seorang user. Nilai disimpan
+// di kolom sesuai tipenya agar dapat dicari dan diurutkan dengan index.
+type UserAttributeValue struct {
+	UserID       uuid.UUID           `gorm:"type:char(36);primaryKey" json:"user_id"`
+	DefinitionID uuid.UUID           `gorm:"type:char(36);primaryKey;index:idx_attr_string,priority:1;index:idx_attr_number,priority:1;index:idx_attr_date,priority:1" json:"definition_id"`
+	Definition   AttributeDefinition `gorm:"foreignKey:DefinitionID" json:"-"`
+	StringValue  *string             `gorm:"type:varchar(255);index:idx_attr_string,priority:2" json:"string_value,omitempty"` // tipe string dan enum
+	NumberValue  *float64            `gorm:"index:idx_attr_number,priority:2" json:"number_value,omitempty"`
+	BoolValue    *bool               `json:"bool_value,omitempty"`
+	DateValue    *time.Time          `gorm:"type:date;index:idx_attr_date,priority:2" json:"date_value,omitempty"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// TypedValue mengembalikan nilai attribute sesuai tipenya; tanggal dalam format YYYY-MM-DD
+func (v *UserAttributeValue) TypedValue() interface{} {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.NumberValue != nil:
+		return *v.NumberValue
+	case v.BoolValue != nil:
+		return *v.BoolValue
+	case v.DateValue != nil:
+		return v.DateValue.Format("2006-01-02")
+	default:
+		return nil
+	}
+}
+
+// AttributeMap membuat map nama attribute ke nilai. Attribute dengan visibilitas admin
+// hanya disertakan jika includeAdminOnly bernilai true.
+func AttributeMap(values []UserAttributeValue, includeAdminOnly bool) map[string]interface{} {
+	attributes := make(map[string]interface{}, len(values))
+	for i := range values {
+		if !includeAdminOnly && !values[i].Definition.VisibleToSelf() {
+			continue
+		}
+		attributes[values[i].Definition.Name] = values[i].TypedValue()
+	}
+	return attributes
+}
+
+// AttributeClaims membuat map nama klaim JWT ke nilai untuk attribute yang dipetakan ke access token
+func AttributeClaims(values []UserAttributeValue) map[string]interface{} {
+	claims := make(map[string]interface{})
+	for i := range values {
+		if values[i].Definition.JWTClaim != "" {
+			claims[values[i].Definition.JWTClaim] = values[i].TypedValue()
+		}
+	}
+	if len(claims) == 0 {
+		return nil
+	}
+	return claims
+}
+
+// AttributeFilter adalah filter daftar user berdasarkan nilai custom attribute yang sudah
+// dikonversi ke tipe definisinya
+type AttributeFilter struct {
+	DefinitionID uuid.UUID
+	Type         string
+	Value        interface{}
+}
+
+// AttributeDefinitionResponse adalah struktur respons definisi custom attribute
+type AttributeDefinitionResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Required    bool      `json:"required"`
+	Visibility  string    `json:"visibility"`
+	Pattern     string    `json:"pattern,omitempty"`
+	MinValue    *float64  `json:"min_value,omitempty"`
+	MaxValue    *float64  `json:"max_value,omitempty"`
+	Options     []string  `json:"options,omitempty"`
+	JWTClaim    string    `json:"jwt_claim,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateAttributeDefinitionRequest adalah struktur untuk request membuat definisi custom attribute
+type CreateAttributeDefinitionRequest struct {
+	Name        string   `json:"name" validate:"required,min=1,max=64"`
+	DisplayName string   `json:"display_name" validate:"required,min=1,max=100"`
+	Description string   `json:"description" validate:"max=255"`
+	Type        string   `json:"type" validate:"required,oneof=string number boolean date enum"`
+	Required    bool     `json:"required"`
+	Visibility  string   `json:"visibility" validate:"omitempty,oneof=admin self self_edit"`
+	Pattern     string   `json:"pattern" validate:"max=255"`
+	MinValue    *float64 `json:"min_value"`
+	MaxValue    *float64 `json:"max_value"`
+	Options     []string `json:"options" validate:"omitempty,max=100,dive,min=1,max=255"`
+	JWTClaim    string   `json:"jwt_claim" validate:"omitempty,max=64"`
+}
+
+// UpdateAttributeDefinitionRequest adalah struktur untuk request update definisi custom attribute.
+// Nama dan tipe tidak dapat diubah karena nilai yang sudah tersimpan bergantung padanya.
+type UpdateAttributeDefinitionRequest struct {
+	DisplayName *string   `json:"display_name" validate:"omitempty,min=1,max=100"`
+	Description *string   `json:"description" validate:"omitempty,max=255"`
+	Required    *bool     `json:"required"`
+	Visibility  *string   `json:"visibility" validate:"omitempty,oneof=admin self self_edit"`
+	Pattern     *string   `json:"pattern" validate:"omitempty,max=255"`
+	MinValue    *float64  `json:"min_value"`
+	MaxValue    *float64  `json:"max_value"`
+	Options     *[]string `json:"options" validate:"omitempty,max=100,dive,min=1,max=255"`
+	JWTClaim    *string   `json:"jwt_claim" validate:"omitempty,max=64"`
+}
+
+// SetUserAttributesRequest adalah struktur untuk request mengubah custom attribute user.
+// Attribute yang tidak disebutkan tidak berubah; nilai null menghapus attribute.
+type SetUserAttributesRequest struct {
+	Attributes map[string]interface{} `json:"attributes" validate:"required,max=100"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// Aksi yang dapat dijalankan secara massal terhadap user
+const (
+	BulkActionDeactivate         = "deactivate"
+	BulkActionActivate           = "activate"
+	BulkActionChangeRole         = "change_role"
+	BulkActionForcePasswordReset = "force_password_reset"
+	BulkActionRevokeSessions     = "revoke_sessions"
+)
+
+// Status hasil bulk action per user
+const (
+	BulkResultSucceeded = "succeeded"
+	BulkResultSkipped   = "skipped"
+	BulkResultFailed    = "failed"
+)
+
+// BulkUserActionRequest adalah struktur untuk request bulk action user.
+// Target dipilih dengan user_ids atau filter (sama dengan filter GET /users), tidak keduanya.
+type BulkUserActionRequest struct {
+	Action  string      `json:"action" validate:"required,oneof=deactivate activate change_role force_password_reset revoke_sessions"`
+	UserIDs []uuid.UUID `json:"user_ids" validate:"omitempty,max=1000"`
+	Filter  *UserFilter `json:"filter"`
+	Role    string      `json:"role" validate:"omitempty,max=50"` // nama role untuk change_role
+	RoleID  *uuid.UUID  `json:"role_id"`                          // ID role untuk change_role
+}
+
+// BulkUserActionResult adalah hasil bulk action untuk satu user
+type BulkUserActionResult struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Email      string    `json:"email,omitempty"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	ResetToken string    `json:"reset_token,omitempty"` // token force_password_reset, hanya untuk driver mail "log"
+}
+
+// BulkUserActionResponse adalah struktur untuk response bulk action user
+type BulkUserActionResponse struct {
+	Action    string                 `json:"action"`
+	Total     int                    `json:"total"`
+	Succeeded int                    `json:"succeeded"`
+	Skipped   int                    `json:"skipped"`
+	Failed    int                    `json:"failed"`
+	Results   []BulkUserActionResult `json:"results"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+// Format file untuk import dan export user
+const (
+	UserImportFormatCSV  = "csv"
+	UserImportFormatJSON = "json"
+	UserExportFormatCSV  = "csv"
+	UserExportFormatJSON = "ndjson"
+)
+
+// Status hasil import per baris
+const (
+	ImportRowStatusCreated = "created" // user berhasil dibuat
+	ImportRowStatusValid   = "valid"   // baris valid pada dry-run
+	ImportRowStatusFailed  = "failed"  // baris gagal validasi atau gagal dibuat
+)
+
+// ImportUserRow adalah satu baris data pada bulk import user
+type ImportUserRow struct {
+	Email      string `json:"email" validate:"required,email"`
+	Name       string `json:"name" validate:"required,min=1"`
+	Password   string `json:"password" validate:"omitempty,min=8"`
+	Role       string `json:"role" validate:"omitempty,max=50"` // nama role (user, admin, atau role RBAC lain)
+	Verified   bool   `json:"verified"`
+	SendInvite bool   `json:"send_invite"`
+}
+
+// ImportUserResult adalah hasil import untuk satu baris
+type ImportUserResult struct {
+	Row         int        `json:"row"` // nomor baris data, dimulai dari 1
+	Email       string     `json:"email"`
+	Status      string     `json:"status"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	InviteToken string     `json:"invite_token,omitempty"` // hanya dikembalikan jika MAIL_DRIVER=log
+	Errors      []string   `json:"errors,omitempty"`
+}
+
+// ImportUsersResponse adalah struktur untuk response bulk import user
+type ImportUsersResponse struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Valid   int                `json:"valid"`
+	Failed  int                `json:"failed"`
+	Results []ImportUserResult `json:"results"`
+}
@@ -0,0 +1,226 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tipe event identitas yang dapat dilanggan webhook
+const (
+	WebhookEventUserRegistered         = EventUserRegistered
+	WebhookEventUserLoggedIn           = EventUserLoggedIn
+	WebhookEventUserLocked             = EventUserLocked
+	WebhookEventUserRoleChanged        = EventUserRoleChanged
+	WebhookEventUserDeleted            = EventUserDeleted
+	WebhookEventRoleCreated            = EventRoleCreated
+	WebhookEventRoleUpdated            = EventRoleUpdated
+	WebhookEventRoleDeleted            = EventRoleDeleted
+	WebhookEventRolePermissionsChanged = EventRolePermissionsChanged
+	WebhookEventPing                   = "webhook.ping" // event uji coba, selalu dikirim ke subscription yang dituju
+	WebhookEventAll                    = "*"            // berlangganan semua event
+)
+
+// WebhookEventTypes adalah daftar event yang dapat dipilih saat membuat subscription
+var WebhookEventTypes = []string{
+	WebhookEventUserRegistered,
+	WebhookEventUserLoggedIn,
+	WebhookEventUserLocked,
+	WebhookEventUserRoleChanged,
+	WebhookEventUserDeleted,
+	WebhookEventRoleCreated,
+	WebhookEventRoleUpdated,
+	WebhookEventRoleDeleted,
+	WebhookEventRolePermissionsChanged,
+}
+
+// Status pengiriman webhook
+const (
+	WebhookDeliveryPending   = "pending"   // menunggu pengiriman pertama
+	WebhookDeliveryFailed    = "failed"    // gagal, akan dicoba lagi pada next_attempt_at
+	WebhookDeliverySucceeded = "succeeded" // diterima endpoint dengan status 2xx
+	WebhookDeliveryDead      = "dead"      // batas percobaan habis, hanya dikirim ulang secara manual
+)
+
+// Header pada request webhook
+const (
+	WebhookHeaderID        = "X-Webhook-ID"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+
+	// WebhookHeaderIdempotencyKey berisi ID domain event, sama untuk setiap pengiriman ulang
+	WebhookHeaderIdempotencyKey = "Idempotency-Key"
+)
+
+// WebhookSubscription adalah endpoint eksternal yang menerima event identitas
+type WebhookSubscription struct {
+	ID          uuid.UUID      `gorm:"type:char(36);primary_key" json:"id"`
+	Name        string         `gorm:"type:varchar(100)" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	URL         string         `gorm:"type:varchar(2048)" json:"url"`
+	Secret      string         `gorm:"type:varchar(255)" json:"-"`       // kunci HMAC payload, disimpan plaintext karena dibutuhkan saat menandatangani
+	Events      string         `gorm:"type:varchar(1000)" json:"events"` // daftar event dipisah spasi, "*" untuk semua event
+	Active      bool           `gorm:"default:true" json:"active"`
+	CreatedBy   *uuid.UUID     `gorm:"type:char(36)" json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan subscription baru
+func (w *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Subscribes mengecek apakah subscription berlangganan tipe event tertentu
+func (w *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, event := range strings.Fields(w.Events) {
+		if event == WebhookEventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionResponse adalah struktur untuk respons API subscription webhook
+type WebhookSubscriptionResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	URL         string     `json:"url"`
+	Events      []string   `json:"events"`
+	Active      bool       `json:"active"`
+	CreatedBy   *uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ToWebhookSubscriptionResponse mengkonversi WebhookSubscription ke WebhookSubscriptionResponse
+func (w *WebhookSubscription) ToWebhookSubscriptionResponse() WebhookSubscriptionResponse {
+	return WebhookSubscriptionResponse{
+		ID:          w.ID,
+		Name:        w.Name,
+		Description: w.Description,
+		URL:         w.URL,
+		Events:      strings.Fields(w.Events),
+		Active:      w.Active,
+		CreatedBy:   w.CreatedBy,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+	}
+}
+
+// WebhookSubscriptionSecretResponse berisi signing secret dalam bentuk plaintext.
+// Hanya dikembalikan sekali saat subscription dibuat atau secret dirotasi.
+type WebhookSubscriptionSecretResponse struct {
+	Subscription WebhookSubscriptionResponse `json:"subscription"`
+	Secret       string                      `json:"secret"`
+}
+
+// CreateWebhookSubscriptionRequest adalah struktur untuk request create subscription webhook
+type CreateWebhookSubscriptionRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100"`
+	Description string   `json:"description" validate:"max=500"`
+	URL         string   `json:"url" validate:"required,url,max=2048"`
+	Events      []string `json:"events" validate:"required,min=1,dive,required"`
+}
+
+// UpdateWebhookSubscriptionRequest adalah struktur untuk request update subscription webhook
+type UpdateWebhookSubscriptionRequest struct {
+	Name        *string  `json:"name" validate:"omitempty,min=2,max=100"`
+	Description *string  `json:"description" validate:"omitempty,max=500"`
+	URL         *string  `json:"url" validate:"omitempty,url,max=2048"`
+	Events      []string `json:"events" validate:"omitempty,dive,required"`
+	Active      *bool    `json:"active"`
+}
+
+// WebhookSubscriptionsListResponse adalah struktur untuk response daftar subscription webhook
+type WebhookSubscriptionsListResponse struct {
+	Subscriptions []WebhookSubscriptionResponse `json:"subscriptions"`
+	Total         int64                         `json:"total"`
+	Page          int                           `json:"page"`
+	Limit         int                           `json:"limit"`
+	TotalPages    int                           `json:"total_pages"`
+}
+
+// WebhookEvent adalah envelope payload yang dikirim ke endpoint webhook
+type WebhookEvent struct {
+	ID        uuid.UUID              `json:"id"`
+	Type      string                 `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// WebhookDelivery adalah pengiriman satu event ke satu subscription beserta status terakhirnya
+type WebhookDelivery struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primary_key" json:"id"`
+	SubscriptionID uuid.UUID  `gorm:"type:char(36);index;uniqueIndex:idx_webhook_deliveries_event,priority:2" json:"subscription_id"`
+	EventID        uuid.UUID  `gorm:"type:char(36);uniqueIndex:idx_webhook_deliveries_event,priority:1" json:"event_id"` // ID domain event, satu delivery per subscription
+	EventType      string     `gorm:"type:varchar(100);index" json:"event_type"`
+	Payload        string     `gorm:"type:mediumtext" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `gorm:"type:varchar(1000)" json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	Subscription *WebhookSubscription     `gorm:"foreignKey:SubscriptionID" json:"-"`
+	AttemptLogs  []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID" json:"attempt_logs,omitempty"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan delivery baru
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookDeliveryAttempt adalah log satu percobaan pengiriman webhook
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `gorm:"type:char(36);primary_key" json:"id"`
+	DeliveryID     uuid.UUID `gorm:"type:char(36);index" json:"delivery_id"`
+	Attempt        int       `json:"attempt"`
+	ResponseStatus int       `json:"response_status"`                // 0 jika request gagal sebelum ada respons
+	ResponseBody   string    `gorm:"type:text" json:"response_body"` // dipotong maksimal 1 KB
+	Error          string    `gorm:"type:varchar(1000)" json:"error"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook untuk mengatur UUID sebelum menyimpan log percobaan baru
+func (a *WebhookDeliveryAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookDeliveryFilter adalah filter untuk daftar pengiriman webhook
+type WebhookDeliveryFilter struct {
+	SubscriptionID *uuid.UUID `json:"subscription_id"`
+	EventType      string     `json:"event_type" validate:"max=100"`
+	Status         string     `json:"status" validate:"omitempty,oneof=pending failed succeeded dead"`
+	Page           int        `json:"page" validate:"min=1"`
+	Limit          int        `json:"limit" validate:"min=1,max=100"`
+}
+
+// WebhookDeliveriesListResponse adalah struktur untuk response daftar pengiriman webhook
+type WebhookDeliveriesListResponse struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"total_pages"`
+}
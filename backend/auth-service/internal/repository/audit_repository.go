@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditBatchSize membatasi jumlah baris per INSERT saat menyimpan audit log sekaligus
+const auditBatchSize = 100
+
+// auditChainHeadID adalah ID satu-satunya baris pada tabel audit_chain_head
+const auditChainHeadID = 1
+
+// AuditRepository interface untuk operasi database audit log.
+// Audit log bersifat append-only sehingga tidak ada operasi update maupun delete.
+// Setiap entry baru ditambahkan ke hash chain saat disimpan.
+type AuditRepository interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+	CreateBatch(ctx context.Context, entries []model.AuditLog) error
+	List(ctx context.Context, filter *model.AuditFilter, offset, limit int) ([]model.AuditLog, int64, error)
+	ListAfter(ctx context.Context, filter *model.AuditFilter, after *model.AuditLog, limit int) ([]model.AuditLog, error)
+
+	// Hash chain dan checkpoint
+	GetChainHead(ctx context.Context) (*model.AuditChainHead, error)
+	ListChain(ctx context.Context, afterSequence uint64, limit int) ([]model.AuditLog, error)
+	CountUnchained(ctx context.Context) (int64, error)
+	CreateCheckpoint(ctx context.Context, checkpoint *model.AuditCheckpoint) error
+	ListCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error)
+}
+
+// auditRepository implementasi AuditRepository
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository membuat instance baru AuditRepository
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create menyimpan satu audit log di ujung hash chain
+func (r *auditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	if err := r.appendToChain(ctx, []*model.AuditLog{entry}); err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// CreateBatch menyimpan beberapa audit log berurutan di ujung hash chain dalam satu transaksi
+func (r *auditRepository) CreateBatch(ctx context.Context, entries []model.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	chain := make([]*model.AuditLog, len(entries))
+	for i := range entries {
+		chain[i] = &entries[i]
+	}
+	if err := r.appendToChain(ctx, chain); err != nil {
+		return fmt.Errorf("failed to create audit logs: %w", err)
+	}
+	return nil
+}
+
+// appendToChain memberi sequence, prev_hash, dan hash pada entries lalu menyimpannya.
+// Baris audit_chain_head dikunci selama transaksi sehingga penulisan bersamaan
+// tetap menghasilkan chain yang linear.
+func (r *auditRepository) appendToChain(ctx context.Context, entries []*model.AuditLog) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Pastikan baris ujung chain ada sebelum dikunci
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.AuditChainHead{ID: auditChainHeadID, Hash: model.AuditGenesisHash, UpdatedAt: time.Now()}).Error
+		if err != nil {
+			return err
+		}
+
+		var head model.AuditChainHead
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, auditChainHeadID).Error; err != nil {
+			return err
+		}
+
+		// Waktu dibulatkan ke detik agar sama dengan nilai yang dibaca kembali dari kolom TIMESTAMP
+		now := time.Now().Truncate(time.Second)
+		for _, entry := range entries {
+			if entry.ID == uuid.Nil {
+				entry.ID = uuid.New()
+			}
+			if entry.Details == "" {
+				entry.Details = "{}"
+			}
+			sequence := head.Sequence + 1
+			entry.Sequence = &sequence
+			entry.PrevHash = head.Hash
+			entry.CreatedAt = now
+			entry.Hash = entry.ComputeHash()
+
+			head.Sequence = sequence
+			head.Hash = entry.Hash
+		}
+
+		if err := tx.CreateInBatches(entries, auditBatchSize).Error; err != nil {
+			return err
+		}
+
+		head.UpdatedAt = time.Now()
+		return tx.Save(&head).Error
+	})
+}
+
+// List mendapatkan audit log yang cocok dengan filter, terbaru lebih dulu
+func (r *auditRepository) List(ctx context.Context, filter *model.AuditFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	var entries []model.AuditLog
+	var total int64
+
+	query := applyAuditFilter(r.db.WithContext(ctx).Model(&model.AuditLog{}), filter)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// ListAfter mendapatkan audit log yang cocok dengan filter secara kronologis, dimulai
+// setelah entry after (nil untuk batch pertama). Pagination berbasis (created_at, id)
+// sehingga entry baru yang ditulis selama export tidak menggeser batch berikutnya.
+func (r *auditRepository) ListAfter(ctx context.Context, filter *model.AuditFilter, after *model.AuditLog, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+
+	query := applyAuditFilter(r.db.WithContext(ctx).Model(&model.AuditLog{}), filter)
+	if after != nil {
+		query = query.Where("(created_at > ?) OR (created_at = ? AND id > ?)", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	if err := query.Order("created_at ASC, id ASC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetChainHead mendapatkan ujung hash chain, bernilai sequence 0 jika chain masih kosong
+func (r *auditRepository) GetChainHead(ctx context.Context) (*model.AuditChainHead, error) {
+	var head model.AuditChainHead
+	err := r.db.WithContext(ctx).First(&head, auditChainHeadID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.AuditChainHead{ID: auditChainHeadID, Hash: model.AuditGenesisHash}, nil
+		}
+		return nil, fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+	return &head, nil
+}
+
+// ListChain mendapatkan entry hash chain berurutan sesuai sequence, dimulai setelah afterSequence
+func (r *auditRepository) ListChain(ctx context.Context, afterSequence uint64, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	err := r.db.WithContext(ctx).
+		Where("sequence IS NOT NULL AND sequence > ?", afterSequence).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit chain: %w", err)
+	}
+	return entries, nil
+}
+
+// CountUnchained menghitung entry lama yang ditulis sebelum hash chain diaktifkan
+func (r *auditRepository) CountUnchained(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).Where("sequence IS NULL").Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unchained audit logs: %w", err)
+	}
+	return count, nil
+}
+
+// CreateCheckpoint menyimpan checkpoint hash chain yang sudah ditandatangani
+func (r *auditRepository) CreateCheckpoint(ctx context.Context, checkpoint *model.AuditCheckpoint) error {
+	if err := r.db.WithContext(ctx).Create(checkpoint).Error; err != nil {
+		return fmt.Errorf("failed to create audit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// ListCheckpoints mendapatkan semua checkpoint berurutan sesuai sequence
+func (r *auditRepository) ListCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	var checkpoints []model.AuditCheckpoint
+	if err := r.db.WithContext(ctx).Order("sequence ASC, created_at ASC").Find(&checkpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list audit checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
+
+// applyAuditFilter menerapkan filter audit log ke query
+func applyAuditFilter(query *gorm.DB, filter *model.AuditFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.ActorType != "" {
+		query = query.Where("actor_type = ?", filter.ActorType)
+	}
+	if prefix, ok := strings.CutSuffix(filter.Action, "*"); ok {
+		query = query.Where("action LIKE ?", escapeLike(prefix)+"%")
+	} else if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	return query
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/auth-service/internal/model"
+	"github.com/go-redis/redis/v8"
+)
+
+// ChatBroker interface untuk fan-out event chat ke semua instance service
+type ChatBroker interface {
+	Publish(ctx context.Context, event *model.ChatEvent) error
+	// Subscribe menerima event dari semua instance sampai ctx dibatalkan, lalu menutup channel
+	Subscribe(ctx context.Context) (<-chan *model.ChatEvent, error)
+}
+
+// redisChatBroker implementasi ChatBroker berbasis Redis pub/sub
+type redisChatBroker struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisChatBroker membuat instance baru ChatBroker pada channel Redis tertentu
+func NewRedisChatBroker(client *redis.Client, channel string) ChatBroker {
+	return &redisChatBroker{
+		client:  client,
+		channel: channel,
+	}
+}
+
+// Publish mempublikasikan event chat ke channel Redis
+func (b *redisChatBroker) Publish(ctx context.Context, event *model.ChatEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode chat event: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish chat event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe berlangganan channel Redis. Koneksi pub/sub yang terputus disambung ulang
+// oleh client Redis, event yang dipublikasikan selama terputus tidak diterima.
+func (b *redisChatBroker) Subscribe(ctx context.Context) (<-chan *model.ChatEvent, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to chat channel: %w", err)
+	}
+
+	events := make(chan *model.ChatEvent)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		incoming := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-incoming:
+				if !ok {
+					return
+				}
+				var event model.ChatEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					log.Printf("Failed to decode chat event: %v", err)
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// ChatPresenceRepository interface untuk penyimpanan presence chat lintas instance
+type ChatPresenceRepository interface {
+	// Connect mencatat koneksi user yang berlaku sampai ttl. Mengembalikan true jika
+	// ini satu-satunya koneksi user yang aktif, artinya user baru saja online.
+	Connect(ctx context.Context, userID uuid.UUID, connectionID string, ttl time.Duration) (bool, error)
+	// Disconnect menghapus koneksi user. Mengembalikan true jika tidak ada koneksi lain
+	// yang aktif, artinya user baru saja offline, dan waktu terakhir terlihat dicatat.
+	Disconnect(ctx context.Context, userID uuid.UUID, connectionID string, at time.Time) (bool, error)
+	GetPresence(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.ChatPresence, error)
+}
+
+// redisChatPresenceRepository implementasi ChatPresenceRepository berbasis Redis.
+// Koneksi user disimpan pada sorted set dengan score waktu kedaluwarsa, sehingga koneksi
+// dari instance yang berhenti mendadak tidak lagi dihitung setelah ttl terlewati.
+type redisChatPresenceRepository struct {
+	client *redis.Client
+}
+
+// NewRedisChatPresenceRepository membuat instance baru ChatPresenceRepository
+func NewRedisChatPresenceRepository(client *redis.Client) ChatPresenceRepository {
+	return &redisChatPresenceRepository{client: client}
+}
+
+// Connect mencatat atau memperpanjang koneksi user
+func (r *redisChatPresenceRepository) Connect(ctx context.Context, userID uuid.UUID, connectionID string, ttl time.Duration) (bool, error) {
+	key := chatPresenceKey(userID)
+	now := time.Now()
+
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.UnixMilli(), 10))
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(now.Add(ttl).UnixMilli()), Member: connectionID})
+	count := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to record chat presence: %w", err)
+	}
+
+	return count.Val() == 1, nil
+}
+
+// Disconnect menghapus koneksi user
+func (r *redisChatPresenceRepository) Disconnect(ctx context.Context, userID uuid.UUID, connectionID string, at time.Time) (bool, error) {
+	key := chatPresenceKey(userID)
+
+	pipe := r.client.TxPipeline()
+	pipe.ZRem(ctx, key, connectionID)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(at.UnixMilli(), 10))
+	count := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to remove chat presence: %w", err)
+	}
+	if count.Val() > 0 {
+		return false, nil
+	}
+
+	if err := r.client.Set(ctx, chatLastSeenKey(userID), at.Unix(), 0).Err(); err != nil {
+		return true, fmt.Errorf("failed to record chat last seen: %w", err)
+	}
+	return true, nil
+}
+
+// GetPresence mendapatkan status online dan waktu terakhir terlihat beberapa user sekaligus
+func (r *redisChatPresenceRepository) GetPresence(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]model.ChatPresence, error) {
+	presence := make(map[uuid.UUID]model.ChatPresence, len(userIDs))
+	if len(userIDs) == 0 {
+		return presence, nil
+	}
+
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	pipe := r.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(userIDs))
+	lastSeen := make([]*redis.StringCmd, len(userIDs))
+	for i, userID := range userIDs {
+		counts[i] = pipe.ZCount(ctx, chatPresenceKey(userID), "("+now, "+inf")
+		lastSeen[i] = pipe.Get(ctx, chatLastSeenKey(userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get chat presence: %w", err)
+	}
+
+	for i, userID := range userIDs {
+		item := model.ChatPresence{UserID: userID, Online: counts[i].Val() > 0}
+		if seconds, err := lastSeen[i].Int64(); err == nil {
+			seen := time.Unix(seconds, 0)
+			item.LastSeen = &seen
+		}
+		presence[userID] = item
+	}
+	return presence, nil
+}
+
+// chatPresenceKey adalah key sorted set koneksi aktif user
+func chatPresenceKey(userID uuid.UUID) string {
+	return fmt.Sprintf("chat:presence:%s", userID)
+}
+
+// chatLastSeenKey adalah key waktu terakhir user terlihat online
+func chatLastSeenKey(userID uuid.UUID) string {
+	return fmt.Sprintf("chat:last_seen:%s", userID)
+}
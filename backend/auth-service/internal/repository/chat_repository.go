@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository errors
+var (
+	ErrChatConversationNotFound = errors.New("chat conversation not found")
+	ErrChatMessageNotFound      = errors.New("chat message not found")
+)
+
+// ChatRepository interface untuk operasi database percakapan dan pesan chat
+type ChatRepository interface {
+	// Conversation
+	FindOrCreateConversation(ctx context.Context, userID, peerID uuid.UUID) (*model.ChatConversation, error)
+	FindConversationByID(ctx context.Context, id uuid.UUID) (*model.ChatConversation, error)
+	ListConversations(ctx context.Context, userID uuid.UUID, offset, limit int) ([]model.ChatConversation, int64, error)
+	FindPeerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+
+	// Message
+	CreateMessage(ctx context.Context, message *model.ChatMessage) (bool, error)
+	FindMessageByID(ctx context.Context, id uuid.UUID) (*model.ChatMessage, error)
+	FindMessagesByIDs(ctx context.Context, ids []uuid.UUID) ([]model.ChatMessage, error)
+	ListMessages(ctx context.Context, conversationID uuid.UUID, before *model.ChatMessage, limit int) ([]model.ChatMessage, error)
+	CountUnreadByConversation(ctx context.Context, userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	MarkDelivered(ctx context.Context, conversationID, recipientID uuid.UUID, upTo, at time.Time) (int64, error)
+	MarkRead(ctx context.Context, conversationID, recipientID uuid.UUID, upTo, at time.Time) (int64, error)
+}
+
+// chatRepository implementasi ChatRepository
+type chatRepository struct {
+	db *gorm.DB
+}
+
+// NewChatRepository membuat instance baru ChatRepository
+func NewChatRepository(db *gorm.DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+// FindOrCreateConversation mengembalikan percakapan antara dua user, membuatnya jika belum ada
+func (r *chatRepository) FindOrCreateConversation(ctx context.Context, userID, peerID uuid.UUID) (*model.ChatConversation, error) {
+	userA, userB := userID, peerID
+	if userB.String() < userA.String() {
+		userA, userB = userB, userA
+	}
+
+	conversation := model.ChatConversation{UserAID: userA, UserBID: userB}
+	// Permintaan bersamaan untuk pasangan yang sama tidak membuat percakapan ganda
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&conversation).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat conversation: %w", err)
+	}
+
+	var existing model.ChatConversation
+	if err := r.db.WithContext(ctx).Where("user_a_id = ? AND user_b_id = ?", userA, userB).First(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to find chat conversation: %w", err)
+	}
+	return &existing, nil
+}
+
+// FindConversationByID mencari percakapan berdasarkan ID
+func (r *chatRepository) FindConversationByID(ctx context.Context, id uuid.UUID) (*model.ChatConversation, error) {
+	var conversation model.ChatConversation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&conversation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChatConversationNotFound
+		}
+		return nil, fmt.Errorf("failed to find chat conversation: %w", err)
+	}
+	return &conversation, nil
+}
+
+// ListConversations mendapatkan percakapan user, yang paling baru aktif lebih dulu
+func (r *chatRepository) ListConversations(ctx context.Context, userID uuid.UUID, offset, limit int) ([]model.ChatConversation, int64, error) {
+	var conversations []model.ChatConversation
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.ChatConversation{}).Where("user_a_id = ? OR user_b_id = ?", userID, userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count chat conversations: %w", err)
+	}
+
+	err := query.Order("COALESCE(last_message_at, created_at) DESC").Offset(offset).Limit(limit).Find(&conversations).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list chat conversations: %w", err)
+	}
+
+	return conversations, total, nil
+}
+
+// FindPeerIDs mendapatkan ID semua user yang memiliki percakapan dengan user
+func (r *chatRepository) FindPeerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	var conversations []model.ChatConversation
+	err := r.db.WithContext(ctx).Select("user_a_id", "user_b_id").
+		Where("user_a_id = ? OR user_b_id = ?", userID, userID).
+		Find(&conversations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find chat peers: %w", err)
+	}
+
+	peers := make([]uuid.UUID, len(conversations))
+	for i := range conversations {
+		peers[i] = conversations[i].PeerOf(userID)
+	}
+	return peers, nil
+}
+
+// CreateMessage menyimpan pesan dan memperbarui pesan terakhir percakapan dalam satu transaksi.
+// Jika pengirim sudah pernah mengirim pesan dengan ClientMessageID yang sama, pesan yang
+// tersimpan dimuat ke message dan mengembalikan false.
+func (r *chatRepository) CreateMessage(ctx context.Context, message *model.ChatMessage) (bool, error) {
+	created := false
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(message)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return tx.Where("sender_id = ? AND client_message_id = ?", message.SenderID, message.ClientMessageID).First(message).Error
+		}
+
+		created = true
+		return tx.Model(&model.ChatConversation{}).Where("id = ?", message.ConversationID).Updates(map[string]interface{}{
+			"last_message_id": message.ID,
+			"last_message_at": message.CreatedAt,
+			"updated_at":      time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create chat message: %w", err)
+	}
+	return created, nil
+}
+
+// FindMessageByID mencari pesan berdasarkan ID
+func (r *chatRepository) FindMessageByID(ctx context.Context, id uuid.UUID) (*model.ChatMessage, error) {
+	var message model.ChatMessage
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&message).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrChatMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to find chat message: %w", err)
+	}
+	return &message, nil
+}
+
+// FindMessagesByIDs mencari beberapa pesan sekaligus
+func (r *chatRepository) FindMessagesByIDs(ctx context.Context, ids []uuid.UUID) ([]model.ChatMessage, error) {
+	var messages []model.ChatMessage
+	if len(ids) == 0 {
+		return messages, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to find chat messages: %w", err)
+	}
+	return messages, nil
+}
+
+// ListMessages mendapatkan maksimal limit pesan percakapan, terbaru lebih dulu. Jika before
+// diisi, hanya pesan yang lebih lama dari before yang diambil.
+func (r *chatRepository) ListMessages(ctx context.Context, conversationID uuid.UUID, before *model.ChatMessage, limit int) ([]model.ChatMessage, error) {
+	var messages []model.ChatMessage
+
+	query := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID)
+	if before != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", before.CreatedAt, before.CreatedAt, before.ID)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to list chat messages: %w", err)
+	}
+	return messages, nil
+}
+
+// CountUnreadByConversation menghitung pesan belum dibaca user pada setiap percakapan
+func (r *chatRepository) CountUnreadByConversation(ctx context.Context, userID uuid.UUID, conversationIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64)
+	if len(conversationIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ConversationID uuid.UUID
+		Count          int64
+	}
+	err := r.db.WithContext(ctx).Model(&model.ChatMessage{}).
+		Select("conversation_id, COUNT(*) AS count").
+		Where("recipient_id = ? AND read_at IS NULL AND conversation_id IN ?", userID, conversationIDs).
+		Group("conversation_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread chat messages: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Count
+	}
+	return counts, nil
+}
+
+// MarkDelivered menandai pesan untuk recipient sampai waktu upTo sebagai sudah diterima
+func (r *chatRepository) MarkDelivered(ctx context.Context, conversationID, recipientID uuid.UUID, upTo, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.ChatMessage{}).
+		Where("conversation_id = ? AND recipient_id = ? AND delivered_at IS NULL AND created_at <= ?", conversationID, recipientID, upTo).
+		Update("delivered_at", at)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark chat messages as delivered: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MarkRead menandai pesan untuk recipient sampai waktu upTo sebagai sudah dibaca,
+// sekaligus diterima jika belum
+func (r *chatRepository) MarkRead(ctx context.Context, conversationID, recipientID uuid.UUID, upTo, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.ChatMessage{}).
+		Where("conversation_id = ? AND recipient_id = ? AND read_at IS NULL AND created_at <= ?", conversationID, recipientID, upTo).
+		Updates(map[string]interface{}{
+			"read_at":      at,
+			"delivered_at": gorm.Expr("COALESCE(delivered_at, ?)", at),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark chat messages as read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/go-redis/redis/v8"
+)
+
+// EventSink interface untuk tujuan relay domain event dari outbox yang dapat diganti
+// antara webhook, Redis Streams, dan NATS. Publish dapat dipanggil lebih dari sekali
+// untuk event yang sama, sehingga consumer harus memakai idempotency key event.
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, event *model.DomainEvent) error
+}
+
+// redisStreamEventSink implementasi EventSink yang menambahkan event ke Redis Stream
+type redisStreamEventSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamEventSink membuat instance baru EventSink berbasis Redis Streams.
+// Stream dipangkas secara perkiraan sampai maxLen entry, 0 untuk tidak memangkas.
+func NewRedisStreamEventSink(client *redis.Client, stream string, maxLen int64) EventSink {
+	return &redisStreamEventSink{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+// Name mengembalikan nama sink
+func (s *redisStreamEventSink) Name() string {
+	return "redis"
+}
+
+// Publish menambahkan event ke stream dengan XADD. Entry berisi idempotency_key agar
+// consumer group dapat mengabaikan event yang diterima ulang.
+func (s *redisStreamEventSink) Publish(ctx context.Context, event *model.DomainEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{
+			"id":              event.ID.String(),
+			"idempotency_key": event.IdempotencyKey(),
+			"type":            event.Type,
+			"aggregate_type":  event.AggregateType,
+			"aggregate_id":    event.AggregateID,
+			"occurred_at":     event.OccurredAt.Format(time.RFC3339),
+			"data":            string(data),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add event to redis stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,530 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository errors
+var (
+	ErrFileNotFound      = errors.New("file not found")
+	ErrFolderNotFound    = errors.New("folder not found")
+	ErrFolderExists      = errors.New("folder already exists")
+	ErrQuotaExceeded     = errors.New("storage quota exceeded")
+	ErrFolderPathTooLong = errors.New("folder path too long")
+)
+
+// maxFolderPathLength sama dengan panjang kolom folders.path
+const maxFolderPathLength = 512
+
+// FileRepository interface untuk operasi database metadata file, folder, dan kuota storage
+type FileRepository interface {
+	// File
+	CreateFile(ctx context.Context, file *model.File) error
+	FindFileByID(ctx context.Context, id uuid.UUID) (*model.File, error)
+	FindFilesByIDs(ctx context.Context, ids []uuid.UUID) ([]model.File, error)
+	ListFiles(ctx context.Context, ownerID uuid.UUID, filter *model.FileFilter, offset, limit int) ([]model.File, int64, error)
+	UpdateFile(ctx context.Context, file *model.File) error
+	RecordDownload(ctx context.Context, id uuid.UUID, at time.Time) error
+	DeleteFiles(ctx context.Context, ids []uuid.UUID) ([]model.File, error)
+	CountFiles(ctx context.Context, ownerID uuid.UUID) (int64, error)
+
+	// Folder
+	CreateFolder(ctx context.Context, folder *model.Folder) error
+	FindFolderByID(ctx context.Context, id uuid.UUID) (*model.Folder, error)
+	FindFolderByPath(ctx context.Context, ownerID uuid.UUID, path string) (*model.Folder, error)
+	FindFoldersByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Folder, error)
+	ListFolders(ctx context.Context, ownerID uuid.UUID, parentID *uuid.UUID) ([]model.Folder, error)
+	GetFolderStats(ctx context.Context, folderIDs []uuid.UUID) ([]model.FolderStats, error)
+	FolderHasChildren(ctx context.Context, folder *model.Folder) (bool, error)
+	RenameFolder(ctx context.Context, folder *model.Folder, name, path string) error
+	DeleteFolderTree(ctx context.Context, folder *model.Folder) ([]model.File, error)
+	CountFolders(ctx context.Context, ownerID uuid.UUID) (int64, error)
+
+	// Kuota
+	GetQuota(ctx context.Context, userID uuid.UUID) (*model.StorageQuota, error)
+	SetQuota(ctx context.Context, userID uuid.UUID, quotaBytes *int64) error
+	ReserveQuota(ctx context.Context, userID uuid.UUID, bytes, defaultQuota int64) error
+	ReleaseQuota(ctx context.Context, userID uuid.UUID, bytes int64) error
+}
+
+// fileRepository implementasi FileRepository
+type fileRepository struct {
+	db *gorm.DB
+}
+
+// NewFileRepository membuat instance baru FileRepository
+func NewFileRepository(db *gorm.DB) FileRepository {
+	return &fileRepository{db: db}
+}
+
+// CreateFile menyimpan metadata file baru
+func (r *fileRepository) CreateFile(ctx context.Context, file *model.File) error {
+	if err := r.db.WithContext(ctx).Create(file).Error; err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	return nil
+}
+
+// FindFileByID mencari file berdasarkan ID
+func (r *fileRepository) FindFileByID(ctx context.Context, id uuid.UUID) (*model.File, error) {
+	var file model.File
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&file).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return &file, nil
+}
+
+// FindFilesByIDs mencari beberapa file sekaligus. File yang tidak ditemukan dilewati.
+func (r *fileRepository) FindFilesByIDs(ctx context.Context, ids []uuid.UUID) ([]model.File, error) {
+	var files []model.File
+	if len(ids) == 0 {
+		return files, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&files).Error; err != nil {
+		return nil, fmt.Errorf("failed to get files: %w", err)
+	}
+	return files, nil
+}
+
+// ListFiles mendapatkan daftar file milik user dengan filter, pencarian nama, dan pengurutan.
+// Filter folder "/" berarti hanya file di folder root.
+func (r *fileRepository) ListFiles(ctx context.Context, ownerID uuid.UUID, filter *model.FileFilter, offset, limit int) ([]model.File, int64, error) {
+	var files []model.File
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.File{}).Where("owner_id = ?", ownerID)
+
+	switch filter.Folder {
+	case "":
+	case "/":
+		query = query.Where("folder_id IS NULL")
+	default:
+		query = query.Where("folder_id = (?)",
+			r.db.Model(&model.Folder{}).Select("id").Where("owner_id = ? AND path = ?", ownerID, filter.Folder))
+	}
+
+	if filter.MimeType != "" {
+		// Filter tipe umum seperti "image/" atau "image/*" mencocokkan semua subtipe
+		if mimePrefix, ok := strings.CutSuffix(filter.MimeType, "*"); ok || strings.HasSuffix(filter.MimeType, "/") {
+			query = query.Where("mime_type LIKE ?", strings.TrimSuffix(mimePrefix, "/")+"/%")
+		} else {
+			query = query.Where("mime_type = ?", filter.MimeType)
+		}
+	}
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where("(name LIKE ? OR original_name LIKE ?)", searchPattern, searchPattern)
+	}
+	if filter.DateFrom != nil {
+		query = query.Where("created_at >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("created_at <= ?", *filter.DateTo)
+	}
+	if filter.SizeMin != nil {
+		query = query.Where("size >= ?", *filter.SizeMin)
+	}
+	if filter.SizeMax != nil {
+		query = query.Where("size <= ?", *filter.SizeMax)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count files: %w", err)
+	}
+
+	sortColumn := "created_at"
+	switch filter.SortBy {
+	case "name":
+		sortColumn = "name"
+	case "size":
+		sortColumn = "size"
+	}
+	sortOrder := "DESC"
+	if filter.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	err := query.Order(sortColumn + " " + sortOrder).Order("id " + sortOrder).
+		Offset(offset).Limit(limit).Find(&files).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get files: %w", err)
+	}
+
+	return files, total, nil
+}
+
+// UpdateFile memperbarui metadata file
+func (r *fileRepository) UpdateFile(ctx context.Context, file *model.File) error {
+	if err := r.db.WithContext(ctx).Save(file).Error; err != nil {
+		return fmt.Errorf("failed to update file: %w", err)
+	}
+	return nil
+}
+
+// RecordDownload menambah jumlah download file secara atomik
+func (r *fileRepository) RecordDownload(ctx context.Context, id uuid.UUID, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&model.File{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"download_count":   gorm.Expr("download_count + 1"),
+			"last_download_at": at,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to record download: %w", err)
+	}
+	return nil
+}
+
+// DeleteFiles menghapus metadata beberapa file dan mengembalikan kuota pemiliknya dalam satu
+// transaksi, lalu mengembalikan file yang benar-benar dihapus oleh pemanggilan ini
+func (r *fileRepository) DeleteFiles(ctx context.Context, ids []uuid.UUID) ([]model.File, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var files []model.File
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Find(&files).Error; err != nil {
+			return fmt.Errorf("failed to lock files: %w", err)
+		}
+		return deleteFilesAndReleaseQuota(tx, files)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// deleteFilesAndReleaseQuota menghapus file yang sudah dikunci dengan SELECT ... FOR UPDATE dan
+// mengurangi pemakaian storage pemiliknya. Karena baris dikunci, penghapusan bersamaan atas file
+// yang sama menunggu transaksi ini selesai lalu tidak menemukan file tersebut, sehingga ukuran
+// file hanya dikembalikan sekali.
+func deleteFilesAndReleaseQuota(tx *gorm.DB, files []model.File) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(files))
+	released := make(map[uuid.UUID]int64)
+	for i, file := range files {
+		ids[i] = file.ID
+		released[file.OwnerID] += file.Size
+	}
+
+	if err := tx.Where("id IN ?", ids).Delete(&model.File{}).Error; err != nil {
+		return fmt.Errorf("failed to delete files: %w", err)
+	}
+	for ownerID, size := range released {
+		err := tx.Model(&model.StorageQuota{}).
+			Where("user_id = ?", ownerID).
+			Updates(map[string]interface{}{
+				"used_bytes": gorm.Expr("GREATEST(used_bytes - ?, 0)", size),
+				"updated_at": time.Now(),
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to release storage quota: %w", err)
+		}
+	}
+	return nil
+}
+
+// CountFiles menghitung jumlah file milik user
+func (r *fileRepository) CountFiles(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.File{}).Where("owner_id = ?", ownerID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count files: %w", err)
+	}
+	return count, nil
+}
+
+// CreateFolder menyimpan folder baru. Path harus unik per user.
+func (r *fileRepository) CreateFolder(ctx context.Context, folder *model.Folder) error {
+	if len(folder.Path) > maxFolderPathLength {
+		return ErrFolderPathTooLong
+	}
+
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Folder{}).Where("owner_id = ? AND path = ?", folder.OwnerID, folder.Path).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("failed to check folder path: %w", err)
+	}
+	if count > 0 {
+		return ErrFolderExists
+	}
+
+	if err := r.db.WithContext(ctx).Create(folder).Error; err != nil {
+		return fmt.Errorf("failed to create folder: %w", err)
+	}
+	return nil
+}
+
+// FindFolderByID mencari folder berdasarkan ID
+func (r *fileRepository) FindFolderByID(ctx context.Context, id uuid.UUID) (*model.Folder, error) {
+	var folder model.Folder
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+	return &folder, nil
+}
+
+// FindFolderByPath mencari folder milik user berdasarkan path lengkap
+func (r *fileRepository) FindFolderByPath(ctx context.Context, ownerID uuid.UUID, path string) (*model.Folder, error) {
+	var folder model.Folder
+	err := r.db.WithContext(ctx).Where("owner_id = ? AND path = ?", ownerID, path).First(&folder).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+	return &folder, nil
+}
+
+// FindFoldersByIDs mencari beberapa folder sekaligus
+func (r *fileRepository) FindFoldersByIDs(ctx context.Context, ids []uuid.UUID) ([]model.Folder, error) {
+	var folders []model.Folder
+	if len(ids) == 0 {
+		return folders, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&folders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+	return folders, nil
+}
+
+// ListFolders mendapatkan sub folder langsung dari parentID (nil untuk folder root)
+func (r *fileRepository) ListFolders(ctx context.Context, ownerID uuid.UUID, parentID *uuid.UUID) ([]model.Folder, error) {
+	var folders []model.Folder
+
+	query := r.db.WithContext(ctx).Where("owner_id = ?", ownerID)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+
+	if err := query.Order("name ASC").Find(&folders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get folders: %w", err)
+	}
+	return folders, nil
+}
+
+// GetFolderStats menghitung jumlah dan total ukuran file langsung di dalam setiap folder
+func (r *fileRepository) GetFolderStats(ctx context.Context, folderIDs []uuid.UUID) ([]model.FolderStats, error) {
+	var stats []model.FolderStats
+	if len(folderIDs) == 0 {
+		return stats, nil
+	}
+
+	err := r.db.WithContext(ctx).Model(&model.File{}).
+		Select("folder_id, COUNT(*) AS file_count, COALESCE(SUM(size), 0) AS size").
+		Where("folder_id IN ?", folderIDs).
+		Group("folder_id").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder stats: %w", err)
+	}
+	return stats, nil
+}
+
+// FolderHasChildren memeriksa apakah folder masih berisi file atau sub folder
+func (r *fileRepository) FolderHasChildren(ctx context.Context, folder *model.Folder) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.File{}).Where("folder_id = ?", folder.ID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count folder files: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := r.db.WithContext(ctx).Model(&model.Folder{}).Where("parent_id = ?", folder.ID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count sub folders: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RenameFolder mengubah nama folder dan memperbarui path folder tersebut beserta
+// semua sub folder-nya dalam satu transaksi
+func (r *fileRepository) RenameFolder(ctx context.Context, folder *model.Folder, name, path string) error {
+	if len(path) > maxFolderPathLength {
+		return ErrFolderPathTooLong
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.Folder{}).Where("owner_id = ? AND path = ?", folder.OwnerID, path).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check folder path: %w", err)
+		}
+		if count > 0 {
+			return ErrFolderExists
+		}
+
+		// Path sub folder yang menjadi terlalu panjang akan ditolak oleh kolom path
+		var longest int
+		err := tx.Model(&model.Folder{}).
+			Select("COALESCE(MAX(CHAR_LENGTH(path)), 0)").
+			Where("owner_id = ? AND path LIKE ?", folder.OwnerID, escapeLike(folder.Path)+"/%").
+			Scan(&longest).Error
+		if err != nil {
+			return fmt.Errorf("failed to check sub folder paths: %w", err)
+		}
+		if longest-len(folder.Path)+len(path) > maxFolderPathLength {
+			return ErrFolderPathTooLong
+		}
+
+		err = tx.Model(&model.Folder{}).
+			Where("owner_id = ? AND path LIKE ?", folder.OwnerID, escapeLike(folder.Path)+"/%").
+			Update("path", gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", path, len(folder.Path)+1)).Error
+		if err != nil {
+			return fmt.Errorf("failed to update sub folder paths: %w", err)
+		}
+
+		now := time.Now()
+		err = tx.Model(&model.Folder{}).Where("id = ?", folder.ID).
+			Updates(map[string]interface{}{"name": name, "path": path, "updated_at": now}).Error
+		if err != nil {
+			return fmt.Errorf("failed to rename folder: %w", err)
+		}
+
+		folder.Name = name
+		folder.Path = path
+		folder.UpdatedAt = now
+		return nil
+	})
+}
+
+// DeleteFolderTree menghapus folder beserta semua sub folder dan file di dalamnya serta
+// mengembalikan kuota pemilik file dalam satu transaksi, lalu mengembalikan file yang
+// dihapus agar isinya dapat dihapus dari file storage
+func (r *fileRepository) DeleteFolderTree(ctx context.Context, folder *model.Folder) ([]model.File, error) {
+	var files []model.File
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var folderIDs []uuid.UUID
+		err := tx.Model(&model.Folder{}).
+			Where("owner_id = ? AND (id = ? OR path LIKE ?)", folder.OwnerID, folder.ID, escapeLike(folder.Path)+"/%").
+			Pluck("id", &folderIDs).Error
+		if err != nil {
+			return fmt.Errorf("failed to get folder tree: %w", err)
+		}
+
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("folder_id IN ?", folderIDs).Find(&files).Error
+		if err != nil {
+			return fmt.Errorf("failed to lock folder files: %w", err)
+		}
+		if err := deleteFilesAndReleaseQuota(tx, files); err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", folderIDs).Delete(&model.Folder{}).Error; err != nil {
+			return fmt.Errorf("failed to delete folders: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// CountFolders menghitung jumlah folder milik user
+func (r *fileRepository) CountFolders(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Folder{}).Where("owner_id = ?", ownerID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count folders: %w", err)
+	}
+	return count, nil
+}
+
+// GetQuota mendapatkan kuota dan pemakaian storage user. User yang belum pernah
+// mengunggah file mendapat kuota kosong dengan kuota default.
+func (r *fileRepository) GetQuota(ctx context.Context, userID uuid.UUID) (*model.StorageQuota, error) {
+	var quota model.StorageQuota
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.StorageQuota{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("failed to get storage quota: %w", err)
+	}
+	return &quota, nil
+}
+
+// SetQuota mengatur kuota khusus user; nil mengembalikan user ke kuota default
+func (r *fileRepository) SetQuota(ctx context.Context, userID uuid.UUID, quotaBytes *int64) error {
+	quota := model.StorageQuota{UserID: userID, QuotaBytes: quotaBytes, UpdatedAt: time.Now()}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quota_bytes", "updated_at"}),
+	}).Create(&quota).Error
+	if err != nil {
+		return fmt.Errorf("failed to set storage quota: %w", err)
+	}
+	return nil
+}
+
+// ReserveQuota menambah pemakaian storage user secara atomik hanya jika masih di
+// bawah kuota, sehingga upload bersamaan tidak dapat melewati kuota
+func (r *fileRepository) ReserveQuota(ctx context.Context, userID uuid.UUID, bytes, defaultQuota int64) error {
+	db := r.db.WithContext(ctx)
+
+	// Pastikan baris kuota ada
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.StorageQuota{UserID: userID, UpdatedAt: time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage quota: %w", err)
+	}
+
+	result := db.Model(&model.StorageQuota{}).
+		Where("user_id = ? AND used_bytes + ? <= COALESCE(quota_bytes, ?)", userID, bytes, defaultQuota).
+		Updates(map[string]interface{}{
+			"used_bytes": gorm.Expr("used_bytes + ?", bytes),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reserve storage quota: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrQuotaExceeded
+	}
+
+	return nil
+}
+
+// ReleaseQuota mengurangi pemakaian storage user setelah file dihapus
+func (r *fileRepository) ReleaseQuota(ctx context.Context, userID uuid.UUID, bytes int64) error {
+	err := r.db.WithContext(ctx).Model(&model.StorageQuota{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{
+			"used_bytes": gorm.Expr("GREATEST(used_bytes - ?, 0)", bytes),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release storage quota: %w", err)
+	}
+	return nil
+}
+
+// escapeLike meng-escape karakter wildcard LIKE agar path dicocokkan secara harfiah
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/utils"
+)
+
+// File storage errors
+var (
+	ErrObjectNotFound   = errors.New("object not found")
+	ErrInvalidObjectKey = errors.New("invalid object key")
+)
+
+// FileStorage interface untuk penyimpanan object (file) yang dapat diganti
+// antara filesystem lokal dan storage S3-compatible
+type FileStorage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// localObjectRoute adalah path endpoint service yang menyajikan object dari storage lokal
+const localObjectRoute = "/api/v1/objects"
+
+// localFileStorage implementasi FileStorage yang menyimpan object di filesystem lokal.
+// Signed URL mengarah ke endpoint service sendiri dan diverifikasi dengan HMAC.
+type localFileStorage struct {
+	basePath   string
+	baseURL    string
+	signingKey string
+}
+
+// NewLocalFileStorage membuat instance baru FileStorage berbasis filesystem lokal
+func NewLocalFileStorage(basePath, baseURL, signingKey string) (FileStorage, error) {
+	if err := os.MkdirAll(basePath, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &localFileStorage{
+		basePath:   basePath,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		signingKey: signingKey,
+	}, nil
+}
+
+// ValidateObjectKey memastikan key object berupa path relatif tanpa segmen "." atau ".."
+func ValidateObjectKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return ErrInvalidObjectKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return ErrInvalidObjectKey
+		}
+	}
+	return nil
+}
+
+// objectPath mengembalikan path file untuk key object di dalam direktori storage
+func (s *localFileStorage) objectPath(key string) (string, error) {
+	if err := ValidateObjectKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.basePath, filepath.FromSlash(key)), nil
+}
+
+// Put menyimpan object. File ditulis ke file sementara lalu di-rename agar
+// pembaca tidak pernah melihat file yang setengah tertulis.
+func (s *localFileStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0o750); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(objectPath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), objectPath); err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return nil
+}
+
+// Open membuka object untuk dibaca
+func (s *localFileStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	return file, nil
+}
+
+// Delete menghapus object. Object yang sudah tidak ada tidak dianggap error.
+func (s *localFileStorage) Delete(ctx context.Context, key string) error {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(objectPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+
+	return nil
+}
+
+// SignedURL membuat URL sementara ke endpoint storage lokal yang ditandatangani dengan HMAC
+func (s *localFileStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateObjectKey(key); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", utils.SignObjectURL(key, expires, s.signingKey))
+
+	return s.baseURL + localObjectRoute + "/" + strings.Join(segments, "/") + "?" + query.Encode(), nil
+}
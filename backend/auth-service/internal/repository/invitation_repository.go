@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository errors
+var (
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+)
+
+// InvitationRepository interface untuk operasi database undangan user
+type InvitationRepository interface {
+	Create(ctx context.Context, invitation *model.Invitation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error)
+	FindPendingByEmail(ctx context.Context, email string, now time.Time) (*model.Invitation, error)
+	List(ctx context.Context, filter *model.InvitationFilter, now time.Time, offset, limit int) ([]model.Invitation, int64, error)
+	MarkAccepted(ctx context.Context, id, userID uuid.UUID, acceptedAt time.Time) error
+	Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// invitationRepository implementasi InvitationRepository
+type invitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository membuat instance baru InvitationRepository
+func NewInvitationRepository(db *gorm.DB) InvitationRepository {
+	return &invitationRepository{db: db}
+}
+
+// Create menyimpan undangan baru
+func (r *invitationRepository) Create(ctx context.Context, invitation *model.Invitation) error {
+	if err := r.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return nil
+}
+
+// FindByID mencari undangan berdasarkan ID
+func (r *invitationRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+// FindPendingByEmail mencari undangan pending yang belum kedaluwarsa untuk email tertentu
+func (r *invitationRepository) FindPendingByEmail(ctx context.Context, email string, now time.Time) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := r.db.WithContext(ctx).
+		Where("email = ? AND status = ? AND expires_at > ?", email, model.InvitationStatusPending, now).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+// List mendapatkan daftar undangan dengan filter status dan pencarian email atau nama.
+// Status pending dan expired dibedakan berdasarkan expires_at terhadap now.
+func (r *invitationRepository) List(ctx context.Context, filter *model.InvitationFilter, now time.Time, offset, limit int) ([]model.Invitation, int64, error) {
+	var invitations []model.Invitation
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Invitation{})
+
+	switch filter.Status {
+	case model.InvitationStatusPending:
+		query = query.Where("status = ? AND expires_at > ?", model.InvitationStatusPending, now)
+	case model.InvitationStatusExpired:
+		query = query.Where("status = ? AND expires_at <= ?", model.InvitationStatusPending, now)
+	case model.InvitationStatusAccepted, model.InvitationStatusRevoked:
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where("(email LIKE ? OR name LIKE ?)", searchPattern, searchPattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count invitations: %w", err)
+	}
+
+	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&invitations).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get invitations: %w", err)
+	}
+
+	return invitations, total, nil
+}
+
+// MarkAccepted menandai undangan pending sebagai diterima oleh user yang baru dibuat
+func (r *invitationRepository) MarkAccepted(ctx context.Context, id, userID uuid.UUID, acceptedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND status = ?", id, model.InvitationStatusPending).
+		Updates(map[string]interface{}{
+			"status":           model.InvitationStatusAccepted,
+			"accepted_user_id": userID,
+			"accepted_at":      acceptedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to accept invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// Revoke mencabut undangan yang masih pending
+func (r *invitationRepository) Revoke(ctx context.Context, id uuid.UUID, revokedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND status = ?", id, model.InvitationStatusPending).
+		Updates(map[string]interface{}{
+			"status":     model.InvitationStatusRevoked,
+			"revoked_at": revokedAt,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvitationNotPending
+	}
+
+	return nil
+}
+
+// DeleteExpired menghapus undangan pending yang kedaluwarsa dan undangan yang dicabut
+// sebelum waktu tertentu. Undangan yang diterima disimpan sebagai jejak asal akun.
+func (r *invitationRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("(status = ? AND expires_at < ?) OR (status = ? AND revoked_at < ?)",
+			model.InvitationStatusPending, before, model.InvitationStatusRevoked, before).
+		Delete(&model.Invitation{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired invitations: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// torExitNodesKey adalah set Redis berisi alamat IP exit node TOR
+const torExitNodesKey = "ip_reputation:tor_exit_nodes"
+
+// torExitNodesBatchSize adalah jumlah alamat yang ditambahkan per perintah SADD
+const torExitNodesBatchSize = 1000
+
+// IPReputationRepository interface untuk daftar reputasi alamat IP yang dipakai penilaian risiko login
+type IPReputationRepository interface {
+	// ReplaceTorExitNodes mengganti seluruh daftar exit node TOR secara atomik
+	ReplaceTorExitNodes(ctx context.Context, ips []string) error
+	IsTorExitNode(ctx context.Context, ip string) (bool, error)
+}
+
+// redisIPReputationRepository implementasi IPReputationRepository menggunakan set Redis
+type redisIPReputationRepository struct {
+	client *redis.Client
+}
+
+// NewRedisIPReputationRepository membuat instance baru IPReputationRepository
+func NewRedisIPReputationRepository(client *redis.Client) IPReputationRepository {
+	return &redisIPReputationRepository{client: client}
+}
+
+// ReplaceTorExitNodes mengisi set sementara lalu menggantikan set lama dengan RENAME,
+// sehingga pemeriksaan login tidak pernah melihat daftar yang kosong atau setengah terisi
+func (r *redisIPReputationRepository) ReplaceTorExitNodes(ctx context.Context, ips []string) error {
+	if len(ips) == 0 {
+		if err := r.client.Del(ctx, torExitNodesKey).Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrRedisError, err)
+		}
+		return nil
+	}
+
+	tmpKey := torExitNodesKey + ":tmp"
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	for start := 0; start < len(ips); start += torExitNodesBatchSize {
+		end := start + torExitNodesBatchSize
+		if end > len(ips) {
+			end = len(ips)
+		}
+		members := make([]interface{}, 0, end-start)
+		for _, ip := range ips[start:end] {
+			members = append(members, ip)
+		}
+		pipe.SAdd(ctx, tmpKey, members...)
+	}
+	pipe.Rename(ctx, tmpKey, torExitNodesKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// IsTorExitNode memeriksa apakah alamat IP termasuk exit node TOR
+func (r *redisIPReputationRepository) IsTorExitNode(ctx context.Context, ip string) (bool, error) {
+	ok, err := r.client.SIsMember(ctx, torExitNodesKey, ip).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// releaseJobLockScript menghapus lock hanya jika masih dimiliki pemegang token,
+// sehingga lock yang sudah kedaluwarsa dan diambil instance lain tidak ikut terhapus
+var releaseJobLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// JobLocker interface untuk lock terdistribusi job, memastikan satu job hanya berjalan
+// di satu instance pada satu waktu
+type JobLocker interface {
+	// Acquire mengambil lock job selama ttl. Mengembalikan token pemilik dan false tanpa
+	// error jika lock sedang dipegang instance lain.
+	Acquire(ctx context.Context, name string, ttl time.Duration) (string, bool, error)
+	Release(ctx context.Context, name, token string) error
+	// ClaimSlot menandai jadwal job pada waktu slot sudah diambil. Hanya satu instance yang
+	// berhasil mengklaim setiap slot sehingga job tidak berjalan dua kali untuk jadwal yang sama.
+	ClaimSlot(ctx context.Context, name string, slot time.Time, ttl time.Duration) (bool, error)
+}
+
+// redisJobLocker implementasi JobLocker berbasis Redis SET NX
+type redisJobLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisJobLocker membuat instance baru JobLocker dengan prefix key Redis tertentu
+func NewRedisJobLocker(client *redis.Client, prefix string) JobLocker {
+	return &redisJobLocker{client: client, prefix: prefix}
+}
+
+// Acquire mengambil lock <prefix>:lock:<name> dengan token acak
+func (l *redisJobLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (string, bool, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	ok, err := l.client.SetNX(ctx, l.lockKey(name), token, ttl).Result()
+	if err != nil {
+		return "", false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	return token, ok, nil
+}
+
+// Release melepas lock jika token masih cocok
+func (l *redisJobLocker) Release(ctx context.Context, name, token string) error {
+	if err := releaseJobLockScript.Run(ctx, l.client, []string{l.lockKey(name)}, token).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	return nil
+}
+
+// ClaimSlot menyimpan <prefix>:slot:<name>:<unix slot> dengan SET NX
+func (l *redisJobLocker) ClaimSlot(ctx context.Context, name string, slot time.Time, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("%s:slot:%s:%d", l.prefix, name, slot.Unix())
+	ok, err := l.client.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	return ok, nil
+}
+
+// lockKey mengembalikan key Redis lock job
+func (l *redisJobLocker) lockKey(name string) string {
+	return fmt.Sprintf("%s:lock:%s", l.prefix, name)
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"gorm.io/gorm"
+)
+
+// JobRunRepository interface untuk operasi database riwayat run job
+type JobRunRepository interface {
+	CreateRun(ctx context.Context, run *model.JobRun) error
+	UpdateRun(ctx context.Context, run *model.JobRun) error
+	ListRuns(ctx context.Context, filter *model.JobRunFilter, offset, limit int) ([]model.JobRun, int64, error)
+	// FindLatestRuns mengembalikan run terakhir setiap job
+	FindLatestRuns(ctx context.Context) ([]model.JobRun, error)
+	// FailStaleRuns menandai run yang masih running sejak sebelum startedBefore sebagai gagal,
+	// misalnya karena instance yang menjalankannya berhenti mendadak
+	FailStaleRuns(ctx context.Context, startedBefore time.Time, reason string) (int64, error)
+	DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// jobRunRepository implementasi JobRunRepository
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRunRepository membuat instance baru JobRunRepository
+func NewJobRunRepository(db *gorm.DB) JobRunRepository {
+	return &jobRunRepository{db: db}
+}
+
+// CreateRun menyimpan run job baru
+func (r *jobRunRepository) CreateRun(ctx context.Context, run *model.JobRun) error {
+	if err := r.db.WithContext(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+	return nil
+}
+
+// UpdateRun menyimpan hasil run job
+func (r *jobRunRepository) UpdateRun(ctx context.Context, run *model.JobRun) error {
+	if err := r.db.WithContext(ctx).Save(run).Error; err != nil {
+		return fmt.Errorf("failed to update job run: %w", err)
+	}
+	return nil
+}
+
+// ListRuns mendapatkan riwayat run job sesuai filter, terbaru lebih dulu
+func (r *jobRunRepository) ListRuns(ctx context.Context, filter *model.JobRunFilter, offset, limit int) ([]model.JobRun, int64, error) {
+	var runs []model.JobRun
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.JobRun{})
+	if filter.JobName != "" {
+		query = query.Where("job_name = ?", filter.JobName)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count job runs: %w", err)
+	}
+
+	if err := query.Order("started_at DESC, id DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list job runs: %w", err)
+	}
+
+	return runs, total, nil
+}
+
+// FindLatestRuns mengembalikan run dengan ID terbesar untuk setiap job
+func (r *jobRunRepository) FindLatestRuns(ctx context.Context) ([]model.JobRun, error) {
+	var runs []model.JobRun
+
+	latest := r.db.Model(&model.JobRun{}).Select("MAX(id)").Group("job_name")
+	if err := r.db.WithContext(ctx).Where("id IN (?)", latest).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find latest job runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// FailStaleRuns menandai run yang tidak pernah selesai sebagai gagal
+func (r *jobRunRepository) FailStaleRuns(ctx context.Context, startedBefore time.Time, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.JobRun{}).
+		Where("status = ? AND started_at < ?", model.JobRunRunning, startedBefore).
+		Updates(map[string]interface{}{
+			"status": model.JobRunFailed,
+			"error":  reason,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail stale job runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteRunsBefore menghapus riwayat run yang dimulai sebelum waktu tertentu
+func (r *jobRunRepository) DeleteRunsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("started_at < ? AND status <> ?", before, model.JobRunRunning).
+		Delete(&model.JobRun{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete job runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/go-redis/redis/v8"
+)
+
+// mailDeadListMaxLen membatasi jumlah email gagal yang disimpan untuk diperiksa
+const mailDeadListMaxLen = 1000
+
+// promoteMailScript memindahkan email terjadwal yang sudah jatuh tempo ke antrean siap kirim
+// secara atomik, sehingga beberapa instance yang menjalankannya bersamaan tidak mengirim ganda
+var promoteMailScript = redis.NewScript(`
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[2])
+for _, job in ipairs(due) do
+	redis.call('ZREM', KEYS[1], job)
+	redis.call('LPUSH', KEYS[2], job)
+end
+return #due
+`)
+
+// requeueMailScript mengembalikan email pada list processing yang mulai dikirim sebelum
+// ARGV[1] ke antrean siap kirim. Email tanpa waktu mulai (diambil tepat sebelum waktunya
+// dicatat) diberi waktu ARGV[2] dan baru dikembalikan pada pemeriksaan berikutnya.
+var requeueMailScript = redis.NewScript(`
+local jobs = redis.call('LRANGE', KEYS[1], 0, -1)
+local moved = 0
+for _, job in ipairs(jobs) do
+	local since = redis.call('HGET', KEYS[2], job)
+	if not since then
+		redis.call('HSET', KEYS[2], job, ARGV[2])
+	elseif tonumber(since) <= tonumber(ARGV[1]) then
+		redis.call('LREM', KEYS[1], 1, job)
+		redis.call('HDEL', KEYS[2], job)
+		redis.call('RPUSH', KEYS[3], job)
+		moved = moved + 1
+	end
+end
+return moved
+`)
+
+// MailQueue interface untuk antrean pengiriman email yang dipakai bersama semua instance
+type MailQueue interface {
+	Enqueue(ctx context.Context, job *model.EmailJob) error
+	// Dequeue memindahkan satu email dari antrean ke list processing, menunggu paling lama
+	// timeout. Mengembalikan nil tanpa error jika antrean tetap kosong. Email tetap berada
+	// di list processing sampai Ack, Schedule, atau Bury dipanggil.
+	Dequeue(ctx context.Context, timeout time.Duration) (*model.EmailJob, error)
+	// Ack menghapus email yang sudah terkirim dari list processing
+	Ack(ctx context.Context, job *model.EmailJob) error
+	// Schedule menjadwalkan email untuk dimasukkan kembali ke antrean pada waktu at
+	Schedule(ctx context.Context, job *model.EmailJob, at time.Time) error
+	// PromoteDue memindahkan maksimal limit email terjadwal yang sudah jatuh tempo ke antrean
+	PromoteDue(ctx context.Context, now time.Time, limit int) (int, error)
+	// RequeueStale mengembalikan email di list processing yang mulai dikirim sebelum before,
+	// misalnya milik instance yang berhenti mendadak, ke antrean
+	RequeueStale(ctx context.Context, before time.Time) (int, error)
+	// Bury menyimpan email yang gagal setelah semua percobaan ke dead list
+	Bury(ctx context.Context, job *model.EmailJob) error
+}
+
+// redisMailQueue implementasi MailQueue berbasis Redis. Email siap kirim disimpan pada list
+// <prefix>:ready, email yang menunggu retry pada sorted set <prefix>:scheduled dengan score
+// waktu jatuh tempo, dan email yang gagal pada list <prefix>:dead. Email yang sedang dikirim
+// disimpan pada list <prefix>:processing beserta waktu mulainya di hash <prefix>:processing_since,
+// sehingga email milik instance yang berhenti mendadak dapat dikembalikan ke antrean.
+type redisMailQueue struct {
+	client        *redis.Client
+	readyKey      string
+	scheduledKey  string
+	processingKey string
+	sinceKey      string
+	deadKey       string
+}
+
+// NewRedisMailQueue membuat instance baru MailQueue dengan prefix key Redis tertentu
+func NewRedisMailQueue(client *redis.Client, prefix string) MailQueue {
+	return &redisMailQueue{
+		client:        client,
+		readyKey:      prefix + ":ready",
+		scheduledKey:  prefix + ":scheduled",
+		processingKey: prefix + ":processing",
+		sinceKey:      prefix + ":processing_since",
+		deadKey:       prefix + ":dead",
+	}
+}
+
+// Enqueue memasukkan email ke antrean siap kirim
+func (q *redisMailQueue) Enqueue(ctx context.Context, job *model.EmailJob) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode email job: %w", err)
+	}
+	if err := q.client.LPush(ctx, q.readyKey, payload).Err(); err != nil {
+		return fmt.Errorf("failed to enqueue email: %w", err)
+	}
+	return nil
+}
+
+// Dequeue memindahkan email terlama dari antrean siap kirim ke list processing
+func (q *redisMailQueue) Dequeue(ctx context.Context, timeout time.Duration) (*model.EmailJob, error) {
+	payload, err := q.client.BLMove(ctx, q.readyKey, q.processingKey, "RIGHT", "LEFT", timeout).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to dequeue email: %w", err)
+	}
+
+	if err := q.client.HSet(ctx, q.sinceKey, payload, time.Now().UnixMilli()).Err(); err != nil {
+		log.Printf("Failed to record email processing start: %v", err)
+	}
+
+	var job model.EmailJob
+	if err := json.Unmarshal([]byte(payload), &job); err != nil {
+		// Payload yang rusak tidak akan pernah berhasil dikirim
+		q.remove(ctx, q.client, payload)
+		return nil, fmt.Errorf("failed to decode email job: %w", err)
+	}
+	job.Receipt = payload
+	return &job, nil
+}
+
+// Ack menghapus email dari list processing
+func (q *redisMailQueue) Ack(ctx context.Context, job *model.EmailJob) error {
+	pipe := q.client.TxPipeline()
+	q.remove(ctx, pipe, job.Receipt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to ack email: %w", err)
+	}
+	return nil
+}
+
+// remove menghapus payload dari list processing beserta waktu mulainya
+func (q *redisMailQueue) remove(ctx context.Context, cmd redis.Cmdable, payload string) {
+	cmd.LRem(ctx, q.processingKey, 1, payload)
+	cmd.HDel(ctx, q.sinceKey, payload)
+}
+
+// Schedule menyimpan email pada sorted set terjadwal
+func (q *redisMailQueue) Schedule(ctx context.Context, job *model.EmailJob, at time.Time) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode email job: %w", err)
+	}
+	// Penjadwalan dan penghapusan dari list processing dilakukan dalam satu transaksi
+	pipe := q.client.TxPipeline()
+	pipe.ZAdd(ctx, q.scheduledKey, &redis.Z{Score: float64(at.UnixMilli()), Member: payload})
+	q.remove(ctx, pipe, job.Receipt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to schedule email: %w", err)
+	}
+	return nil
+}
+
+// PromoteDue memindahkan email terjadwal yang jatuh tempo ke antrean siap kirim
+func (q *redisMailQueue) PromoteDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	moved, err := promoteMailScript.Run(ctx, q.client, []string{q.scheduledKey, q.readyKey},
+		strconv.FormatInt(now.UnixMilli(), 10), limit).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to promote scheduled emails: %w", err)
+	}
+	return moved, nil
+}
+
+// RequeueStale mengembalikan email processing yang sudah terlalu lama ke antrean siap kirim
+func (q *redisMailQueue) RequeueStale(ctx context.Context, before time.Time) (int, error) {
+	moved, err := requeueMailScript.Run(ctx, q.client, []string{q.processingKey, q.sinceKey, q.readyKey},
+		strconv.FormatInt(before.UnixMilli(), 10), strconv.FormatInt(time.Now().UnixMilli(), 10)).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue stale emails: %w", err)
+	}
+	return moved, nil
+}
+
+// Bury menyimpan email pada dead list yang dipangkas sampai mailDeadListMaxLen entry
+func (q *redisMailQueue) Bury(ctx context.Context, job *model.EmailJob) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode email job: %w", err)
+	}
+
+	pipe := q.client.TxPipeline()
+	pipe.LPush(ctx, q.deadKey, payload)
+	pipe.LTrim(ctx, q.deadKey, 0, mailDeadListMaxLen-1)
+	q.remove(ctx, pipe, job.Receipt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to bury email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/model"
+)
+
+// Mailer interface untuk transport email yang dapat diganti antara SMTP, file, dan log
+type Mailer interface {
+	Send(ctx context.Context, message *model.EmailMessage) error
+}
+
+// logMailer implementasi Mailer yang hanya menulis email ke log,
+// digunakan untuk development sebelum pengiriman email dikonfigurasi
+type logMailer struct{}
+
+// NewLogMailer membuat instance baru Mailer yang menulis email ke log
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+// Send menulis versi teks email ke log
+func (m *logMailer) Send(ctx context.Context, message *model.EmailMessage) error {
+	log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.Text)
+	return nil
+}
+
+// fileMailer implementasi Mailer yang menyimpan setiap email sebagai file .eml,
+// digunakan untuk development dan pengujian tanpa server SMTP
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer membuat instance baru Mailer yang menulis email ke direktori dir
+func NewFileMailer(dir, from string) (Mailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &fileMailer{dir: dir, from: from}, nil
+}
+
+// Send menulis email lengkap dengan header MIME sehingga dapat dibuka di email client.
+// Nama file diawali waktu pengiriman agar urut saat ditampilkan.
+func (m *fileMailer) Send(ctx context.Context, message *model.EmailMessage) error {
+	raw, err := buildMIMEMessage(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), message.ID)
+	if err := os.WriteFile(filepath.Join(m.dir, name), raw, 0o640); err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+	return nil
+}
+
+// buildMIMEMessage menyusun email multipart/alternative berisi versi teks dan HTML
+func buildMIMEMessage(from string, message *model.EmailMessage) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+	recipient := &mail.Address{Name: message.ToName, Address: to.Address}
+
+	boundary, err := mimeBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+	writeHeader("From", sender.String())
+	writeHeader("To", recipient.String())
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@%s>", message.ID, messageIDDomain(sender.Address)))
+	writeHeader("MIME-Version", "1.0")
+	if message.Locale != "" {
+		writeHeader("Content-Language", message.Locale)
+	}
+	writeHeader("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", part.contentType)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+
+		writer := quotedprintable.NewWriter(&buf)
+		if _, err := writer.Write([]byte(normalizeLineEndings(part.body))); err != nil {
+			return nil, fmt.Errorf("failed to encode email body: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode email body: %w", err)
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// mimeBoundary membuat boundary multipart acak
+func mimeBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate mime boundary: %w", err)
+	}
+	return "auth-" + hex.EncodeToString(b), nil
+}
+
+// messageIDDomain mengambil domain alamat pengirim untuk Message-ID
+func messageIDDomain(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 && at < len(address)-1 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
+
+// normalizeLineEndings mengubah semua akhir baris menjadi CRLF sesuai RFC 5322
+func normalizeLineEndings(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\n", "\r\n")
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -12,9 +14,11 @@ import (
 
 // Errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrEmailAlreadyExists = errors.New("email already exists")
-	ErrDatabaseError      = errors.New("database error")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrEmailAlreadyExists   = errors.New("email already exists")
+	ErrDatabaseError        = errors.New("database error")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrLoginHistoryNotFound = errors.New("login history not found")
 )
 
 // UserRepository interface untuk operasi database user
@@ -23,6 +27,7 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (*model.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*model.User, error)
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	FindExistingEmails(ctx context.Context, emails []string) ([]string, error)
 	FindByProviderID(ctx context.Context, provider, providerID string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
@@ -32,15 +37,36 @@ type UserRepository interface {
 	LockAccount(ctx context.Context, userID uuid.UUID, duration time.Duration) error
 	SaveLoginHistory(ctx context.Context, history *model.LoginHistory) error
 	GetLoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginHistory, error)
+	HasSuccessfulLoginFromDevice(ctx context.Context, userID uuid.UUID, fingerprint, browser, os, deviceInfo string) (bool, error)
+	HasSuccessfulLoginFromCountry(ctx context.Context, userID uuid.UUID, country string) (bool, error)
+	FindLastSuccessfulLogin(ctx context.Context, userID uuid.UUID) (*model.LoginHistory, error)
+	// Maintenance methods
+	ClearExpiredLockouts(ctx context.Context, now time.Time) (int64, error)
+	FindLoginHistoryBefore(ctx context.Context, success bool, before time.Time, limit int) ([]model.LoginHistory, error)
+	DeleteLoginHistoryByIDs(ctx context.Context, ids []uint) (int64, error)
+	FindUnverifiedUserIDs(ctx context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error)
 	// User Management methods
-	GetAllUsers(ctx context.Context, offset, limit int, search string) ([]model.User, int64, error)
+	GetAllUsers(ctx context.Context, filter *model.UserFilter) ([]model.User, int64, string, error)
 	UpdateUserStatus(ctx context.Context, userID uuid.UUID, active bool) error
 	UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error
+	// Bulk methods
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.User, error)
+	UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, active bool) error
+	UpdateRoleBatch(ctx context.Context, ids []uuid.UUID, role string, roleID *uuid.UUID) error
+	SetPasswordResetRequired(ctx context.Context, ids []uuid.UUID, required bool) error
 	GetUserStats(ctx context.Context) (*model.UserStats, error)
 	GetUserActivity(ctx context.Context, userID uuid.UUID, days int) ([]model.UserActivity, error)
 	GetUserActivityResponse(ctx context.Context, userID uuid.UUID, days int) (*model.UserActivityResponse, error)
 	GetLoginSessions(ctx context.Context, userID uuid.UUID, days int) ([]model.LoginSession, error)
 	GetActivityStatistics(ctx context.Context, userID uuid.UUID, days int) (*model.ActivityStats, error)
+	// Trash (soft-deleted users) methods
+	GetDeletedUsers(ctx context.Context, offset, limit int, search string) ([]model.User, int64, error)
+	FindDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Purge mengembalikan key object di file storage (file dan avatar) milik user yang
+	// harus dihapus setelah transaksi berhasil
+	Purge(ctx context.Context, id uuid.UUID) ([]string, error)
+	FindPurgeableUserIDs(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error)
 	// Role-related methods
 	CountUsersByRoleID(ctx context.Context, roleID uuid.UUID) (int64, error)
 }
@@ -57,9 +83,10 @@ func NewMySQLUserRepository(db *gorm.DB) UserRepository {
 
 // Create menyimpan user baru ke database
 func (r *MySQLUserRepository) Create(ctx context.Context, user *model.User) error {
-	// Cek apakah email sudah ada
+	// Cek apakah email sudah ada, termasuk milik user yang sudah dihapus (soft delete)
+	// karena unique index email tetap berlaku sampai user tersebut di-purge
 	var count int64
-	if err := r.db.Model(&model.User{}).Where("email = ?", user.Email).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).Where("email = ?", user.Email).Count(&count).Error; err != nil {
 		return ErrDatabaseError
 	}
 
@@ -67,7 +94,7 @@ func (r *MySQLUserRepository) Create(ctx context.Context, user *model.User) erro
 		return ErrEmailAlreadyExists
 	}
 
-	result := r.db.WithContext(ctx).Create(user)
+	result := dbWithContext(ctx, r.db).Create(user)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -78,7 +105,7 @@ func (r *MySQLUserRepository) Create(ctx context.Context, user *model.User) erro
 // FindByID mencari user berdasarkan ID
 func (r *MySQLUserRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
 	var user model.User
-	result := r.db.WithContext(ctx).Preload("LoginHistory", func(db *gorm.DB) *gorm.DB {
+	result := dbWithContext(ctx, r.db).Preload("LoginHistory", func(db *gorm.DB) *gorm.DB {
 		return db.Order("login_time DESC").Limit(5)
 	}).First(&user, "id = ?", id)
 
@@ -100,7 +127,7 @@ func (r *MySQLUserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*m
 // FindByEmail mencari user berdasarkan email
 func (r *MySQLUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	result := r.db.WithContext(ctx).Where("email = ?", email).First(&user)
+	result := dbWithContext(ctx, r.db).Where("email = ?", email).First(&user)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -112,10 +139,26 @@ func (r *MySQLUserRepository) FindByEmail(ctx context.Context, email string) (*m
 	return &user, nil
 }
 
+// FindExistingEmails mengembalikan email dari daftar yang sudah terdaftar, termasuk milik user
+// yang dihapus (soft delete) karena email tersebut masih terikat unique index
+func (r *MySQLUserRepository) FindExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	var existing []string
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	result := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).Where("email IN ?", emails).Pluck("email", &existing)
+	if result.Error != nil {
+		return nil, ErrDatabaseError
+	}
+
+	return existing, nil
+}
+
 // FindByProviderID mencari user berdasarkan provider dan provider ID (untuk OAuth)
 func (r *MySQLUserRepository) FindByProviderID(ctx context.Context, provider, providerID string) (*model.User, error) {
 	var user model.User
-	result := r.db.WithContext(ctx).Where("provider = ? AND provider_id = ?", provider, providerID).First(&user)
+	result := dbWithContext(ctx, r.db).Where("provider = ? AND provider_id = ?", provider, providerID).First(&user)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -129,7 +172,7 @@ func (r *MySQLUserRepository) FindByProviderID(ctx context.Context, provider, pr
 
 // Update memperbarui data user
 func (r *MySQLUserRepository) Update(ctx context.Context, user *model.User) error {
-	result := r.db.WithContext(ctx).Save(user)
+	result := dbWithContext(ctx, r.db).Save(user)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -139,7 +182,7 @@ func (r *MySQLUserRepository) Update(ctx context.Context, user *model.User) erro
 
 // Delete menghapus user (soft delete)
 func (r *MySQLUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&model.User{}, "id = ?", id)
+	result := dbWithContext(ctx, r.db).Delete(&model.User{}, "id = ?", id)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -153,7 +196,7 @@ func (r *MySQLUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 // UpdateLastLogin memperbarui waktu login terakhir
 func (r *MySQLUserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID, loginTime time.Time) error {
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("last_login", loginTime)
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("last_login", loginTime)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -167,7 +210,7 @@ func (r *MySQLUserRepository) UpdateLastLogin(ctx context.Context, userID uuid.U
 
 // IncrementLoginAttempts menambah jumlah percobaan login yang gagal
 func (r *MySQLUserRepository) IncrementLoginAttempts(ctx context.Context, userID uuid.UUID) error {
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("login_attempts", gorm.Expr("login_attempts + 1"))
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("login_attempts", gorm.Expr("login_attempts + 1"))
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -181,7 +224,7 @@ func (r *MySQLUserRepository) IncrementLoginAttempts(ctx context.Context, userID
 
 // ResetLoginAttempts mengatur ulang jumlah percobaan login
 func (r *MySQLUserRepository) ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error {
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("login_attempts", 0)
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("login_attempts", 0)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -196,7 +239,7 @@ func (r *MySQLUserRepository) ResetLoginAttempts(ctx context.Context, userID uui
 // LockAccount mengunci akun user untuk durasi tertentu
 func (r *MySQLUserRepository) LockAccount(ctx context.Context, userID uuid.UUID, duration time.Duration) error {
 	lockUntil := time.Now().Add(duration)
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("locked_until", lockUntil)
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("locked_until", lockUntil)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -210,7 +253,7 @@ func (r *MySQLUserRepository) LockAccount(ctx context.Context, userID uuid.UUID,
 
 // SaveLoginHistory menyimpan riwayat login
 func (r *MySQLUserRepository) SaveLoginHistory(ctx context.Context, history *model.LoginHistory) error {
-	result := r.db.WithContext(ctx).Create(history)
+	result := dbWithContext(ctx, r.db).Create(history)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -226,7 +269,7 @@ func (r *MySQLUserRepository) GetLoginHistory(ctx context.Context, userID uuid.U
 		limit = 10 // Default limit
 	}
 
-	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("login_time DESC").Limit(limit).Find(&histories)
+	result := dbWithContext(ctx, r.db).Where("user_id = ?", userID).Order("login_time DESC").Limit(limit).Find(&histories)
 	if result.Error != nil {
 		return nil, ErrDatabaseError
 	}
@@ -234,38 +277,333 @@ func (r *MySQLUserRepository) GetLoginHistory(ctx context.Context, userID uuid.U
 	return histories, nil
 }
 
-// GetAllUsers mendapatkan semua user dengan pagination dan pencarian
-func (r *MySQLUserRepository) GetAllUsers(ctx context.Context, offset, limit int, search string) ([]model.User, int64, error) {
-	var users []model.User
-	var total int64
+// HasSuccessfulLoginFromDevice memeriksa apakah user pernah login berhasil dari perangkat
+// dengan fingerprint yang sama. Riwayat lama yang belum memiliki fingerprint dicocokkan
+// berdasarkan browser (tanpa versi), OS, dan model perangkat.
+func (r *MySQLUserRepository) HasSuccessfulLoginFromDevice(ctx context.Context, userID uuid.UUID, fingerprint, browser, os, deviceInfo string) (bool, error) {
+	var count int64
 
-	query := r.db.WithContext(ctx).Model(&model.User{})
+	result := dbWithContext(ctx, r.db).Model(&model.LoginHistory{}).
+		Where("user_id = ? AND success = ?", userID, true).
+		Where(
+			"device_fingerprint = ? OR ((device_fingerprint IS NULL OR device_fingerprint = '') AND browser LIKE ? AND os = ? AND device_info = ?)",
+			fingerprint, browser+" %", os, deviceInfo,
+		).
+		Limit(1).
+		Count(&count)
+	if result.Error != nil {
+		return false, ErrDatabaseError
+	}
 
-	// Jika ada parameter pencarian
-	if search != "" {
-		query = query.Where("email LIKE ? OR name LIKE ?", "%"+search+"%", "%"+search+"%")
+	return count > 0, nil
+}
+
+// HasSuccessfulLoginFromCountry memeriksa apakah user pernah login berhasil dari negara tertentu
+func (r *MySQLUserRepository) HasSuccessfulLoginFromCountry(ctx context.Context, userID uuid.UUID, country string) (bool, error) {
+	var count int64
+
+	result := dbWithContext(ctx, r.db).Model(&model.LoginHistory{}).
+		Where("user_id = ? AND success = ? AND country = ?", userID, true, country).
+		Limit(1).
+		Count(&count)
+	if result.Error != nil {
+		return false, ErrDatabaseError
+	}
+
+	return count > 0, nil
+}
+
+// FindLastSuccessfulLogin mendapatkan riwayat login berhasil terbaru milik user
+func (r *MySQLUserRepository) FindLastSuccessfulLogin(ctx context.Context, userID uuid.UUID) (*model.LoginHistory, error) {
+	var history model.LoginHistory
+
+	result := dbWithContext(ctx, r.db).
+		Where("user_id = ? AND success = ?", userID, true).
+		Order("login_time DESC, id DESC").
+		First(&history)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrLoginHistoryNotFound
+		}
+		return nil, ErrDatabaseError
 	}
 
+	return &history, nil
+}
+
+// ClearExpiredLockouts membuka akun yang masa kuncinya sudah lewat dan mengatur ulang
+// jumlah percobaan login, sehingga satu kegagalan setelah kunci berakhir tidak langsung
+// mengunci akun lagi
+func (r *MySQLUserRepository) ClearExpiredLockouts(ctx context.Context, now time.Time) (int64, error) {
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).
+		Where("locked_until IS NOT NULL AND locked_until < ?", now).
+		Updates(map[string]interface{}{
+			"locked_until":   nil,
+			"login_attempts": 0,
+		})
+	if result.Error != nil {
+		return 0, ErrDatabaseError
+	}
+
+	return result.RowsAffected, nil
+}
+
+// FindLoginHistoryBefore mendapatkan maksimal limit riwayat login berhasil atau gagal yang
+// lebih lama dari waktu tertentu, termasuk yang sudah di-soft delete, terlama lebih dulu
+func (r *MySQLUserRepository) FindLoginHistoryBefore(ctx context.Context, success bool, before time.Time, limit int) ([]model.LoginHistory, error) {
+	var histories []model.LoginHistory
+
+	result := dbWithContext(ctx, r.db).Unscoped().
+		Where("success = ? AND login_time < ?", success, before).
+		Order("login_time ASC, id ASC").
+		Limit(limit).
+		Find(&histories)
+	if result.Error != nil {
+		return nil, ErrDatabaseError
+	}
+
+	return histories, nil
+}
+
+// DeleteLoginHistoryByIDs menghapus permanen riwayat login berdasarkan primary key,
+// sehingga hanya baris tersebut yang dikunci selama penghapusan
+func (r *MySQLUserRepository) DeleteLoginHistoryByIDs(ctx context.Context, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := dbWithContext(ctx, r.db).Unscoped().Where("id IN ?", ids).Delete(&model.LoginHistory{})
+	if result.Error != nil {
+		return 0, ErrDatabaseError
+	}
+
+	return result.RowsAffected, nil
+}
+
+// FindUnverifiedUserIDs mendapatkan ID user lokal yang belum terverifikasi, belum pernah
+// login, dan dibuat sebelum waktu tertentu
+func (r *MySQLUserRepository) FindUnverifiedUserIDs(ctx context.Context, createdBefore time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).
+		Where("verified = ? AND provider = ? AND last_login IS NULL AND created_at < ?", false, "local", createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, ErrDatabaseError
+	}
+
+	return ids, nil
+}
+
+// GetAllUsers mendapatkan user sesuai filter dengan offset atau cursor pagination.
+// Mengembalikan daftar user, total user yang cocok dengan filter, dan cursor halaman berikutnya
+// (kosong jika tidak menggunakan cursor atau sudah di halaman terakhir).
+func (r *MySQLUserRepository) GetAllUsers(ctx context.Context, filter *model.UserFilter) ([]model.User, int64, string, error) {
+	var users []model.User
+	var total int64
+
+	query := applyUserFilter(dbWithContext(ctx, r.db).Model(&model.User{}), filter)
+
 	// Hitung total records
 	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, ErrDatabaseError
+		return nil, 0, "", ErrDatabaseError
 	}
 
-	// Ambil data dengan pagination
-	result := query.Preload("LoginHistory", func(db *gorm.DB) *gorm.DB {
+	sortBy, desc := userSort(filter)
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+
+	// ID sebagai pengurut kedua agar urutan tetap stabil untuk nilai yang sama
+	query = query.Preload("LoginHistory", func(db *gorm.DB) *gorm.DB {
 		return db.Order("login_time DESC").Limit(1)
-	}).Order("created_at DESC").Offset(offset).Limit(limit).Find(&users)
+	}).Order(sortBy + " " + order).Order("id " + order)
+
+	if !filter.UseCursor {
+		result := query.Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).Find(&users)
+		if result.Error != nil {
+			return nil, 0, "", ErrDatabaseError
+		}
+		return users, total, "", nil
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeUserCursor(filter.Cursor, sortBy, desc)
+		if err != nil {
+			return nil, 0, "", err
+		}
+		query = applyUserCursor(query, cursor, sortBy, desc)
+	}
 
+	// Ambil satu baris ekstra untuk mengetahui apakah masih ada halaman berikutnya
+	result := query.Limit(filter.Limit + 1).Find(&users)
 	if result.Error != nil {
-		return nil, 0, ErrDatabaseError
+		return nil, 0, "", ErrDatabaseError
 	}
 
-	return users, total, nil
+	nextCursor := ""
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+		nextCursor = encodeUserCursor(&users[len(users)-1], sortBy, desc)
+	}
+
+	return users, total, nextCursor, nil
+}
+
+// applyUserFilter menerapkan kriteria filter ke query user
+func applyUserFilter(query *gorm.DB, filter *model.UserFilter) *gorm.DB {
+	if filter.Search != "" {
+		query = query.Where("(email LIKE ? OR name LIKE ?)", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.RoleID != nil {
+		query = query.Where("role_id = ?", *filter.RoleID)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+	if filter.Verified != nil {
+		query = query.Where("verified = ?", *filter.Verified)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Locked != nil {
+		if *filter.Locked {
+			query = query.Where("locked_until > ?", time.Now())
+		} else {
+			query = query.Where("(locked_until IS NULL OR locked_until <= ?)", time.Now())
+		}
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *filter.CreatedTo)
+	}
+	if filter.LastLoginFrom != nil {
+		query = query.Where("last_login >= ?", *filter.LastLoginFrom)
+	}
+	if filter.LastLoginTo != nil {
+		query = query.Where("last_login <= ?", *filter.LastLoginTo)
+	}
+	for _, attribute := range filter.Attributes {
+		query = query.Where("EXISTS (SELECT 1 FROM user_attribute_values v WHERE v.user_id = users.id AND v.definition_id = ? AND v."+attributeValueColumn(attribute.Type)+" = ?)",
+			attribute.DefinitionID, attribute.Value)
+	}
+
+	return query
+}
+
+// userSort mengembalikan kolom pengurutan yang valid dan arahnya (default created_at DESC)
+func userSort(filter *model.UserFilter) (string, bool) {
+	switch filter.SortBy {
+	case model.UserSortEmail, model.UserSortName, model.UserSortLastLogin, model.UserSortCreatedAt:
+		return filter.SortBy, filter.SortOrder != "asc"
+	default:
+		return model.UserSortCreatedAt, filter.SortOrder != "asc"
+	}
+}
+
+// userCursor adalah posisi terakhir pada cursor pagination. Kolom dan arah pengurutan ikut
+// disimpan agar cursor tidak dipakai dengan pengurutan yang berbeda.
+type userCursor struct {
+	SortBy string    `json:"s"`
+	Desc   bool      `json:"d"`
+	Value  *string   `json:"v"` // nil jika nilai kolom NULL (hanya untuk last_login)
+	ID     uuid.UUID `json:"id"`
+
+	value interface{} // nilai kolom yang sudah dikonversi untuk query
+}
+
+// encodeUserCursor membuat cursor dari user terakhir pada halaman
+func encodeUserCursor(user *model.User, sortBy string, desc bool) string {
+	cursor := userCursor{SortBy: sortBy, Desc: desc, ID: user.ID}
+
+	var value string
+	switch sortBy {
+	case model.UserSortEmail:
+		value = user.Email
+	case model.UserSortName:
+		value = user.Name
+	case model.UserSortLastLogin:
+		if user.LastLogin != nil {
+			value = user.LastLogin.UTC().Format(time.RFC3339Nano)
+		}
+	default:
+		value = user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	if sortBy != model.UserSortLastLogin || user.LastLogin != nil {
+		cursor.Value = &value
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserCursor membaca cursor dan memastikan cursor dibuat untuk pengurutan yang sama
+func decodeUserCursor(encoded, sortBy string, desc bool) (*userCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor userCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.SortBy != sortBy || cursor.Desc != desc || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	switch {
+	case cursor.Value == nil:
+		if sortBy != model.UserSortLastLogin {
+			return nil, ErrInvalidCursor
+		}
+	case sortBy == model.UserSortCreatedAt || sortBy == model.UserSortLastLogin:
+		t, err := time.Parse(time.RFC3339Nano, *cursor.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.value = t
+	default:
+		cursor.value = *cursor.Value
+	}
+
+	return &cursor, nil
+}
+
+// applyUserCursor membatasi query ke baris setelah posisi cursor (keyset pagination)
+func applyUserCursor(query *gorm.DB, cursor *userCursor, sortBy string, desc bool) *gorm.DB {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+
+	// MySQL menempatkan NULL di awal pada urutan ASC dan di akhir pada urutan DESC
+	if sortBy == model.UserSortLastLogin {
+		switch {
+		case cursor.Value == nil && desc:
+			return query.Where("(last_login IS NULL AND id < ?)", cursor.ID)
+		case cursor.Value == nil:
+			return query.Where("((last_login IS NULL AND id > ?) OR last_login IS NOT NULL)", cursor.ID)
+		case desc:
+			return query.Where("(last_login < ? OR (last_login = ? AND id < ?) OR last_login IS NULL)", cursor.value, cursor.value, cursor.ID)
+		}
+	}
+
+	return query.Where("("+sortBy+" "+op+" ? OR ("+sortBy+" = ? AND id "+op+" ?))", cursor.value, cursor.value, cursor.ID)
 }
 
 // UpdateUserStatus mengupdate status aktif user
 func (r *MySQLUserRepository) UpdateUserStatus(ctx context.Context, userID uuid.UUID, active bool) error {
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("active", active)
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("active", active)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -279,7 +617,7 @@ func (r *MySQLUserRepository) UpdateUserStatus(ctx context.Context, userID uuid.
 
 // UpdateUserRole mengupdate role user
 func (r *MySQLUserRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, role string) error {
-	result := r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", userID).Update("role", role)
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id = ?", userID).Update("role", role)
 	if result.Error != nil {
 		return ErrDatabaseError
 	}
@@ -291,40 +629,102 @@ func (r *MySQLUserRepository) UpdateUserRole(ctx context.Context, userID uuid.UU
 	return nil
 }
 
+// FindByIDs mencari beberapa user sekaligus berdasarkan ID
+func (r *MySQLUserRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	result := dbWithContext(ctx, r.db).Where("id IN ?", ids).Find(&users)
+	if result.Error != nil {
+		return nil, ErrDatabaseError
+	}
+
+	return users, nil
+}
+
+// UpdateStatusBatch mengupdate status aktif beberapa user sekaligus
+func (r *MySQLUserRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, active bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id IN ?", ids).Update("active", active)
+	if result.Error != nil {
+		return ErrDatabaseError
+	}
+
+	return nil
+}
+
+// UpdateRoleBatch mengupdate role beberapa user sekaligus. Role legacy hanya diubah jika tidak kosong.
+func (r *MySQLUserRepository) UpdateRoleBatch(ctx context.Context, ids []uuid.UUID, role string, roleID *uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	updates := map[string]interface{}{"role_id": roleID}
+	if role != "" {
+		updates["role"] = role
+	}
+
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id IN ?", ids).Updates(updates)
+	if result.Error != nil {
+		return ErrDatabaseError
+	}
+
+	return nil
+}
+
+// SetPasswordResetRequired menandai beberapa user agar wajib mengatur password baru
+func (r *MySQLUserRepository) SetPasswordResetRequired(ctx context.Context, ids []uuid.UUID, required bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result := dbWithContext(ctx, r.db).Model(&model.User{}).Where("id IN ?", ids).Update("password_reset_required", required)
+	if result.Error != nil {
+		return ErrDatabaseError
+	}
+
+	return nil
+}
+
 // GetUserStats mendapatkan statistik user
 func (r *MySQLUserRepository) GetUserStats(ctx context.Context) (*model.UserStats, error) {
 	stats := &model.UserStats{}
 
 	// Total users
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Count(&stats.TotalUsers).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Count(&stats.TotalUsers).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Active users
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("active = ?", true).Count(&stats.ActiveUsers).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("active = ?", true).Count(&stats.ActiveUsers).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Verified users
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("verified = ?", true).Count(&stats.VerifiedUsers).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("verified = ?", true).Count(&stats.VerifiedUsers).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Users registered today
 	today := time.Now().Truncate(24 * time.Hour)
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("created_at >= ?", today).Count(&stats.NewUsersToday).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("created_at >= ?", today).Count(&stats.NewUsersToday).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Users registered this week
 	weekAgo := time.Now().AddDate(0, 0, -7)
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("created_at >= ?", weekAgo).Count(&stats.NewUsersThisWeek).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("created_at >= ?", weekAgo).Count(&stats.NewUsersThisWeek).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
 	// Users registered this month
 	monthAgo := time.Now().AddDate(0, -1, 0)
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("created_at >= ?", monthAgo).Count(&stats.NewUsersThisMonth).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("created_at >= ?", monthAgo).Count(&stats.NewUsersThisMonth).Error; err != nil {
 		return nil, ErrDatabaseError
 	}
 
@@ -409,7 +809,7 @@ func (r *MySQLUserRepository) GetLoginSessions(ctx context.Context, userID uuid.
 
 	// Query untuk mendapatkan sesi login individual
 	var loginHistories []model.LoginHistory
-	err := r.db.WithContext(ctx).
+	err := dbWithContext(ctx, r.db).
 		Where("user_id = ? AND login_time >= ?", userID, startDate).
 		Order("login_time DESC").
 		Limit(100). // Batasi untuk performa
@@ -454,7 +854,7 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 
 	// Get total logins
 	var totalLogins int64
-	err := r.db.WithContext(ctx).
+	err := dbWithContext(ctx, r.db).
 		Model(&model.LoginHistory{}).
 		Where("user_id = ? AND login_time >= ? AND success = true", userID, startDate).
 		Count(&totalLogins).Error
@@ -464,7 +864,7 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 
 	// Get unique IPs
 	var uniqueIPs int64
-	err = r.db.WithContext(ctx).
+	err = dbWithContext(ctx, r.db).
 		Model(&model.LoginHistory{}).
 		Select("COUNT(DISTINCT ip)").
 		Where("user_id = ? AND login_time >= ? AND success = true", userID, startDate).
@@ -475,7 +875,7 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 
 	// Get total days with activity
 	var totalDays int64
-	err = r.db.WithContext(ctx).
+	err = dbWithContext(ctx, r.db).
 		Model(&model.LoginHistory{}).
 		Select("COUNT(DISTINCT DATE(login_time))").
 		Where("user_id = ? AND login_time >= ? AND success = true", userID, startDate).
@@ -487,7 +887,7 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 	// Get last login
 	var lastLogin *time.Time
 	var loginHistory model.LoginHistory
-	err = r.db.WithContext(ctx).
+	err = dbWithContext(ctx, r.db).
 		Where("user_id = ? AND success = true", userID).
 		Order("login_time DESC").
 		First(&loginHistory).Error
@@ -501,7 +901,7 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 		Day   string
 		Count int64
 	}
-	err = r.db.WithContext(ctx).
+	err = dbWithContext(ctx, r.db).
 		Model(&model.LoginHistory{}).
 		Select("DAYNAME(login_time) as day, COUNT(*) as count").
 		Where("user_id = ? AND login_time >= ? AND success = true", userID, startDate).
@@ -528,10 +928,161 @@ func (r *MySQLUserRepository) GetActivityStatistics(ctx context.Context, userID
 	}, nil
 }
 
+// GetDeletedUsers mendapatkan user yang sudah dihapus (soft delete) dengan pagination dan pencarian
+func (r *MySQLUserRepository) GetDeletedUsers(ctx context.Context, offset, limit int, search string) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL")
+
+	// Jika ada parameter pencarian
+	if search != "" {
+		query = query.Where("(email LIKE ? OR name LIKE ?)", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Hitung total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, ErrDatabaseError
+	}
+
+	result := query.Order("deleted_at DESC").Offset(offset).Limit(limit).Find(&users)
+	if result.Error != nil {
+		return nil, 0, ErrDatabaseError
+	}
+
+	return users, total, nil
+}
+
+// FindDeletedByID mencari user yang sudah dihapus (soft delete) berdasarkan ID
+func (r *MySQLUserRepository) FindDeletedByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	var user model.User
+	result := dbWithContext(ctx, r.db).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+
+	return &user, nil
+}
+
+// Restore mengembalikan user yang sudah dihapus (soft delete)
+func (r *MySQLUserRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return ErrDatabaseError
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Purge menghapus permanen user yang sudah dihapus (soft delete) beserta riwayat login, API key, notifikasi,
+// percakapan chat, serta metadata file, folder, dan kuota storage-nya
+func (r *MySQLUserRepository) Purge(ctx context.Context, id uuid.UUID) ([]string, error) {
+	var objectKeys []string
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Unscoped().Select("avatar_key").Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		// Isi file dan avatar dihapus dari file storage oleh pemanggil setelah transaksi berhasil
+		if err := tx.Model(&model.File{}).Where("owner_id = ? AND storage_key <> ''", id).Pluck("storage_key", &objectKeys).Error; err != nil {
+			return err
+		}
+		if user.AvatarKey != "" {
+			objectKeys = append(objectKeys, user.AvatarKey)
+		}
+
+		if err := tx.Where("owner_id = ?", id).Delete(&model.File{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("owner_id = ?", id).Delete(&model.Folder{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.StorageQuota{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.LoginHistory{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", id).Delete(&model.APIKey{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.UserAttributeValue{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", id).Delete(&model.Notification{}).Error; err != nil {
+			return err
+		}
+
+		// Percakapan chat ikut dihapus bersama pesan milik peserta lain di dalamnya
+		if err := tx.Where("sender_id = ? OR recipient_id = ?", id, id).Delete(&model.ChatMessage{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_a_id = ? OR user_b_id = ?", id, id).Delete(&model.ChatConversation{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrDatabaseError
+	}
+
+	return objectKeys, nil
+}
+
+// FindPurgeableUserIDs mendapatkan ID user yang dihapus sebelum waktu tertentu
+func (r *MySQLUserRepository) FindPurgeableUserIDs(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	result := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, ErrDatabaseError
+	}
+
+	return ids, nil
+}
+
 // CountUsersByRoleID menghitung jumlah user berdasarkan role ID
 func (r *MySQLUserRepository) CountUsersByRoleID(ctx context.Context, roleID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("role_id = ?", roleID).Count(&count).Error
 	if err != nil {
 		return 0, ErrDatabaseError
 	}
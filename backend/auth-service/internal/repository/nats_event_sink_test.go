@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// fakeNATSServer menerima satu koneksi dan menjawab setiap HPUB dengan balasan dari reply
+type fakeNATSServer struct {
+	listener net.Listener
+	reply    func(subject, headers string) string
+	received chan string
+}
+
+func newFakeNATSServer(t *testing.T, reply func(subject, headers string) string) *fakeNATSServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := &fakeNATSServer{listener: listener, reply: reply, received: make(chan string, 10)}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (f *fakeNATSServer) url() string {
+	return "nats://" + f.listener.Addr().String()
+}
+
+func (f *fakeNATSServer) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"headers\":true,\"max_payload\":1048576}\r\n")
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(strings.TrimRight(line, "\r\n"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "HPUB":
+			// HPUB <subject> <reply-to> <#header bytes> <#total bytes>
+			headerSize, _ := strconv.Atoi(fields[3])
+			total, _ := strconv.Atoi(fields[4])
+			data := make([]byte, total+2)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return
+			}
+			headers := string(data[:headerSize])
+			f.received <- headers
+			fmt.Fprint(conn, f.reply(fields[2], headers))
+		}
+	}
+}
+
+func newTestEvent() *model.DomainEvent {
+	return model.NewDomainEvent(model.EventUserRegistered, model.EventAggregateUser, uuid.New().String(), nil)
+}
+
+func TestNATSEventSinkPublishWaitsForPubAck(t *testing.T) {
+	server := newFakeNATSServer(t, func(reply, _ string) string {
+		// Balasan untuk subject lain di inbox harus diabaikan
+		stale := "MSG " + reply + ".stale 1 2\r\n{}\r\n"
+		ack := `{"stream":"AUTH_EVENTS","seq":7}`
+		return stale + fmt.Sprintf("MSG %s 1 %d\r\n%s\r\n", reply, len(ack), ack)
+	})
+
+	sink, err := NewNATSEventSink(server.url(), "auth.events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSEventSink: %v", err)
+	}
+	event := newTestEvent()
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	headers := <-server.received
+	if !strings.Contains(headers, "Nats-Msg-Id: "+event.IdempotencyKey()+"\r\n") {
+		t.Errorf("Nats-Msg-Id header missing, got %q", headers)
+	}
+}
+
+func TestNATSEventSinkPublishFailsWithoutStream(t *testing.T) {
+	server := newFakeNATSServer(t, func(reply, _ string) string {
+		status := "NATS/1.0 503\r\n\r\n"
+		return fmt.Sprintf("HMSG %s 1 %d %d\r\n%s\r\n", reply, len(status), len(status), status)
+	})
+
+	sink, err := NewNATSEventSink(server.url(), "auth.events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSEventSink: %v", err)
+	}
+	err = sink.Publish(context.Background(), newTestEvent())
+	if err == nil || !strings.Contains(err.Error(), "no jetstream stream") {
+		t.Fatalf("expected no stream error, got %v", err)
+	}
+}
+
+func TestNATSEventSinkPublishFailsOnJetStreamError(t *testing.T) {
+	server := newFakeNATSServer(t, func(reply, _ string) string {
+		ack := `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+		return fmt.Sprintf("MSG %s 1 %d\r\n%s\r\n", reply, len(ack), ack)
+	})
+
+	sink, err := NewNATSEventSink(server.url(), "auth.events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSEventSink: %v", err)
+	}
+	err = sink.Publish(context.Background(), newTestEvent())
+	if err == nil || !strings.Contains(err.Error(), "maximum messages exceeded") {
+		t.Fatalf("expected jetstream error, got %v", err)
+	}
+}
+
+// TestNATSEventSinkJetStream memerlukan nats-server lokal dengan JetStream aktif,
+// misalnya `nats-server -js`, dan NATS_TEST_URL=nats://localhost:4222
+func TestNATSEventSinkJetStream(t *testing.T) {
+	natsURL := os.Getenv("NATS_TEST_URL")
+	if natsURL == "" {
+		t.Skip("NATS_TEST_URL is not set")
+	}
+
+	prefix := "authtest" + strings.ReplaceAll(uuid.New().String()[:8], "-", "")
+	sink, err := NewNATSEventSink(natsURL, prefix, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewNATSEventSink: %v", err)
+	}
+	natsSink := sink.(*natsEventSink)
+	ctx := context.Background()
+
+	// Tanpa stream yang menangkap subject, event tidak boleh dianggap terkirim
+	if err := sink.Publish(ctx, newTestEvent()); err == nil {
+		t.Fatal("expected publish without a stream to fail")
+	}
+
+	stream := strings.ToUpper(prefix)
+	config, _ := json.Marshal(map[string]interface{}{
+		"name":     stream,
+		"subjects": []string{prefix + ".>"},
+		"storage":  "memory",
+	})
+	natsSink.mu.Lock()
+	_, reply, err := natsSink.request(ctx, "$JS.API.STREAM.CREATE."+stream, "", config)
+	natsSink.mu.Unlock()
+	if err != nil {
+		t.Fatalf("failed to create stream: %v", err)
+	}
+	t.Cleanup(func() {
+		natsSink.mu.Lock()
+		defer natsSink.mu.Unlock()
+		natsSink.request(context.Background(), "$JS.API.STREAM.DELETE."+stream, "", nil)
+	})
+	if strings.Contains(string(reply), `"error"`) {
+		t.Fatalf("failed to create stream: %s", reply)
+	}
+
+	event := newTestEvent()
+	if err := sink.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	// Publish ulang event yang sama dibalas PubAck duplicate dan tetap berhasil
+	if err := sink.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish duplicate: %v", err)
+	}
+}
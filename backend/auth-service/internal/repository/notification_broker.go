@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/auth-service/internal/model"
+	"github.com/go-redis/redis/v8"
+)
+
+// NotificationBroker interface untuk fan-out pesan notifikasi ke semua instance service
+type NotificationBroker interface {
+	Publish(ctx context.Context, message *model.NotificationMessage) error
+	// Subscribe menerima pesan dari semua instance sampai ctx dibatalkan, lalu menutup channel
+	Subscribe(ctx context.Context) (<-chan *model.NotificationMessage, error)
+}
+
+// redisNotificationBroker implementasi NotificationBroker berbasis Redis pub/sub
+type redisNotificationBroker struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisNotificationBroker membuat instance baru NotificationBroker pada channel Redis tertentu
+func NewRedisNotificationBroker(client *redis.Client, channel string) NotificationBroker {
+	return &redisNotificationBroker{
+		client:  client,
+		channel: channel,
+	}
+}
+
+// Publish mempublikasikan pesan notifikasi ke channel Redis
+func (b *redisNotificationBroker) Publish(ctx context.Context, message *model.NotificationMessage) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification message: %w", err)
+	}
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish notification message: %w", err)
+	}
+	return nil
+}
+
+// Subscribe berlangganan channel Redis. Koneksi pub/sub yang terputus disambung ulang
+// oleh client Redis, pesan yang dipublikasikan selama terputus tidak diterima.
+func (b *redisNotificationBroker) Subscribe(ctx context.Context) (<-chan *model.NotificationMessage, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to notification channel: %w", err)
+	}
+
+	messages := make(chan *model.NotificationMessage)
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		incoming := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-incoming:
+				if !ok {
+					return
+				}
+				var message model.NotificationMessage
+				if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+					log.Printf("Failed to decode notification message: %v", err)
+					continue
+				}
+				select {
+				case messages <- &message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
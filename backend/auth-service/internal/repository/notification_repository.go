@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository errors
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationRepository interface untuk operasi database notifikasi user
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *model.Notification) error
+	List(ctx context.Context, userID uuid.UUID, filter *model.NotificationFilter, offset, limit int) ([]model.Notification, int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) (bool, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) (int64, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	PurgeOlderThan(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// notificationRepository implementasi NotificationRepository
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository membuat instance baru NotificationRepository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create menyimpan notifikasi baru
+func (r *notificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	if err := r.db.WithContext(ctx).Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// List mendapatkan notifikasi user yang cocok dengan filter, terbaru lebih dulu
+func (r *notificationRepository) List(ctx context.Context, userID uuid.UUID, filter *model.NotificationFilter, offset, limit int) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// CountUnread menghitung notifikasi user yang belum dibaca
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead menandai satu notifikasi milik user sudah dibaca. Mengembalikan false jika
+// notifikasi sudah dibaca sebelumnya.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id uuid.UUID, readAt time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", readAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark notification as read: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Bedakan notifikasi yang sudah dibaca dari notifikasi yang tidak ada
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Notification{}).Where("id = ? AND user_id = ?", id, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to find notification: %w", err)
+	}
+	if count == 0 {
+		return false, ErrNotificationNotFound
+	}
+	return false, nil
+}
+
+// MarkAllRead menandai semua notifikasi user yang belum dibaca sebagai sudah dibaca
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, readAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", readAt)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Delete menghapus notifikasi milik user
+func (r *notificationRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&model.Notification{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete notification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// PurgeOlderThan menghapus maksimal limit notifikasi yang dibuat sebelum waktu tertentu
+func (r *notificationRepository) PurgeOlderThan(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("created_at < ?", before).
+		Limit(limit).
+		Delete(&model.Notification{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
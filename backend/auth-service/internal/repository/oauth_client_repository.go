@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository errors
+var (
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+)
+
+// OAuthClientRepository interface untuk operasi database service account
+type OAuthClientRepository interface {
+	Create(ctx context.Context, client *model.OAuthClient) error
+	FindByID(ctx context.Context, id uuid.UUID) (*model.OAuthClient, error)
+	FindByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error)
+	GetAll(ctx context.Context, offset, limit int, search string) ([]model.OAuthClient, int64, error)
+	Update(ctx context.Context, client *model.OAuthClient) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+}
+
+// oauthClientRepository implementasi OAuthClientRepository
+type oauthClientRepository struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientRepository membuat instance baru OAuthClientRepository
+func NewOAuthClientRepository(db *gorm.DB) OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+// Create menyimpan service account baru
+func (r *oauthClientRepository) Create(ctx context.Context, client *model.OAuthClient) error {
+	if err := r.db.WithContext(ctx).Create(client).Error; err != nil {
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+	return nil
+}
+
+// FindByID mencari service account berdasarkan ID internal
+func (r *oauthClientRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).Preload("Role.Permissions").Where("id = ?", id).First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+	return &client, nil
+}
+
+// FindByClientID mencari service account berdasarkan client_id publik
+func (r *oauthClientRepository) FindByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).Preload("Role.Permissions").Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+	return &client, nil
+}
+
+// GetAll mendapatkan semua service account dengan pagination dan search
+func (r *oauthClientRepository) GetAll(ctx context.Context, offset, limit int, search string) ([]model.OAuthClient, int64, error) {
+	var clients []model.OAuthClient
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.OAuthClient{})
+
+	// Apply search filter
+	if search != "" {
+		searchPattern := "%" + strings.ToLower(search) + "%"
+		query = query.Where("LOWER(name) LIKE ? OR LOWER(client_id) LIKE ?", searchPattern, searchPattern)
+	}
+
+	// Count total records
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count oauth clients: %w", err)
+	}
+
+	// Get paginated results
+	if err := query.Preload("Role").Offset(offset).Limit(limit).Order("created_at DESC").Find(&clients).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get oauth clients: %w", err)
+	}
+
+	return clients, total, nil
+}
+
+// Update memperbarui data service account
+func (r *oauthClientRepository) Update(ctx context.Context, client *model.OAuthClient) error {
+	if err := r.db.WithContext(ctx).Omit("Role").Save(client).Error; err != nil {
+		return fmt.Errorf("failed to update oauth client: %w", err)
+	}
+	return nil
+}
+
+// Delete menghapus service account (soft delete)
+func (r *oauthClientRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&model.OAuthClient{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete oauth client: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrOAuthClientNotFound
+	}
+
+	return nil
+}
+
+// UpdateLastUsed memperbarui waktu terakhir service account digunakan
+func (r *oauthClientRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.OAuthClient{}).Where("id = ?", id).Update("last_used_at", usedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update oauth client: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository interface untuk operasi database outbox domain event
+type OutboxRepository interface {
+	// Create menulis event ke outbox, ikut transaksi pada ctx jika ada
+	Create(ctx context.Context, events []model.OutboxEvent) error
+	ClaimDueEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error)
+	SaveRelayResult(ctx context.Context, event *model.OutboxEvent) error
+	PurgePublished(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// outboxRepository implementasi OutboxRepository
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository membuat instance baru OutboxRepository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create menulis event ke outbox. Dipanggil di dalam TransactionManager.WithinTransaction
+// agar event hanya tersimpan jika perubahan state yang memicunya ikut di-commit.
+func (r *outboxRepository) Create(ctx context.Context, events []model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := dbWithContext(ctx, r.db).Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to write outbox events: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueEvents mengambil event yang sudah jatuh tempo, terlama lebih dulu, dan menunda
+// next_attempt_at selama lease sehingga relay pada instance lain tidak mengirim event yang
+// sama secara bersamaan. Baris yang sedang dikunci instance lain dilewati.
+func (r *outboxRepository) ClaimDueEvents(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.OutboxEvent, error) {
+	var events []model.OutboxEvent
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{model.OutboxEventPending, model.OutboxEventFailed}, now).
+			Order("occurred_at ASC, id ASC").
+			Limit(limit).
+			Find(&events).Error
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		return tx.Model(&model.OutboxEvent{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+// SaveRelayResult menyimpan hasil relay satu event
+func (r *outboxRepository) SaveRelayResult(ctx context.Context, event *model.OutboxEvent) error {
+	err := r.db.WithContext(ctx).Model(&model.OutboxEvent{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"status":          event.Status,
+		"attempts":        event.Attempts,
+		"next_attempt_at": event.NextAttemptAt,
+		"published_sinks": event.PublishedSinks,
+		"last_error":      event.LastError,
+		"published_at":    event.PublishedAt,
+		"updated_at":      time.Now(),
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save outbox relay result: %w", err)
+	}
+	return nil
+}
+
+// PurgePublished menghapus maksimal limit event yang sudah terkirim sebelum waktu tertentu
+func (r *outboxRepository) PurgePublished(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND published_at < ?", model.OutboxEventPublished, before).
+		Limit(limit).
+		Delete(&model.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge published outbox events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -15,11 +15,14 @@ import (
 
 // Errors
 var (
-	ErrRedisError     = errors.New("redis error")
-	ErrTokenNotFound  = errors.New("token not found")
-	ErrTokenRevoked   = errors.New("token has been revoked")
-	ErrTokenExpired   = errors.New("token has expired")
+	ErrRedisError        = errors.New("redis error")
+	ErrTokenNotFound     = errors.New("token not found")
+	ErrTokenRevoked      = errors.New("token has been revoked")
+	ErrTokenExpired      = errors.New("token has expired")
 	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+	ErrUserCodeConflict  = errors.New("user code already in use")
+
+	ErrDeviceAuthorizationHandled = errors.New("device authorization already handled")
 )
 
 // TokenRepository interface untuk operasi token
@@ -37,6 +40,35 @@ type TokenRepository interface {
 	CacheUserData(ctx context.Context, userID uuid.UUID, userData *model.UserResponse, duration time.Duration) error
 	GetCachedUserData(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error)
 	InvalidateUserCache(ctx context.Context, userID uuid.UUID) error
+	RevokeClientTokens(ctx context.Context, clientID uuid.UUID) error
+	GetClientTokenGeneration(ctx context.Context, clientID uuid.UUID) (int64, error)
+	RevokeUserAccessTokens(ctx context.Context, userID uuid.UUID) error
+	GetUserTokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error)
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresIn time.Duration) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	StoreDeviceAuthorization(ctx context.Context, auth *model.DeviceAuthorization, expiresIn time.Duration) error
+	GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (*model.DeviceAuthorization, error)
+	GetDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*model.DeviceAuthorization, error)
+	// SetDeviceAuthorizationStatus mengubah status device authorization dari status from secara atomik
+	SetDeviceAuthorizationStatus(ctx context.Context, deviceCodeHash string, userID uuid.UUID, from, to string) error
+	// RecordDevicePoll mencatat polling device code dan mengembalikan true jika client polling
+	// lebih cepat dari interval-nya; interval kemudian dinaikkan sebesar increment
+	RecordDevicePoll(ctx context.Context, deviceCodeHash string, interval, increment, expiresIn time.Duration) (bool, error)
+	DeleteDeviceAuthorization(ctx context.Context, auth *model.DeviceAuthorization) error
+	StorePasswordSetupToken(ctx context.Context, tokenHash string, userID uuid.UUID, expiresIn time.Duration) error
+	ConsumePasswordSetupToken(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	StoreEmailChange(ctx context.Context, change *model.EmailChange, expiresIn time.Duration) error
+	GetEmailChange(ctx context.Context, userID uuid.UUID) (*model.EmailChange, error)
+	GetEmailChangeByToken(ctx context.Context, tokenHash string) (*model.EmailChange, error)
+	// ConfirmEmailChange menandai konfirmasi milik tokenHash secara atomik. completed bernilai true
+	// hanya untuk pemanggilan yang melengkapi konfirmasi kedua alamat.
+	ConfirmEmailChange(ctx context.Context, userID uuid.UUID, tokenHash string) (change *model.EmailChange, completed bool, err error)
+	DeleteEmailChange(ctx context.Context, change *model.EmailChange) error
+	StoreLoginChallenge(ctx context.Context, challenge *model.LoginChallenge, expiresIn time.Duration) error
+	GetLoginChallenge(ctx context.Context, tokenHash string) (*model.LoginChallenge, error)
+	UpdateLoginChallenge(ctx context.Context, challenge *model.LoginChallenge) error
+	DeleteLoginChallenge(ctx context.Context, tokenHash string) error
+	DeleteStaleOAuthState(ctx context.Context) (int64, error)
 }
 
 // RedisTokenRepository implementasi TokenRepository menggunakan Redis
@@ -330,4 +362,579 @@ func (r *RedisTokenRepository) GetOAuthState(ctx context.Context, state string)
 	}
 
 	return sessionData, nil
-}
\ No newline at end of file
+}
+
+// RevokeClientTokens menaikkan generasi token service account sehingga semua access token
+// dengan generasi sebelumnya tidak lagi valid. Counter disimpan tanpa TTL karena jika
+// direset, token yang diterbitkan setelah pencabutan terakhir dapat lolos dari pencabutan berikutnya.
+func (r *RedisTokenRepository) RevokeClientTokens(ctx context.Context, clientID uuid.UUID) error {
+	key := fmt.Sprintf("client_generation:%s", clientID.String())
+
+	err := r.redisClient.Incr(ctx, key).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// GetClientTokenGeneration mendapatkan generasi token service account saat ini.
+// Client yang tokennya belum pernah dicabut berada pada generasi 0.
+func (r *RedisTokenRepository) GetClientTokenGeneration(ctx context.Context, clientID uuid.UUID) (int64, error) {
+	key := fmt.Sprintf("client_generation:%s", clientID.String())
+
+	generation, err := r.redisClient.Get(ctx, key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return generation, nil
+}
+
+// RevokeUserAccessTokens menaikkan generasi token user sehingga semua access token user yang
+// sudah diterbitkan tidak lagi valid. Seperti generasi service account, counter disimpan tanpa TTL.
+func (r *RedisTokenRepository) RevokeUserAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	key := fmt.Sprintf("user_generation:%s", userID.String())
+
+	err := r.redisClient.Incr(ctx, key).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// GetUserTokenGeneration mendapatkan generasi token user saat ini.
+// User yang tokennya belum pernah dicabut berada pada generasi 0.
+func (r *RedisTokenRepository) GetUserTokenGeneration(ctx context.Context, userID uuid.UUID) (int64, error) {
+	key := fmt.Sprintf("user_generation:%s", userID.String())
+
+	generation, err := r.redisClient.Get(ctx, key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return generation, nil
+}
+
+// RevokeAccessToken memasukkan access token ke daftar token yang dicabut berdasarkan jti.
+// Penanda cukup disimpan sampai token kedaluwarsa.
+func (r *RedisTokenRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresIn time.Duration) error {
+	key := fmt.Sprintf("revoked_access_token:%s", tokenID)
+
+	err := r.redisClient.Set(ctx, key, "revoked", expiresIn).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked memeriksa apakah access token telah dicabut
+func (r *RedisTokenRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	key := fmt.Sprintf("revoked_access_token:%s", tokenID)
+
+	exists, err := r.redisClient.Exists(ctx, key).Result()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return exists > 0, nil
+}
+
+// StoreDeviceAuthorization menyimpan device authorization baru beserta indeks user code-nya.
+// Mengembalikan ErrUserCodeConflict jika user code sudah digunakan oleh otorisasi lain.
+func (r *RedisTokenRepository) StoreDeviceAuthorization(ctx context.Context, auth *model.DeviceAuthorization, expiresIn time.Duration) error {
+	userCodeKey := fmt.Sprintf("device_user_code:%s", auth.UserCode)
+
+	ok, err := r.redisClient.SetNX(ctx, userCodeKey, auth.DeviceCodeHash, expiresIn).Result()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	if !ok {
+		return ErrUserCodeConflict
+	}
+
+	key := fmt.Sprintf("device_code:%s", auth.DeviceCodeHash)
+
+	authJSON, err := json.Marshal(auth)
+	if err != nil {
+		return fmt.Errorf("failed to marshal device authorization: %v", err)
+	}
+
+	err = r.redisClient.Set(ctx, key, authJSON, expiresIn).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// GetDeviceAuthorization mendapatkan device authorization berdasarkan hash device code
+func (r *RedisTokenRepository) GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (*model.DeviceAuthorization, error) {
+	key := fmt.Sprintf("device_code:%s", deviceCodeHash)
+
+	authJSON, err := r.redisClient.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	var auth model.DeviceAuthorization
+	if err := json.Unmarshal([]byte(authJSON), &auth); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device authorization: %v", err)
+	}
+
+	return &auth, nil
+}
+
+// GetDeviceAuthorizationByUserCode mendapatkan device authorization berdasarkan user code
+func (r *RedisTokenRepository) GetDeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*model.DeviceAuthorization, error) {
+	userCodeKey := fmt.Sprintf("device_user_code:%s", userCode)
+
+	deviceCodeHash, err := r.redisClient.Get(ctx, userCodeKey).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return r.GetDeviceAuthorization(ctx, deviceCodeHash)
+}
+
+// setDeviceStatusScript mengubah status device authorization hanya jika status saat ini sesuai,
+// sehingga perubahan yang bersamaan tidak saling menimpa. Mengembalikan 0 jika record tidak ada,
+// -1 jika status sudah berubah, dan 1 jika berhasil.
+var setDeviceStatusScript = redis.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+	return 0
+end
+local auth = cjson.decode(raw)
+if auth.status ~= ARGV[1] then
+	return -1
+end
+auth.status = ARGV[2]
+auth.user_id = ARGV[3]
+redis.call('SET', KEYS[1], cjson.encode(auth), 'KEEPTTL')
+return 1
+`)
+
+// SetDeviceAuthorizationStatus mengubah status device authorization jika statusnya masih from.
+// Mengembalikan ErrTokenNotFound jika otorisasi tidak ada dan ErrDeviceAuthorizationHandled
+// jika status sudah diubah oleh request lain.
+func (r *RedisTokenRepository) SetDeviceAuthorizationStatus(ctx context.Context, deviceCodeHash string, userID uuid.UUID, from, to string) error {
+	key := fmt.Sprintf("device_code:%s", deviceCodeHash)
+
+	result, err := setDeviceStatusScript.Run(ctx, r.redisClient, []string{key}, from, to, userID.String()).Int()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	switch result {
+	case 0:
+		return ErrTokenNotFound
+	case -1:
+		return ErrDeviceAuthorizationHandled
+	}
+
+	return nil
+}
+
+// recordDevicePollScript menyimpan waktu polling terakhir dan interval polling di key terpisah
+// dari record device authorization, sehingga polling tidak menimpa perubahan status.
+// Mengembalikan 1 jika polling lebih cepat dari interval.
+var recordDevicePollScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(redis.call('HGET', KEYS[1], 'interval') or ARGV[2])
+local last = redis.call('HGET', KEYS[1], 'last_polled_at')
+local slow = 0
+if last and now - tonumber(last) < interval then
+	interval = interval + tonumber(ARGV[3])
+	slow = 1
+end
+redis.call('HSET', KEYS[1], 'last_polled_at', now, 'interval', interval)
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return slow
+`)
+
+// RecordDevicePoll mencatat polling device code
+func (r *RedisTokenRepository) RecordDevicePoll(ctx context.Context, deviceCodeHash string, interval, increment, expiresIn time.Duration) (bool, error) {
+	key := fmt.Sprintf("device_poll:%s", deviceCodeHash)
+
+	slow, err := recordDevicePollScript.Run(ctx, r.redisClient, []string{key},
+		time.Now().UnixMilli(), interval.Milliseconds(), increment.Milliseconds(), expiresIn.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return slow == 1, nil
+}
+
+// DeleteDeviceAuthorization menghapus device authorization. Mengembalikan ErrTokenNotFound
+// jika otorisasi sudah dihapus sebelumnya, sehingga device code hanya dapat ditukar sekali.
+func (r *RedisTokenRepository) DeleteDeviceAuthorization(ctx context.Context, auth *model.DeviceAuthorization) error {
+	key := fmt.Sprintf("device_code:%s", auth.DeviceCodeHash)
+	userCodeKey := fmt.Sprintf("device_user_code:%s", auth.UserCode)
+	pollKey := fmt.Sprintf("device_poll:%s", auth.DeviceCodeHash)
+
+	deleted, err := r.redisClient.Del(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	if err := r.redisClient.Del(ctx, userCodeKey, pollKey).Err(); err != nil {
+		log.Printf("Failed to delete device user code: %v", err)
+	}
+
+	if deleted == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// StorePasswordSetupToken menyimpan token sekali pakai untuk mengatur password user baru
+func (r *RedisTokenRepository) StorePasswordSetupToken(ctx context.Context, tokenHash string, userID uuid.UUID, expiresIn time.Duration) error {
+	key := fmt.Sprintf("password_setup:%s", tokenHash)
+
+	err := r.redisClient.Set(ctx, key, userID.String(), expiresIn).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// ConsumePasswordSetupToken mengambil dan menghapus token pengaturan password (one-time use)
+func (r *RedisTokenRepository) ConsumePasswordSetupToken(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	key := fmt.Sprintf("password_setup:%s", tokenHash)
+
+	userIDStr, err := r.redisClient.GetDel(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return uuid.Nil, ErrTokenNotFound
+		}
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, ErrTokenNotFound
+	}
+
+	return userID, nil
+}
+
+// StoreEmailChange menyimpan permintaan perubahan email beserta indeks kedua token konfirmasinya.
+// Permintaan sebelumnya milik user yang sama diganti sehingga token lamanya tidak berlaku lagi.
+func (r *RedisTokenRepository) StoreEmailChange(ctx context.Context, change *model.EmailChange, expiresIn time.Duration) error {
+	if previous, err := r.GetEmailChange(ctx, change.UserID); err == nil {
+		if err := r.DeleteEmailChange(ctx, previous); err != nil {
+			return err
+		}
+	}
+
+	changeJSON, err := json.Marshal(change)
+	if err != nil {
+		return fmt.Errorf("failed to marshal email change: %v", err)
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, fmt.Sprintf("email_change:%s", change.UserID), changeJSON, expiresIn)
+	pipe.Set(ctx, fmt.Sprintf("email_change_token:%s", change.OldTokenHash), change.UserID.String(), expiresIn)
+	pipe.Set(ctx, fmt.Sprintf("email_change_token:%s", change.NewTokenHash), change.UserID.String(), expiresIn)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// GetEmailChange mendapatkan permintaan perubahan email yang masih menunggu konfirmasi milik user
+func (r *RedisTokenRepository) GetEmailChange(ctx context.Context, userID uuid.UUID) (*model.EmailChange, error) {
+	key := fmt.Sprintf("email_change:%s", userID)
+
+	changeJSON, err := r.redisClient.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	var change model.EmailChange
+	if err := json.Unmarshal([]byte(changeJSON), &change); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal email change: %v", err)
+	}
+
+	return &change, nil
+}
+
+// GetEmailChangeByToken mendapatkan permintaan perubahan email berdasarkan hash salah satu token konfirmasinya
+func (r *RedisTokenRepository) GetEmailChangeByToken(ctx context.Context, tokenHash string) (*model.EmailChange, error) {
+	key := fmt.Sprintf("email_change_token:%s", tokenHash)
+
+	userIDStr, err := r.redisClient.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, ErrTokenNotFound
+	}
+
+	return r.GetEmailChange(ctx, userID)
+}
+
+// confirmEmailChangeScript mengubah satu flag konfirmasi dan memeriksa kedua flag dalam satu
+// operasi, sehingga klik bersamaan pada kedua link tidak saling menimpa. Mengembalikan record
+// setelah diperbarui dan 1 jika pemanggilan ini melengkapi kedua konfirmasi.
+var confirmEmailChangeScript = redis.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+	return false
+end
+local change = cjson.decode(raw)
+local before = change.old_confirmed and change.new_confirmed
+if change.old_token_hash == ARGV[1] then
+	change.old_confirmed = true
+elseif change.new_token_hash == ARGV[1] then
+	change.new_confirmed = true
+else
+	return false
+end
+local encoded = cjson.encode(change)
+redis.call('SET', KEYS[1], encoded, 'KEEPTTL')
+local completed = 0
+if not before and change.old_confirmed and change.new_confirmed then
+	completed = 1
+end
+return {encoded, completed}
+`)
+
+// ConfirmEmailChange menandai konfirmasi tanpa mengubah masa berlakunya. Mengembalikan
+// ErrTokenNotFound jika permintaan tidak ada atau token bukan milik permintaan tersebut.
+func (r *RedisTokenRepository) ConfirmEmailChange(ctx context.Context, userID uuid.UUID, tokenHash string) (*model.EmailChange, bool, error) {
+	key := fmt.Sprintf("email_change:%s", userID)
+
+	result, err := confirmEmailChangeScript.Run(ctx, r.redisClient, []string{key}, tokenHash).Slice()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, false, ErrTokenNotFound
+		}
+		return nil, false, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	if len(result) != 2 {
+		return nil, false, fmt.Errorf("%w: unexpected email change script result", ErrRedisError)
+	}
+
+	changeJSON, _ := result[0].(string)
+	var change model.EmailChange
+	if err := json.Unmarshal([]byte(changeJSON), &change); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal email change: %v", err)
+	}
+	completed, _ := result[1].(int64)
+
+	return &change, completed == 1, nil
+}
+
+// DeleteEmailChange menghapus permintaan perubahan email beserta kedua token konfirmasinya
+func (r *RedisTokenRepository) DeleteEmailChange(ctx context.Context, change *model.EmailChange) error {
+	err := r.redisClient.Del(ctx,
+		fmt.Sprintf("email_change:%s", change.UserID),
+		fmt.Sprintf("email_change_token:%s", change.OldTokenHash),
+		fmt.Sprintf("email_change_token:%s", change.NewTokenHash),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// StoreLoginChallenge menyimpan login yang menunggu verifikasi step-up
+func (r *RedisTokenRepository) StoreLoginChallenge(ctx context.Context, challenge *model.LoginChallenge, expiresIn time.Duration) error {
+	key := fmt.Sprintf("login_challenge:%s", challenge.TokenHash)
+
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal login challenge: %v", err)
+	}
+
+	err = r.redisClient.Set(ctx, key, challengeJSON, expiresIn).Err()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return nil
+}
+
+// GetLoginChallenge mendapatkan login yang menunggu verifikasi step-up berdasarkan hash token challenge
+func (r *RedisTokenRepository) GetLoginChallenge(ctx context.Context, tokenHash string) (*model.LoginChallenge, error) {
+	key := fmt.Sprintf("login_challenge:%s", tokenHash)
+
+	challengeJSON, err := r.redisClient.Get(ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	var challenge model.LoginChallenge
+	if err := json.Unmarshal([]byte(challengeJSON), &challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal login challenge: %v", err)
+	}
+
+	return &challenge, nil
+}
+
+// UpdateLoginChallenge memperbarui jumlah percobaan tanpa mengubah masa berlakunya
+func (r *RedisTokenRepository) UpdateLoginChallenge(ctx context.Context, challenge *model.LoginChallenge) error {
+	key := fmt.Sprintf("login_challenge:%s", challenge.TokenHash)
+
+	challengeJSON, err := json.Marshal(challenge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal login challenge: %v", err)
+	}
+
+	updated, err := r.redisClient.SetXX(ctx, key, challengeJSON, redis.KeepTTL).Result()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	if !updated {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// DeleteLoginChallenge menghapus login yang menunggu verifikasi step-up. ErrTokenNotFound
+// dikembalikan jika challenge sudah tidak ada, sehingga hanya satu request yang dapat
+// menyelesaikan challenge yang sama.
+func (r *RedisTokenRepository) DeleteLoginChallenge(ctx context.Context, tokenHash string) error {
+	key := fmt.Sprintf("login_challenge:%s", tokenHash)
+
+	deleted, err := r.redisClient.Del(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+	if deleted == 0 {
+		return ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// oauthStateScanCount adalah jumlah key yang diminta per iterasi SCAN saat membersihkan state OAuth
+const oauthStateScanCount = 500
+
+// DeleteStaleOAuthState menghapus state OAuth yang tidak memiliki masa berlaku, misalnya
+// hasil restore snapshot atau versi lama, serta indeks user code device authorization yang
+// device code-nya sudah tidak ada. Key yang masih memiliki TTL dibiarkan kedaluwarsa sendiri.
+func (r *RedisTokenRepository) DeleteStaleOAuthState(ctx context.Context) (int64, error) {
+	var deleted int64
+
+	err := r.scanKeys(ctx, "oauth_state:*", func(keys []string) error {
+		pipe := r.redisClient.Pipeline()
+		ttls := make([]*redis.DurationCmd, len(keys))
+		for i, key := range keys {
+			ttls[i] = pipe.TTL(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+
+		var stale []string
+		for i, ttl := range ttls {
+			// TTL -1 berarti key ada tanpa masa berlaku
+			if ttl.Val() == -1 {
+				stale = append(stale, keys[i])
+			}
+		}
+		return r.deleteKeys(ctx, stale, &deleted)
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	err = r.scanKeys(ctx, "device_user_code:*", func(keys []string) error {
+		pipe := r.redisClient.Pipeline()
+		hashes := make([]*redis.StringCmd, len(keys))
+		for i, key := range keys {
+			hashes[i] = pipe.Get(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return err
+		}
+
+		pipe = r.redisClient.Pipeline()
+		exists := make([]*redis.IntCmd, len(keys))
+		for i, hash := range hashes {
+			exists[i] = pipe.Exists(ctx, fmt.Sprintf("device_code:%s", hash.Val()))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+
+		var orphaned []string
+		for i, exist := range exists {
+			if hashes[i].Err() == nil && exist.Val() == 0 {
+				orphaned = append(orphaned, keys[i])
+			}
+		}
+		return r.deleteKeys(ctx, orphaned, &deleted)
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("%w: %v", ErrRedisError, err)
+	}
+
+	return deleted, nil
+}
+
+// scanKeys memanggil fn untuk setiap batch key yang cocok dengan pattern tanpa memblokir Redis
+func (r *RedisTokenRepository) scanKeys(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.redisClient.Scan(ctx, cursor, pattern, oauthStateScanCount).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// deleteKeys menghapus key dan menambahkan jumlah yang terhapus ke deleted
+func (r *RedisTokenRepository) deleteKeys(ctx context.Context, keys []string, deleted *int64) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	n, err := r.redisClient.Del(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+	*deleted += n
+	return nil
+}
@@ -75,7 +75,7 @@ func (r *roleRepository) GetAllRoles(ctx context.Context, offset, limit int, sea
 	var roles []model.Role
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&model.Role{}).Preload("Permissions")
+	query := dbWithContext(ctx, r.db).Model(&model.Role{}).Preload("Permissions")
 
 	// Apply search filter
 	if search != "" {
@@ -100,7 +100,7 @@ func (r *roleRepository) GetAllRoles(ctx context.Context, offset, limit int, sea
 // GetRoleByID mendapatkan role berdasarkan ID
 func (r *roleRepository) GetRoleByID(ctx context.Context, roleID uuid.UUID) (*model.Role, error) {
 	var role model.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", roleID).First(&role).Error
+	err := dbWithContext(ctx, r.db).Preload("Permissions").Where("id = ?", roleID).First(&role).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRoleNotFound
@@ -113,7 +113,7 @@ func (r *roleRepository) GetRoleByID(ctx context.Context, roleID uuid.UUID) (*mo
 // GetRoleByName mendapatkan role berdasarkan nama
 func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (*model.Role, error) {
 	var role model.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	err := dbWithContext(ctx, r.db).Preload("Permissions").Where("name = ?", name).First(&role).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRoleNotFound
@@ -125,7 +125,7 @@ func (r *roleRepository) GetRoleByName(ctx context.Context, name string) (*model
 
 // CreateRole membuat role baru
 func (r *roleRepository) CreateRole(ctx context.Context, role *model.Role) (*model.Role, error) {
-	err := r.db.WithContext(ctx).Create(role).Error
+	err := dbWithContext(ctx, r.db).Create(role).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to create role: %w", err)
 	}
@@ -134,7 +134,7 @@ func (r *roleRepository) CreateRole(ctx context.Context, role *model.Role) (*mod
 
 // UpdateRole mengupdate role
 func (r *roleRepository) UpdateRole(ctx context.Context, role *model.Role) (*model.Role, error) {
-	err := r.db.WithContext(ctx).Save(role).Error
+	err := dbWithContext(ctx, r.db).Save(role).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to update role: %w", err)
 	}
@@ -143,27 +143,20 @@ func (r *roleRepository) UpdateRole(ctx context.Context, role *model.Role) (*mod
 
 // DeleteRole menghapus role
 func (r *roleRepository) DeleteRole(ctx context.Context, roleID uuid.UUID) error {
-	// Start transaction
-	tx := r.db.WithContext(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Transaksi bergabung ke transaksi pada ctx jika ada (savepoint)
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Remove all role-permission associations
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID).Error; err != nil {
+			return fmt.Errorf("failed to remove role permissions: %w", err)
 		}
-	}()
 
-	// Remove all role-permission associations
-	if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", roleID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to remove role permissions: %w", err)
-	}
-
-	// Delete the role
-	if err := tx.Delete(&model.Role{}, "id = ?", roleID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete role: %w", err)
-	}
+		// Delete the role
+		if err := tx.Delete(&model.Role{}, "id = ?", roleID).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
 
-	return tx.Commit().Error
+		return nil
+	})
 }
 
 // AssignPermissionsToRole menambahkan permissions ke role
@@ -174,7 +167,7 @@ func (r *roleRepository) AssignPermissionsToRole(ctx context.Context, roleID uui
 
 	// Get role to ensure it exists
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ?", roleID).First(&role).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", roleID).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRoleNotFound
 		}
@@ -183,7 +176,7 @@ func (r *roleRepository) AssignPermissionsToRole(ctx context.Context, roleID uui
 
 	// Get permissions to ensure they exist
 	var permissions []model.Permission
-	if err := r.db.WithContext(ctx).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
 		return fmt.Errorf("failed to get permissions: %w", err)
 	}
 
@@ -192,7 +185,7 @@ func (r *roleRepository) AssignPermissionsToRole(ctx context.Context, roleID uui
 	}
 
 	// Associate permissions with role
-	if err := r.db.WithContext(ctx).Model(&role).Association("Permissions").Append(&permissions); err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&role).Association("Permissions").Append(&permissions); err != nil {
 		return fmt.Errorf("failed to assign permissions: %w", err)
 	}
 
@@ -207,7 +200,7 @@ func (r *roleRepository) RemovePermissionsFromRole(ctx context.Context, roleID u
 
 	// Get role to ensure it exists
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ?", roleID).First(&role).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", roleID).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRoleNotFound
 		}
@@ -216,12 +209,12 @@ func (r *roleRepository) RemovePermissionsFromRole(ctx context.Context, roleID u
 
 	// Get permissions to remove
 	var permissions []model.Permission
-	if err := r.db.WithContext(ctx).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
 		return fmt.Errorf("failed to get permissions: %w", err)
 	}
 
 	// Remove permissions from role
-	if err := r.db.WithContext(ctx).Model(&role).Association("Permissions").Delete(&permissions); err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&role).Association("Permissions").Delete(&permissions); err != nil {
 		return fmt.Errorf("failed to remove permissions: %w", err)
 	}
 
@@ -232,7 +225,7 @@ func (r *roleRepository) RemovePermissionsFromRole(ctx context.Context, roleID u
 func (r *roleRepository) ReplaceRolePermissions(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
 	// Get role to ensure it exists
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ?", roleID).First(&role).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("id = ?", roleID).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrRoleNotFound
 		}
@@ -242,7 +235,7 @@ func (r *roleRepository) ReplaceRolePermissions(ctx context.Context, roleID uuid
 	// Get new permissions
 	var permissions []model.Permission
 	if len(permissionIDs) > 0 {
-		if err := r.db.WithContext(ctx).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
+		if err := dbWithContext(ctx, r.db).Where("id IN ?", permissionIDs).Find(&permissions).Error; err != nil {
 			return fmt.Errorf("failed to get permissions: %w", err)
 		}
 
@@ -252,7 +245,7 @@ func (r *roleRepository) ReplaceRolePermissions(ctx context.Context, roleID uuid
 	}
 
 	// Replace all permissions
-	if err := r.db.WithContext(ctx).Model(&role).Association("Permissions").Replace(&permissions); err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&role).Association("Permissions").Replace(&permissions); err != nil {
 		return fmt.Errorf("failed to replace permissions: %w", err)
 	}
 
@@ -262,7 +255,7 @@ func (r *roleRepository) ReplaceRolePermissions(ctx context.Context, roleID uuid
 // GetRolePermissions mendapatkan permissions dari role
 func (r *roleRepository) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]model.Permission, error) {
 	var role model.Role
-	err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", roleID).First(&role).Error
+	err := dbWithContext(ctx, r.db).Preload("Permissions").Where("id = ?", roleID).First(&role).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrRoleNotFound
@@ -279,7 +272,7 @@ func (p *permissionRepository) GetAllPermissions(ctx context.Context, offset, li
 	var permissions []model.Permission
 	var total int64
 
-	query := p.db.WithContext(ctx).Model(&model.Permission{})
+	query := dbWithContext(ctx, p.db).Model(&model.Permission{})
 
 	// Apply search filter
 	if search != "" {
@@ -309,7 +302,7 @@ func (p *permissionRepository) GetAllPermissions(ctx context.Context, offset, li
 // GetPermissionByID mendapatkan permission berdasarkan ID
 func (p *permissionRepository) GetPermissionByID(ctx context.Context, permissionID uuid.UUID) (*model.Permission, error) {
 	var permission model.Permission
-	err := p.db.WithContext(ctx).Where("id = ?", permissionID).First(&permission).Error
+	err := dbWithContext(ctx, p.db).Where("id = ?", permissionID).First(&permission).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrPermissionNotFound
@@ -322,7 +315,7 @@ func (p *permissionRepository) GetPermissionByID(ctx context.Context, permission
 // GetPermissionByName mendapatkan permission berdasarkan nama
 func (p *permissionRepository) GetPermissionByName(ctx context.Context, name string) (*model.Permission, error) {
 	var permission model.Permission
-	err := p.db.WithContext(ctx).Where("name = ?", name).First(&permission).Error
+	err := dbWithContext(ctx, p.db).Where("name = ?", name).First(&permission).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrPermissionNotFound
@@ -334,7 +327,7 @@ func (p *permissionRepository) GetPermissionByName(ctx context.Context, name str
 
 // CreatePermission membuat permission baru
 func (p *permissionRepository) CreatePermission(ctx context.Context, permission *model.Permission) (*model.Permission, error) {
-	err := p.db.WithContext(ctx).Create(permission).Error
+	err := dbWithContext(ctx, p.db).Create(permission).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to create permission: %w", err)
 	}
@@ -343,7 +336,7 @@ func (p *permissionRepository) CreatePermission(ctx context.Context, permission
 
 // UpdatePermission mengupdate permission
 func (p *permissionRepository) UpdatePermission(ctx context.Context, permission *model.Permission) (*model.Permission, error) {
-	err := p.db.WithContext(ctx).Save(permission).Error
+	err := dbWithContext(ctx, p.db).Save(permission).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to update permission: %w", err)
 	}
@@ -352,33 +345,26 @@ func (p *permissionRepository) UpdatePermission(ctx context.Context, permission
 
 // DeletePermission menghapus permission
 func (p *permissionRepository) DeletePermission(ctx context.Context, permissionID uuid.UUID) error {
-	// Start transaction
-	tx := p.db.WithContext(ctx).Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Transaksi bergabung ke transaksi pada ctx jika ada (savepoint)
+	return dbWithContext(ctx, p.db).Transaction(func(tx *gorm.DB) error {
+		// Remove all role-permission associations
+		if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", permissionID).Error; err != nil {
+			return fmt.Errorf("failed to remove permission associations: %w", err)
 		}
-	}()
 
-	// Remove all role-permission associations
-	if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", permissionID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to remove permission associations: %w", err)
-	}
-
-	// Delete the permission
-	if err := tx.Delete(&model.Permission{}, "id = ?", permissionID).Error; err != nil {
-		tx.Rollback()
-		return fmt.Errorf("failed to delete permission: %w", err)
-	}
+		// Delete the permission
+		if err := tx.Delete(&model.Permission{}, "id = ?", permissionID).Error; err != nil {
+			return fmt.Errorf("failed to delete permission: %w", err)
+		}
 
-	return tx.Commit().Error
+		return nil
+	})
 }
 
 // GetPermissionsByIDs mendapatkan permissions berdasarkan IDs
 func (p *permissionRepository) GetPermissionsByIDs(ctx context.Context, permissionIDs []uuid.UUID) ([]model.Permission, error) {
 	var permissions []model.Permission
-	err := p.db.WithContext(ctx).Where("id IN ?", permissionIDs).Find(&permissions).Error
+	err := dbWithContext(ctx, p.db).Where("id IN ?", permissionIDs).Find(&permissions).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to get permissions: %w", err)
 	}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3UnsignedPayload  = "UNSIGNED-PAYLOAD"
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+)
+
+// s3FileStorage implementasi FileStorage untuk storage S3-compatible (AWS S3, MinIO, dll).
+// Request ditandatangani dengan AWS Signature Version 4 tanpa SDK.
+type s3FileStorage struct {
+	endpoint     *url.URL
+	region       string
+	bucket       string
+	accessKey    string
+	secretKey    string
+	usePathStyle bool
+	client       *http.Client
+}
+
+// NewS3FileStorage membuat instance baru FileStorage berbasis S3-compatible storage.
+// usePathStyle menaruh bucket di path URL (http://host/bucket/key) seperti yang dipakai MinIO.
+func NewS3FileStorage(endpoint, region, bucket, accessKey, secretKey string, usePathStyle bool) (FileStorage, error) {
+	endpointURL, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %s", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+
+	return &s3FileStorage{
+		endpoint:     endpointURL,
+		region:       region,
+		bucket:       bucket,
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		usePathStyle: usePathStyle,
+		client:       &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Put mengunggah object ke bucket
+func (s *s3FileStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, body, size, contentType)
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload object: %s", s3ErrorMessage(resp))
+	}
+
+	return nil
+}
+
+// Open mengunduh object dari bucket
+func (s *s3FileStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to open object: %s", s3ErrorMessage(resp))
+	}
+
+	return resp.Body, nil
+}
+
+// Delete menghapus object dari bucket. Object yang sudah tidak ada tidak dianggap error.
+func (s *s3FileStorage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("failed to delete object: %s", s3ErrorMessage(resp))
+	}
+}
+
+// SignedURL membuat presigned URL GET yang dapat diakses langsung dari bucket
+func (s *s3FileStorage) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := ValidateObjectKey(key); err != nil {
+		return "", err
+	}
+	return s.presignGet(key, expiry, time.Now()), nil
+}
+
+// objectURL mengembalikan URL object dengan path yang sudah di-encode sesuai aturan SigV4
+func (s *s3FileStorage) objectURL(key string) *url.URL {
+	objectURL := *s.endpoint
+	basePath := strings.TrimRight(s.endpoint.Path, "/")
+	if s.usePathStyle {
+		basePath += "/" + s.bucket
+	} else {
+		objectURL.Host = s.bucket + "." + s.endpoint.Host
+	}
+
+	objectURL.Path = basePath + "/" + key
+	objectURL.RawPath = s3Encode(basePath, false) + "/" + s3Encode(key, false)
+	objectURL.RawQuery = ""
+	return &objectURL
+}
+
+// do mengirim request ke S3 yang ditandatangani melalui header Authorization
+func (s *s3FileStorage) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	if err := ValidateObjectKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.signRequest(req, time.Now())
+
+	return s.client.Do(req)
+}
+
+// signRequest menambahkan header Authorization SigV4 ke request.
+// Payload tidak ikut di-hash (UNSIGNED-PAYLOAD) agar body dapat di-stream.
+func (s *s3FileStorage) signRequest(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": s3UnsignedPayload,
+		"x-amz-date":           amzDate,
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	scope := s.scope(amzDate)
+	signature := s.signature(amzDate, scope, canonicalRequest)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, scope, signedHeaders, signature))
+}
+
+// presignGet membuat presigned URL GET dengan signature di query string
+func (s *s3FileStorage) presignGet(key string, expiry time.Duration, now time.Time) string {
+	if expiry > s3MaxPresignExpiry {
+		expiry = s3MaxPresignExpiry
+	}
+
+	objectURL := s.objectURL(key)
+	amzDate := now.UTC().Format("20060102T150405Z")
+	scope := s.scope(amzDate)
+
+	query := map[string]string{
+		"X-Amz-Algorithm":     s3Algorithm,
+		"X-Amz-Credential":    s.accessKey + "/" + scope,
+		"X-Amz-Date":          amzDate,
+		"X-Amz-Expires":       strconv.Itoa(int(expiry.Seconds())),
+		"X-Amz-SignedHeaders": "host",
+	}
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]string, len(names))
+	for i, name := range names {
+		params[i] = s3Encode(name, true) + "=" + s3Encode(query[name], true)
+	}
+	canonicalQuery := strings.Join(params, "&")
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		canonicalQuery,
+		"host:" + objectURL.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	objectURL.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(amzDate, scope, canonicalRequest)
+	return objectURL.String()
+}
+
+// scope mengembalikan credential scope SigV4: tanggal/region/s3/aws4_request
+func (s *s3FileStorage) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature menghitung signature SigV4 dari canonical request
+func (s *s3FileStorage) signature(amzDate, scope, canonicalRequest string) string {
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(hashedRequest[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// hmacSHA256 menghitung HMAC-SHA256 dari data dengan key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Encode melakukan URI encoding sesuai aturan SigV4: hanya karakter unreserved
+// yang tidak di-encode, dan "/" hanya di-encode jika encodeSlash bernilai true
+func s3Encode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		switch {
+		case (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9'),
+			b == '-', b == '_', b == '.', b == '~':
+			encoded.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+// s3ErrorMessage membaca pesan error singkat dari response S3
+func s3ErrorMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
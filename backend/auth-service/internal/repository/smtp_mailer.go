@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/auth-service/internal/model"
+)
+
+// Mode TLS koneksi SMTP
+const (
+	SMTPTLSNone     = "none"     // tanpa TLS, untuk server lokal seperti MailHog atau smtp4dev
+	SMTPTLSStartTLS = "starttls" // upgrade koneksi dengan STARTTLS, biasanya port 587
+	SMTPTLSImplicit = "tls"      // koneksi TLS sejak awal, biasanya port 465
+)
+
+// SMTPMailerConfig menyimpan pengaturan koneksi ke server SMTP
+type SMTPMailerConfig struct {
+	Host     string
+	Port     string
+	Username string // kosong untuk server tanpa autentikasi
+	Password string
+	TLSMode  string
+	From     string
+	Timeout  time.Duration
+}
+
+// smtpMailer implementasi Mailer yang mengirim email melalui server SMTP.
+// Setiap email dikirim dengan koneksi baru sehingga aman dipakai beberapa worker sekaligus.
+type smtpMailer struct {
+	config SMTPMailerConfig
+	sender string
+}
+
+// NewSMTPMailer membuat instance baru Mailer berbasis SMTP
+func NewSMTPMailer(cfg SMTPMailerConfig) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	switch cfg.TLSMode {
+	case SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+	default:
+		return nil, fmt.Errorf("unsupported smtp tls mode: %s", cfg.TLSMode)
+	}
+
+	return &smtpMailer{config: cfg, sender: from.Address}, nil
+}
+
+// Send mengirim email dan menunggu server menerima pesan
+func (m *smtpMailer) Send(ctx context.Context, message *model.EmailMessage) error {
+	raw, err := buildMIMEMessage(m.config.From, message)
+	if err != nil {
+		return err
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Batasi seluruh percakapan SMTP, bukan hanya pembukaan koneksi
+	deadline := time.Now().Add(m.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set smtp deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start smtp session: %w", err)
+	}
+	defer client.Close()
+
+	if m.config.TLSMode == SMTPTLSStartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate to smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.sender); err != nil {
+		return fmt.Errorf("smtp server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("smtp server rejected recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start smtp data: %w", err)
+	}
+	if _, err := writer.Write(raw); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("smtp server rejected email: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial membuka koneksi ke server SMTP, langsung dengan TLS untuk mode tls
+func (m *smtpMailer) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(m.config.Host, m.config.Port)
+	dialer := &net.Dialer{Timeout: m.config.Timeout}
+
+	var conn net.Conn
+	var err error
+	if m.config.TLSMode == SMTPTLSImplicit {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12},
+		}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to smtp server: %w", err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txContextKey adalah key context untuk transaksi GORM yang sedang berjalan
+type txContextKey struct{}
+
+// TransactionManager menjalankan beberapa operasi repository dalam satu transaksi database
+type TransactionManager interface {
+	// WithinTransaction menjalankan fn dalam satu transaksi. Repository yang dipanggil dengan
+	// ctx yang diberikan ke fn ikut menggunakan transaksi tersebut. Jika ctx sudah membawa
+	// transaksi, fn bergabung ke transaksi yang ada.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// gormTransactionManager implementasi TransactionManager dengan GORM
+type gormTransactionManager struct {
+	db *gorm.DB
+}
+
+// NewTransactionManager membuat instance baru TransactionManager
+func NewTransactionManager(db *gorm.DB) TransactionManager {
+	return &gormTransactionManager{db: db}
+}
+
+// WithinTransaction menjalankan fn dalam satu transaksi, commit jika fn berhasil dan rollback jika tidak
+func (m *gormTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// dbWithContext mengembalikan transaksi yang dibawa ctx, atau db jika tidak ada transaksi
+func dbWithContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository errors
+var (
+	ErrAttributeDefinitionNotFound = errors.New("attribute definition not found")
+	ErrAttributeDefinitionExists   = errors.New("attribute definition already exists")
+)
+
+// UserAttributeRepository interface untuk operasi database definisi dan nilai custom attribute user
+type UserAttributeRepository interface {
+	CreateDefinition(ctx context.Context, definition *model.AttributeDefinition) error
+	FindDefinitionByID(ctx context.Context, id uuid.UUID) (*model.AttributeDefinition, error)
+	FindDefinitionsByNames(ctx context.Context, names []string) ([]model.AttributeDefinition, error)
+	ListDefinitions(ctx context.Context) ([]model.AttributeDefinition, error)
+	UpdateDefinition(ctx context.Context, definition *model.AttributeDefinition) error
+	DeleteDefinition(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	FindValuesByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]model.UserAttributeValue, error)
+	SaveUserValues(ctx context.Context, userID uuid.UUID, values []model.UserAttributeValue, removedDefinitionIDs []uuid.UUID) error
+}
+
+// userAttributeRepository implementasi UserAttributeRepository
+type userAttributeRepository struct {
+	db *gorm.DB
+}
+
+// NewUserAttributeRepository membuat instance baru UserAttributeRepository
+func NewUserAttributeRepository(db *gorm.DB) UserAttributeRepository {
+	return &userAttributeRepository{db: db}
+}
+
+// CreateDefinition menyimpan definisi attribute baru. Nama attribute harus unik.
+func (r *userAttributeRepository) CreateDefinition(ctx context.Context, definition *model.AttributeDefinition) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.AttributeDefinition{}).Where("name = ?", definition.Name).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check attribute definition: %w", err)
+	}
+	if count > 0 {
+		return ErrAttributeDefinitionExists
+	}
+
+	if err := r.db.WithContext(ctx).Create(definition).Error; err != nil {
+		return fmt.Errorf("failed to create attribute definition: %w", err)
+	}
+	return nil
+}
+
+// FindDefinitionByID mencari definisi attribute berdasarkan ID
+func (r *userAttributeRepository) FindDefinitionByID(ctx context.Context, id uuid.UUID) (*model.AttributeDefinition, error) {
+	var definition model.AttributeDefinition
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&definition).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttributeDefinitionNotFound
+		}
+		return nil, fmt.Errorf("failed to get attribute definition: %w", err)
+	}
+	return &definition, nil
+}
+
+// FindDefinitionsByNames mencari definisi attribute berdasarkan daftar nama
+func (r *userAttributeRepository) FindDefinitionsByNames(ctx context.Context, names []string) ([]model.AttributeDefinition, error) {
+	var definitions []model.AttributeDefinition
+	if len(names) == 0 {
+		return definitions, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("name IN ?", names).Find(&definitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get attribute definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// ListDefinitions mendapatkan semua definisi attribute diurutkan berdasarkan nama
+func (r *userAttributeRepository) ListDefinitions(ctx context.Context) ([]model.AttributeDefinition, error) {
+	var definitions []model.AttributeDefinition
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&definitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list attribute definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// UpdateDefinition menyimpan perubahan definisi attribute
+func (r *userAttributeRepository) UpdateDefinition(ctx context.Context, definition *model.AttributeDefinition) error {
+	if err := r.db.WithContext(ctx).Save(definition).Error; err != nil {
+		return fmt.Errorf("failed to update attribute definition: %w", err)
+	}
+	return nil
+}
+
+// DeleteDefinition menghapus definisi attribute beserta semua nilainya.
+// Mengembalikan ID user yang nilainya ikut terhapus.
+func (r *userAttributeRepository) DeleteDefinition(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserAttributeValue{}).Where("definition_id = ?", id).Pluck("user_id", &userIDs).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("definition_id = ?", id).Delete(&model.UserAttributeValue{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ?", id).Delete(&model.AttributeDefinition{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAttributeDefinitionNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, ErrAttributeDefinitionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to delete attribute definition: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// FindValuesByUserIDs mendapatkan nilai attribute beberapa user beserta definisinya
+func (r *userAttributeRepository) FindValuesByUserIDs(ctx context.Context, userIDs []uuid.UUID) ([]model.UserAttributeValue, error) {
+	var values []model.UserAttributeValue
+	if len(userIDs) == 0 {
+		return values, nil
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Definition").Where("user_id IN ?", userIDs).Find(&values).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user attributes: %w", err)
+	}
+	return values, nil
+}
+
+// SaveUserValues menyimpan (insert atau update) nilai attribute user dan menghapus
+// nilai untuk removedDefinitionIDs dalam satu transaksi
+func (r *userAttributeRepository) SaveUserValues(ctx context.Context, userID uuid.UUID, values []model.UserAttributeValue, removedDefinitionIDs []uuid.UUID) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(removedDefinitionIDs) > 0 {
+			if err := tx.Where("user_id = ? AND definition_id IN ?", userID, removedDefinitionIDs).Delete(&model.UserAttributeValue{}).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(values) > 0 {
+			if err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{UpdateAll: true}).Create(&values).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to save user attributes: %w", err)
+	}
+	return nil
+}
+
+// attributeValueColumn mengembalikan kolom user_attribute_values untuk tipe attribute
+func attributeValueColumn(attributeType string) string {
+	switch attributeType {
+	case model.AttributeTypeNumber:
+		return "number_value"
+	case model.AttributeTypeBoolean:
+		return "bool_value"
+	case model.AttributeTypeDate:
+		return "date_value"
+	default:
+		return "string_value"
+	}
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository errors
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+)
+
+// WebhookRepository interface untuk operasi database subscription dan pengiriman webhook
+type WebhookRepository interface {
+	// Subscription
+	CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	FindSubscriptionByID(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error)
+	GetAllSubscriptions(ctx context.Context, offset, limit int, search string) ([]model.WebhookSubscription, int64, error)
+	GetActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+
+	// Delivery
+	CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error
+	FindDeliveryByID(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error)
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error
+	ResetDelivery(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error
+}
+
+// webhookRepository implementasi WebhookRepository
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository membuat instance baru WebhookRepository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateSubscription menyimpan subscription webhook baru
+func (r *webhookRepository) CreateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	if err := r.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// FindSubscriptionByID mencari subscription webhook berdasarkan ID
+func (r *webhookRepository) FindSubscriptionByID(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// GetAllSubscriptions mendapatkan semua subscription webhook dengan pagination dan search
+func (r *webhookRepository) GetAllSubscriptions(ctx context.Context, offset, limit int, search string) ([]model.WebhookSubscription, int64, error) {
+	var subscriptions []model.WebhookSubscription
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WebhookSubscription{})
+	if search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where("name LIKE ? OR url LIKE ?", pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook subscriptions: %w", err)
+	}
+
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&subscriptions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	return subscriptions, total, nil
+}
+
+// GetActiveSubscriptions mendapatkan semua subscription webhook yang aktif
+func (r *webhookRepository) GetActiveSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// UpdateSubscription mengupdate subscription webhook
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, subscription *model.WebhookSubscription) error {
+	if err := r.db.WithContext(ctx).Save(subscription).Error; err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteSubscription menghapus subscription webhook (soft delete), log pengiriman tetap disimpan
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.WebhookSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookSubscriptionNotFound
+	}
+	return nil
+}
+
+// CreateDeliveries menyimpan pengiriman webhook baru. Delivery untuk pasangan event dan
+// subscription yang sudah ada dilewati, sehingga event yang di-relay ulang tidak terkirim dua kali.
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&deliveries).Error
+	if err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// FindDeliveryByID mencari pengiriman webhook beserta log percobaannya
+func (r *webhookRepository) FindDeliveryByID(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Preload("AttemptLogs", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt ASC")
+		}).
+		Where("id = ?", id).
+		First(&delivery).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries mendapatkan pengiriman webhook yang cocok dengan filter, terbaru lebih dulu
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter, offset, limit int) ([]model.WebhookDelivery, int64, error) {
+	var deliveries []model.WebhookDelivery
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&model.WebhookDelivery{})
+	if filter.SubscriptionID != nil {
+		query = query.Where("subscription_id = ?", *filter.SubscriptionID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// ClaimDueDeliveries mengambil pengiriman yang sudah jatuh tempo dan menunda next_attempt_at
+// selama lease, sehingga instance lain tidak mengirim delivery yang sama secara bersamaan.
+// Baris yang sedang dikunci instance lain dilewati.
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND next_attempt_at <= ?", []string{model.WebhookDeliveryPending, model.WebhookDeliveryFailed}, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	// Subscription dimuat terpisah; subscription yang sudah dihapus bernilai nil sehingga
+	// delivery-nya dapat langsung ditutup oleh service
+	subscriptionIDs := make([]uuid.UUID, 0, len(deliveries))
+	for i := range deliveries {
+		subscriptionIDs = append(subscriptionIDs, deliveries[i].SubscriptionID)
+	}
+	var subscriptions []model.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("id IN ?", subscriptionIDs).Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+	byID := make(map[uuid.UUID]*model.WebhookSubscription, len(subscriptions))
+	for i := range subscriptions {
+		byID[subscriptions[i].ID] = &subscriptions[i]
+	}
+	for i := range deliveries {
+		deliveries[i].Subscription = byID[deliveries[i].SubscriptionID]
+	}
+
+	return deliveries, nil
+}
+
+// SaveAttempt menyimpan log satu percobaan dan status terbaru pengiriman dalam satu transaksi
+func (r *webhookRepository) SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+			"updated_at":      time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// ResetDelivery menjadwalkan ulang pengiriman dengan jatah percobaan penuh
+func (r *webhookRepository) ResetDelivery(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          model.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      "",
+		"updated_at":      time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reset webhook delivery: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// API key related errors
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrInvalidAPIKey       = errors.New("invalid or expired api key")
+	ErrAPIKeyLimitReached  = errors.New("maximum number of api keys reached")
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+)
+
+// apiKeyLastUsedInterval membatasi frekuensi penulisan last_used_at ke database
+const apiKeyLastUsedInterval = time.Minute
+
+// APIKeyService interface untuk layanan personal access token
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID uuid.UUID, req *model.CreateAPIKeyRequest) (*model.APIKeyCreatedResponse, error)
+	ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKeyResponse, error)
+	GetAPIKey(ctx context.Context, userID, keyID uuid.UUID) (*model.APIKeyResponse, error)
+	UpdateAPIKey(ctx context.Context, userID, keyID uuid.UUID, req *model.UpdateAPIKeyRequest) (*model.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID, actor *model.AuditActor) error
+	ValidateAPIKey(ctx context.Context, rawKey, clientIP string) (*APIKeyPrincipal, error)
+}
+
+// APIKeyPrincipal adalah hasil autentikasi menggunakan API key
+type APIKeyPrincipal struct {
+	KeyID  uuid.UUID
+	User   *model.UserResponse
+	Scopes []string // scope key yang masih dimiliki user saat ini
+}
+
+// apiKeyService implementasi APIKeyService
+type apiKeyService struct {
+	apiKeyRepo   repository.APIKeyRepository
+	authService  AuthService
+	roleService  RoleService
+	auditService AuditService
+	config       *config.Config
+}
+
+// NewAPIKeyService membuat instance baru APIKeyService
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, authService AuthService, roleService RoleService, auditService AuditService, cfg *config.Config) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:   apiKeyRepo,
+		authService:  authService,
+		roleService:  roleService,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// CreateAPIKey membuat API key baru untuk user
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, userID uuid.UUID, req *model.CreateAPIKeyRequest) (*model.APIKeyCreatedResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	// Batasi jumlah key aktif per user
+	count, err := s.apiKeyRepo.CountActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if s.config.Security.APIKeyMaxPerUser > 0 && count >= int64(s.config.Security.APIKeyMaxPerUser) {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	// Scope harus merupakan subset dari permission user
+	scopes, err := s.validateUserScopes(ctx, userID, req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	rawKey := model.APIKeyPrefix + secret
+
+	key := &model.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    rawKey[:len(model.APIKeyPrefix)+8],
+		KeyHash:   utils.HashSecret(rawKey),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return &model.APIKeyCreatedResponse{
+		APIKey: key.ToAPIKeyResponse(),
+		Key:    rawKey,
+	}, nil
+}
+
+// ListAPIKeys mendapatkan semua API key milik user
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	keyResponses := make([]model.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		keyResponses[i] = key.ToAPIKeyResponse()
+	}
+
+	return keyResponses, nil
+}
+
+// GetAPIKey mendapatkan detail API key milik user
+func (s *apiKeyService) GetAPIKey(ctx context.Context, userID, keyID uuid.UUID) (*model.APIKeyResponse, error) {
+	key, err := s.findUserKey(ctx, userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	keyResponse := key.ToAPIKeyResponse()
+	return &keyResponse, nil
+}
+
+// UpdateAPIKey mengupdate nama atau scope API key
+func (s *apiKeyService) UpdateAPIKey(ctx context.Context, userID, keyID uuid.UUID, req *model.UpdateAPIKeyRequest) (*model.APIKeyResponse, error) {
+	key, err := s.findUserKey(ctx, userID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if req.Name != nil {
+		key.Name = *req.Name
+	}
+	if req.Scopes != nil {
+		scopes, err := s.validateUserScopes(ctx, userID, req.Scopes)
+		if err != nil {
+			return nil, err
+		}
+		key.Scopes = strings.Join(scopes, " ")
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	keyResponse := key.ToAPIKeyResponse()
+	return &keyResponse, nil
+}
+
+// RevokeAPIKey mencabut API key milik user
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID, actor *model.AuditActor) error {
+	key, err := s.findUserKey(ctx, userID, keyID)
+	if err != nil {
+		return err
+	}
+
+	err = s.apiKeyRepo.Revoke(ctx, keyID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			// Key sudah dicabut sebelumnya
+			return nil
+		}
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	s.auditService.Record(ctx, actor, &userID, model.AuditActionAPIKeyRevoked, model.AuditResourceAPIKey, keyID.String(), map[string]interface{}{
+		"name":   key.Name,
+		"prefix": key.Prefix,
+	})
+
+	return nil
+}
+
+// ValidateAPIKey memvalidasi API key dan mengembalikan principal pemiliknya
+func (s *apiKeyService) ValidateAPIKey(ctx context.Context, rawKey, clientIP string) (*APIKeyPrincipal, error) {
+	if !strings.HasPrefix(rawKey, model.APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.FindByHash(ctx, utils.HashSecret(rawKey))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, ErrInternalServerError
+	}
+
+	now := time.Now()
+	if !key.IsUsable(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.authService.GetUserByID(ctx, key.UserID)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if !user.Active {
+		return nil, ErrUserInactive
+	}
+
+	// Scope efektif mengikuti permission user saat ini, sehingga key ikut kehilangan
+	// akses ketika permission user dicabut
+	permissions, err := s.roleService.GetUserPermissions(ctx, key.UserID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyLastUsedInterval {
+		s.apiKeyRepo.UpdateLastUsed(ctx, key.ID, now, clientIP)
+	}
+
+	return &APIKeyPrincipal{
+		KeyID:  key.ID,
+		User:   user,
+		Scopes: intersectScopes(strings.Fields(key.Scopes), permissions),
+	}, nil
+}
+
+// findUserKey mencari API key dan memastikan key tersebut milik user
+func (s *apiKeyService) findUserKey(ctx context.Context, userID, keyID uuid.UUID) (*model.APIKey, error) {
+	key, err := s.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Key milik user lain diperlakukan sebagai tidak ditemukan
+	if key.UserID != userID {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+// validateUserScopes memastikan scope yang diminta dimiliki oleh user
+func (s *apiKeyService) validateUserScopes(ctx context.Context, userID uuid.UUID, requested []string) ([]string, error) {
+	permissions, err := s.roleService.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	scopes, err := validateScopes(requested, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	return scopes, nil
+}
+
+// intersectScopes mengembalikan scope yang masih tercakup oleh daftar scope yang diizinkan
+func intersectScopes(scopes, allowed []string) []string {
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, err := validateScopes([]string{scope}, allowed); err == nil {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// auditExportBatchSize adalah jumlah audit log yang dibaca per batch saat export
+const auditExportBatchSize = 500
+
+// auditVerifyBatchSize adalah jumlah entry hash chain yang dibaca per batch saat verifikasi
+const auditVerifyBatchSize = 1000
+
+// AuditService interface untuk pencatatan dan query audit log keamanan dan admin
+type AuditService interface {
+	Record(ctx context.Context, actor *model.AuditActor, userID *uuid.UUID, action, resource, resourceID string, details map[string]interface{})
+	GetAuditLogs(ctx context.Context, filter *model.AuditFilter) (*model.AuditLogsListResponse, error)
+	ExportAuditLogs(ctx context.Context, filter *model.AuditFilter, write func(entries []model.AuditLog) error) error
+
+	// Hash chain dan checkpoint
+	CreateCheckpoint(ctx context.Context) (*model.AuditCheckpoint, error)
+	GetCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error)
+	VerifyChain(ctx context.Context) (*model.AuditVerifyResult, error)
+}
+
+// auditService implementasi AuditService
+type auditService struct {
+	auditRepo repository.AuditRepository
+	config    *config.Config
+}
+
+// NewAuditService membuat instance baru AuditService
+func NewAuditService(auditRepo repository.AuditRepository, cfg *config.Config) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+		config:    cfg,
+	}
+}
+
+// Record menyimpan satu audit log. Kegagalan menulis audit log hanya dicatat di log
+// aplikasi agar tidak menggagalkan aksi yang sudah berhasil dijalankan.
+func (s *auditService) Record(ctx context.Context, actor *model.AuditActor, userID *uuid.UUID, action, resource, resourceID string, details map[string]interface{}) {
+	entry := model.NewAuditLog(actor, userID, action, resource, resourceID, details)
+	if err := s.auditRepo.Create(ctx, &entry); err != nil {
+		log.Printf("Failed to write audit log %s for %s %s: %v", action, resource, resourceID, err)
+	}
+}
+
+// GetAuditLogs mendapatkan audit log yang cocok dengan filter dengan pagination
+func (s *auditService) GetAuditLogs(ctx context.Context, filter *model.AuditFilter) (*model.AuditLogsListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	entries, total, err := s.auditRepo.List(ctx, filter, offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.AuditLogsListResponse{
+		Entries:    entries,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ExportAuditLogs membaca semua audit log yang cocok dengan filter secara kronologis
+// per batch dan meneruskannya ke fungsi write, sehingga export tidak memuat semua entry sekaligus
+func (s *auditService) ExportAuditLogs(ctx context.Context, filter *model.AuditFilter, write func(entries []model.AuditLog) error) error {
+	var after *model.AuditLog
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entries, err := s.auditRepo.ListAfter(ctx, filter, after, auditExportBatchSize)
+		if err != nil {
+			return ErrInternalServerError
+		}
+
+		if len(entries) > 0 {
+			if err := write(entries); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < auditExportBatchSize {
+			return nil
+		}
+		after = &entries[len(entries)-1]
+	}
+}
+
+// CreateCheckpoint menandatangani ujung hash chain saat ini. Checkpoint tetap dibuat meskipun
+// belum ada entry baru, sehingga checkpoint terakhir yang lebih tua dari interval checkpoint
+// menandakan checkpoint dihapus (lihat VerifyChain). Checkpoint tidak dibuat (nil) jika chain
+// masih kosong.
+func (s *auditService) CreateCheckpoint(ctx context.Context) (*model.AuditCheckpoint, error) {
+	head, err := s.auditRepo.GetChainHead(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if head.Sequence == 0 {
+		return nil, nil
+	}
+
+	createdAt := time.Now().Truncate(time.Second)
+	checkpoint := &model.AuditCheckpoint{
+		Sequence:  head.Sequence,
+		Hash:      head.Hash,
+		Signature: utils.SignAuditCheckpoint(head.Sequence, head.Hash, createdAt.Unix(), s.config.Audit.CheckpointKey),
+		CreatedAt: createdAt,
+	}
+	if err := s.auditRepo.CreateCheckpoint(ctx, checkpoint); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return checkpoint, nil
+}
+
+// GetCheckpoints mendapatkan semua checkpoint hash chain
+func (s *auditService) GetCheckpoints(ctx context.Context) ([]model.AuditCheckpoint, error) {
+	checkpoints, err := s.auditRepo.ListCheckpoints(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	return checkpoints, nil
+}
+
+// VerifyChain menelusuri hash chain dari awal dan melaporkan link pertama yang rusak:
+// sequence yang hilang, prev_hash yang tidak cocok, hash yang tidak sesuai isi entry,
+// checkpoint dengan signature tidak valid atau yang tidak cocok dengan entry, dan
+// entry di ujung chain yang terhapus. Karena checkpoint dibuat setiap interval, checkpoint
+// terakhir yang terlalu tua dilaporkan sebagai celah: chain yang dipotong bersama
+// checkpoint-checkpoint terakhirnya tidak lagi terlihat valid.
+func (s *auditService) VerifyChain(ctx context.Context) (*model.AuditVerifyResult, error) {
+	result := &model.AuditVerifyResult{Valid: true, LastHash: model.AuditGenesisHash}
+
+	head, err := s.auditRepo.GetChainHead(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	checkpoints, err := s.auditRepo.ListCheckpoints(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if result.LegacyEntries, err = s.auditRepo.CountUnchained(ctx); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	// Checkpoint diperiksa saat entry dengan sequence yang sama ditemukan
+	next := 0
+	checkCheckpoints := func(entry *model.AuditLog) bool {
+		for ; next < len(checkpoints) && checkpoints[next].Sequence <= *entry.Sequence; next++ {
+			if !s.verifyCheckpoint(result, &checkpoints[next], entry) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var firstEntryAt time.Time
+	var expected uint64 = 1
+	for result.FirstBroken == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entries, err := s.auditRepo.ListChain(ctx, result.LastSequence, auditVerifyBatchSize)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			if *entry.Sequence != expected {
+				result.FirstBroken = &model.AuditChainBreak{
+					Sequence: expected,
+					Reason:   fmt.Sprintf("entry with sequence %d is missing", expected),
+				}
+				break
+			}
+			if entry.PrevHash != result.LastHash {
+				result.FirstBroken = &model.AuditChainBreak{
+					Sequence: expected,
+					EntryID:  &entry.ID,
+					Reason:   "prev_hash does not match the hash of the previous entry",
+				}
+				break
+			}
+			if entry.ComputeHash() != entry.Hash {
+				result.FirstBroken = &model.AuditChainBreak{
+					Sequence: expected,
+					EntryID:  &entry.ID,
+					Reason:   "hash does not match the entry content",
+				}
+				break
+			}
+			if !checkCheckpoints(entry) {
+				break
+			}
+
+			if expected == 1 {
+				firstEntryAt = entry.CreatedAt
+			}
+			result.EntriesChecked++
+			result.LastSequence = expected
+			result.LastHash = entry.Hash
+			expected++
+		}
+
+		if len(entries) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	// Entry yang tercatat di ujung chain atau checkpoint tetapi tidak ada lagi berarti chain dipotong
+	if result.FirstBroken == nil && head.Sequence > result.LastSequence {
+		result.FirstBroken = &model.AuditChainBreak{
+			Sequence: result.LastSequence + 1,
+			Reason:   fmt.Sprintf("chain ends at sequence %d but the chain head is at sequence %d", result.LastSequence, head.Sequence),
+		}
+	}
+	if result.FirstBroken == nil && next < len(checkpoints) {
+		result.FirstBroken = &model.AuditChainBreak{
+			Sequence: checkpoints[next].Sequence,
+			Reason:   fmt.Sprintf("checkpoint at sequence %d refers to an entry that no longer exists", checkpoints[next].Sequence),
+		}
+	}
+
+	result.VerifiedAt = time.Now()
+	if len(checkpoints) > 0 {
+		latest := checkpoints[len(checkpoints)-1]
+		result.LatestCheckpoint = &model.AuditCheckpointSummary{Sequence: latest.Sequence, CreatedAt: latest.CreatedAt}
+	}
+	s.checkCheckpointGap(result, firstEntryAt)
+
+	result.Valid = result.FirstBroken == nil && result.CheckpointGap == ""
+	return result, nil
+}
+
+// checkCheckpointGap menandai celah jika chain berisi entry tetapi tidak ada checkpoint dalam
+// dua kali AUDIT_CHECKPOINT_INTERVAL terakhir. Toleransi dua interval menampung jeda ticker
+// setelah service dimulai; service yang mati lebih lama juga dilaporkan sebagai celah.
+func (s *auditService) checkCheckpointGap(result *model.AuditVerifyResult, firstEntryAt time.Time) {
+	interval := s.config.Audit.CheckpointInterval
+	if interval <= 0 || result.LastSequence == 0 {
+		return
+	}
+
+	threshold := result.VerifiedAt.Add(-2 * interval)
+	switch {
+	case result.LatestCheckpoint == nil && firstEntryAt.Before(threshold):
+		result.CheckpointGap = fmt.Sprintf("no checkpoint exists although the chain started at %s", firstEntryAt.Format(time.RFC3339))
+	case result.LatestCheckpoint != nil && result.LatestCheckpoint.CreatedAt.Before(threshold):
+		result.CheckpointGap = fmt.Sprintf("latest checkpoint was created at %s, expected one every %s", result.LatestCheckpoint.CreatedAt.Format(time.RFC3339), interval)
+	}
+}
+
+// verifyCheckpoint memeriksa signature checkpoint dan kecocokannya dengan entry pada
+// sequence yang sama, lalu mencatat kerusakan pertama ke result
+func (s *auditService) verifyCheckpoint(result *model.AuditVerifyResult, checkpoint *model.AuditCheckpoint, entry *model.AuditLog) bool {
+	if !utils.VerifyAuditCheckpoint(checkpoint.Sequence, checkpoint.Hash, checkpoint.CreatedAt.Unix(), checkpoint.Signature, s.config.Audit.CheckpointKey) {
+		result.FirstBroken = &model.AuditChainBreak{
+			Sequence: checkpoint.Sequence,
+			Reason:   "checkpoint signature is invalid",
+		}
+		return false
+	}
+	if checkpoint.Sequence != *entry.Sequence || checkpoint.Hash != entry.Hash {
+		result.FirstBroken = &model.AuditChainBreak{
+			Sequence: checkpoint.Sequence,
+			EntryID:  &entry.ID,
+			Reason:   "entry hash does not match the signed checkpoint",
+		}
+		return false
+	}
+
+	result.CheckpointsChecked++
+	return true
+}
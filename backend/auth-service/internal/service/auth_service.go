@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/auth-service/config"
@@ -20,38 +21,99 @@ import (
 
 // Errors
 var (
-	ErrInvalidCredentials  = errors.New("invalid email or password")
-	ErrUserAlreadyExists   = errors.New("user already exists")
-	ErrInvalidToken        = errors.New("invalid token")
-	ErrAccountLocked       = errors.New("account is locked due to too many failed login attempts")
-	ErrUserInactive        = errors.New("user account is inactive")
-	ErrPasswordTooWeak     = errors.New("password is too weak")
-	ErrInvalidRefreshToken = errors.New("invalid refresh token")
-	ErrRateLimitExceeded   = errors.New("rate limit exceeded")
-	ErrInternalServerError = errors.New("internal server error")
-	ErrGoogleAuthFailed    = errors.New("google authentication failed")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrUnauthorized        = errors.New("unauthorized access")
-	ErrInvalidRole         = errors.New("invalid role")
+	ErrInvalidCredentials    = errors.New("invalid email or password")
+	ErrUserAlreadyExists     = errors.New("user already exists")
+	ErrInvalidToken          = errors.New("invalid token")
+	ErrAccountLocked         = errors.New("account is locked due to too many failed login attempts")
+	ErrUserInactive          = errors.New("user account is inactive")
+	ErrPasswordTooWeak       = errors.New("password is too weak")
+	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrRateLimitExceeded     = errors.New("rate limit exceeded")
+	ErrInternalServerError   = errors.New("internal server error")
+	ErrGoogleAuthFailed      = errors.New("google authentication failed")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrUnauthorized          = errors.New("unauthorized access")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrPasswordRequired      = errors.New("password is required when no invite is sent")
+	ErrInvalidInviteToken    = errors.New("invalid or expired invite token")
+	ErrRetentionNotElapsed   = errors.New("user cannot be purged before the retention period has elapsed")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrPasswordResetRequired = errors.New("password reset required")
+	ErrInvalidInvitation     = errors.New("invalid, expired or revoked invitation")
+	ErrInvitationAccepted    = errors.New("invitation has already been accepted")
+	ErrInviteEmailMismatch   = errors.New("google account email does not match the invitation")
+	ErrNameRequired          = errors.New("name is required")
+	ErrStepUpRequired        = errors.New("additional verification is required to complete login")
+	ErrInvalidLoginChallenge = errors.New("invalid or expired login challenge")
+	ErrInvalidVerifyCode     = errors.New("invalid verification code")
+)
+
+// StepUpRequiredError dikembalikan Login dan HandleGoogleCallback saat risiko login cukup tinggi
+// sehingga user harus memasukkan kode verifikasi yang dikirim ke email sebelum token diterbitkan
+type StepUpRequiredError struct {
+	Challenge *model.LoginChallengeResponse
+}
+
+func (e *StepUpRequiredError) Error() string {
+	return ErrStepUpRequired.Error()
+}
+
+// Is membuat errors.Is(err, ErrStepUpRequired) bernilai true
+func (e *StepUpRequiredError) Is(target error) bool {
+	return target == ErrStepUpRequired
+}
+
+// purgeBatchSize membatasi jumlah user yang diproses per batch saat purge massal
+const purgeBatchSize = 100
+
+const (
+	// loginVerificationCodeLength adalah jumlah digit kode verifikasi step-up
+	loginVerificationCodeLength = 6
+	// defaultStepUpExpiry dipakai jika LOGIN_RISK_STEP_UP_EXPIRY tidak valid
+	defaultStepUpExpiry = 10 * time.Minute
+	// defaultStepUpMaxAttempts dipakai jika LOGIN_RISK_STEP_UP_MAX_ATTEMPTS tidak valid
+	defaultStepUpMaxAttempts = 5
 )
 
 // AuthService interface untuk layanan autentikasi
 type AuthService interface {
 	Register(ctx context.Context, req *model.RegisterRequest, clientInfo *ClientInfo) (*model.UserResponse, error)
 	Login(ctx context.Context, req *model.LoginRequest, clientInfo *ClientInfo) (*model.TokenResponse, error)
-	Logout(ctx context.Context, userID uuid.UUID, refreshToken string) error
+	VerifyLoginChallenge(ctx context.Context, req *model.VerifyLoginRequest, clientInfo *ClientInfo) (*model.TokenResponse, error)
+	Logout(ctx context.Context, userID uuid.UUID, refreshToken string, actor *model.AuditActor) error
 	RefreshToken(ctx context.Context, refreshToken string) (*model.TokenResponse, error)
 	GetUserProfile(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error)
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error)
+	GetUserDetail(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error)
 	ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error)
+	IssueTokensForUser(ctx context.Context, userID uuid.UUID, method, scope string, clientInfo *ClientInfo) (*model.TokenResponse, error)
 	GetGoogleAuthURL(redirectURL string) string
 	GetRedirectURLFromState(state string) string
 	HandleGoogleCallback(ctx context.Context, code string, clientInfo *ClientInfo) (*model.TokenResponse, error)
+	// Invitation methods
+	GetInvitation(ctx context.Context, token string) (*model.InvitationResponse, error)
+	AcceptInvitation(ctx context.Context, req *model.AcceptInvitationRequest, clientInfo *ClientInfo) (*model.UserResponse, error)
+	GetGoogleAuthURLForInvitation(ctx context.Context, token, redirectURL string) (string, error)
+	GetInvitationTokenFromState(state string) string
+	HandleGoogleInvitationCallback(ctx context.Context, code, invitationToken string, clientInfo *ClientInfo) (*model.TokenResponse, error)
 	GetLoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginHistory, error)
 	CheckRateLimit(ctx context.Context, key string, path string, limit int, duration int) (bool, error)
 	// User Management methods
-	GetAllUsers(ctx context.Context, page, limit int, search string) (*model.UsersListResponse, error)
-	UpdateUser(ctx context.Context, userID uuid.UUID, req *model.UpdateUserRequest) (*model.UserResponse, error)
+	GetAllUsers(ctx context.Context, filter *model.UserFilter) (*model.UsersListResponse, error)
+	UpdateUser(ctx context.Context, userID uuid.UUID, req *model.UpdateUserRequest, actor *model.AuditActor) (*model.UserResponse, error)
+	CreateUser(ctx context.Context, req *model.CreateUserRequest, actor *model.AuditActor) (*model.CreateUserResponse, error)
+	DeleteUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) error
+	ToggleUserStatus(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) (*model.UserResponse, error)
+	AcceptInvite(ctx context.Context, req *model.AcceptInviteRequest) (*model.UserResponse, error)
+	// SendPasswordSetupLink menerbitkan token pengaturan password dan mengirim link-nya ke email user.
+	// Token hanya dikembalikan jika driver mail adalah "log".
+	SendPasswordSetupLink(ctx context.Context, user *model.User) (string, time.Time, error)
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID)
+	GetDeletedUsers(ctx context.Context, page, limit int, search string) (*model.DeletedUsersListResponse, error)
+	RestoreUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) (*model.UserResponse, error)
+	PurgeUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) error
+	PurgeExpiredUsers(ctx context.Context, actor *model.AuditActor) (int, error)
+	DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time) (int, error)
 	GetUserStats(ctx context.Context) (*model.UserStats, error)
 	GetUserActivity(ctx context.Context, userID uuid.UUID, days int) ([]model.UserActivity, error)
 	GetUserActivityResponse(ctx context.Context, userID uuid.UUID, days int) (*model.UserActivityResponse, error)
@@ -63,18 +125,30 @@ type ClientInfo struct {
 	UserAgent string
 	Country   string
 	City      string
+	Latitude  *float64
+	Longitude *float64
 }
 
 // authService implementasi AuthService
 type authService struct {
 	userRepo       repository.UserRepository
 	tokenRepo      repository.TokenRepository
+	invitationRepo repository.InvitationRepository
+	attributeRepo  repository.UserAttributeRepository
+	fileStorage    repository.FileStorage
+	chatBroker     repository.ChatBroker
+	txManager      repository.TransactionManager
+	auditService   AuditService
+	eventBus       EventBus
+	notifications  NotificationService
+	emails         EmailService
+	riskService    LoginRiskService
 	config         *config.Config
 	googleOAuthCfg *oauth2.Config
 }
 
 // NewAuthService membuat instance baru AuthService
-func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, cfg *config.Config) AuthService {
+func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, invitationRepo repository.InvitationRepository, attributeRepo repository.UserAttributeRepository, fileStorage repository.FileStorage, chatBroker repository.ChatBroker, txManager repository.TransactionManager, auditService AuditService, eventBus EventBus, notifications NotificationService, emails EmailService, riskService LoginRiskService, cfg *config.Config) AuthService {
 	// Konfigurasi Google OAuth
 	googleOAuthCfg := &oauth2.Config{
 		ClientID:     cfg.Google.ClientID,
@@ -90,6 +164,16 @@ func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.Tok
 	return &authService{
 		userRepo:       userRepo,
 		tokenRepo:      tokenRepo,
+		invitationRepo: invitationRepo,
+		attributeRepo:  attributeRepo,
+		fileStorage:    fileStorage,
+		chatBroker:     chatBroker,
+		txManager:      txManager,
+		auditService:   auditService,
+		eventBus:       eventBus,
+		notifications:  notifications,
+		emails:         emails,
+		riskService:    riskService,
 		config:         cfg,
 		googleOAuthCfg: googleOAuthCfg,
 	}
@@ -97,56 +181,86 @@ func NewAuthService(userRepo repository.UserRepository, tokenRepo repository.Tok
 
 // Register mendaftarkan pengguna baru
 func (s *authService) Register(ctx context.Context, req *model.RegisterRequest, clientInfo *ClientInfo) (*model.UserResponse, error) {
+	user := &model.User{
+		Email:    req.Email,
+		Name:     req.Name,
+		Provider: "local",
+		Role:     "user",
+		Verified: false,
+	}
+
+	if err := s.registerUser(ctx, user, req.Password); err != nil {
+		return nil, err
+	}
+
+	// Konversi ke response
+	userResponse := user.ToUserResponse()
+
+	return &userResponse, nil
+}
+
+// registerUser menyimpan akun baru melalui jalur registrasi: validasi password,
+// rate limit per email, hash password, simpan ke database, lalu cache data user.
+// Password kosong hanya diperbolehkan untuk akun dari provider eksternal.
+func (s *authService) registerUser(ctx context.Context, user *model.User, password string) error {
 	// Validasi password
-	if len(req.Password) < s.config.Security.PasswordMinLength {
-		return nil, ErrPasswordTooWeak
+	if user.Provider == "local" && len(password) < s.config.Security.PasswordMinLength {
+		return ErrPasswordTooWeak
 	}
 
 	// Cek rate limit untuk registrasi
-	key := fmt.Sprintf("register:%s", req.Email)
+	key := fmt.Sprintf("register:%s", user.Email)
 	allowed, err := s.tokenRepo.CheckRateLimit(ctx, key, s.config.Security.RateLimitRequests, s.config.Security.RateLimitDuration)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if !allowed {
-		return nil, ErrRateLimitExceeded
+		return ErrRateLimitExceeded
 	}
 
 	// Hash password
-	hashedPassword, err := utils.HashPassword(req.Password)
-	if err != nil {
-		return nil, ErrInternalServerError
+	if password != "" {
+		hashedPassword, err := utils.HashPassword(password)
+		if err != nil {
+			return ErrInternalServerError
+		}
+		user.Password = hashedPassword
 	}
 
-	// Buat user baru
-	user := &model.User{
-		Email:     req.Email,
-		Password:  hashedPassword,
-		Name:      req.Name,
-		Provider:  "local",
-		Role:      "user",
-		Verified:  false,
-		Active:    true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+	user.Active = true
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
 
 	// Simpan user ke database
-	err = s.userRepo.Create(ctx, user)
+	err = s.createUserWithEvent(ctx, user, nil)
 	if err != nil {
 		if errors.Is(err, repository.ErrEmailAlreadyExists) {
-			return nil, ErrUserAlreadyExists
+			return ErrUserAlreadyExists
 		}
-		return nil, ErrInternalServerError
+		return ErrInternalServerError
 	}
 
-	// Konversi ke response
-	userResponse := user.ToUserResponse()
-
 	// Cache data user
+	userResponse := user.ToUserResponse()
 	s.tokenRepo.CacheUserData(ctx, user.ID, &userResponse, 1*time.Hour)
 
-	return &userResponse, nil
+	return nil
+}
+
+// createUserWithEvent menyimpan user baru dan event user.registered dalam satu transaksi,
+// extra ditambahkan ke data event
+func (s *authService) createUserWithEvent(ctx context.Context, user *model.User, extra map[string]interface{}) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+
+		data := userEventData(user)
+		for key, value := range extra {
+			data[key] = value
+		}
+		return s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventUserRegistered, model.EventAggregateUser, user.ID.String(), data))
+	})
 }
 
 // Login melakukan autentikasi pengguna
@@ -165,6 +279,7 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest, client
 	user, err := s.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
+			s.recordLogin(ctx, nil, req.Email, clientInfo, "password", "Unknown email")
 			return nil, ErrInvalidCredentials
 		}
 		return nil, ErrInternalServerError
@@ -172,31 +287,45 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest, client
 
 	// Cek apakah akun aktif
 	if !user.Active {
+		s.recordLogin(ctx, user, req.Email, clientInfo, "password", "Account inactive")
 		return nil, ErrUserInactive
 	}
 
 	// Cek apakah akun terkunci
 	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.recordLogin(ctx, user, req.Email, clientInfo, "password", "Account locked")
 		return nil, ErrAccountLocked
 	}
 
 	// Verifikasi password
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
-		// Tambah jumlah percobaan login yang gagal
-		s.userRepo.IncrementLoginAttempts(ctx, user.ID)
-
-		// Jika melebihi batas percobaan, kunci akun
-		if user.LoginAttempts+1 >= s.config.Security.MaxLoginAttempts {
-			s.userRepo.LockAccount(ctx, user.ID, s.config.Security.LockoutDuration)
-		}
+		s.registerFailedLogin(ctx, user, clientInfo)
 
 		// Catat riwayat login gagal
 		loginHistory := createLoginHistory(user.ID, clientInfo, false, "Invalid password")
 		s.userRepo.SaveLoginHistory(ctx, loginHistory)
+		s.recordLogin(ctx, user, req.Email, clientInfo, "password", "Invalid password")
 
 		return nil, ErrInvalidCredentials
 	}
 
+	// Admin memaksa user mengatur password baru melalui token reset
+	if user.PasswordResetRequired {
+		s.userRepo.ResetLoginAttempts(ctx, user.ID)
+		loginHistory := createLoginHistory(user.ID, clientInfo, false, "Password reset required")
+		s.userRepo.SaveLoginHistory(ctx, loginHistory)
+		s.recordLogin(ctx, user, req.Email, clientInfo, "password", "Password reset required")
+		return nil, ErrPasswordResetRequired
+	}
+
+	// Nilai risiko login sebelum token diterbitkan. Percobaan login tidak di-reset selama
+	// menunggu verifikasi agar kode yang salah ikut dihitung menuju penguncian akun.
+	loginHistory := createLoginHistory(user.ID, clientInfo, true, "")
+	risk := s.assessLogin(ctx, user, loginHistory)
+	if risk.Action == model.LoginRiskStepUp {
+		return nil, s.startStepUp(ctx, user, loginHistory, clientInfo, "password", "", risk)
+	}
+
 	// Reset percobaan login
 	s.userRepo.ResetLoginAttempts(ctx, user.ID)
 
@@ -205,11 +334,110 @@ func (s *authService) Login(ctx context.Context, req *model.LoginRequest, client
 	s.userRepo.UpdateLastLogin(ctx, user.ID, now)
 
 	// Catat riwayat login berhasil
-	loginHistory := createLoginHistory(user.ID, clientInfo, true, "")
+	s.notifyRiskyLogin(ctx, user, loginHistory, risk, "password")
+	s.userRepo.SaveLoginHistory(ctx, loginHistory)
+	s.recordLogin(ctx, user, user.Email, clientInfo, "password", "")
+
+	// Generate token
+	tokenResponse, err := s.generateTokens(ctx, user, "")
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return tokenResponse, nil
+}
+
+// VerifyLoginChallenge menyelesaikan login yang memerlukan verifikasi step-up dengan kode
+// yang dikirim ke email. Kode yang salah dihitung sebagai percobaan login gagal, dan
+// challenge dibatalkan setelah jumlah percobaan maksimum.
+func (s *authService) VerifyLoginChallenge(ctx context.Context, req *model.VerifyLoginRequest, clientInfo *ClientInfo) (*model.TokenResponse, error) {
+	tokenHash := utils.HashSecret(req.ChallengeToken)
+	challenge, err := s.tokenRepo.GetLoginChallenge(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, ErrInternalServerError
+	}
+
+	user, err := s.userRepo.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			s.tokenRepo.DeleteLoginChallenge(ctx, tokenHash)
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Cek apakah akun aktif
+	if !user.Active {
+		s.tokenRepo.DeleteLoginChallenge(ctx, tokenHash)
+		return nil, ErrUserInactive
+	}
+
+	// Cek apakah akun terkunci
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.tokenRepo.DeleteLoginChallenge(ctx, tokenHash)
+		return nil, ErrAccountLocked
+	}
+
+	// Verifikasi kode
+	if !utils.CheckPasswordHash(req.Code, challenge.CodeHash) {
+		s.registerFailedLogin(ctx, user, clientInfo)
+
+		maxAttempts := s.config.LoginRisk.StepUpMaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = defaultStepUpMaxAttempts
+		}
+		challenge.Attempts++
+		if challenge.Attempts >= maxAttempts {
+			s.tokenRepo.DeleteLoginChallenge(ctx, tokenHash)
+		} else {
+			s.tokenRepo.UpdateLoginChallenge(ctx, challenge)
+		}
+
+		// Catat riwayat login gagal
+		loginHistory := createLoginHistory(user.ID, clientInfo, false, "Invalid verification code")
+		s.userRepo.SaveLoginHistory(ctx, loginHistory)
+		s.recordLogin(ctx, user, user.Email, clientInfo, challenge.Method, "Invalid verification code")
+
+		return nil, ErrInvalidVerifyCode
+	}
+
+	// Challenge hanya dapat diselesaikan sekali
+	if err := s.tokenRepo.DeleteLoginChallenge(ctx, tokenHash); err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidLoginChallenge
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Reset percobaan login
+	s.userRepo.ResetLoginAttempts(ctx, user.ID)
+
+	// Update waktu login terakhir
+	now := time.Now()
+	s.userRepo.UpdateLastLogin(ctx, user.ID, now)
+
+	// Catat riwayat login berhasil dengan informasi klien dan hasil penilaian risiko
+	// saat login dimulai
+	loginClient := &ClientInfo{
+		IP:        challenge.IP,
+		UserAgent: challenge.UserAgent,
+		Country:   challenge.Country,
+		City:      challenge.City,
+		Latitude:  challenge.Latitude,
+		Longitude: challenge.Longitude,
+	}
+	loginHistory := createLoginHistory(user.ID, loginClient, true, "")
+	loginHistory.RiskScore = challenge.RiskScore
+	loginHistory.RiskFactors = strings.Join(challenge.RiskFactors, ",")
+	loginHistory.RiskAction = model.LoginRiskStepUp
 	s.userRepo.SaveLoginHistory(ctx, loginHistory)
+	s.recordLogin(ctx, user, user.Email, loginClient, challenge.Method, "")
 
 	// Generate token
-	tokenResponse, err := s.generateTokens(ctx, user)
+	tokenResponse, err := s.generateTokens(ctx, user, challenge.Scope)
 	if err != nil {
 		return nil, ErrInternalServerError
 	}
@@ -242,8 +470,8 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		return nil, ErrUserInactive
 	}
 
-	// Generate token baru
-	tokenResponse, err := s.generateTokens(ctx, user)
+	// Generate token baru dengan scope yang sama seperti token lama
+	tokenResponse, err := s.generateTokens(ctx, user, claims.Scope)
 	if err != nil {
 		return nil, ErrInternalServerError
 	}
@@ -255,7 +483,7 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*m
 }
 
 // Logout mengeluarkan pengguna
-func (s *authService) Logout(ctx context.Context, userID uuid.UUID, refreshToken string) error {
+func (s *authService) Logout(ctx context.Context, userID uuid.UUID, refreshToken string, actor *model.AuditActor) error {
 	// Parse token - jika invalid, tetap lanjutkan dengan cleanup
 	claims, err := utils.ParseRefreshToken(refreshToken, s.config.JWT.SecretKey)
 	if err != nil {
@@ -263,6 +491,7 @@ func (s *authService) Logout(ctx context.Context, userID uuid.UUID, refreshToken
 		log.Printf("Invalid refresh token during logout, proceeding with cleanup: %v", err)
 		// Tetap hapus sesi pengguna meskipun token invalid
 		s.tokenRepo.DeleteUserSession(ctx, userID)
+		s.auditService.Record(ctx, actor, &userID, model.AuditActionLogout, model.AuditResourceSession, userID.String(), nil)
 		return nil
 	}
 
@@ -281,6 +510,10 @@ func (s *authService) Logout(ctx context.Context, userID uuid.UUID, refreshToken
 	// Selalu hapus sesi pengguna
 	s.tokenRepo.DeleteUserSession(ctx, userID)
 
+	s.auditService.Record(ctx, actor, &userID, model.AuditActionLogout, model.AuditResourceSession, userID.String(), map[string]interface{}{
+		"refresh_token_id": claims.TokenID,
+	})
+
 	return nil
 }
 
@@ -300,13 +533,18 @@ func (s *authService) GetUserByID(ctx context.Context, userID uuid.UUID) (*model
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
-			return nil, repository.ErrUserNotFound
+			return nil, ErrUserNotFound
 		}
 		return nil, ErrInternalServerError
 	}
 
-	// Konversi ke response
+	// Konversi ke response dengan custom attribute yang terlihat oleh user sendiri
 	userResponse := user.ToUserResponse()
+	values, err := s.attributeRepo.FindValuesByUserIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	userResponse.Attributes = model.AttributeMap(values, false)
 
 	// Cache data user
 	s.tokenRepo.CacheUserData(ctx, userID, &userResponse, 1*time.Hour)
@@ -314,21 +552,47 @@ func (s *authService) GetUserByID(ctx context.Context, userID uuid.UUID) (*model
 	return &userResponse, nil
 }
 
-// GetGoogleAuthURL mendapatkan URL untuk autentikasi Google
-func (s *authService) GetGoogleAuthURL(redirectURL string) string {
-	// Generate random state
-	state := utils.GenerateRandomString(32)
+// GetUserDetail mendapatkan data pengguna untuk admin, termasuk semua custom attribute.
+// Data tidak diambil dari cache karena cache hanya berisi attribute yang terlihat oleh user sendiri.
+func (s *authService) GetUserDetail(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
 
-	// Simpan state dan redirect URL ke Redis untuk validasi nanti
-	ctx := context.Background()
-	sessionData := map[string]string{
-		"state": state,
+	values, err := s.attributeRepo.FindValuesByUserIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, ErrInternalServerError
 	}
+
+	userResponse := user.ToUserResponse()
+	userResponse.Attributes = model.AttributeMap(values, true)
+
+	return &userResponse, nil
+}
+
+// GetGoogleAuthURL mendapatkan URL untuk autentikasi Google
+func (s *authService) GetGoogleAuthURL(redirectURL string) string {
+	sessionData := map[string]string{}
 	if redirectURL != "" {
 		sessionData["redirect_url"] = redirectURL
 	}
 
+	return s.googleAuthURL(sessionData)
+}
+
+// googleAuthURL membuat state acak, menyimpannya bersama session data ke Redis
+// untuk dibaca kembali saat callback, lalu mengembalikan URL autentikasi Google
+func (s *authService) googleAuthURL(sessionData map[string]string) string {
+	// Generate random state
+	state := utils.GenerateRandomString(32)
+	sessionData["state"] = state
+
 	// Simpan dengan state sebagai key
+	ctx := context.Background()
 	if err := s.tokenRepo.StoreOAuthState(ctx, state, sessionData, 15*time.Minute); err != nil {
 		fmt.Printf("Failed to store OAuth state: %v\n", err)
 	}
@@ -341,6 +605,17 @@ func (s *authService) GetGoogleAuthURL(redirectURL string) string {
 
 // GetRedirectURLFromState mengambil redirect URL dari state yang disimpan
 func (s *authService) GetRedirectURLFromState(state string) string {
+	return s.getOAuthStateValue(state, "redirect_url")
+}
+
+// GetInvitationTokenFromState mengambil token undangan dari state yang disimpan,
+// kosong jika login Google tidak dimulai dari alur undangan
+func (s *authService) GetInvitationTokenFromState(state string) string {
+	return s.getOAuthStateValue(state, "invitation_token")
+}
+
+// getOAuthStateValue mengambil satu nilai dari session data state OAuth
+func (s *authService) getOAuthStateValue(state, key string) string {
 	ctx := context.Background()
 
 	// Ambil session data dari Redis berdasarkan state
@@ -356,19 +631,20 @@ func (s *authService) GetRedirectURLFromState(state string) string {
 		return ""
 	}
 
-	// Kembalikan redirect URL jika ada
-	if redirectURL, exists := sessionData["redirect_url"]; exists {
-		return redirectURL
-	}
-
-	return ""
+	return sessionData[key]
 }
 
-// HandleGoogleCallback menangani callback dari Google OAuth
-func (s *authService) HandleGoogleCallback(ctx context.Context, code string, clientInfo *ClientInfo) (*model.TokenResponse, error) {
-	// Log untuk debugging
-	fmt.Printf("HandleGoogleCallback called with code: %s\n", code)
+// googleUserInfo adalah profil user dari endpoint userinfo Google
+type googleUserInfo struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	EmailVerified bool   `json:"email_verified"`
+}
 
+// fetchGoogleUser menukar authorization code dengan token lalu mengambil profil user dari Google
+func (s *authService) fetchGoogleUser(ctx context.Context, code string) (*googleUserInfo, error) {
 	// Exchange authorization code dengan token
 	oauth2Token, err := s.googleOAuthCfg.Exchange(ctx, code)
 	if err != nil {
@@ -387,18 +663,24 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string, cli
 	defer resp.Body.Close()
 
 	// Parse respons
-	var googleUser struct {
-		Sub           string `json:"sub"`
-		Email         string `json:"email"`
-		Name          string `json:"name"`
-		Picture       string `json:"picture"`
-		EmailVerified bool   `json:"email_verified"`
-	}
-
+	var googleUser googleUserInfo
 	if err := json.NewDecoder(resp.Body).Decode(&googleUser); err != nil {
 		return nil, ErrGoogleAuthFailed
 	}
 
+	return &googleUser, nil
+}
+
+// HandleGoogleCallback menangani callback dari Google OAuth
+func (s *authService) HandleGoogleCallback(ctx context.Context, code string, clientInfo *ClientInfo) (*model.TokenResponse, error) {
+	// Log untuk debugging
+	fmt.Printf("HandleGoogleCallback called with code: %s\n", code)
+
+	googleUser, err := s.fetchGoogleUser(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
 	// Cari user berdasarkan provider ID
 	user, err := s.userRepo.FindByProviderID(ctx, "google", googleUser.Sub)
 	if err != nil {
@@ -418,7 +700,7 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string, cli
 			}
 
 			// Simpan user ke database
-			err = s.userRepo.Create(ctx, user)
+			err = s.createUserWithEvent(ctx, user, nil)
 			if err != nil {
 				// Jika email sudah ada, coba update user yang ada
 				if errors.Is(err, repository.ErrEmailAlreadyExists) {
@@ -454,16 +736,24 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string, cli
 		return nil, ErrUserInactive
 	}
 
+	// Nilai risiko login sebelum token diterbitkan
+	loginHistory := createLoginHistory(user.ID, clientInfo, true, "")
+	risk := s.assessLogin(ctx, user, loginHistory)
+	if risk.Action == model.LoginRiskStepUp {
+		return nil, s.startStepUp(ctx, user, loginHistory, clientInfo, "google", "", risk)
+	}
+
 	// Update waktu login terakhir
 	now := time.Now()
 	s.userRepo.UpdateLastLogin(ctx, user.ID, now)
 
 	// Catat riwayat login berhasil
-	loginHistory := createLoginHistory(user.ID, clientInfo, true, "")
+	s.notifyRiskyLogin(ctx, user, loginHistory, risk, "google")
 	s.userRepo.SaveLoginHistory(ctx, loginHistory)
+	s.recordLogin(ctx, user, user.Email, clientInfo, "google", "")
 
 	// Generate token
-	tokenResponse, err := s.generateTokens(ctx, user)
+	tokenResponse, err := s.generateTokens(ctx, user, "")
 	if err != nil {
 		return nil, ErrInternalServerError
 	}
@@ -471,124 +761,695 @@ func (s *authService) HandleGoogleCallback(ctx context.Context, code string, cli
 	return tokenResponse, nil
 }
 
-// ValidateToken memvalidasi token JWT
-func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error) {
-	// Parse token
-	claims, err := utils.ParseAccessToken(tokenString, s.config.JWT.SecretKey)
+// GetInvitation mengembalikan undangan pending dari token undangan,
+// digunakan halaman penerimaan undangan untuk menampilkan email dan role
+func (s *authService) GetInvitation(ctx context.Context, token string) (*model.InvitationResponse, error) {
+	invitation, err := s.findPendingInvitation(ctx, token)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	return claims, nil
-}
-
-// GetLoginHistory mendapatkan riwayat login pengguna
-func (s *authService) GetLoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginHistory, error) {
-	return s.userRepo.GetLoginHistory(ctx, userID, limit)
+	invitationResponse := invitation.ToInvitationResponse()
+	return &invitationResponse, nil
 }
 
-// generateTokens menghasilkan access token dan refresh token
-func (s *authService) generateTokens(ctx context.Context, user *model.User) (*model.TokenResponse, error) {
-	// Generate token ID
-	tokenID := utils.GenerateRandomString(32)
-
-	// Generate access token
-	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, user.Role, s.config.JWT.SecretKey, s.config.JWT.AccessTokenExpiry)
+// AcceptInvitation membuat akun baru dengan password dari token undangan
+// melalui jalur yang sama dengan Register, dengan role dari undangan
+func (s *authService) AcceptInvitation(ctx context.Context, req *model.AcceptInvitationRequest, clientInfo *ClientInfo) (*model.UserResponse, error) {
+	invitation, err := s.findPendingInvitation(ctx, req.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate refresh token
-	refreshToken, err := utils.GenerateRefreshToken(user.ID, tokenID, s.config.JWT.SecretKey, s.config.JWT.RefreshTokenExpiry)
-	if err != nil {
-		return nil, err
+	name := req.Name
+	if name == "" {
+		name = invitation.Name
+	}
+	if name == "" {
+		return nil, ErrNameRequired
 	}
 
-	// Simpan refresh token ke Redis
-	err = s.tokenRepo.StoreRefreshToken(ctx, user.ID, tokenID, s.config.JWT.RefreshTokenExpiry)
-	if err != nil {
+	user := newInvitedUser(invitation, name)
+	user.Provider = "local"
+
+	if err := s.registerUser(ctx, user, req.Password); err != nil {
 		return nil, err
 	}
 
-	// Konversi user ke response
-	userResponse := user.ToUserResponse()
-
-	// Cache data user
-	s.tokenRepo.CacheUserData(ctx, user.ID, &userResponse, 1*time.Hour)
+	s.completeInvitation(ctx, invitation, user.ID)
 
-	return &model.TokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(s.config.JWT.AccessTokenExpiry / time.Second),
-		TokenType:    "Bearer",
-		User:         userResponse,
-	}, nil
+	userResponse := user.ToUserResponse()
+	return &userResponse, nil
 }
 
-// createLoginHistory membuat objek riwayat login
-func createLoginHistory(userID uuid.UUID, clientInfo *ClientInfo, success bool, failureReason string) *model.LoginHistory {
-	ua := user_agent.New(clientInfo.UserAgent)
-	browser, version := ua.Browser()
+// GetGoogleAuthURLForInvitation memvalidasi undangan lalu mengembalikan URL autentikasi Google
+// yang state-nya membawa token undangan, sehingga callback membuat akun dari undangan tersebut
+func (s *authService) GetGoogleAuthURLForInvitation(ctx context.Context, token, redirectURL string) (string, error) {
+	if _, err := s.findPendingInvitation(ctx, token); err != nil {
+		return "", err
+	}
 
-	return &model.LoginHistory{
-		UserID:        userID,
-		IP:            clientInfo.IP,
-		UserAgent:     clientInfo.UserAgent,
-		DeviceInfo:    ua.Model(),
-		Browser:       fmt.Sprintf("%s %s", browser, version),
-		OS:            ua.OS(),
-		Country:       clientInfo.Country,
-		City:          clientInfo.City,
-		Success:       success,
-		FailureReason: failureReason,
-		LoginTime:     time.Now(),
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+	sessionData := map[string]string{
+		"invitation_token": token,
+	}
+	if redirectURL != "" {
+		sessionData["redirect_url"] = redirectURL
 	}
-}
 
-// CheckRateLimit memeriksa apakah permintaan melebihi batas rate
-func (s *authService) CheckRateLimit(ctx context.Context, key string, path string, limit int, duration int) (bool, error) {
-	// Buat kunci unik berdasarkan IP dan path
-	fullKey := fmt.Sprintf("rate_limit:%s:%s", key, path)
-	return s.tokenRepo.CheckRateLimit(ctx, fullKey, limit, time.Duration(duration)*time.Second)
+	return s.googleAuthURL(sessionData), nil
 }
 
-// GetAllUsers mendapatkan semua user dengan pagination
-func (s *authService) GetAllUsers(ctx context.Context, page, limit int, search string) (*model.UsersListResponse, error) {
-	if page < 1 {
-		page = 1
+// HandleGoogleInvitationCallback menangani callback Google OAuth yang dimulai dari undangan.
+// Email akun Google harus sama dengan email undangan dan sudah diverifikasi oleh Google.
+func (s *authService) HandleGoogleInvitationCallback(ctx context.Context, code, invitationToken string, clientInfo *ClientInfo) (*model.TokenResponse, error) {
+	invitation, err := s.findPendingInvitation(ctx, invitationToken)
+	if err != nil {
+		return nil, err
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+
+	googleUser, err := s.fetchGoogleUser(ctx, code)
+	if err != nil {
+		return nil, err
 	}
 
-	offset := (page - 1) * limit
+	if !googleUser.EmailVerified || !strings.EqualFold(googleUser.Email, invitation.Email) {
+		return nil, ErrInviteEmailMismatch
+	}
 
-	users, total, err := s.userRepo.GetAllUsers(ctx, offset, limit, search)
-	if err != nil {
+	// Akun Google yang sudah terhubung ke user lain tidak dapat dipakai untuk undangan
+	if _, err := s.userRepo.FindByProviderID(ctx, "google", googleUser.Sub); err == nil {
+		return nil, ErrUserAlreadyExists
+	} else if !errors.Is(err, repository.ErrUserNotFound) {
 		return nil, ErrInternalServerError
 	}
 
-	// Konversi ke UserResponse
-	userResponses := make([]model.UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = user.ToUserResponse()
+	name := googleUser.Name
+	if name == "" {
+		name = invitation.Name
 	}
 
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	user := newInvitedUser(invitation, name)
+	user.Provider = "google"
+	user.ProviderID = googleUser.Sub
+	user.ProfilePicture = googleUser.Picture
 
-	return &model.UsersListResponse{
-		Users:      userResponses,
-		Total:      total,
-		Page:       page,
-		Limit:      limit,
-		TotalPages: totalPages,
+	if err := s.registerUser(ctx, user, ""); err != nil {
+		return nil, err
+	}
+
+	s.completeInvitation(ctx, invitation, user.ID)
+
+	return s.IssueTokensForUser(ctx, user.ID, "google", "", clientInfo)
+}
+
+// findPendingInvitation memvalidasi tanda tangan token undangan lalu memastikan token tersebut
+// adalah token terakhir yang diterbitkan untuk undangan yang masih pending
+func (s *authService) findPendingInvitation(ctx context.Context, token string) (*model.Invitation, error) {
+	claims, err := utils.ParseInvitationToken(token, s.config.JWT.SecretKey)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	invitationID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	invitation, err := s.invitationRepo.FindByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if !utils.CheckSecretHash(token, invitation.TokenHash) {
+		return nil, ErrInvalidInvitation
+	}
+
+	switch invitation.CurrentStatus(time.Now()) {
+	case model.InvitationStatusPending:
+		return invitation, nil
+	case model.InvitationStatusAccepted:
+		return nil, ErrInvitationAccepted
+	default:
+		return nil, ErrInvalidInvitation
+	}
+}
+
+// completeInvitation menandai undangan sebagai diterima setelah akun berhasil dibuat.
+// Kegagalan hanya dicatat karena unique email sudah mencegah undangan dipakai dua kali.
+func (s *authService) completeInvitation(ctx context.Context, invitation *model.Invitation, userID uuid.UUID) {
+	if err := s.invitationRepo.MarkAccepted(ctx, invitation.ID, userID, time.Now()); err != nil {
+		log.Printf("Failed to mark invitation %s as accepted: %v", invitation.ID, err)
+	}
+}
+
+// newInvitedUser membuat user dari undangan. Email dianggap terverifikasi
+// karena token undangan hanya dikirim ke alamat tersebut.
+func newInvitedUser(invitation *model.Invitation, name string) *model.User {
+	role := invitation.Role
+	if role == "" {
+		role = "user"
+	}
+
+	return &model.User{
+		Email:    invitation.Email,
+		Name:     name,
+		Role:     role,
+		RoleID:   invitation.RoleID,
+		Verified: true,
+	}
+}
+
+// IssueTokensForUser menerbitkan token untuk user yang identitasnya sudah diverifikasi
+// oleh alur lain, misalnya device authorization grant. Token dibatasi ke scope jika tidak
+// kosong, dan pemeriksaan akun terkunci, reset password, serta risiko login sama dengan Login.
+func (s *authService) IssueTokensForUser(ctx context.Context, userID uuid.UUID, method, scope string, clientInfo *ClientInfo) (*model.TokenResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Cek apakah akun aktif
+	if !user.Active {
+		s.recordLogin(ctx, user, user.Email, clientInfo, method, "Account inactive")
+		return nil, ErrUserInactive
+	}
+
+	// Cek apakah akun terkunci
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.recordLogin(ctx, user, user.Email, clientInfo, method, "Account locked")
+		return nil, ErrAccountLocked
+	}
+
+	// Admin memaksa user mengatur password baru melalui token reset
+	if user.PasswordResetRequired {
+		loginHistory := createLoginHistory(user.ID, clientInfo, false, "Password reset required")
+		s.userRepo.SaveLoginHistory(ctx, loginHistory)
+		s.recordLogin(ctx, user, user.Email, clientInfo, method, "Password reset required")
+		return nil, ErrPasswordResetRequired
+	}
+
+	// Nilai risiko login sebelum token diterbitkan
+	loginHistory := createLoginHistory(user.ID, clientInfo, true, "")
+	risk := s.assessLogin(ctx, user, loginHistory)
+	if risk.Action == model.LoginRiskStepUp {
+		return nil, s.startStepUp(ctx, user, loginHistory, clientInfo, method, scope, risk)
+	}
+
+	// Update waktu login terakhir
+	now := time.Now()
+	s.userRepo.UpdateLastLogin(ctx, user.ID, now)
+
+	// Catat riwayat login berhasil
+	s.notifyRiskyLogin(ctx, user, loginHistory, risk, method)
+	s.userRepo.SaveLoginHistory(ctx, loginHistory)
+	s.recordLogin(ctx, user, user.Email, clientInfo, method, "")
+
+	// Generate token
+	tokenResponse, err := s.generateTokens(ctx, user, scope)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return tokenResponse, nil
+}
+
+// ValidateToken memvalidasi token JWT
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error) {
+	// Parse token
+	claims, err := utils.ParseAccessToken(tokenString, s.config.JWT.SecretKey)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	// Token service account dicabut saat client dinonaktifkan, dihapus, atau secret dirotasi.
+	// Status pencabutan yang tidak dapat diperiksa dianggap dicabut (fail closed).
+	if claims.IsClient() {
+		generation, err := s.tokenRepo.GetClientTokenGeneration(ctx, claims.UserID)
+		if err != nil {
+			log.Printf("Failed to check client token generation for %s: %v", claims.ClientID, err)
+			return nil, ErrInternalServerError
+		}
+		if claims.ClientGen < generation {
+			return nil, ErrInvalidToken
+		}
+	} else {
+		// Token user dicabut saat semua sesi user dicabut (RevokeUserSessions)
+		generation, err := s.tokenRepo.GetUserTokenGeneration(ctx, claims.UserID)
+		if err != nil {
+			log.Printf("Failed to check user token generation for %s: %v", claims.UserID, err)
+			return nil, ErrInternalServerError
+		}
+		if claims.UserGen < generation {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	// Token yang dicabut melalui endpoint revocation
+	if claims.ID != "" {
+		revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			log.Printf("Failed to check access token revocation for %s: %v", claims.ID, err)
+			return nil, ErrInternalServerError
+		}
+		if revoked {
+			return nil, ErrInvalidToken
+		}
+	}
+
+	return claims, nil
+}
+
+// GetLoginHistory mendapatkan riwayat login pengguna
+func (s *authService) GetLoginHistory(ctx context.Context, userID uuid.UUID, limit int) ([]model.LoginHistory, error) {
+	return s.userRepo.GetLoginHistory(ctx, userID, limit)
+}
+
+// generateTokens menghasilkan access token dan refresh token. scope kosong berarti sesi
+// login penuh; selain itu kedua token dibatasi ke scope tersebut.
+func (s *authService) generateTokens(ctx context.Context, user *model.User, scope string) (*model.TokenResponse, error) {
+	// Generate token ID
+	tokenID := utils.GenerateRandomString(32)
+
+	// Custom attribute untuk response dan klaim JWT
+	values, err := s.attributeRepo.FindValuesByUserIDs(ctx, []uuid.UUID{user.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Generasi token user saat ini, dinaikkan oleh RevokeUserSessions
+	generation, err := s.tokenRepo.GetUserTokenGeneration(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate access token
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, user.Role, scope, model.AttributeClaims(values), generation, s.config.JWT.SecretKey, s.config.JWT.AccessTokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate refresh token
+	refreshToken, err := utils.GenerateRefreshToken(user.ID, tokenID, scope, s.config.JWT.SecretKey, s.config.JWT.RefreshTokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Simpan refresh token ke Redis
+	err = s.tokenRepo.StoreRefreshToken(ctx, user.ID, tokenID, s.config.JWT.RefreshTokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Konversi user ke response
+	userResponse := user.ToUserResponse()
+	userResponse.Attributes = model.AttributeMap(values, false)
+
+	// Cache data user
+	s.tokenRepo.CacheUserData(ctx, user.ID, &userResponse, 1*time.Hour)
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.config.JWT.AccessTokenExpiry / time.Second),
+		TokenType:    "Bearer",
+		User:         userResponse,
+	}, nil
+}
+
+// createLoginHistory membuat objek riwayat login
+func createLoginHistory(userID uuid.UUID, clientInfo *ClientInfo, success bool, failureReason string) *model.LoginHistory {
+	ua := user_agent.New(clientInfo.UserAgent)
+	browser, version := ua.Browser()
+
+	return &model.LoginHistory{
+		UserID:            userID,
+		IP:                clientInfo.IP,
+		UserAgent:         clientInfo.UserAgent,
+		DeviceInfo:        ua.Model(),
+		DeviceFingerprint: deviceFingerprint(ua),
+		Browser:           fmt.Sprintf("%s %s", browser, version),
+		OS:                ua.OS(),
+		Country:           clientInfo.Country,
+		City:              clientInfo.City,
+		Latitude:          clientInfo.Latitude,
+		Longitude:         clientInfo.Longitude,
+		Success:           success,
+		FailureReason:     failureReason,
+		LoginTime:         time.Now(),
+		CreatedAt:         time.Now(),
+		UpdatedAt:         time.Now(),
+	}
+}
+
+// recordLogin mencatat audit log percobaan login. user bernilai nil jika email tidak
+// terdaftar, dan failureReason kosong berarti login berhasil.
+func (s *authService) recordLogin(ctx context.Context, user *model.User, email string, clientInfo *ClientInfo, method, failureReason string) {
+	actor := &model.AuditActor{
+		Type:      model.AuditActorUser,
+		Name:      email,
+		IP:        clientInfo.IP,
+		UserAgent: clientInfo.UserAgent,
+	}
+
+	var userID *uuid.UUID
+	resourceID := ""
+	if user != nil {
+		id := user.ID
+		userID = &id
+		actor.ID = &id
+		resourceID = id.String()
+	}
+
+	action := model.AuditActionLoginSucceeded
+	details := map[string]interface{}{"method": method}
+	if failureReason != "" {
+		action = model.AuditActionLoginFailed
+		details["reason"] = failureReason
+	}
+
+	s.auditService.Record(ctx, actor, userID, action, model.AuditResourceSession, resourceID, details)
+
+	if user != nil && failureReason == "" {
+		data := userEventData(user)
+		data["method"] = method
+		data["ip_address"] = clientInfo.IP
+		data["user_agent"] = clientInfo.UserAgent
+		if err := s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventUserLoggedIn, model.EventAggregateUser, user.ID.String(), data)); err != nil {
+			log.Printf("Failed to publish %s event for user %s: %v", model.EventUserLoggedIn, user.ID, err)
+		}
+	}
+}
+
+// registerFailedLogin menambah jumlah percobaan login gagal dan mengunci akun jika
+// jumlahnya mencapai batas
+func (s *authService) registerFailedLogin(ctx context.Context, user *model.User, clientInfo *ClientInfo) {
+	// Tambah jumlah percobaan login yang gagal
+	s.userRepo.IncrementLoginAttempts(ctx, user.ID)
+
+	// Jika melebihi batas percobaan, kunci akun
+	if user.LoginAttempts+1 >= s.config.Security.MaxLoginAttempts {
+		lockedUntil := time.Now().Add(s.config.Security.LockoutDuration).UTC()
+		err := s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.userRepo.LockAccount(ctx, user.ID, s.config.Security.LockoutDuration); err != nil {
+				return err
+			}
+
+			data := userEventData(user)
+			data["locked_until"] = lockedUntil
+			data["reason"] = "too_many_failed_logins"
+			data["ip_address"] = clientInfo.IP
+			return s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventUserLocked, model.EventAggregateUser, user.ID.String(), data))
+		})
+		if err == nil {
+			s.notifyAccountLocked(ctx, user, clientInfo, lockedUntil)
+		}
+	}
+}
+
+// assessLogin menilai risiko login lalu mencatat hasilnya pada riwayat login.
+// Dipanggil sebelum riwayat login disimpan.
+func (s *authService) assessLogin(ctx context.Context, user *model.User, history *model.LoginHistory) *LoginRisk {
+	risk := s.riskService.Assess(ctx, user, history)
+	history.RiskScore = risk.Score
+	history.RiskFactors = strings.Join(risk.Factors, ",")
+	history.RiskAction = risk.Action
+	return risk
+}
+
+// startStepUp menyimpan login berisiko sebagai challenge, mengirim kode verifikasi ke email
+// user, dan mencatat percobaan login yang menunggu verifikasi. Mengembalikan
+// StepUpRequiredError berisi token challenge untuk VerifyLoginChallenge.
+func (s *authService) startStepUp(ctx context.Context, user *model.User, history *model.LoginHistory, clientInfo *ClientInfo, method, scope string, risk *LoginRisk) error {
+	expiry := s.config.LoginRisk.StepUpExpiry
+	if expiry <= 0 {
+		expiry = defaultStepUpExpiry
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return ErrInternalServerError
+	}
+	code, err := utils.GenerateNumericCode(loginVerificationCodeLength)
+	if err != nil {
+		return ErrInternalServerError
+	}
+	codeHash, err := utils.HashPassword(code)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	challenge := &model.LoginChallenge{
+		TokenHash:   utils.HashSecret(token),
+		UserID:      user.ID,
+		Method:      method,
+		Scope:       scope,
+		CodeHash:    codeHash,
+		IP:          clientInfo.IP,
+		UserAgent:   clientInfo.UserAgent,
+		Country:     clientInfo.Country,
+		City:        clientInfo.City,
+		Latitude:    clientInfo.Latitude,
+		Longitude:   clientInfo.Longitude,
+		RiskScore:   risk.Score,
+		RiskFactors: risk.Factors,
+		ExpiresAt:   time.Now().Add(expiry).UTC(),
+	}
+	if err := s.tokenRepo.StoreLoginChallenge(ctx, challenge, expiry); err != nil {
+		return ErrInternalServerError
+	}
+
+	browser, _ := user_agent.New(history.UserAgent).Browser()
+	email := &model.EmailRequest{
+		To:       user.Email,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplateLoginVerification,
+		Data: map[string]interface{}{
+			"Code":      code,
+			"Browser":   browser,
+			"OS":        history.OS,
+			"Location":  loginLocation(history),
+			"Time":      formatEmailTime(history.LoginTime, user.Timezone),
+			"ExpiresIn": int(expiry / time.Minute),
+			"Reasons":   risk.Factors,
+		},
+	}
+	if err := s.emails.Send(ctx, email); err != nil {
+		log.Printf("Failed to email login verification code to user %s: %v", user.ID, err)
+		s.tokenRepo.DeleteLoginChallenge(ctx, challenge.TokenHash)
+		return ErrInternalServerError
+	}
+
+	// Catat percobaan login yang menunggu verifikasi
+	history.Success = false
+	history.FailureReason = "Step-up verification required"
+	s.userRepo.SaveLoginHistory(ctx, history)
+	s.recordLogin(ctx, user, user.Email, clientInfo, method, "Step-up verification required")
+
+	return &StepUpRequiredError{
+		Challenge: &model.LoginChallengeResponse{
+			StepUpRequired: true,
+			ChallengeToken: token,
+			Method:         model.LoginChallengeMethodEmailCode,
+			RiskFactors:    risk.Factors,
+			ExpiresAt:      challenge.ExpiresAt,
+		},
+	}
+}
+
+// notifyRiskyLogin mengirim notifikasi keamanan jika hasil penilaian risiko login berhasil
+// adalah notify. Login yang hanya berasal dari perangkat baru memakai peringatan perangkat
+// baru, faktor lain dilaporkan sebagai login mencurigakan. Dipanggil sebelum riwayat login disimpan.
+func (s *authService) notifyRiskyLogin(ctx context.Context, user *model.User, history *model.LoginHistory, risk *LoginRisk, method string) {
+	if risk.Action != model.LoginRiskNotify {
+		return
+	}
+
+	browser, _ := user_agent.New(history.UserAgent).Browser()
+	location := loginLocation(history)
+
+	event := model.NotificationEventNewDeviceLogin
+	template := model.EmailTemplateNewDeviceLogin
+	title := "New login from a new device"
+	message := fmt.Sprintf("Your account was signed in with %s on %s from %s. If this wasn't you, change your password now.", browser, history.OS, location)
+	if len(risk.Factors) != 1 || risk.Factors[0] != model.RiskFactorNewDevice {
+		event = model.NotificationEventSuspiciousLogin
+		template = model.EmailTemplateSuspiciousLogin
+		title = "Unusual login to your account"
+		message = fmt.Sprintf("Your account was signed in with %s on %s from %s, which looks unusual: %s. If this wasn't you, change your password now.", browser, history.OS, location, describeRiskFactors(risk.Factors))
+	}
+
+	notification := &model.Notification{
+		UserID:   user.ID,
+		Type:     model.NotificationTypeWarning,
+		Category: model.NotificationCategorySecurity,
+		Event:    event,
+		Title:    title,
+		Message:  message,
+	}
+	data := map[string]interface{}{
+		"method":       method,
+		"ip_address":   history.IP,
+		"user_agent":   history.UserAgent,
+		"browser":      history.Browser,
+		"os":           history.OS,
+		"device_info":  history.DeviceInfo,
+		"country":      history.Country,
+		"city":         history.City,
+		"login_time":   history.LoginTime.UTC(),
+		"risk_score":   risk.Score,
+		"risk_factors": risk.Factors,
+	}
+	if err := s.notifications.Notify(ctx, notification, data); err != nil {
+		log.Printf("Failed to notify user %s about %s: %v", user.ID, event, err)
+	}
+
+	email := &model.EmailRequest{
+		To:       user.Email,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: template,
+		Data: map[string]interface{}{
+			"Browser":  browser,
+			"OS":       history.OS,
+			"Location": location,
+			"Time":     formatEmailTime(history.LoginTime, user.Timezone),
+			"Reasons":  risk.Factors,
+		},
+	}
+	if err := s.emails.Send(ctx, email); err != nil {
+		log.Printf("Failed to email user %s about %s: %v", user.ID, event, err)
+	}
+}
+
+// loginLocation mengembalikan IP login beserta kota dan negaranya jika diketahui
+func loginLocation(history *model.LoginHistory) string {
+	if place := strings.Trim(history.City+", "+history.Country, ", "); place != "" {
+		return fmt.Sprintf("%s (%s)", history.IP, place)
+	}
+	return history.IP
+}
+
+// describeRiskFactors mengubah faktor risiko menjadi keterangan singkat untuk pesan notifikasi
+func describeRiskFactors(factors []string) string {
+	descriptions := map[string]string{
+		model.RiskFactorNewDevice:        "new device",
+		model.RiskFactorNewCountry:       "new country",
+		model.RiskFactorImpossibleTravel: "too far from your previous login location",
+		model.RiskFactorTorExitNode:      "TOR network",
+		model.RiskFactorDeniedIP:         "blocked IP address",
+	}
+
+	parts := make([]string, 0, len(factors))
+	for _, factor := range factors {
+		if description, ok := descriptions[factor]; ok {
+			parts = append(parts, description)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// notifyAccountLocked mengirim notifikasi keamanan saat akun terkunci karena login gagal berulang
+func (s *authService) notifyAccountLocked(ctx context.Context, user *model.User, clientInfo *ClientInfo, lockedUntil time.Time) {
+	notification := &model.Notification{
+		UserID:   user.ID,
+		Type:     model.NotificationTypeError,
+		Category: model.NotificationCategorySecurity,
+		Event:    model.NotificationEventAccountLocked,
+		Title:    "Account locked",
+		Message:  fmt.Sprintf("Your account was locked after too many failed login attempts from %s. It will be unlocked at %s.", clientInfo.IP, lockedUntil.Format(time.RFC1123)),
+	}
+	data := map[string]interface{}{
+		"ip_address":   clientInfo.IP,
+		"user_agent":   clientInfo.UserAgent,
+		"locked_until": lockedUntil,
+	}
+	if err := s.notifications.Notify(ctx, notification, data); err != nil {
+		log.Printf("Failed to notify user %s about account lock: %v", user.ID, err)
+	}
+
+	email := &model.EmailRequest{
+		To:       user.Email,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplateAccountLocked,
+		Data: map[string]interface{}{
+			"IP":          clientInfo.IP,
+			"LockedUntil": formatEmailTime(lockedUntil, user.Timezone),
+		},
+	}
+	if err := s.emails.Send(ctx, email); err != nil {
+		log.Printf("Failed to email user %s about account lock: %v", user.ID, err)
+	}
+}
+
+// CheckRateLimit memeriksa apakah permintaan melebihi batas rate
+func (s *authService) CheckRateLimit(ctx context.Context, key string, path string, limit int, duration int) (bool, error) {
+	// Buat kunci unik berdasarkan IP dan path
+	fullKey := fmt.Sprintf("rate_limit:%s:%s", key, path)
+	return s.tokenRepo.CheckRateLimit(ctx, fullKey, limit, time.Duration(duration)*time.Second)
+}
+
+// GetAllUsers mendapatkan user sesuai filter dengan offset atau cursor pagination
+func (s *authService) GetAllUsers(ctx context.Context, filter *model.UserFilter) (*model.UsersListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 10
+	}
+
+	attributeFilters, err := resolveAttributeFilters(ctx, s.attributeRepo, filter.AttributeQuery)
+	if err != nil {
+		return nil, err
+	}
+	filter.Attributes = attributeFilters
+
+	users, total, nextCursor, err := s.userRepo.GetAllUsers(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
+		return nil, ErrInternalServerError
+	}
+
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	values, err := s.attributeRepo.FindValuesByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	valuesByUser := groupAttributeValues(values)
+
+	// Konversi ke UserResponse dengan semua custom attribute (untuk admin)
+	userResponses := make([]model.UserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = user.ToUserResponse()
+		userResponses[i].Attributes = model.AttributeMap(valuesByUser[user.ID], true)
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.UsersListResponse{
+		Users:      userResponses,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+		NextCursor: nextCursor,
 	}, nil
 }
 
 // UpdateUser mengupdate data user
-func (s *authService) UpdateUser(ctx context.Context, userID uuid.UUID, req *model.UpdateUserRequest) (*model.UserResponse, error) {
+func (s *authService) UpdateUser(ctx context.Context, userID uuid.UUID, req *model.UpdateUserRequest, actor *model.AuditActor) (*model.UserResponse, error) {
 	// Cari user berdasarkan ID
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
@@ -598,21 +1459,38 @@ func (s *authService) UpdateUser(ctx context.Context, userID uuid.UUID, req *mod
 		return nil, ErrInternalServerError
 	}
 
-	// Update field yang diberikan
-	if req.Name != "" {
+	// Update field yang diberikan, perubahan dicatat untuk audit log
+	deactivated := false
+	changes := make(map[string]interface{})
+	if req.Name != "" && req.Name != user.Name {
+		changes["name"] = map[string]interface{}{"from": user.Name, "to": req.Name}
 		user.Name = req.Name
 	}
-	if req.Active != nil {
+	if req.Active != nil && *req.Active != user.Active {
+		changes["active"] = map[string]interface{}{"from": user.Active, "to": *req.Active}
+		deactivated = user.Active && !*req.Active
 		user.Active = *req.Active
 	}
+	previousRole := user.Role
 	if req.Role != "" {
 		user.Role = req.Role
 	}
 
 	user.UpdatedAt = time.Now()
 
-	// Simpan perubahan
-	err = s.userRepo.Update(ctx, user)
+	// Simpan perubahan beserta event perubahan role
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if user.Role == previousRole {
+			return nil
+		}
+
+		data := userEventData(user)
+		data["previous_role"] = previousRole
+		return s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventUserRoleChanged, model.EventAggregateUser, userID.String(), data))
+	})
 	if err != nil {
 		return nil, ErrInternalServerError
 	}
@@ -620,10 +1498,444 @@ func (s *authService) UpdateUser(ctx context.Context, userID uuid.UUID, req *mod
 	// Hapus cache user
 	s.tokenRepo.InvalidateUserCache(ctx, userID)
 
+	// Cabut semua sesi user yang dinonaktifkan
+	if deactivated {
+		s.RevokeUserSessions(ctx, userID)
+	}
+
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, actor, &userID, model.AuditActionUserUpdated, model.AuditResourceUser, userID.String(), map[string]interface{}{
+			"changes": changes,
+		})
+	}
+	if user.Role != previousRole {
+		s.auditService.Record(ctx, actor, &userID, model.AuditActionUserRoleChanged, model.AuditResourceUser, userID.String(), map[string]interface{}{
+			"previous_role": previousRole,
+			"role":          user.Role,
+		})
+	}
+
+	userResponse := user.ToUserResponse()
+	return &userResponse, nil
+}
+
+// CreateUser membuat user baru oleh admin, dengan password awal atau undangan untuk mengatur password
+func (s *authService) CreateUser(ctx context.Context, req *model.CreateUserRequest, actor *model.AuditActor) (*model.CreateUserResponse, error) {
+	if req.Password == "" && !req.SendInvite {
+		return nil, ErrPasswordRequired
+	}
+
+	// User yang diundang mendapat password acak yang tidak diketahui siapa pun
+	// sampai user mengatur password-nya sendiri
+	password := req.Password
+	if password == "" {
+		randomPassword, err := utils.GenerateSecureToken(32)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+		password = randomPassword
+	} else if len(password) < s.config.Security.PasswordMinLength {
+		return nil, ErrPasswordTooWeak
+	}
+
+	// Hash password
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	role := req.Role
+	if role == "" {
+		role = "user"
+	}
+
+	// Buat user baru
+	user := &model.User{
+		Email:     req.Email,
+		Password:  hashedPassword,
+		Name:      req.Name,
+		Provider:  "local",
+		Role:      role,
+		RoleID:    req.RoleID,
+		Verified:  req.Verified,
+		Active:    true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	// Simpan user ke database
+	var eventData map[string]interface{}
+	if actor != nil {
+		eventData = map[string]interface{}{"created_by": actor.ID}
+	}
+	err = s.createUserWithEvent(ctx, user, eventData)
+	if err != nil {
+		if errors.Is(err, repository.ErrEmailAlreadyExists) {
+			return nil, ErrUserAlreadyExists
+		}
+		return nil, ErrInternalServerError
+	}
+
+	response := &model.CreateUserResponse{
+		User: user.ToUserResponse(),
+	}
+
+	if req.SendInvite {
+		// Token hanya dikirim ke email user dan tidak dikembalikan ke admin
+		inviteToken, expiresAt, err := s.SendPasswordSetupLink(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		response.InviteToken = inviteToken
+		response.InviteExpiresAt = &expiresAt
+	}
+
+	s.auditService.Record(ctx, actor, &user.ID, model.AuditActionUserCreated, model.AuditResourceUser, user.ID.String(), map[string]interface{}{
+		"email":       user.Email,
+		"role":        user.Role,
+		"role_id":     user.RoleID,
+		"send_invite": req.SendInvite,
+	})
+
+	return response, nil
+}
+
+// DeleteUser menghapus user (soft delete) dan mencabut semua sesinya
+func (s *authService) DeleteUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) error {
+	err := s.deleteUserWithEvent(ctx, userID, false, s.userRepo.Delete)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	s.RevokeUserSessions(ctx, userID)
+
+	s.auditService.Record(ctx, actor, &userID, model.AuditActionUserDeleted, model.AuditResourceUser, userID.String(), nil)
+
+	return nil
+}
+
+// ToggleUserStatus mengaktifkan atau menonaktifkan user
+func (s *authService) ToggleUserStatus(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	user.Active = !user.Active
+	err = s.userRepo.UpdateUserStatus(ctx, userID, user.Active)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	action := model.AuditActionUserActivated
+	if user.Active {
+		s.tokenRepo.InvalidateUserCache(ctx, userID)
+	} else {
+		action = model.AuditActionUserDeactivated
+		s.RevokeUserSessions(ctx, userID)
+	}
+
+	s.auditService.Record(ctx, actor, &userID, action, model.AuditResourceUser, userID.String(), nil)
+
+	userResponse := user.ToUserResponse()
+	return &userResponse, nil
+}
+
+// AcceptInvite mengatur password user dari token undangan
+func (s *authService) AcceptInvite(ctx context.Context, req *model.AcceptInviteRequest) (*model.UserResponse, error) {
+	if len(req.Password) < s.config.Security.PasswordMinLength {
+		return nil, ErrPasswordTooWeak
+	}
+
+	userID, err := s.tokenRepo.ConsumePasswordSetupToken(ctx, utils.HashSecret(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidInviteToken
+		}
+		return nil, ErrInternalServerError
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidInviteToken
+		}
+		return nil, ErrInternalServerError
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	// Menerima undangan membuktikan kepemilikan email
+	user.Password = hashedPassword
+	user.Verified = true
+	user.PasswordResetRequired = false
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
 	userResponse := user.ToUserResponse()
 	return &userResponse, nil
 }
 
+// GetDeletedUsers mendapatkan daftar user yang sudah dihapus (soft delete) dengan pagination
+func (s *authService) GetDeletedUsers(ctx context.Context, page, limit int, search string) (*model.DeletedUsersListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	users, total, err := s.userRepo.GetDeletedUsers(ctx, offset, limit, search)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	userResponses := make([]model.DeletedUserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = model.DeletedUserResponse{
+			UserResponse: user.ToUserResponse(),
+			DeletedAt:    user.DeletedAt.Time,
+			PurgeableAt:  user.DeletedAt.Time.Add(s.config.Security.DeletedUserRetention),
+		}
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &model.DeletedUsersListResponse{
+		Users:      userResponses,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// RestoreUser mengembalikan user yang sudah dihapus (soft delete)
+func (s *authService) RestoreUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) (*model.UserResponse, error) {
+	err := s.userRepo.Restore(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
+	s.auditService.Record(ctx, actor, &userID, model.AuditActionUserRestored, model.AuditResourceUser, userID.String(), nil)
+
+	userResponse := user.ToUserResponse()
+	return &userResponse, nil
+}
+
+// PurgeUser menghapus permanen user yang sudah melewati masa retensi di trash
+func (s *authService) PurgeUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) error {
+	user, err := s.userRepo.FindDeletedByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	if time.Since(user.DeletedAt.Time) < s.config.Security.DeletedUserRetention {
+		return ErrRetentionNotElapsed
+	}
+
+	return s.purgeUser(ctx, userID, actor)
+}
+
+// PurgeExpiredUsers menghapus permanen semua user yang sudah melewati masa retensi di trash
+func (s *authService) PurgeExpiredUsers(ctx context.Context, actor *model.AuditActor) (int, error) {
+	deletedBefore := time.Now().Add(-s.config.Security.DeletedUserRetention)
+	purged := 0
+
+	for {
+		userIDs, err := s.userRepo.FindPurgeableUserIDs(ctx, deletedBefore, purgeBatchSize)
+		if err != nil {
+			return purged, ErrInternalServerError
+		}
+
+		for _, userID := range userIDs {
+			if err := s.purgeUser(ctx, userID, actor); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(userIDs) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// DeleteUnverifiedUsers memindahkan user lokal yang belum terverifikasi dan belum pernah login
+// sejak dibuat sebelum createdBefore ke trash. User tetap dapat dipulihkan sampai masa retensi
+// trash berakhir.
+func (s *authService) DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time) (int, error) {
+	deleted := 0
+
+	for {
+		userIDs, err := s.userRepo.FindUnverifiedUserIDs(ctx, createdBefore, purgeBatchSize)
+		if err != nil {
+			return deleted, ErrInternalServerError
+		}
+
+		for _, userID := range userIDs {
+			if err := s.DeleteUser(ctx, userID, nil); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+
+		if len(userIDs) < purgeBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// purgeUser menghapus permanen user beserta sesi yang mungkin masih tersisa.
+// Audit log purge tidak merujuk user_id karena user sudah tidak ada; ID user tetap
+// tersimpan sebagai resource_id.
+func (s *authService) purgeUser(ctx context.Context, userID uuid.UUID, actor *model.AuditActor) error {
+	var objectKeys []string
+	err := s.deleteUserWithEvent(ctx, userID, true, func(ctx context.Context, id uuid.UUID) error {
+		keys, err := s.userRepo.Purge(ctx, id)
+		objectKeys = keys
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	// Isi file dihapus setelah metadata-nya terhapus; kegagalan hanya menyisakan object tanpa referensi
+	for _, key := range objectKeys {
+		if err := s.fileStorage.Delete(ctx, key); err != nil && !errors.Is(err, repository.ErrObjectNotFound) {
+			log.Printf("Failed to delete file object %s of purged user %s: %v", key, userID, err)
+		}
+	}
+
+	s.RevokeUserSessions(ctx, userID)
+
+	s.auditService.Record(ctx, actor, nil, model.AuditActionUserPurged, model.AuditResourceUser, userID.String(), nil)
+
+	return nil
+}
+
+// deleteUserWithEvent menjalankan penghapusan user dan menulis event user.deleted dalam satu transaksi
+func (s *authService) deleteUserWithEvent(ctx context.Context, userID uuid.UUID, permanent bool, remove func(ctx context.Context, id uuid.UUID) error) error {
+	return s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := remove(ctx, userID); err != nil {
+			return err
+		}
+
+		return s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventUserDeleted, model.EventAggregateUser, userID.String(), map[string]interface{}{
+			"user_id":   userID,
+			"permanent": permanent,
+		}))
+	})
+}
+
+// returnSetupTokens menentukan apakah token pengaturan password ikut dikembalikan di response API.
+// Token hanya dikirim melalui email, kecuali driver mail "log" untuk development yang tidak
+// mengirim email sungguhan.
+func (s *authService) returnSetupTokens() bool {
+	return s.config.Mail.Driver == "log"
+}
+
+// SendPasswordSetupLink menerbitkan token pengaturan password dan mengirim link-nya ke email user,
+// digunakan untuk undangan dan reset password yang dipaksakan admin. Kegagalan email hanya dicatat
+// di log; link baru dapat diterbitkan dengan force password reset. Token yang dikembalikan kosong
+// kecuali driver mail adalah "log".
+func (s *authService) SendPasswordSetupLink(ctx context.Context, user *model.User) (string, time.Time, error) {
+	token, expiresAt, err := s.issuePasswordSetupToken(ctx, user.ID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	email := &model.EmailRequest{
+		To:       user.Email,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplatePasswordSetup,
+		Data: map[string]interface{}{
+			"Link":      tokenLink(s.config.Security.PasswordSetupURL, token),
+			"ExpiresAt": formatEmailTime(expiresAt, user.Timezone),
+		},
+	}
+	if err := s.emails.Send(ctx, email); err != nil {
+		log.Printf("Failed to send password setup email to %s: %v", user.Email, err)
+	}
+
+	if !s.returnSetupTokens() {
+		token = ""
+	}
+	return token, expiresAt, nil
+}
+
+// issuePasswordSetupToken membuat token sekali pakai untuk mengatur password user
+func (s *authService) issuePasswordSetupToken(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", time.Time{}, ErrInternalServerError
+	}
+
+	expiresAt := time.Now().Add(s.config.Security.InviteExpiry)
+	err = s.tokenRepo.StorePasswordSetupToken(ctx, utils.HashSecret(token), userID, s.config.Security.InviteExpiry)
+	if err != nil {
+		return "", time.Time{}, ErrInternalServerError
+	}
+
+	return token, expiresAt, nil
+}
+
+// RevokeUserSessions mencabut semua refresh token, sesi, dan cache user, serta menaikkan
+// generasi token user sehingga access token yang masih berlaku ikut ditolak oleh middleware.
+// Koneksi chat WebSocket dan stream notifikasi user di semua instance ditutup melalui broker
+// masing-masing.
+func (s *authService) RevokeUserSessions(ctx context.Context, userID uuid.UUID) {
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, userID); err != nil {
+		log.Printf("Failed to revoke tokens for user %s: %v", userID, err)
+	}
+	if err := s.tokenRepo.RevokeUserAccessTokens(ctx, userID); err != nil {
+		log.Printf("Failed to revoke access tokens for user %s: %v", userID, err)
+	}
+	s.tokenRepo.DeleteUserSession(ctx, userID)
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
+	event, _ := model.NewChatEvent(model.ChatEventSessionsRevoked, struct{}{}, userID)
+	if err := s.chatBroker.Publish(ctx, event); err != nil {
+		log.Printf("Failed to close chat sessions for user %s: %v", userID, err)
+	}
+	if err := s.notifications.CloseUser(ctx, userID); err != nil {
+		log.Printf("Failed to close notification streams for user %s: %v", userID, err)
+	}
+}
+
 // GetUserStats mendapatkan statistik user
 func (s *authService) GetUserStats(ctx context.Context) (*model.UserStats, error) {
 	stats, err := s.userRepo.GetUserStats(ctx)
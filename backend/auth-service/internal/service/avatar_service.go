@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registrasi decoder GIF
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// maxAvatarSourceDimension membatasi lebar dan tinggi gambar asli agar gambar
+// kecil dengan resolusi sangat besar tidak menghabiskan memori saat di-decode
+const maxAvatarSourceDimension = 8192
+
+// maxAvatarSourcePixels membatasi jumlah piksel gambar asli (sekitar 100 MB RGBA saat di-decode)
+const maxAvatarSourcePixels = 25_000_000
+
+// maxConcurrentAvatarDecodes membatasi jumlah gambar yang di-decode bersamaan pada satu instance,
+// karena rate limit per user tidak membatasi upload bersamaan dari banyak user
+const maxConcurrentAvatarDecodes = 4
+
+// Avatar errors
+var (
+	ErrAvatarTooLarge   = errors.New("avatar file is too large")
+	ErrUnsupportedImage = errors.New("unsupported image type")
+	ErrInvalidImage     = errors.New("invalid or too large image")
+	ErrAvatarNotFound   = errors.New("avatar not found")
+)
+
+// AvatarService interface untuk pengelolaan avatar yang diunggah user
+type AvatarService interface {
+	UploadAvatar(ctx context.Context, userID uuid.UUID, file io.Reader) (*model.AvatarResponse, error)
+	DeleteAvatar(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error)
+	GetAvatarURL(ctx context.Context, userID uuid.UUID) (string, error)
+}
+
+// avatarService implementasi AvatarService
+type avatarService struct {
+	userRepo    repository.UserRepository
+	tokenRepo   repository.TokenRepository
+	fileStorage repository.FileStorage
+	config      *config.Config
+	decodeSlots chan struct{}
+}
+
+// NewAvatarService membuat instance baru AvatarService
+func NewAvatarService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, fileStorage repository.FileStorage, cfg *config.Config) AvatarService {
+	return &avatarService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		fileStorage: fileStorage,
+		config:      cfg,
+		decodeSlots: make(chan struct{}, maxConcurrentAvatarDecodes),
+	}
+}
+
+// UploadAvatar memvalidasi gambar berdasarkan isinya (bukan nama file atau header),
+// mengubah ukurannya menjadi persegi dengan ukuran tetap, lalu menyimpannya ke file storage.
+// Avatar lama dihapus setelah avatar baru tersimpan.
+func (s *avatarService) UploadAvatar(ctx context.Context, userID uuid.UUID, file io.Reader) (*model.AvatarResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Cek rate limit upload avatar
+	allowed, err := s.tokenRepo.CheckRateLimit(ctx, fmt.Sprintf("avatar_upload:%s", userID), 10, time.Hour)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if !allowed {
+		return nil, ErrRateLimitExceeded
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, s.config.Storage.AvatarMaxSize+1))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if int64(len(data)) > s.config.Storage.AvatarMaxSize {
+		return nil, ErrAvatarTooLarge
+	}
+
+	// Tunggu slot decode agar upload bersamaan tidak menghabiskan memori
+	select {
+	case s.decodeSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ErrInternalServerError
+	}
+	avatar, contentType, extension, err := s.processImage(data)
+	<-s.decodeSlots
+	if err != nil {
+		return nil, err
+	}
+
+	// Key baru untuk setiap upload agar cache browser dan CDN tidak menyajikan avatar lama
+	key := fmt.Sprintf("avatars/%s/%s%s", userID, uuid.New(), extension)
+	if err := s.fileStorage.Put(ctx, key, bytes.NewReader(avatar), int64(len(avatar)), contentType); err != nil {
+		log.Printf("Failed to store avatar for user %s: %v", userID, err)
+		return nil, ErrInternalServerError
+	}
+
+	oldKey := user.AvatarKey
+	now := time.Now()
+	user.AvatarKey = key
+	user.ProfilePicture = s.avatarURL(userID, now)
+	user.UpdatedAt = now
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.deleteObject(ctx, key)
+		return nil, ErrInternalServerError
+	}
+
+	// Hapus cache user
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
+	if oldKey != "" {
+		s.deleteObject(ctx, oldKey)
+	}
+
+	return &model.AvatarResponse{
+		AvatarURL: user.ProfilePicture,
+		User:      user.ToUserResponse(),
+	}, nil
+}
+
+// processImage mendeteksi tipe gambar dari isinya, memeriksa dimensi, lalu
+// mengubah ukuran gambar. GIF disimpan sebagai PNG (frame pertama).
+func (s *avatarService) processImage(data []byte) ([]byte, string, string, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, "", "", ErrUnsupportedImage
+	}
+
+	imageConfig, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", ErrInvalidImage
+	}
+	if imageConfig.Width < 1 || imageConfig.Height < 1 ||
+		imageConfig.Width > maxAvatarSourceDimension || imageConfig.Height > maxAvatarSourceDimension ||
+		imageConfig.Width*imageConfig.Height > maxAvatarSourcePixels {
+		return nil, "", "", ErrInvalidImage
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", ErrInvalidImage
+	}
+
+	size := s.config.Storage.AvatarSize
+	resized := utils.ResizeImageToFill(img, size, size)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 90}); err != nil {
+			return nil, "", "", ErrInternalServerError
+		}
+		return buf.Bytes(), "image/jpeg", ".jpg", nil
+	}
+
+	// PNG dan GIF dapat memiliki transparansi, simpan sebagai PNG
+	if err := png.Encode(&buf, resized); err != nil {
+		return nil, "", "", ErrInternalServerError
+	}
+	return buf.Bytes(), "image/png", ".png", nil
+}
+
+// DeleteAvatar menghapus avatar yang diunggah user dan mengosongkan profile picture
+func (s *avatarService) DeleteAvatar(ctx context.Context, userID uuid.UUID) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if user.AvatarKey == "" {
+		return nil, ErrAvatarNotFound
+	}
+
+	key := user.AvatarKey
+	user.AvatarKey = ""
+	user.ProfilePicture = ""
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	// Hapus cache user
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
+	s.deleteObject(ctx, key)
+
+	userResponse := user.ToUserResponse()
+	return &userResponse, nil
+}
+
+// GetAvatarURL membuat signed URL untuk avatar user yang masih berlaku selama
+// STORAGE_SIGNED_URL_EXPIRY
+func (s *avatarService) GetAvatarURL(ctx context.Context, userID uuid.UUID) (string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return "", ErrAvatarNotFound
+		}
+		return "", ErrInternalServerError
+	}
+
+	if user.AvatarKey == "" {
+		return "", ErrAvatarNotFound
+	}
+
+	signedURL, err := s.fileStorage.SignedURL(ctx, user.AvatarKey, s.config.Storage.SignedURLExpiry)
+	if err != nil {
+		return "", ErrInternalServerError
+	}
+
+	return signedURL, nil
+}
+
+// avatarURL membuat URL tetap untuk avatar user. Parameter v berubah setiap upload
+// sehingga browser memuat ulang avatar yang baru.
+func (s *avatarService) avatarURL(userID uuid.UUID, updatedAt time.Time) string {
+	return fmt.Sprintf("%s/api/v1/avatars/%s?v=%d", s.config.Storage.PublicURL, userID, updatedAt.Unix())
+}
+
+// deleteObject menghapus object avatar tanpa menggagalkan operasi utama
+func (s *avatarService) deleteObject(ctx context.Context, key string) {
+	if err := s.fileStorage.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete avatar object %s: %v", key, err)
+	}
+}
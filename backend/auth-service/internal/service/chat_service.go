@@ -0,0 +1,717 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Errors
+var (
+	ErrChatConversationNotFound = errors.New("chat conversation not found")
+	ErrChatMessageNotFound      = errors.New("chat message not found")
+	ErrChatInvalidPeer          = errors.New("cannot start a conversation with this user")
+	ErrChatMessageEmpty         = errors.New("message body is required")
+	ErrChatMessageTooLong       = errors.New("message body is too long")
+	ErrChatInvalidMessageType   = errors.New("invalid message type")
+	ErrChatInvalidClientID      = errors.New("client message ID is too long")
+	ErrChatUnknownEvent         = errors.New("unknown chat event type")
+)
+
+// chatResubscribeDelay adalah jeda sebelum berlangganan ulang broker setelah gagal
+const chatResubscribeDelay = 5 * time.Second
+
+// chatDefaultPingInterval dipakai jika interval ping WebSocket tidak dikonfigurasi
+const chatDefaultPingInterval = 25 * time.Second
+
+// chatContactSearchLimit membatasi jumlah hasil pencarian kontak chat
+const chatContactSearchLimit = 20
+
+// ChatService interface untuk layanan chat 1:1 dan koneksi real-time
+type ChatService interface {
+	ListConversations(ctx context.Context, userID uuid.UUID, page, limit int) (*model.ChatConversationsListResponse, error)
+	StartConversation(ctx context.Context, userID, peerID uuid.UUID) (*model.ChatConversationResponse, error)
+	GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*model.ChatConversationResponse, error)
+	GetMessages(ctx context.Context, userID, conversationID uuid.UUID, before string, limit int) (*model.ChatMessagesPageResponse, error)
+	SendMessage(ctx context.Context, userID, conversationID uuid.UUID, req *model.SendChatMessageRequest) (*model.ChatMessage, error)
+	MarkDelivered(ctx context.Context, userID, conversationID, messageID uuid.UUID) error
+	MarkRead(ctx context.Context, userID, conversationID, messageID uuid.UUID) error
+	SearchContacts(ctx context.Context, userID uuid.UUID, search string) ([]model.ChatParticipant, error)
+	// Connect membuka sesi real-time user pada instance ini dan mencatat presence-nya
+	Connect(ctx context.Context, userID uuid.UUID) (*ChatSession, error)
+	// HandleClientEvent memproses satu frame dari client pada sesi
+	HandleClientEvent(ctx context.Context, session *ChatSession, event *model.ChatClientEvent) error
+	// Touch memperpanjang presence sesi, dipanggil setiap PingInterval
+	Touch(ctx context.Context, session *ChatSession)
+	// Disconnect menutup sesi dan memperbarui presence user
+	Disconnect(ctx context.Context, session *ChatSession)
+	// PingInterval mengembalikan jarak ping WebSocket
+	PingInterval() time.Duration
+	// IsAllowedOrigin memeriksa origin browser yang membuka WebSocket terhadap CORS_ALLOW_ORIGINS
+	IsAllowedOrigin(origin string) bool
+	// Run meneruskan event dari broker ke sesi lokal sampai ctx dibatalkan,
+	// lalu menutup semua sesi yang masih terbuka
+	Run(ctx context.Context)
+}
+
+// ChatSession adalah satu koneksi chat real-time yang terbuka pada instance ini.
+// Events ditutup saat sesi tidak mampu mengikuti event yang masuk atau service berhenti,
+// dan koneksi harus ditutup agar client menyambung ulang lalu memuat riwayat pesan.
+type ChatSession struct {
+	ID     string
+	UserID uuid.UUID
+	Events <-chan *model.ChatEvent
+
+	events chan *model.ChatEvent
+	closed bool
+	// peers menyimpan peserta lain per percakapan yang sudah diperiksa, hanya diakses
+	// dari goroutine pembaca frame client
+	peers map[uuid.UUID]uuid.UUID
+}
+
+// chatService implementasi ChatService
+type chatService struct {
+	chatRepo     repository.ChatRepository
+	presenceRepo repository.ChatPresenceRepository
+	broker       repository.ChatBroker
+	userRepo     repository.UserRepository
+	tokenRepo    repository.TokenRepository
+	config       *config.Config
+
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]map[*ChatSession]struct{}
+	stopped  bool
+}
+
+// NewChatService membuat instance baru ChatService
+func NewChatService(chatRepo repository.ChatRepository, presenceRepo repository.ChatPresenceRepository, broker repository.ChatBroker, userRepo repository.UserRepository, tokenRepo repository.TokenRepository, cfg *config.Config) ChatService {
+	return &chatService{
+		chatRepo:     chatRepo,
+		presenceRepo: presenceRepo,
+		broker:       broker,
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		config:       cfg,
+		sessions:     make(map[uuid.UUID]map[*ChatSession]struct{}),
+	}
+}
+
+// ListConversations mendapatkan percakapan user beserta peserta lain, pesan terakhir,
+// dan jumlah pesan belum dibaca
+func (s *chatService) ListConversations(ctx context.Context, userID uuid.UUID, page, limit int) (*model.ChatConversationsListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	conversations, total, err := s.chatRepo.ListConversations(ctx, userID, offset, limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses, err := s.toConversationResponses(ctx, userID, conversations)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &model.ChatConversationsListResponse{
+		Conversations: responses,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// StartConversation mengembalikan percakapan dengan user lain, membuatnya jika belum ada
+func (s *chatService) StartConversation(ctx context.Context, userID, peerID uuid.UUID) (*model.ChatConversationResponse, error) {
+	if userID == peerID {
+		return nil, ErrChatInvalidPeer
+	}
+
+	peer, err := s.userRepo.FindByID(ctx, peerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrChatInvalidPeer
+		}
+		return nil, ErrInternalServerError
+	}
+	if !peer.Active {
+		return nil, ErrChatInvalidPeer
+	}
+
+	conversation, err := s.chatRepo.FindOrCreateConversation(ctx, userID, peerID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses, err := s.toConversationResponses(ctx, userID, []model.ChatConversation{*conversation})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// GetConversation mendapatkan percakapan milik user
+func (s *chatService) GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*model.ChatConversationResponse, error) {
+	conversation, err := s.findConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.toConversationResponses(ctx, userID, []model.ChatConversation{*conversation})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// GetMessages mendapatkan satu halaman riwayat pesan, terbaru lebih dulu. before berisi
+// next_cursor dari halaman sebelumnya, kosong untuk halaman pertama.
+func (s *chatService) GetMessages(ctx context.Context, userID, conversationID uuid.UUID, before string, limit int) (*model.ChatMessagesPageResponse, error) {
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	if _, err := s.findConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	var cursor *model.ChatMessage
+	if before != "" {
+		cursorID, err := uuid.Parse(before)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor, err = s.chatRepo.FindMessageByID(ctx, cursorID)
+		if err != nil {
+			if errors.Is(err, repository.ErrChatMessageNotFound) {
+				return nil, ErrInvalidCursor
+			}
+			return nil, ErrInternalServerError
+		}
+		if cursor.ConversationID != conversationID {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	// Ambil satu pesan lebih banyak untuk mengetahui apakah masih ada halaman berikutnya
+	messages, err := s.chatRepo.ListMessages(ctx, conversationID, cursor, limit+1)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	response := &model.ChatMessagesPageResponse{Messages: messages}
+	if len(messages) > limit {
+		response.Messages = messages[:limit]
+		response.HasMore = true
+		response.NextCursor = response.Messages[limit-1].ID.String()
+	}
+	return response, nil
+}
+
+// SendMessage menyimpan pesan lalu mengirimnya ke kedua peserta. Pesan dengan
+// client_message_id yang sudah pernah dikirim tidak disimpan ulang.
+func (s *chatService) SendMessage(ctx context.Context, userID, conversationID uuid.UUID, req *model.SendChatMessageRequest) (*model.ChatMessage, error) {
+	messageType := req.Type
+	if messageType == "" {
+		messageType = model.ChatMessageTypeText
+	}
+	if messageType != model.ChatMessageTypeText && messageType != model.ChatMessageTypeImage && messageType != model.ChatMessageTypeFile {
+		return nil, ErrChatInvalidMessageType
+	}
+
+	body := sanitizeChatMessage(req.Body)
+	if body == "" {
+		return nil, ErrChatMessageEmpty
+	}
+	if utf8.RuneCountInString(body) > s.config.Chat.MaxMessageLength {
+		return nil, ErrChatMessageTooLong
+	}
+	if len(req.ClientMessageID) > 64 {
+		return nil, ErrChatInvalidClientID
+	}
+
+	conversation, err := s.findConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rate limit pengiriman pesan per user
+	key := fmt.Sprintf("chat:%s", userID)
+	allowed, err := s.tokenRepo.CheckRateLimit(ctx, key, s.config.Chat.RateLimitMessages, s.config.Chat.RateLimitWindow)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if !allowed {
+		return nil, ErrRateLimitExceeded
+	}
+
+	message := &model.ChatMessage{
+		ConversationID: conversation.ID,
+		SenderID:       userID,
+		RecipientID:    conversation.PeerOf(userID),
+		Type:           messageType,
+		Body:           body,
+		CreatedAt:      time.Now(),
+	}
+	if req.ClientMessageID != "" {
+		clientMessageID := req.ClientMessageID
+		message.ClientMessageID = &clientMessageID
+	}
+
+	created, err := s.chatRepo.CreateMessage(ctx, message)
+	if err != nil {
+		log.Printf("Failed to save chat message in conversation %s: %v", conversation.ID, err)
+		return nil, ErrInternalServerError
+	}
+
+	// Pesan ulang hanya dikonfirmasi ke pengirim
+	recipients := []uuid.UUID{userID}
+	if created {
+		recipients = append(recipients, message.RecipientID)
+	}
+	s.publish(ctx, model.ChatEventMessage, message, recipients...)
+
+	return message, nil
+}
+
+// MarkDelivered menandai pesan untuk user sampai messageID sudah diterima dan mengirim
+// receipt ke kedua peserta
+func (s *chatService) MarkDelivered(ctx context.Context, userID, conversationID, messageID uuid.UUID) error {
+	return s.markReceipt(ctx, userID, conversationID, messageID, model.ChatEventDelivered, s.chatRepo.MarkDelivered)
+}
+
+// MarkRead menandai pesan untuk user sampai messageID sudah dibaca dan mengirim receipt
+// ke kedua peserta
+func (s *chatService) MarkRead(ctx context.Context, userID, conversationID, messageID uuid.UUID) error {
+	return s.markReceipt(ctx, userID, conversationID, messageID, model.ChatEventRead, s.chatRepo.MarkRead)
+}
+
+// markReceipt menjalankan mark lalu mempublikasikan receipt jika ada pesan yang berubah
+func (s *chatService) markReceipt(ctx context.Context, userID, conversationID, messageID uuid.UUID, eventType string, mark func(ctx context.Context, conversationID, recipientID uuid.UUID, upTo, at time.Time) (int64, error)) error {
+	conversation, err := s.findConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	message, err := s.chatRepo.FindMessageByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, repository.ErrChatMessageNotFound) {
+			return ErrChatMessageNotFound
+		}
+		return ErrInternalServerError
+	}
+	if message.ConversationID != conversation.ID {
+		return ErrChatMessageNotFound
+	}
+
+	now := time.Now()
+	updated, err := mark(ctx, conversation.ID, userID, message.CreatedAt, now)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	if updated > 0 {
+		receipt := &model.ChatReceipt{
+			ConversationID: conversation.ID,
+			UserID:         userID,
+			MessageID:      message.ID,
+			At:             now,
+		}
+		s.publish(ctx, eventType, receipt, userID, conversation.PeerOf(userID))
+	}
+
+	return nil
+}
+
+// SearchContacts mencari user aktif lain yang dapat diajak chat berdasarkan nama atau email
+func (s *chatService) SearchContacts(ctx context.Context, userID uuid.UUID, search string) ([]model.ChatParticipant, error) {
+	active := true
+	filter := &model.UserFilter{
+		Search:    search,
+		Active:    &active,
+		SortBy:    model.UserSortName,
+		SortOrder: "asc",
+		Page:      1,
+		Limit:     chatContactSearchLimit + 1,
+	}
+
+	users, _, _, err := s.userRepo.GetAllUsers(ctx, filter)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	ids := make([]uuid.UUID, 0, len(users))
+	for i := range users {
+		if users[i].ID != userID {
+			ids = append(ids, users[i].ID)
+		}
+	}
+	if len(ids) > chatContactSearchLimit {
+		ids = ids[:chatContactSearchLimit]
+	}
+
+	return s.participants(ctx, ids, users)
+}
+
+// Connect membuka sesi real-time dan mengumumkan user online ke peserta percakapannya
+func (s *chatService) Connect(ctx context.Context, userID uuid.UUID) (*ChatSession, error) {
+	events := make(chan *model.ChatEvent, s.config.Chat.SendBuffer)
+	session := &ChatSession{
+		ID:     uuid.New().String(),
+		UserID: userID,
+		Events: events,
+		events: events,
+		peers:  make(map[uuid.UUID]uuid.UUID),
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil, ErrInternalServerError
+	}
+	if s.sessions[userID] == nil {
+		s.sessions[userID] = make(map[*ChatSession]struct{})
+	}
+	s.sessions[userID][session] = struct{}{}
+	s.mu.Unlock()
+
+	online, err := s.presenceRepo.Connect(ctx, userID, session.ID, s.presenceTTL())
+	if err != nil {
+		log.Printf("Failed to record chat presence for user %s: %v", userID, err)
+	}
+	if online {
+		s.publishPresence(ctx, &model.ChatPresence{UserID: userID, Online: true})
+	}
+
+	return session, nil
+}
+
+// HandleClientEvent memproses frame message.send, message.delivered, message.read, dan typing
+func (s *chatService) HandleClientEvent(ctx context.Context, session *ChatSession, event *model.ChatClientEvent) error {
+	switch event.Type {
+	case model.ChatClientSendMessage:
+		_, err := s.SendMessage(ctx, session.UserID, event.ConversationID, &model.SendChatMessageRequest{
+			Type:            event.MessageType,
+			Body:            event.Body,
+			ClientMessageID: event.ClientMessageID,
+		})
+		return err
+	case model.ChatClientDelivered:
+		return s.MarkDelivered(ctx, session.UserID, event.ConversationID, event.MessageID)
+	case model.ChatClientRead:
+		return s.MarkRead(ctx, session.UserID, event.ConversationID, event.MessageID)
+	case model.ChatClientTyping:
+		return s.setTyping(ctx, session, event.ConversationID, event.Typing)
+	default:
+		return ErrChatUnknownEvent
+	}
+}
+
+// setTyping meneruskan indikator mengetik ke peserta lain tanpa menyimpannya
+func (s *chatService) setTyping(ctx context.Context, session *ChatSession, conversationID uuid.UUID, typing bool) error {
+	peerID, ok := session.peers[conversationID]
+	if !ok {
+		conversation, err := s.findConversation(ctx, session.UserID, conversationID)
+		if err != nil {
+			return err
+		}
+		peerID = conversation.PeerOf(session.UserID)
+		session.peers[conversationID] = peerID
+	}
+
+	s.publish(ctx, model.ChatEventTyping, &model.ChatTyping{
+		ConversationID: conversationID,
+		UserID:         session.UserID,
+		Typing:         typing,
+	}, peerID)
+	return nil
+}
+
+// Touch memperpanjang presence sesi
+func (s *chatService) Touch(ctx context.Context, session *ChatSession) {
+	if _, err := s.presenceRepo.Connect(ctx, session.UserID, session.ID, s.presenceTTL()); err != nil {
+		log.Printf("Failed to refresh chat presence for user %s: %v", session.UserID, err)
+	}
+}
+
+// Disconnect menutup sesi dan mengumumkan user offline jika tidak ada koneksi lain
+func (s *chatService) Disconnect(ctx context.Context, session *ChatSession) {
+	s.mu.Lock()
+	s.closeSession(session)
+	s.mu.Unlock()
+
+	now := time.Now()
+	offline, err := s.presenceRepo.Disconnect(ctx, session.UserID, session.ID, now)
+	if err != nil {
+		log.Printf("Failed to remove chat presence for user %s: %v", session.UserID, err)
+	}
+	if offline {
+		s.publishPresence(ctx, &model.ChatPresence{UserID: session.UserID, Online: false, LastSeen: &now})
+	}
+}
+
+// PingInterval mengembalikan jarak ping WebSocket
+func (s *chatService) PingInterval() time.Duration {
+	if s.config.Chat.PingInterval <= 0 {
+		return chatDefaultPingInterval
+	}
+	return s.config.Chat.PingInterval
+}
+
+// IsAllowedOrigin memeriksa origin browser yang membuka WebSocket. WebSocket tidak dilindungi
+// CORS sehingga origin harus diperiksa agar halaman lain tidak dapat memakai cookie user.
+func (s *chatService) IsAllowedOrigin(origin string) bool {
+	for _, allowed := range s.config.Server.CorsAllowOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run berlangganan broker dan meneruskan setiap event ke sesi penerima yang terbuka pada
+// instance ini. Langganan yang gagal atau terputus dicoba lagi setelah jeda.
+func (s *chatService) Run(ctx context.Context) {
+	defer s.stop()
+
+	for ctx.Err() == nil {
+		events, err := s.broker.Subscribe(ctx)
+		if err != nil {
+			log.Printf("Failed to subscribe to chat broker: %v", err)
+		} else {
+			for event := range events {
+				s.dispatch(event)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(chatResubscribeDelay):
+		}
+	}
+}
+
+// dispatch mengirim event ke semua sesi milik penerima tanpa menunggu. Sesi yang buffernya
+// penuh ditutup agar client menyambung ulang dan memuat riwayat, bukan kehilangan pesan diam-diam.
+// Event sessions.revoked menutup semua sesi penerima setelah event tersebut diantrikan.
+func (s *chatService) dispatch(event *model.ChatEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := event.Type == model.ChatEventSessionsRevoked
+	for _, userID := range event.Recipients {
+		for session := range s.sessions[userID] {
+			select {
+			case session.events <- event:
+				if revoked {
+					s.closeSession(session)
+				}
+			default:
+				log.Printf("Chat session %s of user %s is too slow, closing", session.ID, userID)
+				s.closeSession(session)
+			}
+		}
+	}
+}
+
+// closeSession melepas sesi dari daftar dan menutup channel event-nya, s.mu harus dikunci
+func (s *chatService) closeSession(session *ChatSession) {
+	if session.closed {
+		return
+	}
+	session.closed = true
+	close(session.events)
+
+	delete(s.sessions[session.UserID], session)
+	if len(s.sessions[session.UserID]) == 0 {
+		delete(s.sessions, session.UserID)
+	}
+}
+
+// stop menutup semua sesi agar koneksi WebSocket selesai saat server berhenti
+func (s *chatService) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for _, sessions := range s.sessions {
+		for session := range sessions {
+			s.closeSession(session)
+		}
+	}
+}
+
+// publish mempublikasikan event ke broker. Gagal publikasi hanya dicatat di log karena
+// pesan tetap tersimpan dan dapat dimuat melalui riwayat.
+func (s *chatService) publish(ctx context.Context, eventType string, data interface{}, recipients ...uuid.UUID) {
+	event, err := model.NewChatEvent(eventType, data, recipients...)
+	if err != nil {
+		log.Printf("Failed to encode chat event %s: %v", eventType, err)
+		return
+	}
+	if err := s.broker.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish chat event %s: %v", eventType, err)
+	}
+}
+
+// publishPresence mengumumkan perubahan presence user ke semua peserta percakapannya
+func (s *chatService) publishPresence(ctx context.Context, presence *model.ChatPresence) {
+	peers, err := s.chatRepo.FindPeerIDs(ctx, presence.UserID)
+	if err != nil {
+		log.Printf("Failed to find chat peers of user %s: %v", presence.UserID, err)
+		return
+	}
+	if len(peers) == 0 {
+		return
+	}
+	s.publish(ctx, model.ChatEventPresence, presence, peers...)
+}
+
+// presenceTTL mengembalikan lama koneksi dianggap online, minimal dua kali interval ping
+func (s *chatService) presenceTTL() time.Duration {
+	if ttl := s.config.Chat.PresenceTTL; ttl >= 2*s.PingInterval() {
+		return ttl
+	}
+	return 2 * s.PingInterval()
+}
+
+// findConversation mencari percakapan yang diikuti user. Percakapan milik user lain
+// diperlakukan seperti tidak ada.
+func (s *chatService) findConversation(ctx context.Context, userID, conversationID uuid.UUID) (*model.ChatConversation, error) {
+	conversation, err := s.chatRepo.FindConversationByID(ctx, conversationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrChatConversationNotFound) {
+			return nil, ErrChatConversationNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+	if !conversation.HasParticipant(userID) {
+		return nil, ErrChatConversationNotFound
+	}
+	return conversation, nil
+}
+
+// toConversationResponses melengkapi percakapan dengan data peserta lain, pesan terakhir,
+// dan jumlah pesan belum dibaca menggunakan query batch
+func (s *chatService) toConversationResponses(ctx context.Context, userID uuid.UUID, conversations []model.ChatConversation) ([]model.ChatConversationResponse, error) {
+	conversationIDs := make([]uuid.UUID, len(conversations))
+	peerIDs := make([]uuid.UUID, len(conversations))
+	var lastMessageIDs []uuid.UUID
+	for i := range conversations {
+		conversationIDs[i] = conversations[i].ID
+		peerIDs[i] = conversations[i].PeerOf(userID)
+		if conversations[i].LastMessageID != nil {
+			lastMessageIDs = append(lastMessageIDs, *conversations[i].LastMessageID)
+		}
+	}
+
+	participants, err := s.participants(ctx, peerIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+	participantsByID := make(map[uuid.UUID]model.ChatParticipant, len(participants))
+	for _, participant := range participants {
+		participantsByID[participant.ID] = participant
+	}
+
+	lastMessages, err := s.chatRepo.FindMessagesByIDs(ctx, lastMessageIDs)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	lastMessagesByID := make(map[uuid.UUID]*model.ChatMessage, len(lastMessages))
+	for i := range lastMessages {
+		lastMessagesByID[lastMessages[i].ID] = &lastMessages[i]
+	}
+
+	unread, err := s.chatRepo.CountUnreadByConversation(ctx, userID, conversationIDs)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses := make([]model.ChatConversationResponse, len(conversations))
+	for i := range conversations {
+		conversation := &conversations[i]
+		response := model.ChatConversationResponse{
+			ID:            conversation.ID,
+			Participant:   participantsByID[peerIDs[i]],
+			UnreadCount:   unread[conversation.ID],
+			LastMessageAt: conversation.LastMessageAt,
+			CreatedAt:     conversation.CreatedAt,
+		}
+		if conversation.LastMessageID != nil {
+			response.LastMessage = lastMessagesByID[*conversation.LastMessageID]
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+// participants membuat data peserta beserta presence untuk daftar user ID sesuai urutannya.
+// users yang sudah dimuat dapat diberikan agar tidak diambil ulang dari database. User yang
+// sudah dihapus tetap dikembalikan tanpa nama.
+func (s *chatService) participants(ctx context.Context, ids []uuid.UUID, users []model.User) ([]model.ChatParticipant, error) {
+	if users == nil {
+		var err error
+		users, err = s.userRepo.FindByIDs(ctx, ids)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+	}
+	usersByID := make(map[uuid.UUID]*model.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+
+	presence, err := s.presenceRepo.GetPresence(ctx, ids)
+	if err != nil {
+		// Presence tidak tersedia bukan alasan untuk menggagalkan daftar percakapan
+		log.Printf("Failed to get chat presence: %v", err)
+		presence = map[uuid.UUID]model.ChatPresence{}
+	}
+
+	participants := make([]model.ChatParticipant, len(ids))
+	for i, id := range ids {
+		participant := model.ChatParticipant{
+			ID:       id,
+			Online:   presence[id].Online,
+			LastSeen: presence[id].LastSeen,
+		}
+		if user, ok := usersByID[id]; ok {
+			participant.Name = user.Name
+			participant.ProfilePicture = user.ProfilePicture
+		}
+		participants[i] = participant
+	}
+	return participants, nil
+}
+
+// sanitizeChatMessage membuang karakter kontrol kecuali baris baru dan tab, lalu
+// memangkas spasi di awal dan akhir pesan. Isi pesan tidak di-escape karena client
+// harus menampilkannya sebagai teks.
+func sanitizeChatMessage(body string) string {
+	body = strings.Map(func(r rune) rune {
+		if (r < 32 && r != '\n' && r != '\t') || r == 127 {
+			return -1
+		}
+		return r
+	}, body)
+	return strings.TrimSpace(body)
+}
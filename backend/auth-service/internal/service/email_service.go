@@ -0,0 +1,375 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// emailTemplateFS berisi template email per bahasa. Setiap template terdiri dari
+// <nama>.txt.tmpl yang juga mendefinisikan "subject", dan <nama>.html.tmpl yang
+// mendefinisikan "content" untuk dirender di dalam layout.html.tmpl bahasa yang sama.
+//
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// emailFallbackLocale dipakai jika bahasa user maupun bahasa default tidak tersedia
+const emailFallbackLocale = "en"
+
+// emailDequeueTimeout adalah lama worker menunggu email baru sebelum memeriksa ctx lagi
+const emailDequeueTimeout = 2 * time.Second
+
+// emailPromoteInterval adalah jarak pemindahan email retry yang jatuh tempo ke antrean
+const emailPromoteInterval = time.Second
+
+// emailPromoteBatchSize membatasi jumlah email retry yang dipindahkan per pemeriksaan
+const emailPromoteBatchSize = 100
+
+// emailRequeueInterval adalah jarak pemeriksaan email yang tertinggal di list processing
+const emailRequeueInterval = time.Minute
+
+// emailQueueErrorDelay adalah jeda worker setelah antrean tidak dapat dibaca
+const emailQueueErrorDelay = 5 * time.Second
+
+// emailTimeFormat adalah format waktu yang ditampilkan pada email
+const emailTimeFormat = "2006-01-02 15:04 MST"
+
+// EmailService interface untuk pengiriman email transaksional dari template
+type EmailService interface {
+	// Send merender template lalu memasukkan email ke antrean. Pengiriman dilakukan oleh
+	// worker di latar belakang sehingga pemanggil tidak menunggu server SMTP.
+	Send(ctx context.Context, req *model.EmailRequest) error
+	// Run menjalankan worker pengiriman dan retry sampai ctx dibatalkan
+	Run(ctx context.Context)
+}
+
+// emailTemplate adalah satu template email yang sudah di-parse untuk satu bahasa
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// emailService implementasi EmailService
+type emailService struct {
+	mailer    repository.Mailer
+	queue     repository.MailQueue
+	config    *config.Config
+	templates map[string]map[string]*emailTemplate // bahasa -> nama template
+}
+
+// NewEmailService membuat instance baru EmailService dan mem-parse semua template email
+func NewEmailService(mailer repository.Mailer, queue repository.MailQueue, cfg *config.Config) (EmailService, error) {
+	templates, err := loadEmailTemplates()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := templates[emailFallbackLocale]; !ok {
+		return nil, fmt.Errorf("missing email templates for locale %s", emailFallbackLocale)
+	}
+
+	return &emailService{
+		mailer:    mailer,
+		queue:     queue,
+		config:    cfg,
+		templates: templates,
+	}, nil
+}
+
+// loadEmailTemplates mem-parse semua template email dari emailTemplateFS
+func loadEmailTemplates() (map[string]map[string]*emailTemplate, error) {
+	root, err := fs.Sub(emailTemplateFS, "templates/email")
+	if err != nil {
+		return nil, err
+	}
+
+	locales, err := fs.ReadDir(root, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email templates: %w", err)
+	}
+
+	templates := make(map[string]map[string]*emailTemplate)
+	for _, locale := range locales {
+		if !locale.IsDir() {
+			continue
+		}
+
+		textFiles, err := fs.Glob(root, locale.Name()+"/*.txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+
+		templates[locale.Name()] = make(map[string]*emailTemplate)
+		for _, textFile := range textFiles {
+			name := strings.TrimSuffix(textFile[len(locale.Name())+1:], ".txt.tmpl")
+
+			text, err := texttemplate.New(name).Option("missingkey=error").ParseFS(root, textFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse email template %s: %w", textFile, err)
+			}
+			if text.Lookup("subject") == nil {
+				return nil, fmt.Errorf("email template %s does not define a subject", textFile)
+			}
+
+			html, err := htmltemplate.New("layout.html.tmpl").Option("missingkey=error").
+				ParseFS(root, locale.Name()+"/layout.html.tmpl", locale.Name()+"/"+name+".html.tmpl")
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse email template %s/%s.html.tmpl: %w", locale.Name(), name, err)
+			}
+
+			templates[locale.Name()][name] = &emailTemplate{text: text.Lookup(name + ".txt.tmpl"), html: html}
+		}
+	}
+
+	return templates, nil
+}
+
+// Send merender email sesuai bahasa penerima lalu memasukkannya ke antrean
+func (s *emailService) Send(ctx context.Context, req *model.EmailRequest) error {
+	message, err := s.render(req)
+	if err != nil {
+		log.Printf("Failed to render email template %s: %v", req.Template, err)
+		return ErrInternalServerError
+	}
+
+	job := &model.EmailJob{
+		ID:         uuid.New(),
+		Message:    *message,
+		EnqueuedAt: time.Now(),
+	}
+	job.Message.ID = job.ID.String()
+
+	if err := s.queue.Enqueue(ctx, job); err != nil {
+		log.Printf("Failed to enqueue %s email to %s: %v", req.Template, req.To, err)
+		return ErrEmailDeliveryFailed
+	}
+	return nil
+}
+
+// render merender subject, versi teks, dan versi HTML email
+func (s *emailService) render(req *model.EmailRequest) (*model.EmailMessage, error) {
+	locale := s.resolveLocale(req.Locale)
+	tmpl, ok := s.templates[locale][req.Template]
+	if !ok {
+		// Template belum diterjemahkan ke bahasa ini
+		locale = emailFallbackLocale
+		tmpl, ok = s.templates[locale][req.Template]
+		if !ok {
+			return nil, fmt.Errorf("unknown email template: %s", req.Template)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		name = req.To
+	}
+	data := map[string]interface{}{
+		"AppName": s.config.Mail.AppName,
+		"Name":    name,
+		"Email":   req.To,
+	}
+	for key, value := range req.Data {
+		data[key] = value
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	data["Subject"] = strings.TrimSpace(subject.String())
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return nil, err
+	}
+
+	return &model.EmailMessage{
+		To:       req.To,
+		ToName:   req.Name,
+		Subject:  strings.TrimSpace(subject.String()),
+		Text:     text.String(),
+		HTML:     html.String(),
+		Template: req.Template,
+		Locale:   locale,
+	}, nil
+}
+
+// resolveLocale memilih bahasa template dari BCP 47 language tag user, misalnya id-ID
+// menjadi id, lalu bahasa default, lalu emailFallbackLocale
+func (s *emailService) resolveLocale(locale string) string {
+	for _, candidate := range []string{locale, s.config.Mail.DefaultLocale} {
+		language := strings.ToLower(strings.TrimSpace(candidate))
+		if i := strings.IndexAny(language, "-_"); i >= 0 {
+			language = language[:i]
+		}
+		if _, ok := s.templates[language]; ok {
+			return language
+		}
+	}
+	return emailFallbackLocale
+}
+
+// Run menjalankan worker pengiriman dan pemindahan email retry yang jatuh tempo.
+// Run selesai setelah ctx dibatalkan dan semua email yang sedang dikirim selesai.
+func (s *emailService) Run(ctx context.Context) {
+	workers := s.config.Mail.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+
+	// Email yang tertinggal di list processing saat instance berhenti mendadak dikembalikan
+	// ke antrean saat startup dan secara berkala
+	s.requeueStale(ctx)
+	requeueTicker := time.NewTicker(emailRequeueInterval)
+	defer requeueTicker.Stop()
+
+	ticker := time.NewTicker(emailPromoteInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-requeueTicker.C:
+			s.requeueStale(ctx)
+		case <-ticker.C:
+			if _, err := s.queue.PromoteDue(ctx, time.Now(), emailPromoteBatchSize); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to promote scheduled emails: %v", err)
+			}
+		}
+	}
+}
+
+// requeueStale mengembalikan email yang mulai dikirim lebih lama dari dua kali batas waktu
+// pengiriman ke antrean, karena pengiriman yang masih berjalan tidak mungkin selama itu
+func (s *emailService) requeueStale(ctx context.Context) {
+	moved, err := s.queue.RequeueStale(ctx, time.Now().Add(-2*s.sendTimeout()))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to requeue stale emails: %v", err)
+		}
+		return
+	}
+	if moved > 0 {
+		log.Printf("Requeued %d emails left in processing", moved)
+	}
+}
+
+// work mengambil email dari antrean dan mengirimnya satu per satu sampai ctx dibatalkan
+func (s *emailService) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.queue.Dequeue(ctx, emailDequeueTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to read email queue: %v", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(emailQueueErrorDelay):
+			}
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		s.deliver(job)
+	}
+}
+
+// deliver mengirim satu email. Email yang gagal dijadwalkan ulang dengan exponential
+// backoff, dan setelah MaxAttempts percobaan disimpan ke dead list tanpa isinya agar
+// link bertoken tidak tersimpan lebih lama dari masa berlakunya.
+func (s *emailService) deliver(job *model.EmailJob) {
+	// Pengiriman yang sudah dimulai tidak dibatalkan saat service berhenti
+	ctx, cancel := context.WithTimeout(context.Background(), s.sendTimeout())
+	defer cancel()
+
+	job.Attempts++
+	err := s.mailer.Send(ctx, &job.Message)
+	if err == nil {
+		if err := s.queue.Ack(ctx, job); err != nil {
+			log.Printf("Failed to ack email %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	job.LastError = truncateString(err.Error(), 500)
+	if job.Attempts >= s.config.Mail.MaxAttempts {
+		log.Printf("Giving up %s email %s to %s after %d attempts: %v", job.Message.Template, job.ID, job.Message.To, job.Attempts, err)
+		job.Message.Text = ""
+		job.Message.HTML = ""
+		if err := s.queue.Bury(ctx, job); err != nil {
+			log.Printf("Failed to bury email %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	delay := s.retryDelay(job.Attempts)
+	log.Printf("Failed to send %s email %s to %s (attempt %d), retrying in %s: %v", job.Message.Template, job.ID, job.Message.To, job.Attempts, delay, err)
+	if err := s.queue.Schedule(ctx, job, time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to schedule retry of email %s: %v", job.ID, err)
+	}
+}
+
+// sendTimeout mengembalikan batas waktu satu pengiriman termasuk penyimpanan hasilnya
+func (s *emailService) sendTimeout() time.Duration {
+	if s.config.Mail.SMTPTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return s.config.Mail.SMTPTimeout + 5*time.Second
+}
+
+// retryDelay menghitung jeda exponential backoff sebelum percobaan berikutnya
+func (s *emailService) retryDelay(attempts int) time.Duration {
+	delay := s.config.Mail.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.config.Mail.RetryMaxDelay {
+			return s.config.Mail.RetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// tokenLink menambahkan token sebagai query parameter pada URL frontend
+func tokenLink(baseURL, token string) string {
+	separator := "?"
+	if strings.Contains(baseURL, "?") {
+		separator = "&"
+	}
+	return baseURL + separator + "token=" + url.QueryEscape(token)
+}
+
+// formatEmailTime memformat waktu pada zona waktu user, atau UTC jika zona waktu tidak dikenal
+func formatEmailTime(t time.Time, timezone string) string {
+	if location, err := time.LoadLocation(timezone); err == nil && timezone != "" {
+		return t.In(location).Format(emailTimeFormat)
+	}
+	return t.UTC().Format(emailTimeFormat)
+}
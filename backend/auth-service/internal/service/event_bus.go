@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+)
+
+// outboxClaimLease adalah lama event yang diklaim relay tidak diambil instance lain
+const outboxClaimLease = 2 * time.Minute
+
+// outboxPurgeBatchSize membatasi jumlah event yang dihapus per query purge
+const outboxPurgeBatchSize = 1000
+
+// EventBus interface untuk publikasi domain event melalui transactional outbox
+type EventBus interface {
+	// Publish menulis event ke outbox. Jika ctx berasal dari TransactionManager.WithinTransaction,
+	// event ikut di-commit atau di-rollback bersama perubahan state yang memicunya.
+	Publish(ctx context.Context, events ...*model.DomainEvent) error
+	// RelayDueEvents mengirim event outbox yang jatuh tempo ke semua sink, dipanggil berkala oleh relay
+	RelayDueEvents(ctx context.Context) (int, error)
+	// PurgePublishedEvents menghapus event yang sudah terkirim dan melewati masa retensi
+	PurgePublishedEvents(ctx context.Context) (int64, error)
+}
+
+// eventBus implementasi EventBus
+type eventBus struct {
+	outboxRepo repository.OutboxRepository
+	sinks      []repository.EventSink
+	config     *config.Config
+}
+
+// NewEventBus membuat instance baru EventBus yang me-relay event ke sinks
+func NewEventBus(outboxRepo repository.OutboxRepository, sinks []repository.EventSink, cfg *config.Config) EventBus {
+	return &eventBus{
+		outboxRepo: outboxRepo,
+		sinks:      sinks,
+		config:     cfg,
+	}
+}
+
+// Publish menulis event ke outbox
+func (b *eventBus) Publish(ctx context.Context, events ...*model.DomainEvent) error {
+	rows := make([]model.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		row, err := event.ToOutboxEvent()
+		if err != nil {
+			return err
+		}
+		rows = append(rows, *row)
+	}
+	return b.outboxRepo.Create(ctx, rows)
+}
+
+// RelayDueEvents mengklaim event yang jatuh tempo lalu mengirimnya ke setiap sink sesuai
+// urutan terjadinya. Sink yang sudah menerima event tidak dikirimi lagi saat retry, tetapi
+// event dapat tetap terkirim ulang jika proses berhenti sebelum hasil relay tersimpan.
+func (b *eventBus) RelayDueEvents(ctx context.Context) (int, error) {
+	events, err := b.outboxRepo.ClaimDueEvents(ctx, time.Now(), outboxClaimLease, b.config.Events.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		b.relay(ctx, &events[i])
+	}
+
+	return len(events), nil
+}
+
+// relay mengirim satu event ke sink yang belum menerimanya lalu menyimpan hasilnya
+func (b *eventBus) relay(ctx context.Context, row *model.OutboxEvent) {
+	now := time.Now()
+	row.Attempts++
+
+	var failures []string
+	event, err := row.ToDomainEvent()
+	if err != nil {
+		failures = append(failures, "decode: "+err.Error())
+	} else {
+		published := strings.Fields(row.PublishedSinks)
+		for _, sink := range b.sinks {
+			if containsString(published, sink.Name()) {
+				continue
+			}
+			if err := sink.Publish(ctx, event); err != nil {
+				failures = append(failures, sink.Name()+": "+err.Error())
+				continue
+			}
+			published = append(published, sink.Name())
+		}
+		row.PublishedSinks = strings.Join(published, " ")
+	}
+
+	if len(failures) == 0 {
+		row.Status = model.OutboxEventPublished
+		row.PublishedAt = &now
+		row.NextAttemptAt = nil
+		row.LastError = ""
+	} else {
+		next := now.Add(b.retryDelay(row.Attempts))
+		row.Status = model.OutboxEventFailed
+		row.NextAttemptAt = &next
+		row.LastError = truncateString(strings.Join(failures, "; "), 1000)
+		log.Printf("Failed to relay outbox event %s (%s), attempt %d: %s", row.ID, row.EventType, row.Attempts, row.LastError)
+	}
+
+	if err := b.outboxRepo.SaveRelayResult(ctx, row); err != nil {
+		log.Printf("Failed to save outbox relay result %s: %v", row.ID, err)
+	}
+}
+
+// PurgePublishedEvents menghapus event terkirim yang lebih lama dari masa retensi
+func (b *eventBus) PurgePublishedEvents(ctx context.Context) (int64, error) {
+	if b.config.Events.Retention <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().Add(-b.config.Events.Retention)
+	var purged int64
+	for {
+		deleted, err := b.outboxRepo.PurgePublished(ctx, before, outboxPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += deleted
+		if deleted < outboxPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// retryDelay menghitung jeda exponential backoff sebelum relay berikutnya
+func (b *eventBus) retryDelay(attempts int) time.Duration {
+	delay := b.config.Events.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= b.config.Events.RetryMaxDelay {
+			return b.config.Events.RetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// webhookEventSink implementasi repository.EventSink yang meneruskan event ke subscription webhook
+type webhookEventSink struct {
+	webhookService WebhookService
+}
+
+// NewWebhookEventSink membuat sink relay outbox untuk webhook
+func NewWebhookEventSink(webhookService WebhookService) repository.EventSink {
+	return &webhookEventSink{webhookService: webhookService}
+}
+
+// Name mengembalikan nama sink
+func (s *webhookEventSink) Name() string {
+	return "webhook"
+}
+
+// Publish mengantrekan delivery webhook untuk event. Pengiriman HTTP dan retry-nya
+// ditangani dispatcher webhook.
+func (s *webhookEventSink) Publish(ctx context.Context, event *model.DomainEvent) error {
+	return s.webhookService.Publish(ctx, event)
+}
+
+// userEventData membuat data domain event dari user
+func userEventData(user *model.User) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":  user.ID,
+		"email":    user.Email,
+		"name":     user.Name,
+		"role":     user.Role,
+		"role_id":  user.RoleID,
+		"provider": user.Provider,
+	}
+}
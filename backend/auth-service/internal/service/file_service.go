@@ -0,0 +1,906 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// fileSniffSize adalah jumlah byte awal file yang dibaca untuk mendeteksi tipe
+// dan dimensi gambar tanpa membaca seluruh file ke memori
+const fileSniffSize = 64 << 10
+
+// maxFolderPathLength sama dengan panjang kolom folders.path
+const maxFolderPathLength = 512
+
+// fileExtensionPattern membatasi ekstensi yang ikut disimpan di key object
+var fileExtensionPattern = regexp.MustCompile(`^\.[a-z0-9]{1,10}$`)
+
+// File errors
+var (
+	ErrFileNotFound         = errors.New("file not found")
+	ErrFolderNotFound       = errors.New("folder not found")
+	ErrFolderExists         = errors.New("folder already exists")
+	ErrFolderNotEmpty       = errors.New("folder is not empty")
+	ErrInvalidFileName      = errors.New("invalid file name")
+	ErrInvalidFolderPath    = errors.New("invalid folder path")
+	ErrFileTooLarge         = errors.New("file is too large")
+	ErrTooManyFiles         = errors.New("too many files in one upload")
+	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	ErrFileAccessDenied     = errors.New("file access denied")
+)
+
+// FileUpload adalah satu file yang diunggah beserta nama asli dan ukurannya
+type FileUpload struct {
+	Name    string
+	Size    int64
+	Content io.Reader
+}
+
+// FileService interface untuk pengelolaan file dan folder milik user
+type FileService interface {
+	UploadFiles(ctx context.Context, userID uuid.UUID, folder string, uploads []FileUpload) ([]model.FileResponse, error)
+	GetFiles(ctx context.Context, userID uuid.UUID, filter *model.FileFilter) (*model.FilesListResponse, error)
+	GetFile(ctx context.Context, userID, fileID uuid.UUID) (*model.FileResponse, error)
+	GetFileURL(ctx context.Context, userID, fileID uuid.UUID) (*model.FileURLResponse, error)
+	DownloadFile(ctx context.Context, userID, fileID uuid.UUID) (*model.File, io.ReadCloser, error)
+	RenameFile(ctx context.Context, userID, fileID uuid.UUID, name string) (*model.FileResponse, error)
+	MoveFile(ctx context.Context, userID, fileID uuid.UUID, folder string) (*model.FileResponse, error)
+	DeleteFiles(ctx context.Context, userID uuid.UUID, fileIDs []uuid.UUID) error
+
+	CreateFolder(ctx context.Context, userID uuid.UUID, req *model.CreateFolderRequest) (*model.FolderResponse, error)
+	GetFolders(ctx context.Context, userID uuid.UUID, parent string) ([]model.FolderResponse, error)
+	RenameFolder(ctx context.Context, userID, folderID uuid.UUID, name string) (*model.FolderResponse, error)
+	DeleteFolder(ctx context.Context, userID, folderID uuid.UUID, force bool) error
+
+	GetStorageStats(ctx context.Context, userID uuid.UUID) (*model.StorageStatsResponse, error)
+	GetStorageQuota(ctx context.Context, userID uuid.UUID) (*model.StorageQuotaResponse, error)
+	UpdateStorageQuota(ctx context.Context, userID uuid.UUID, req *model.UpdateStorageQuotaRequest) (*model.StorageQuotaResponse, error)
+}
+
+// fileService implementasi FileService
+type fileService struct {
+	fileRepo    repository.FileRepository
+	userRepo    repository.UserRepository
+	roleService RoleService
+	fileStorage repository.FileStorage
+	config      *config.Config
+}
+
+// NewFileService membuat instance baru FileService
+func NewFileService(fileRepo repository.FileRepository, userRepo repository.UserRepository, roleService RoleService, fileStorage repository.FileStorage, cfg *config.Config) FileService {
+	return &fileService{
+		fileRepo:    fileRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+		fileStorage: fileStorage,
+		config:      cfg,
+	}
+}
+
+// UploadFiles menyimpan satu atau beberapa file ke folder (dibuat jika belum ada).
+// Kuota dipesan sekaligus untuk semua file; jika salah satu file gagal, file yang
+// sudah tersimpan dihapus kembali sehingga upload bersifat all-or-nothing.
+func (s *fileService) UploadFiles(ctx context.Context, userID uuid.UUID, folder string, uploads []FileUpload) ([]model.FileResponse, error) {
+	if err := s.authorize(ctx, userID, "create"); err != nil {
+		return nil, err
+	}
+
+	if len(uploads) == 0 || len(uploads) > s.config.Storage.MaxUploadFiles {
+		return nil, ErrTooManyFiles
+	}
+
+	var totalSize int64
+	for i := range uploads {
+		if uploads[i].Size > s.config.Storage.FileMaxSize {
+			return nil, ErrFileTooLarge
+		}
+		name, err := sanitizeFileName(uploads[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		uploads[i].Name = name
+		totalSize += uploads[i].Size
+	}
+
+	folderPath, err := normalizeFolderPath(folder)
+	if err != nil {
+		return nil, err
+	}
+	targetFolder, err := s.ensureFolder(ctx, userID, folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.fileRepo.ReserveQuota(ctx, userID, totalSize, s.config.Storage.DefaultQuota); err != nil {
+		if errors.Is(err, repository.ErrQuotaExceeded) {
+			return nil, ErrStorageQuotaExceeded
+		}
+		return nil, ErrInternalServerError
+	}
+
+	files := make([]model.File, 0, len(uploads))
+	var storedSize int64
+	for _, upload := range uploads {
+		file, err := s.storeFile(ctx, userID, targetFolder, upload)
+		if err != nil {
+			// Kuota file yang sudah tersimpan dikembalikan saat metadata-nya dihapus
+			s.removeFiles(ctx, files)
+			s.releaseQuota(ctx, userID, totalSize-storedSize)
+			return nil, err
+		}
+		files = append(files, *file)
+		storedSize += file.Size
+	}
+
+	return s.fileResponses(ctx, files)
+}
+
+// storeFile menulis isi file ke file storage sambil menghitung checksum, lalu
+// menyimpan metadata-nya. Tipe file diambil dari ekstensi, atau dideteksi dari isi
+// file jika ekstensinya tidak dikenal.
+func (s *fileService) storeFile(ctx context.Context, ownerID uuid.UUID, folder *model.Folder, upload FileUpload) (*model.File, error) {
+	reader := bufio.NewReaderSize(io.LimitReader(upload.Content, upload.Size), fileSniffSize)
+	head, err := reader.Peek(fileSniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, ErrInternalServerError
+	}
+
+	extension := strings.ToLower(filepath.Ext(upload.Name))
+	mimeType, _, _ := mime.ParseMediaType(mime.TypeByExtension(extension))
+	if mimeType == "" {
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+
+	file := &model.File{
+		ID:           uuid.New(),
+		OwnerID:      ownerID,
+		Name:         upload.Name,
+		OriginalName: upload.Name,
+		MimeType:     mimeType,
+		Size:         upload.Size,
+	}
+	if folder != nil {
+		file.FolderID = &folder.ID
+	}
+
+	if strings.HasPrefix(mimeType, "image/") {
+		if imageConfig, _, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+			file.Width = &imageConfig.Width
+			file.Height = &imageConfig.Height
+		}
+	}
+
+	if !fileExtensionPattern.MatchString(extension) {
+		extension = ""
+	}
+	file.StorageKey = fmt.Sprintf("files/%s/%s%s", ownerID, file.ID, extension)
+
+	hasher := sha256.New()
+	if err := s.fileStorage.Put(ctx, file.StorageKey, io.TeeReader(reader, hasher), upload.Size, storageContentType(mimeType)); err != nil {
+		log.Printf("Failed to store file %s: %v", file.StorageKey, err)
+		return nil, ErrInternalServerError
+	}
+	file.Checksum = hex.EncodeToString(hasher.Sum(nil))
+
+	if err := s.fileRepo.CreateFile(ctx, file); err != nil {
+		s.deleteObject(ctx, file.StorageKey)
+		return nil, ErrInternalServerError
+	}
+
+	return file, nil
+}
+
+// GetFiles mendapatkan daftar file milik user dengan filter dan pagination
+func (s *fileService) GetFiles(ctx context.Context, userID uuid.UUID, filter *model.FileFilter) (*model.FilesListResponse, error) {
+	if err := s.authorize(ctx, userID, "read"); err != nil {
+		return nil, err
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	if filter.Folder != "" {
+		folderPath, err := normalizeFolderPath(filter.Folder)
+		if err != nil {
+			return nil, err
+		}
+		filter.Folder = folderPath
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	files, total, err := s.fileRepo.ListFiles(ctx, userID, filter, offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	fileResponses, err := s.fileResponses(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.FilesListResponse{
+		Files:      fileResponses,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetFile mendapatkan detail file
+func (s *fileService) GetFile(ctx context.Context, userID, fileID uuid.UUID) (*model.FileResponse, error) {
+	file, err := s.findFile(ctx, userID, fileID, "read")
+	if err != nil {
+		return nil, err
+	}
+
+	return s.fileResponse(ctx, file)
+}
+
+// GetFileURL membuat signed URL file yang berlaku selama STORAGE_SIGNED_URL_EXPIRY
+func (s *fileService) GetFileURL(ctx context.Context, userID, fileID uuid.UUID) (*model.FileURLResponse, error) {
+	file, err := s.findFile(ctx, userID, fileID, "read")
+	if err != nil {
+		return nil, err
+	}
+
+	signedURL, err := s.fileStorage.SignedURL(ctx, file.StorageKey, s.config.Storage.SignedURLExpiry)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return &model.FileURLResponse{
+		URL:       signedURL,
+		ExpiresAt: time.Now().Add(s.config.Storage.SignedURLExpiry),
+	}, nil
+}
+
+// DownloadFile membuka isi file dan mencatat jumlah download
+func (s *fileService) DownloadFile(ctx context.Context, userID, fileID uuid.UUID) (*model.File, io.ReadCloser, error) {
+	file, err := s.findFile(ctx, userID, fileID, "read")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.fileStorage.Open(ctx, file.StorageKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrObjectNotFound) {
+			return nil, nil, ErrFileNotFound
+		}
+		return nil, nil, ErrInternalServerError
+	}
+
+	if err := s.fileRepo.RecordDownload(ctx, file.ID, time.Now()); err != nil {
+		log.Printf("Failed to record download of file %s: %v", file.ID, err)
+	}
+
+	return file, content, nil
+}
+
+// RenameFile mengubah nama tampilan file. Nama asli dan isi file tidak berubah.
+func (s *fileService) RenameFile(ctx context.Context, userID, fileID uuid.UUID, name string) (*model.FileResponse, error) {
+	file, err := s.findFile(ctx, userID, fileID, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = sanitizeFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file.Name = name
+	file.UpdatedAt = time.Now()
+	if err := s.fileRepo.UpdateFile(ctx, file); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return s.fileResponse(ctx, file)
+}
+
+// MoveFile memindahkan file ke folder lain milik pemilik file (dibuat jika belum ada)
+func (s *fileService) MoveFile(ctx context.Context, userID, fileID uuid.UUID, folder string) (*model.FileResponse, error) {
+	file, err := s.findFile(ctx, userID, fileID, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	folderPath, err := normalizeFolderPath(folder)
+	if err != nil {
+		return nil, err
+	}
+	targetFolder, err := s.ensureFolder(ctx, file.OwnerID, folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	file.FolderID = nil
+	if targetFolder != nil {
+		file.FolderID = &targetFolder.ID
+	}
+	file.UpdatedAt = time.Now()
+
+	if err := s.fileRepo.UpdateFile(ctx, file); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return s.fileResponse(ctx, file)
+}
+
+// DeleteFiles menghapus beberapa file sekaligus. Jika salah satu file tidak ditemukan
+// atau tidak dapat diakses, tidak ada file yang dihapus.
+func (s *fileService) DeleteFiles(ctx context.Context, userID uuid.UUID, fileIDs []uuid.UUID) error {
+	if err := s.authorize(ctx, userID, "delete"); err != nil {
+		return err
+	}
+
+	files, err := s.fileRepo.FindFilesByIDs(ctx, fileIDs)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	found := make(map[uuid.UUID]bool, len(files))
+	for _, file := range files {
+		if err := s.authorizeOwner(ctx, userID, file.OwnerID, ErrFileNotFound); err != nil {
+			return err
+		}
+		found[file.ID] = true
+	}
+	for _, id := range fileIDs {
+		if !found[id] {
+			return ErrFileNotFound
+		}
+	}
+
+	ids := make([]uuid.UUID, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+	}
+	// Kuota dikembalikan di repository hanya untuk file yang benar-benar terhapus, sehingga
+	// penghapusan bersamaan atas file yang sama tidak mengembalikan kuota dua kali
+	deleted, err := s.fileRepo.DeleteFiles(ctx, ids)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	s.cleanupFiles(ctx, deleted)
+	return nil
+}
+
+// CreateFolder membuat folder baru di bawah parent folder (dibuat jika belum ada)
+func (s *fileService) CreateFolder(ctx context.Context, userID uuid.UUID, req *model.CreateFolderRequest) (*model.FolderResponse, error) {
+	if err := s.authorize(ctx, userID, "create"); err != nil {
+		return nil, err
+	}
+
+	name, err := sanitizeFolderName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	parentPath, err := normalizeFolderPath(req.ParentFolder)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.ensureFolder(ctx, userID, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := &model.Folder{
+		ID:      uuid.New(),
+		OwnerID: userID,
+		Name:    name,
+		Path:    joinFolderPath(parentPath, name),
+	}
+	if parent != nil {
+		folder.ParentID = &parent.ID
+	}
+
+	if err := s.fileRepo.CreateFolder(ctx, folder); err != nil {
+		return nil, mapFolderError(err)
+	}
+
+	folderResponse := folder.ToFolderResponse()
+	return &folderResponse, nil
+}
+
+// GetFolders mendapatkan sub folder langsung dari parent ("" atau "/" untuk root)
+// beserta jumlah dan ukuran file di dalamnya
+func (s *fileService) GetFolders(ctx context.Context, userID uuid.UUID, parent string) ([]model.FolderResponse, error) {
+	if err := s.authorize(ctx, userID, "read"); err != nil {
+		return nil, err
+	}
+
+	parentPath, err := normalizeFolderPath(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID *uuid.UUID
+	if parentPath != "/" {
+		parentFolder, err := s.fileRepo.FindFolderByPath(ctx, userID, parentPath)
+		if err != nil {
+			if errors.Is(err, repository.ErrFolderNotFound) {
+				return nil, ErrFolderNotFound
+			}
+			return nil, ErrInternalServerError
+		}
+		parentID = &parentFolder.ID
+	}
+
+	folders, err := s.fileRepo.ListFolders(ctx, userID, parentID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	folderIDs := make([]uuid.UUID, len(folders))
+	for i, folder := range folders {
+		folderIDs[i] = folder.ID
+	}
+	stats, err := s.fileRepo.GetFolderStats(ctx, folderIDs)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	statsByFolder := make(map[uuid.UUID]model.FolderStats, len(stats))
+	for _, stat := range stats {
+		statsByFolder[stat.FolderID] = stat
+	}
+
+	folderResponses := make([]model.FolderResponse, len(folders))
+	for i, folder := range folders {
+		folderResponses[i] = folder.ToFolderResponse()
+		folderResponses[i].FileCount = statsByFolder[folder.ID].FileCount
+		folderResponses[i].Size = statsByFolder[folder.ID].Size
+	}
+
+	return folderResponses, nil
+}
+
+// RenameFolder mengubah nama folder; path semua sub folder ikut diperbarui
+func (s *fileService) RenameFolder(ctx context.Context, userID, folderID uuid.UUID, name string) (*model.FolderResponse, error) {
+	folder, err := s.findFolder(ctx, userID, folderID, "update")
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = sanitizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	parentPath := "/"
+	if i := strings.LastIndex(folder.Path, "/"); i > 0 {
+		parentPath = folder.Path[:i]
+	}
+	newPath := joinFolderPath(parentPath, name)
+
+	if newPath != folder.Path {
+		if err := s.fileRepo.RenameFolder(ctx, folder, name, newPath); err != nil {
+			return nil, mapFolderError(err)
+		}
+	}
+
+	folderResponse := folder.ToFolderResponse()
+	return &folderResponse, nil
+}
+
+// DeleteFolder menghapus folder. Folder yang masih berisi file atau sub folder hanya
+// dapat dihapus dengan force, yang ikut menghapus seluruh isinya.
+func (s *fileService) DeleteFolder(ctx context.Context, userID, folderID uuid.UUID, force bool) error {
+	folder, err := s.findFolder(ctx, userID, folderID, "delete")
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		hasChildren, err := s.fileRepo.FolderHasChildren(ctx, folder)
+		if err != nil {
+			return ErrInternalServerError
+		}
+		if hasChildren {
+			return ErrFolderNotEmpty
+		}
+	}
+
+	files, err := s.fileRepo.DeleteFolderTree(ctx, folder)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	s.cleanupFiles(ctx, files)
+	return nil
+}
+
+// GetStorageStats mendapatkan kuota, pemakaian, serta jumlah file dan folder user
+func (s *fileService) GetStorageStats(ctx context.Context, userID uuid.UUID) (*model.StorageStatsResponse, error) {
+	if err := s.authorize(ctx, userID, "read"); err != nil {
+		return nil, err
+	}
+
+	quota, err := s.quotaResponse(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCount, err := s.fileRepo.CountFiles(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	folderCount, err := s.fileRepo.CountFolders(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	available := quota.QuotaBytes - quota.UsedBytes
+	if available < 0 {
+		available = 0
+	}
+
+	return &model.StorageStatsResponse{
+		TotalSize:     quota.QuotaBytes,
+		UsedSize:      quota.UsedBytes,
+		AvailableSize: available,
+		FileCount:     fileCount,
+		FolderCount:   folderCount,
+	}, nil
+}
+
+// GetStorageQuota mendapatkan kuota storage user (untuk admin)
+func (s *fileService) GetStorageQuota(ctx context.Context, userID uuid.UUID) (*model.StorageQuotaResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	return s.quotaResponse(ctx, userID)
+}
+
+// UpdateStorageQuota mengatur kuota khusus user (untuk admin). Kuota di bawah
+// pemakaian saat ini diperbolehkan; user hanya tidak dapat mengunggah file baru.
+func (s *fileService) UpdateStorageQuota(ctx context.Context, userID uuid.UUID, req *model.UpdateStorageQuotaRequest) (*model.StorageQuotaResponse, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if err := s.fileRepo.SetQuota(ctx, userID, req.QuotaBytes); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return s.quotaResponse(ctx, userID)
+}
+
+// quotaResponse membuat StorageQuotaResponse dengan kuota default jika user tidak memiliki kuota khusus
+func (s *fileService) quotaResponse(ctx context.Context, userID uuid.UUID) (*model.StorageQuotaResponse, error) {
+	quota, err := s.fileRepo.GetQuota(ctx, userID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	response := &model.StorageQuotaResponse{
+		UserID:     userID,
+		QuotaBytes: s.config.Storage.DefaultQuota,
+		UsedBytes:  quota.UsedBytes,
+		IsDefault:  quota.QuotaBytes == nil,
+	}
+	if quota.QuotaBytes != nil {
+		response.QuotaBytes = *quota.QuotaBytes
+	}
+
+	return response, nil
+}
+
+// authorize memeriksa permission files:<action> user melalui RoleService.
+// Permission files:manage mencakup semua aksi.
+func (s *fileService) authorize(ctx context.Context, userID uuid.UUID, action string) error {
+	allowed, err := s.roleService.CheckUserPermission(ctx, userID, "files", action)
+	if err != nil {
+		return ErrInternalServerError
+	}
+	if !allowed {
+		return ErrFileAccessDenied
+	}
+	return nil
+}
+
+// authorizeOwner memastikan user adalah pemilik, atau memiliki permission files:manage
+// untuk mengakses file user lain. Selain itu notFound dikembalikan agar keberadaan
+// file milik user lain tidak terungkap.
+func (s *fileService) authorizeOwner(ctx context.Context, userID, ownerID uuid.UUID, notFound error) error {
+	if userID == ownerID {
+		return nil
+	}
+
+	allowed, err := s.roleService.CheckUserPermission(ctx, userID, "files", "manage")
+	if err != nil {
+		return ErrInternalServerError
+	}
+	if !allowed {
+		return notFound
+	}
+	return nil
+}
+
+// findFile mencari file dan memeriksa permission serta kepemilikannya
+func (s *fileService) findFile(ctx context.Context, userID, fileID uuid.UUID, action string) (*model.File, error) {
+	if err := s.authorize(ctx, userID, action); err != nil {
+		return nil, err
+	}
+
+	file, err := s.fileRepo.FindFileByID(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, repository.ErrFileNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if err := s.authorizeOwner(ctx, userID, file.OwnerID, ErrFileNotFound); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// findFolder mencari folder dan memeriksa permission serta kepemilikannya
+func (s *fileService) findFolder(ctx context.Context, userID, folderID uuid.UUID, action string) (*model.Folder, error) {
+	if err := s.authorize(ctx, userID, action); err != nil {
+		return nil, err
+	}
+
+	folder, err := s.fileRepo.FindFolderByID(ctx, folderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrFolderNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if err := s.authorizeOwner(ctx, userID, folder.OwnerID, ErrFolderNotFound); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+// ensureFolder mencari folder berdasarkan path dan membuat folder beserta parent-nya
+// jika belum ada. Path "/" (root) mengembalikan nil.
+func (s *fileService) ensureFolder(ctx context.Context, ownerID uuid.UUID, folderPath string) (*model.Folder, error) {
+	if folderPath == "/" {
+		return nil, nil
+	}
+
+	var parent *model.Folder
+	currentPath := ""
+	for _, name := range strings.Split(strings.TrimPrefix(folderPath, "/"), "/") {
+		currentPath += "/" + name
+
+		folder, err := s.fileRepo.FindFolderByPath(ctx, ownerID, currentPath)
+		if err != nil && !errors.Is(err, repository.ErrFolderNotFound) {
+			return nil, ErrInternalServerError
+		}
+
+		if err != nil {
+			folder = &model.Folder{
+				ID:      uuid.New(),
+				OwnerID: ownerID,
+				Name:    name,
+				Path:    currentPath,
+			}
+			if parent != nil {
+				folder.ParentID = &parent.ID
+			}
+
+			if err := s.fileRepo.CreateFolder(ctx, folder); err != nil {
+				if !errors.Is(err, repository.ErrFolderExists) {
+					return nil, mapFolderError(err)
+				}
+				// Folder dibuat oleh request lain secara bersamaan
+				if folder, err = s.fileRepo.FindFolderByPath(ctx, ownerID, currentPath); err != nil {
+					return nil, ErrInternalServerError
+				}
+			}
+		}
+
+		parent = folder
+	}
+
+	return parent, nil
+}
+
+// fileResponse membuat FileResponse untuk satu file
+func (s *fileService) fileResponse(ctx context.Context, file *model.File) (*model.FileResponse, error) {
+	fileResponses, err := s.fileResponses(ctx, []model.File{*file})
+	if err != nil {
+		return nil, err
+	}
+	return &fileResponses[0], nil
+}
+
+// fileResponses membuat FileResponse beserta path folder dan signed URL setiap file
+func (s *fileService) fileResponses(ctx context.Context, files []model.File) ([]model.FileResponse, error) {
+	var folderIDs []uuid.UUID
+	for _, file := range files {
+		if file.FolderID != nil {
+			folderIDs = append(folderIDs, *file.FolderID)
+		}
+	}
+
+	folders, err := s.fileRepo.FindFoldersByIDs(ctx, folderIDs)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	folderPaths := make(map[uuid.UUID]string, len(folders))
+	for _, folder := range folders {
+		folderPaths[folder.ID] = folder.Path
+	}
+
+	fileResponses := make([]model.FileResponse, len(files))
+	for i, file := range files {
+		folderPath := "/"
+		if file.FolderID != nil {
+			folderPath = folderPaths[*file.FolderID]
+		}
+
+		signedURL, err := s.fileStorage.SignedURL(ctx, file.StorageKey, s.config.Storage.SignedURLExpiry)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+
+		fileResponses[i] = file.ToFileResponse(folderPath, signedURL)
+	}
+
+	return fileResponses, nil
+}
+
+// cleanupFiles menghapus isi file yang metadata-nya sudah dihapus. Kuota sudah dikembalikan
+// oleh repository dalam transaksi penghapusan metadata.
+func (s *fileService) cleanupFiles(ctx context.Context, files []model.File) {
+	for _, file := range files {
+		s.deleteObject(ctx, file.StorageKey)
+	}
+}
+
+// removeFiles menghapus metadata dan isi file yang baru dibuat saat upload gagal,
+// sekaligus mengembalikan kuota file tersebut
+func (s *fileService) removeFiles(ctx context.Context, files []model.File) {
+	ids := make([]uuid.UUID, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+		s.deleteObject(ctx, file.StorageKey)
+	}
+	if _, err := s.fileRepo.DeleteFiles(ctx, ids); err != nil {
+		log.Printf("Failed to remove files of failed upload: %v", err)
+	}
+}
+
+// releaseQuota mengembalikan kuota tanpa menggagalkan operasi utama
+func (s *fileService) releaseQuota(ctx context.Context, userID uuid.UUID, size int64) {
+	if err := s.fileRepo.ReleaseQuota(ctx, userID, size); err != nil {
+		log.Printf("Failed to release storage quota of user %s: %v", userID, err)
+	}
+}
+
+// deleteObject menghapus isi file dari file storage tanpa menggagalkan operasi utama
+func (s *fileService) deleteObject(ctx context.Context, key string) {
+	if err := s.fileStorage.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete file object %s: %v", key, err)
+	}
+}
+
+// storageContentType menentukan Content-Type object di file storage. Hanya gambar
+// yang disimpan dengan tipe aslinya; tipe lain disimpan sebagai octet-stream agar
+// signed URL tidak dapat dipakai untuk menampilkan HTML atau script di browser.
+func storageContentType(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return mimeType
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// sanitizeFileName mengambil nama file tanpa path dan menolak nama kosong,
+// nama khusus, atau nama yang terlalu panjang
+func sanitizeFileName(name string) (string, error) {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name))
+
+	if name == "" || name == "." || name == ".." || len(name) > 255 {
+		return "", ErrInvalidFileName
+	}
+	return name, nil
+}
+
+// sanitizeFolderName memvalidasi nama satu folder
+func sanitizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || len(name) > 255 || strings.ContainsAny(name, `/\`) {
+		return "", ErrInvalidFolderPath
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", ErrInvalidFolderPath
+		}
+	}
+	return name, nil
+}
+
+// normalizeFolderPath mengubah path folder menjadi bentuk baku "/a/b"; kosong berarti root "/"
+func normalizeFolderPath(folderPath string) (string, error) {
+	var names []string
+	for _, name := range strings.Split(folderPath, "/") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		name, err := sanitizeFolderName(name)
+		if err != nil {
+			return "", err
+		}
+		names = append(names, name)
+	}
+
+	normalized := "/" + strings.Join(names, "/")
+	if len(normalized) > maxFolderPathLength {
+		return "", ErrInvalidFolderPath
+	}
+	return normalized, nil
+}
+
+// joinFolderPath menggabungkan path parent dengan nama folder
+func joinFolderPath(parentPath, name string) string {
+	if parentPath == "/" {
+		return "/" + name
+	}
+	return parentPath + "/" + name
+}
+
+// mapFolderError memetakan error repository folder ke error service
+func mapFolderError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrFolderExists):
+		return ErrFolderExists
+	case errors.Is(err, repository.ErrFolderPathTooLong):
+		return ErrInvalidFolderPath
+	default:
+		return ErrInternalServerError
+	}
+}
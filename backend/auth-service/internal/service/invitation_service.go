@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Invitation errors
+var (
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationNotPending = errors.New("invitation is no longer pending")
+	ErrInvitationExists     = errors.New("a pending invitation already exists for this email")
+)
+
+// InvitationService interface untuk pengelolaan undangan user oleh admin
+type InvitationService interface {
+	CreateInvitation(ctx context.Context, req *model.CreateInvitationRequest, invitedBy *uuid.UUID) (*model.CreateInvitationResponse, error)
+	GetInvitations(ctx context.Context, filter *model.InvitationFilter) (*model.InvitationsListResponse, error)
+	GetInvitationByID(ctx context.Context, id uuid.UUID) (*model.InvitationResponse, error)
+	RevokeInvitation(ctx context.Context, id uuid.UUID) (*model.InvitationResponse, error)
+}
+
+// invitationService implementasi InvitationService
+type invitationService struct {
+	invitationRepo repository.InvitationRepository
+	userRepo       repository.UserRepository
+	roleService    RoleService
+	emails         EmailService
+	config         *config.Config
+}
+
+// NewInvitationService membuat instance baru InvitationService
+func NewInvitationService(invitationRepo repository.InvitationRepository, userRepo repository.UserRepository, roleService RoleService, emails EmailService, cfg *config.Config) InvitationService {
+	return &invitationService{
+		invitationRepo: invitationRepo,
+		userRepo:       userRepo,
+		roleService:    roleService,
+		emails:         emails,
+		config:         cfg,
+	}
+}
+
+// CreateInvitation membuat undangan baru dan mengirim link undangan ke email yang diundang.
+// Yang disimpan hanya hash token; token plaintext hanya dikembalikan untuk driver mail "log".
+func (s *invitationService) CreateInvitation(ctx context.Context, req *model.CreateInvitationRequest, invitedBy *uuid.UUID) (*model.CreateInvitationResponse, error) {
+	// Email yang sudah terdaftar, termasuk user di trash, tidak dapat diundang
+	existing, err := s.userRepo.FindExistingEmails(ctx, []string{req.Email})
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if len(existing) > 0 {
+		return nil, ErrUserAlreadyExists
+	}
+
+	now := time.Now()
+	if _, err := s.invitationRepo.FindPendingByEmail(ctx, req.Email, now); err == nil {
+		return nil, ErrInvitationExists
+	} else if !errors.Is(err, repository.ErrInvitationNotFound) {
+		return nil, ErrInternalServerError
+	}
+
+	invitation := &model.Invitation{
+		ID:        uuid.New(),
+		Email:     req.Email,
+		Name:      req.Name,
+		Role:      "user",
+		Status:    model.InvitationStatusPending,
+		InvitedBy: invitedBy,
+		ExpiresAt: now.Add(s.config.Security.InviteExpiry),
+	}
+
+	roleName, err := s.resolveRole(ctx, req, invitation)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := utils.GenerateInvitationToken(invitation.ID, invitation.Email, s.config.JWT.SecretKey, invitation.ExpiresAt)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	invitation.TokenHash = utils.HashSecret(token)
+
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	invitationResponse := invitation.ToInvitationResponse()
+	invitationResponse.RoleName = roleName
+
+	// Kegagalan email tidak membatalkan undangan; undangan dapat dicabut lalu dibuat ulang
+	s.sendInvitationEmail(ctx, invitation, roleName, token)
+
+	response := &model.CreateInvitationResponse{Invitation: invitationResponse}
+	// Sama seperti token pengaturan password, token hanya dikembalikan untuk driver mail "log"
+	if s.config.Mail.Driver == "log" {
+		response.Token = token
+	}
+	return response, nil
+}
+
+// sendInvitationEmail mengirim link penerimaan undangan ke email yang diundang
+func (s *invitationService) sendInvitationEmail(ctx context.Context, invitation *model.Invitation, roleName, token string) {
+	inviterName := ""
+	if invitation.InvitedBy != nil {
+		if inviter, err := s.userRepo.FindByID(ctx, *invitation.InvitedBy); err == nil {
+			inviterName = inviter.Name
+		}
+	}
+	if roleName == "" {
+		roleName = invitation.Role
+	}
+
+	req := &model.EmailRequest{
+		To:       invitation.Email,
+		Name:     invitation.Name,
+		Template: model.EmailTemplateInvitation,
+		Data: map[string]interface{}{
+			"InviterName": inviterName,
+			"RoleName":    roleName,
+			"Link":        tokenLink(s.config.Security.InvitationURL, token),
+			"ExpiresAt":   formatEmailTime(invitation.ExpiresAt, ""),
+		},
+	}
+	if err := s.emails.Send(ctx, req); err != nil {
+		log.Printf("Failed to send invitation email to %s: %v", invitation.Email, err)
+	}
+}
+
+// resolveRole memvalidasi role undangan yang dapat diberikan sebagai nama role
+// (legacy maupun RBAC) atau role ID, lalu mengisi Role dan RoleID pada undangan
+func (s *invitationService) resolveRole(ctx context.Context, req *model.CreateInvitationRequest, invitation *model.Invitation) (string, error) {
+	var role *model.RoleResponse
+	var err error
+
+	switch {
+	case req.RoleID != nil:
+		role, err = s.roleService.GetRoleByID(ctx, *req.RoleID)
+	case req.Role != "":
+		role, err = s.roleService.GetRoleByName(ctx, req.Role)
+	default:
+		return "", nil
+	}
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return "", ErrRoleNotFound
+		}
+		return "", ErrInternalServerError
+	}
+	if req.RoleID != nil && req.Role != "" && req.Role != role.Name {
+		return "", ErrInvalidRole
+	}
+
+	invitation.RoleID = &role.ID
+	// Field role legacy hanya mengenal user dan admin
+	if role.Name == "admin" {
+		invitation.Role = "admin"
+	}
+
+	return role.Name, nil
+}
+
+// GetInvitations mendapatkan daftar undangan dengan filter status dan pagination
+func (s *invitationService) GetInvitations(ctx context.Context, filter *model.InvitationFilter) (*model.InvitationsListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 10
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	invitations, total, err := s.invitationRepo.List(ctx, filter, time.Now(), offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	invitationResponses := make([]model.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		invitationResponses[i] = invitation.ToInvitationResponse()
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.InvitationsListResponse{
+		Invitations: invitationResponses,
+		Total:       total,
+		Page:        filter.Page,
+		Limit:       filter.Limit,
+		TotalPages:  totalPages,
+	}, nil
+}
+
+// GetInvitationByID mendapatkan detail undangan berdasarkan ID
+func (s *invitationService) GetInvitationByID(ctx context.Context, id uuid.UUID) (*model.InvitationResponse, error) {
+	invitation, err := s.invitationRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	invitationResponse := invitation.ToInvitationResponse()
+	return &invitationResponse, nil
+}
+
+// RevokeInvitation mencabut undangan yang masih pending sehingga tokennya tidak dapat dipakai lagi
+func (s *invitationService) RevokeInvitation(ctx context.Context, id uuid.UUID) (*model.InvitationResponse, error) {
+	invitation, err := s.invitationRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvitationNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if invitation.CurrentStatus(time.Now()) != model.InvitationStatusPending {
+		return nil, ErrInvitationNotPending
+	}
+
+	now := time.Now()
+	if err := s.invitationRepo.Revoke(ctx, id, now); err != nil {
+		if errors.Is(err, repository.ErrInvitationNotPending) {
+			return nil, ErrInvitationNotPending
+		}
+		return nil, ErrInternalServerError
+	}
+
+	invitation.Status = model.InvitationStatusRevoked
+	invitation.RevokedAt = &now
+
+	invitationResponse := invitation.ToInvitationResponse()
+	return &invitationResponse, nil
+}
@@ -0,0 +1,505 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Job errors
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobRunning  = errors.New("job is already running")
+)
+
+// jobScheduleOff menonaktifkan jadwal otomatis job; job tetap dapat dipicu manual
+const jobScheduleOff = "off"
+
+// jobLockMargin ditambahkan ke timeout job sebagai masa berlaku lock, sehingga lock tidak
+// kedaluwarsa sebelum job yang terkena timeout selesai mencatat hasilnya
+const jobLockMargin = time.Minute
+
+// JobService interface untuk scheduler job pemeliharaan dan riwayat run-nya
+type JobService interface {
+	ListJobs(ctx context.Context) ([]model.JobResponse, error)
+	GetJobRuns(ctx context.Context, filter *model.JobRunFilter) (*model.JobRunsListResponse, error)
+	// TriggerJob menjalankan job di latar belakang dan mengembalikan run yang baru dimulai
+	TriggerJob(ctx context.Context, name string, actor *model.AuditActor) (*model.JobRun, error)
+	// Run menjalankan job sesuai jadwal sampai ctx dibatalkan, lalu menunggu run yang
+	// sedang berjalan di instance ini selesai
+	Run(ctx context.Context)
+}
+
+// maintenanceJob adalah satu job yang terdaftar pada scheduler
+type maintenanceJob struct {
+	name        string
+	description string
+	schedule    *utils.CronSchedule // nil jika job hanya dapat dipicu manual
+	run         func(ctx context.Context) (int64, error)
+}
+
+// jobService implementasi JobService
+type jobService struct {
+	jobRunRepo          repository.JobRunRepository
+	locker              repository.JobLocker
+	userRepo            repository.UserRepository
+	tokenRepo           repository.TokenRepository
+	invitationRepo      repository.InvitationRepository
+	authService         AuthService
+	loginHistoryService LoginHistoryService
+	loginRiskService    LoginRiskService
+	auditService        AuditService
+	config              *config.Config
+	jobs                []*maintenanceJob
+	instance            string
+
+	mu       sync.Mutex
+	baseCtx  context.Context // ctx dari Run, dipakai run manual agar ikut berhenti saat shutdown
+	stopping bool
+	wg       sync.WaitGroup
+}
+
+// NewJobService membuat instance baru JobService dan mem-parse jadwal semua job
+func NewJobService(jobRunRepo repository.JobRunRepository, locker repository.JobLocker, userRepo repository.UserRepository, tokenRepo repository.TokenRepository, invitationRepo repository.InvitationRepository, authService AuthService, loginHistoryService LoginHistoryService, loginRiskService LoginRiskService, auditService AuditService, cfg *config.Config) (JobService, error) {
+	s := &jobService{
+		jobRunRepo:          jobRunRepo,
+		locker:              locker,
+		userRepo:            userRepo,
+		tokenRepo:           tokenRepo,
+		invitationRepo:      invitationRepo,
+		authService:         authService,
+		loginHistoryService: loginHistoryService,
+		loginRiskService:    loginRiskService,
+		auditService:        auditService,
+		config:              cfg,
+		instance:            jobInstanceName(),
+		baseCtx:             context.Background(),
+	}
+
+	jobs := []struct {
+		name        string
+		description string
+		schedule    string
+		run         func(ctx context.Context) (int64, error)
+	}{
+		{model.JobExpiredLockouts, "Unlock accounts whose lockout has expired and reset their failed login counter", cfg.Jobs.ExpiredLockoutsSchedule, s.clearExpiredLockouts},
+		{model.JobOAuthStateCleanup, "Delete OAuth state and device authorization keys left in Redis without an expiry", cfg.Jobs.OAuthStateSchedule, s.tokenRepo.DeleteStaleOAuthState},
+		{model.JobLoginHistoryCleanup, "Archive and delete successful and failed logins older than their retention period", cfg.Jobs.LoginHistorySchedule, s.loginHistoryService.ApplyRetention},
+		{model.JobUnverifiedUsers, "Move local accounts that were never verified nor used to the trash", cfg.Jobs.UnverifiedUsersSchedule, s.deleteUnverifiedUsers},
+		{model.JobExpiredInvitations, "Delete expired and revoked invitations older than the retention period", cfg.Jobs.InvitationsSchedule, s.deleteExpiredInvitations},
+		{model.JobDeletedUsersPurge, "Permanently delete users whose trash retention has elapsed", cfg.Jobs.DeletedUsersSchedule, s.purgeDeletedUsers},
+		{model.JobRunHistoryCleanup, "Fail unfinished job runs and delete job run history older than the retention period", cfg.Jobs.RunHistorySchedule, s.cleanupRunHistory},
+		{model.JobTorExitListRefresh, "Download the TOR exit node list used to score login risk", cfg.Jobs.TorExitListSchedule, s.loginRiskService.RefreshTorExitNodes},
+	}
+
+	for _, job := range jobs {
+		var schedule *utils.CronSchedule
+		if expr := strings.TrimSpace(job.schedule); expr != "" && !strings.EqualFold(expr, jobScheduleOff) {
+			parsed, err := utils.ParseCronSchedule(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schedule for job %s: %w", job.name, err)
+			}
+			schedule = parsed
+		}
+		s.jobs = append(s.jobs, &maintenanceJob{
+			name:        job.name,
+			description: job.description,
+			schedule:    schedule,
+			run:         job.run,
+		})
+	}
+
+	return s, nil
+}
+
+// jobInstanceName mengembalikan identitas instance untuk riwayat run
+func jobInstanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// ListJobs mendapatkan semua job beserta jadwal dan run terakhirnya
+func (s *jobService) ListJobs(ctx context.Context) ([]model.JobResponse, error) {
+	latest, err := s.jobRunRepo.FindLatestRuns(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	lastRuns := make(map[string]*model.JobRun, len(latest))
+	for i := range latest {
+		lastRuns[latest[i].JobName] = &latest[i]
+	}
+
+	now := time.Now()
+	jobs := make([]model.JobResponse, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		response := model.JobResponse{
+			Name:        job.name,
+			Description: job.description,
+			LastRun:     lastRuns[job.name],
+		}
+		if job.schedule != nil {
+			response.Schedule = job.schedule.String()
+			response.Enabled = s.config.Jobs.Enabled
+		}
+		if response.Enabled {
+			if next := job.schedule.Next(now); !next.IsZero() {
+				response.NextRunAt = &next
+			}
+		}
+		if response.LastRun != nil {
+			response.Running = response.LastRun.Status == model.JobRunRunning
+		}
+		jobs = append(jobs, response)
+	}
+
+	return jobs, nil
+}
+
+// GetJobRuns mendapatkan riwayat run job dengan pagination
+func (s *jobService) GetJobRuns(ctx context.Context, filter *model.JobRunFilter) (*model.JobRunsListResponse, error) {
+	if filter.JobName != "" && s.findJob(filter.JobName) == nil {
+		return nil, ErrJobNotFound
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	runs, total, err := s.jobRunRepo.ListRuns(ctx, filter, offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.JobRunsListResponse{
+		Runs:       runs,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// TriggerJob mengambil lock job lalu menjalankannya di latar belakang. Mengembalikan
+// ErrJobRunning jika job sedang dijalankan instance mana pun.
+func (s *jobService) TriggerJob(ctx context.Context, name string, actor *model.AuditActor) (*model.JobRun, error) {
+	job := s.findJob(name)
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+
+	token, ok, err := s.locker.Acquire(ctx, job.name, s.lockTTL())
+	if err != nil {
+		log.Printf("Failed to acquire lock for job %s: %v", job.name, err)
+		return nil, ErrInternalServerError
+	}
+	if !ok {
+		return nil, ErrJobRunning
+	}
+
+	var triggeredBy *uuid.UUID
+	if actor != nil {
+		triggeredBy = actor.ID
+	}
+	run := s.newRun(job, model.JobTriggerManual, triggeredBy)
+	if err := s.jobRunRepo.CreateRun(ctx, run); err != nil {
+		log.Printf("Failed to record run of job %s: %v", job.name, err)
+		s.release(job.name, token)
+		return nil, ErrInternalServerError
+	}
+
+	// Salinan dikembalikan ke pemanggil karena run diperbarui oleh goroutine job
+	started := *run
+	if !s.start(func(ctx context.Context) { s.execute(ctx, job, run, token) }) {
+		s.finish(run, 0, errors.New("scheduler is shutting down"))
+		s.release(job.name, token)
+		return nil, ErrInternalServerError
+	}
+
+	s.auditService.Record(ctx, actor, nil, model.AuditActionJobTriggered, model.AuditResourceJob, job.name, map[string]interface{}{
+		"run_id": started.ID,
+	})
+
+	return &started, nil
+}
+
+// Run menjalankan setiap job terjadwal pada waktunya. Semua instance menghitung jadwal yang
+// sama; instance pertama yang mengklaim slot jadwal di Redis yang menjalankan job tersebut.
+func (s *jobService) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.baseCtx = ctx
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.stopping = true
+		s.mu.Unlock()
+		s.wg.Wait()
+	}()
+
+	if !s.config.Jobs.Enabled {
+		log.Printf("Scheduled jobs disabled on this instance")
+		<-ctx.Done()
+		return
+	}
+
+	next := make(map[*maintenanceJob]time.Time)
+	now := time.Now()
+	for _, job := range s.jobs {
+		if job.schedule == nil {
+			continue
+		}
+		if at := job.schedule.Next(now); !at.IsZero() {
+			next[job] = at
+		}
+	}
+
+	for {
+		var earliest time.Time
+		for _, at := range next {
+			if earliest.IsZero() || at.Before(earliest) {
+				earliest = at
+			}
+		}
+		if earliest.IsZero() {
+			<-ctx.Done()
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for job, at := range next {
+			if at.After(now) {
+				continue
+			}
+
+			job, slot := job, at
+			s.start(func(ctx context.Context) { s.runScheduled(ctx, job, slot) })
+
+			if following := job.schedule.Next(now); !following.IsZero() {
+				next[job] = following
+			} else {
+				delete(next, job)
+			}
+		}
+	}
+}
+
+// runScheduled menjalankan job untuk satu slot jadwal jika instance ini berhasil mengklaim slot
+// dan lock job. Slot yang jatuh bersamaan dengan run sebelumnya yang belum selesai dilewati.
+func (s *jobService) runScheduled(ctx context.Context, job *maintenanceJob, slot time.Time) {
+	// Klaim slot disimpan sampai slot berikutnya agar instance dengan jam yang sedikit
+	// terlambat tidak menjalankan slot yang sama setelah run pertama selesai
+	slotTTL := jobLockMargin
+	if following := job.schedule.Next(slot); !following.IsZero() {
+		slotTTL += following.Sub(slot)
+	}
+
+	claimed, err := s.locker.ClaimSlot(ctx, job.name, slot, slotTTL)
+	if err != nil {
+		log.Printf("Failed to claim schedule slot of job %s: %v", job.name, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	token, ok, err := s.locker.Acquire(ctx, job.name, s.lockTTL())
+	if err != nil {
+		log.Printf("Failed to acquire lock for job %s: %v", job.name, err)
+		return
+	}
+	if !ok {
+		log.Printf("Skipping scheduled run of job %s: previous run is still in progress", job.name)
+		return
+	}
+
+	run := s.newRun(job, model.JobTriggerSchedule, nil)
+	if err := s.jobRunRepo.CreateRun(ctx, run); err != nil {
+		log.Printf("Failed to record run of job %s: %v", job.name, err)
+		s.release(job.name, token)
+		return
+	}
+
+	s.execute(ctx, job, run, token)
+}
+
+// execute menjalankan job dengan batas waktu, mencatat hasilnya, lalu melepas lock
+func (s *jobService) execute(ctx context.Context, job *maintenanceJob, run *model.JobRun, token string) {
+	defer s.release(job.name, token)
+
+	jobCtx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	affected, err := s.invoke(jobCtx, job)
+	s.finish(run, affected, err)
+
+	if err != nil {
+		log.Printf("Job %s failed after %dms: %v", job.name, run.DurationMs, err)
+		return
+	}
+	log.Printf("Job %s finished in %dms, %d affected", job.name, run.DurationMs, affected)
+}
+
+// invoke memanggil fungsi job dan mengubah panic menjadi error agar scheduler tetap berjalan
+func (s *jobService) invoke(ctx context.Context, job *maintenanceJob) (affected int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return job.run(ctx)
+}
+
+// finish menyimpan status akhir run
+func (s *jobService) finish(run *model.JobRun, affected int64, err error) {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.Affected = affected
+	run.Status = model.JobRunSucceeded
+	if err != nil {
+		run.Status = model.JobRunFailed
+		run.Error = truncateString(err.Error(), 1000)
+	}
+
+	// Hasil tetap disimpan meskipun ctx job sudah dibatalkan karena shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.jobRunRepo.UpdateRun(ctx, run); err != nil {
+		log.Printf("Failed to record result of job %s: %v", run.JobName, err)
+	}
+}
+
+// start menjalankan fn di goroutine yang ditunggu saat Run berhenti. Mengembalikan false
+// jika scheduler sedang berhenti.
+func (s *jobService) start(fn func(ctx context.Context)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopping {
+		return false
+	}
+
+	ctx := s.baseCtx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn(ctx)
+	}()
+	return true
+}
+
+// release melepas lock job tanpa bergantung pada ctx yang mungkin sudah dibatalkan
+func (s *jobService) release(name, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.locker.Release(ctx, name, token); err != nil {
+		log.Printf("Failed to release lock for job %s: %v", name, err)
+	}
+}
+
+// newRun membuat catatan run job yang baru dimulai
+func (s *jobService) newRun(job *maintenanceJob, trigger string, triggeredBy *uuid.UUID) *model.JobRun {
+	return &model.JobRun{
+		JobName:     job.name,
+		TriggerType: trigger,
+		TriggeredBy: triggeredBy,
+		Instance:    s.instance,
+		Status:      model.JobRunRunning,
+		StartedAt:   time.Now(),
+	}
+}
+
+// findJob mencari job berdasarkan nama
+func (s *jobService) findJob(name string) *maintenanceJob {
+	for _, job := range s.jobs {
+		if job.name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+// timeout mengembalikan batas waktu satu run job
+func (s *jobService) timeout() time.Duration {
+	if s.config.Jobs.Timeout <= 0 {
+		return 30 * time.Minute
+	}
+	return s.config.Jobs.Timeout
+}
+
+// lockTTL mengembalikan masa berlaku lock job
+func (s *jobService) lockTTL() time.Duration {
+	return s.timeout() + jobLockMargin
+}
+
+// clearExpiredLockouts membuka akun yang masa kuncinya sudah lewat
+func (s *jobService) clearExpiredLockouts(ctx context.Context) (int64, error) {
+	return s.userRepo.ClearExpiredLockouts(ctx, time.Now())
+}
+
+// deleteUnverifiedUsers memindahkan akun yang tidak pernah diverifikasi maupun dipakai ke trash
+func (s *jobService) deleteUnverifiedUsers(ctx context.Context) (int64, error) {
+	if s.config.Jobs.UnverifiedUserRetention <= 0 {
+		return 0, nil
+	}
+
+	deleted, err := s.authService.DeleteUnverifiedUsers(ctx, time.Now().Add(-s.config.Jobs.UnverifiedUserRetention))
+	return int64(deleted), err
+}
+
+// deleteExpiredInvitations menghapus undangan kedaluwarsa dan dicabut yang melewati masa retensi
+func (s *jobService) deleteExpiredInvitations(ctx context.Context) (int64, error) {
+	if s.config.Jobs.InvitationRetention <= 0 {
+		return 0, nil
+	}
+	return s.invitationRepo.DeleteExpired(ctx, time.Now().Add(-s.config.Jobs.InvitationRetention))
+}
+
+// purgeDeletedUsers menghapus permanen user di trash yang melewati masa retensi
+func (s *jobService) purgeDeletedUsers(ctx context.Context) (int64, error) {
+	purged, err := s.authService.PurgeExpiredUsers(ctx, nil)
+	return int64(purged), err
+}
+
+// cleanupRunHistory menandai run yang tidak pernah selesai sebagai gagal dan menghapus
+// riwayat run yang melewati masa retensi
+func (s *jobService) cleanupRunHistory(ctx context.Context) (int64, error) {
+	// Run yang dimulai sebelum lock terakhirnya kedaluwarsa sudah pasti tidak berjalan lagi
+	failed, err := s.jobRunRepo.FailStaleRuns(ctx, time.Now().Add(-s.lockTTL()), "run did not finish, the instance running it may have stopped")
+	if err != nil {
+		return 0, err
+	}
+
+	if s.config.Jobs.RunHistoryRetention <= 0 {
+		return failed, nil
+	}
+	deleted, err := s.jobRunRepo.DeleteRunsBefore(ctx, time.Now().Add(-s.config.Jobs.RunHistoryRetention))
+	return failed + deleted, err
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+)
+
+// defaultLoginHistoryBatchSize dipakai jika LOGIN_HISTORY_BATCH_SIZE tidak valid
+const defaultLoginHistoryBatchSize = 1000
+
+// LoginHistoryService interface untuk retensi dan arsip riwayat login
+type LoginHistoryService interface {
+	// ApplyRetention mengarsipkan (jika diaktifkan) lalu menghapus riwayat login yang melewati
+	// masa retensi sesuai hasilnya, dan mengembalikan jumlah baris yang dihapus
+	ApplyRetention(ctx context.Context) (int64, error)
+}
+
+// loginHistoryService implementasi LoginHistoryService
+type loginHistoryService struct {
+	userRepo    repository.UserRepository
+	fileStorage repository.FileStorage
+	config      *config.Config
+}
+
+// NewLoginHistoryService membuat instance baru LoginHistoryService
+func NewLoginHistoryService(userRepo repository.UserRepository, fileStorage repository.FileStorage, cfg *config.Config) LoginHistoryService {
+	return &loginHistoryService{
+		userRepo:    userRepo,
+		fileStorage: fileStorage,
+		config:      cfg,
+	}
+}
+
+// ApplyRetention memproses riwayat login berhasil dan gagal dengan retensinya masing-masing
+func (s *loginHistoryService) ApplyRetention(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	var deleted int64
+	outcomes := []struct {
+		success   bool
+		retention time.Duration
+	}{
+		{true, s.config.LoginHistory.SuccessRetention},
+		{false, s.config.LoginHistory.FailureRetention},
+	}
+	for _, outcome := range outcomes {
+		if outcome.retention <= 0 {
+			continue
+		}
+
+		n, err := s.deleteBefore(ctx, outcome.success, now.Add(-outcome.retention))
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// deleteBefore menghapus riwayat login dengan hasil tertentu per batch kecil. Setiap batch
+// diarsipkan lebih dulu dan hanya dihapus jika arsipnya berhasil disimpan, lalu diberi jeda
+// agar baris yang dikunci tidak menahan penulisan riwayat login baru terlalu lama.
+func (s *loginHistoryService) deleteBefore(ctx context.Context, success bool, before time.Time) (int64, error) {
+	batchSize := s.config.LoginHistory.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultLoginHistoryBatchSize
+	}
+
+	var deleted int64
+	for {
+		histories, err := s.userRepo.FindLoginHistoryBefore(ctx, success, before, batchSize)
+		if err != nil {
+			return deleted, err
+		}
+		if len(histories) == 0 {
+			return deleted, nil
+		}
+
+		if s.config.LoginHistory.ArchiveEnabled {
+			if err := s.archive(ctx, success, histories); err != nil {
+				return deleted, err
+			}
+		}
+
+		ids := make([]uint, len(histories))
+		for i, history := range histories {
+			ids[i] = history.ID
+		}
+		n, err := s.userRepo.DeleteLoginHistoryByIDs(ctx, ids)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+		if len(histories) < batchSize {
+			return deleted, nil
+		}
+
+		if err := sleepContext(ctx, s.config.LoginHistory.BatchPause); err != nil {
+			return deleted, err
+		}
+	}
+}
+
+// archive menyimpan satu batch riwayat login sebagai NDJSON terkompresi gzip. Key object
+// ditentukan oleh hasil, tanggal login pertama, dan rentang ID, sehingga batch yang sama
+// menimpa arsip yang sama jika penghapusan sebelumnya gagal setelah arsip tersimpan.
+func (s *loginHistoryService) archive(ctx context.Context, success bool, histories []model.LoginHistory) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for i := range histories {
+		if err := encoder.Encode(&histories[i]); err != nil {
+			return fmt.Errorf("failed to encode login history archive: %w", err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress login history archive: %w", err)
+	}
+
+	outcome := "failure"
+	if success {
+		outcome = "success"
+	}
+	first, last := histories[0], histories[len(histories)-1]
+	key := path.Join(
+		strings.Trim(s.config.LoginHistory.ArchivePrefix, "/"),
+		outcome,
+		first.LoginTime.UTC().Format("2006/01/02"),
+		fmt.Sprintf("%d-%d.ndjson.gz", first.ID, last.ID),
+	)
+
+	if err := s.fileStorage.Put(ctx, key, &buf, int64(buf.Len()), "application/gzip"); err != nil {
+		return fmt.Errorf("failed to store login history archive %s: %w", key, err)
+	}
+	return nil
+}
+
+// sleepContext menunggu selama d atau sampai ctx dibatalkan
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/mssola/user_agent"
+)
+
+const (
+	// maxLoginRiskScore adalah batas atas skor risiko login
+	maxLoginRiskScore = 100
+	// earthRadiusKm dipakai untuk menghitung jarak antar koordinat login
+	earthRadiusKm = 6371.0
+	// torExitListMaxBytes membatasi ukuran daftar exit node TOR yang diunduh
+	torExitListMaxBytes = 16 << 20
+)
+
+// LoginRisk adalah hasil penilaian risiko satu percobaan login
+type LoginRisk struct {
+	Score   int
+	Factors []string
+	Action  string // allow, notify, atau step_up
+}
+
+// HasFactor memeriksa apakah faktor risiko tertentu ikut menyumbang skor
+func (r *LoginRisk) HasFactor(factor string) bool {
+	for _, f := range r.Factors {
+		if f == factor {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginRiskService interface untuk penilaian risiko login dan daftar reputasi IP
+type LoginRiskService interface {
+	// Assess menilai login berdasarkan perangkat, negara, lokasi login sebelumnya, dan
+	// reputasi IP. Dipanggil sebelum riwayat login disimpan; kegagalan pemeriksaan
+	// hanya dicatat di log agar login tidak terhalang.
+	Assess(ctx context.Context, user *model.User, history *model.LoginHistory) *LoginRisk
+	// RefreshTorExitNodes mengunduh ulang daftar exit node TOR dan mengembalikan jumlah alamatnya
+	RefreshTorExitNodes(ctx context.Context) (int64, error)
+}
+
+// loginRiskService implementasi LoginRiskService
+type loginRiskService struct {
+	userRepo   repository.UserRepository
+	ipRepo     repository.IPReputationRepository
+	denylist   []*net.IPNet
+	httpClient *http.Client
+	config     *config.Config
+}
+
+// NewLoginRiskService membuat instance baru LoginRiskService dan mem-parse denylist IP
+func NewLoginRiskService(userRepo repository.UserRepository, ipRepo repository.IPReputationRepository, cfg *config.Config) (LoginRiskService, error) {
+	denylist, err := parseIPDenylist(cfg.LoginRisk.IPDenylist)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loginRiskService{
+		userRepo:   userRepo,
+		ipRepo:     ipRepo,
+		denylist:   denylist,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		config:     cfg,
+	}, nil
+}
+
+// parseIPDenylist mengubah daftar alamat IP atau CIDR menjadi jaringan; alamat tunggal
+// diperlakukan sebagai /32 atau /128
+func parseIPDenylist(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP in login risk denylist: %s", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR in login risk denylist: %s", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// Assess menjumlahkan skor setiap faktor risiko lalu menentukan tindakan sesuai ambang
+func (s *loginRiskService) Assess(ctx context.Context, user *model.User, history *model.LoginHistory) *LoginRisk {
+	cfg := s.config.LoginRisk
+	risk := &LoginRisk{Action: model.LoginRiskAllow}
+	add := func(factor string, score int) {
+		if score <= 0 {
+			return
+		}
+		risk.Factors = append(risk.Factors, factor)
+		risk.Score += score
+	}
+
+	ip := net.ParseIP(strings.TrimSpace(history.IP))
+	if ip != nil && s.isDenied(ip) {
+		add(model.RiskFactorDeniedIP, cfg.DeniedIPScore)
+	}
+	if s.isTorExitNode(ctx, ip, history.Country) {
+		add(model.RiskFactorTorExitNode, cfg.TorExitNodeScore)
+	}
+
+	// Perangkat, negara, dan lokasi hanya dibandingkan jika user pernah login sebelumnya,
+	// sehingga login pertama setelah akun dibuat tidak dianggap berisiko
+	if user.LastLogin != nil {
+		browser, _ := user_agent.New(history.UserAgent).Browser()
+		known, err := s.userRepo.HasSuccessfulLoginFromDevice(ctx, user.ID, history.DeviceFingerprint, browser, history.OS, history.DeviceInfo)
+		if err != nil {
+			log.Printf("Failed to check login device for user %s: %v", user.ID, err)
+		} else if !known {
+			add(model.RiskFactorNewDevice, cfg.NewDeviceScore)
+		}
+
+		last, err := s.userRepo.FindLastSuccessfulLogin(ctx, user.ID)
+		if err != nil {
+			if !errors.Is(err, repository.ErrLoginHistoryNotFound) {
+				log.Printf("Failed to get last login for user %s: %v", user.ID, err)
+			}
+		} else {
+			if s.isNewCountry(ctx, user, last, history) {
+				add(model.RiskFactorNewCountry, cfg.NewCountryScore)
+			}
+			if s.isImpossibleTravel(last, history) {
+				add(model.RiskFactorImpossibleTravel, cfg.ImpossibleTravelScore)
+			}
+		}
+	}
+
+	if risk.Score > maxLoginRiskScore {
+		risk.Score = maxLoginRiskScore
+	}
+
+	switch {
+	case cfg.StepUpThreshold > 0 && risk.Score >= cfg.StepUpThreshold:
+		risk.Action = model.LoginRiskStepUp
+	case cfg.NotifyThreshold > 0 && risk.Score >= cfg.NotifyThreshold:
+		risk.Action = model.LoginRiskNotify
+	}
+
+	return risk
+}
+
+// isDenied memeriksa apakah alamat IP termasuk denylist
+func (s *loginRiskService) isDenied(ip net.IP) bool {
+	for _, network := range s.denylist {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTorExitNode memeriksa alamat IP terhadap daftar exit node TOR. Kode negara T1 dari
+// Cloudflare juga menandakan koneksi melalui TOR.
+func (s *loginRiskService) isTorExitNode(ctx context.Context, ip net.IP, country string) bool {
+	if strings.EqualFold(country, "T1") {
+		return true
+	}
+	if ip == nil || s.config.LoginRisk.TorExitListURL == "" {
+		return false
+	}
+
+	tor, err := s.ipRepo.IsTorExitNode(ctx, ip.String())
+	if err != nil {
+		log.Printf("Failed to check TOR exit node %s: %v", ip, err)
+		return false
+	}
+	return tor
+}
+
+// isNewCountry memeriksa apakah login berasal dari negara yang belum pernah dipakai login
+// berhasil. Hanya dinilai jika negara login ini dan login terakhir diketahui, agar riwayat
+// tanpa data negara tidak membuat setiap login dianggap berasal dari negara baru.
+func (s *loginRiskService) isNewCountry(ctx context.Context, user *model.User, last, history *model.LoginHistory) bool {
+	country := knownCountry(history.Country)
+	if country == "" || knownCountry(last.Country) == "" || strings.EqualFold(country, last.Country) {
+		return false
+	}
+
+	known, err := s.userRepo.HasSuccessfulLoginFromCountry(ctx, user.ID, country)
+	if err != nil {
+		log.Printf("Failed to check login country for user %s: %v", user.ID, err)
+		return false
+	}
+	return !known
+}
+
+// knownCountry mengembalikan kode negara ISO dalam huruf besar, atau string kosong untuk
+// kode Cloudflare yang bukan negara (XX tidak diketahui, T1 jaringan TOR)
+func knownCountry(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		return ""
+	}
+	return country
+}
+
+// isImpossibleTravel memeriksa apakah jarak dari login berhasil terakhir tidak mungkin
+// ditempuh dalam selang waktu kedua login. Hanya dinilai jika kedua login memiliki koordinat.
+func (s *loginRiskService) isImpossibleTravel(last, history *model.LoginHistory) bool {
+	if last.Latitude == nil || last.Longitude == nil || history.Latitude == nil || history.Longitude == nil {
+		return false
+	}
+
+	distance := haversineKm(*last.Latitude, *last.Longitude, *history.Latitude, *history.Longitude)
+	if distance < s.config.LoginRisk.MinTravelDistance {
+		return false
+	}
+
+	hours := history.LoginTime.Sub(last.LoginTime).Hours()
+	if hours <= 0 {
+		return true
+	}
+	return distance/hours > s.config.LoginRisk.MaxTravelSpeed
+}
+
+// haversineKm menghitung jarak lingkaran besar antara dua koordinat dalam kilometer
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// RefreshTorExitNodes mengunduh daftar exit node TOR (satu alamat IP per baris) dan
+// mengganti daftar yang tersimpan. Daftar lama dipertahankan jika unduhan gagal atau kosong.
+func (s *loginRiskService) RefreshTorExitNodes(ctx context.Context) (int64, error) {
+	url := s.config.LoginRisk.TorExitListURL
+	if url == "" {
+		return 0, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create TOR exit list request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to download TOR exit list: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download TOR exit list: unexpected status %d", resp.StatusCode)
+	}
+
+	seen := make(map[string]struct{})
+	var ips []string
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, torExitListMaxBytes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ip := net.ParseIP(line)
+		if ip == nil {
+			continue
+		}
+		if _, ok := seen[ip.String()]; ok {
+			continue
+		}
+		seen[ip.String()] = struct{}{}
+		ips = append(ips, ip.String())
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read TOR exit list: %w", err)
+	}
+	if len(ips) == 0 {
+		return 0, errors.New("TOR exit list is empty")
+	}
+
+	if err := s.ipRepo.ReplaceTorExitNodes(ctx, ips); err != nil {
+		return 0, err
+	}
+	return int64(len(ips)), nil
+}
+
+// deviceFingerprint menghasilkan identitas perangkat dari user agent: keluarga browser dan
+// OS tanpa versi serta model perangkat, sehingga pembaruan browser atau OS dan perubahan IP
+// tidak dianggap perangkat baru
+func deviceFingerprint(ua *user_agent.UserAgent) string {
+	browser, _ := ua.Browser()
+	sum := sha256.Sum256([]byte(strings.Join([]string{browser, ua.OSInfo().Name, ua.Model()}, "|")))
+	return hex.EncodeToString(sum[:])
+}
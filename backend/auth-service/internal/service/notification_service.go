@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Errors
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// notificationResubscribeDelay adalah jeda sebelum berlangganan ulang broker setelah gagal
+const notificationResubscribeDelay = 5 * time.Second
+
+// notificationDefaultHeartbeat dipakai jika interval keep-alive stream tidak dikonfigurasi
+const notificationDefaultHeartbeat = 25 * time.Second
+
+// notificationPurgeBatchSize membatasi jumlah notifikasi yang dihapus per query purge
+const notificationPurgeBatchSize = 1000
+
+// NotificationService interface untuk layanan notifikasi user dan stream real-time
+type NotificationService interface {
+	// Notify menyimpan notifikasi untuk notification.UserID lalu mengirimnya ke stream
+	// user pada semua instance
+	Notify(ctx context.Context, notification *model.Notification, data map[string]interface{}) error
+	GetNotifications(ctx context.Context, userID uuid.UUID, filter *model.NotificationFilter) (*model.NotificationsListResponse, error)
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkAsRead(ctx context.Context, userID, id uuid.UUID) error
+	MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteNotification(ctx context.Context, userID, id uuid.UUID) error
+	PurgeExpiredNotifications(ctx context.Context) (int64, error)
+	// Subscribe membuka stream pesan notifikasi user pada instance ini. Fungsi yang
+	// dikembalikan harus dipanggil saat stream ditutup.
+	Subscribe(userID uuid.UUID) (<-chan *model.NotificationMessage, func())
+	// CloseUser menutup semua stream notifikasi user pada semua instance, misalnya saat
+	// sesi user dicabut
+	CloseUser(ctx context.Context, userID uuid.UUID) error
+	// HeartbeatInterval mengembalikan jarak keep-alive pada stream
+	HeartbeatInterval() time.Duration
+	// Run meneruskan pesan dari broker ke stream lokal sampai ctx dibatalkan,
+	// lalu menutup semua stream yang masih terbuka
+	Run(ctx context.Context)
+}
+
+// notificationSubscriber adalah satu stream notifikasi yang terbuka pada instance ini
+type notificationSubscriber struct {
+	messages chan *model.NotificationMessage
+}
+
+// notificationService implementasi NotificationService
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	broker           repository.NotificationBroker
+	config           *config.Config
+
+	mu          sync.RWMutex
+	subscribers map[uuid.UUID]map[*notificationSubscriber]struct{}
+	closed      bool
+}
+
+// NewNotificationService membuat instance baru NotificationService
+func NewNotificationService(notificationRepo repository.NotificationRepository, broker repository.NotificationBroker, cfg *config.Config) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		broker:           broker,
+		config:           cfg,
+		subscribers:      make(map[uuid.UUID]map[*notificationSubscriber]struct{}),
+	}
+}
+
+// Notify menyimpan notifikasi lalu mempublikasikannya ke broker. Gagal publikasi hanya
+// dicatat di log karena notifikasi tetap dapat diambil melalui daftar notifikasi.
+func (s *notificationService) Notify(ctx context.Context, notification *model.Notification, data map[string]interface{}) error {
+	if len(data) > 0 {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return ErrInternalServerError
+		}
+		notification.Data = string(payload)
+	}
+	if notification.Type == "" {
+		notification.Type = model.NotificationTypeInfo
+	}
+	if notification.Category == "" {
+		notification.Category = model.NotificationCategorySystem
+	}
+	notification.CreatedAt = time.Now()
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		log.Printf("Failed to create notification for user %s: %v", notification.UserID, err)
+		return ErrInternalServerError
+	}
+
+	response := notification.ToNotificationResponse()
+	s.publish(ctx, &model.NotificationMessage{
+		Type:         model.NotificationStreamCreated,
+		UserID:       notification.UserID,
+		Notification: &response,
+	})
+
+	return nil
+}
+
+// GetNotifications mendapatkan daftar notifikasi user dengan pagination
+func (s *notificationService) GetNotifications(ctx context.Context, userID uuid.UUID, filter *model.NotificationFilter) (*model.NotificationsListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	notifications, total, err := s.notificationRepo.List(ctx, userID, filter, offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses := make([]model.NotificationResponse, len(notifications))
+	for i := range notifications {
+		responses[i] = notifications[i].ToNotificationResponse()
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.NotificationsListResponse{
+		Notifications: responses,
+		Total:         total,
+		Page:          filter.Page,
+		Limit:         filter.Limit,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// GetUnreadCount menghitung notifikasi user yang belum dibaca
+func (s *notificationService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, ErrInternalServerError
+	}
+	return count, nil
+}
+
+// MarkAsRead menandai notifikasi sudah dibaca dan menyinkronkan stream user lainnya
+func (s *notificationService) MarkAsRead(ctx context.Context, userID, id uuid.UUID) error {
+	changed, err := s.notificationRepo.MarkRead(ctx, userID, id, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return ErrNotificationNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	if changed {
+		s.publish(ctx, &model.NotificationMessage{
+			Type:           model.NotificationStreamRead,
+			UserID:         userID,
+			NotificationID: &id,
+		})
+	}
+
+	return nil
+}
+
+// MarkAllAsRead menandai semua notifikasi user sudah dibaca
+func (s *notificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	updated, err := s.notificationRepo.MarkAllRead(ctx, userID, time.Now())
+	if err != nil {
+		return 0, ErrInternalServerError
+	}
+
+	if updated > 0 {
+		s.publish(ctx, &model.NotificationMessage{
+			Type:   model.NotificationStreamReadAll,
+			UserID: userID,
+		})
+	}
+
+	return updated, nil
+}
+
+// DeleteNotification menghapus notifikasi milik user
+func (s *notificationService) DeleteNotification(ctx context.Context, userID, id uuid.UUID) error {
+	if err := s.notificationRepo.Delete(ctx, userID, id); err != nil {
+		if errors.Is(err, repository.ErrNotificationNotFound) {
+			return ErrNotificationNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	s.publish(ctx, &model.NotificationMessage{
+		Type:           model.NotificationStreamDeleted,
+		UserID:         userID,
+		NotificationID: &id,
+	})
+
+	return nil
+}
+
+// PurgeExpiredNotifications menghapus notifikasi yang lebih lama dari masa retensi
+func (s *notificationService) PurgeExpiredNotifications(ctx context.Context) (int64, error) {
+	if s.config.Notification.Retention <= 0 {
+		return 0, nil
+	}
+
+	before := time.Now().Add(-s.config.Notification.Retention)
+	var purged int64
+	for {
+		deleted, err := s.notificationRepo.PurgeOlderThan(ctx, before, notificationPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		purged += deleted
+		if deleted < notificationPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// publish melengkapi pesan dengan jumlah notifikasi belum dibaca lalu mempublikasikannya
+func (s *notificationService) publish(ctx context.Context, message *model.NotificationMessage) {
+	count, err := s.notificationRepo.CountUnread(ctx, message.UserID)
+	if err != nil {
+		log.Printf("Failed to count unread notifications for user %s: %v", message.UserID, err)
+		return
+	}
+	message.UnreadCount = count
+
+	if err := s.broker.Publish(ctx, message); err != nil {
+		log.Printf("Failed to publish notification message for user %s: %v", message.UserID, err)
+	}
+}
+
+// Subscribe mendaftarkan stream notifikasi user pada instance ini
+func (s *notificationService) Subscribe(userID uuid.UUID) (<-chan *model.NotificationMessage, func()) {
+	subscriber := &notificationSubscriber{
+		messages: make(chan *model.NotificationMessage, s.config.Notification.StreamBuffer),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Service sedang berhenti, stream langsung ditutup
+	if s.closed {
+		close(subscriber.messages)
+		return subscriber.messages, func() {}
+	}
+
+	if s.subscribers[userID] == nil {
+		s.subscribers[userID] = make(map[*notificationSubscriber]struct{})
+	}
+	s.subscribers[userID][subscriber] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			// Stream sudah ditutup oleh Run saat service berhenti
+			if _, ok := s.subscribers[userID][subscriber]; !ok {
+				return
+			}
+			delete(s.subscribers[userID], subscriber)
+			if len(s.subscribers[userID]) == 0 {
+				delete(s.subscribers, userID)
+			}
+			close(subscriber.messages)
+		})
+	}
+
+	return subscriber.messages, unsubscribe
+}
+
+// CloseUser mempublikasikan pesan sessions.revoked ke broker. Setiap instance mengirim
+// pesan tersebut ke stream user lalu menutupnya (lihat dispatch).
+func (s *notificationService) CloseUser(ctx context.Context, userID uuid.UUID) error {
+	message := &model.NotificationMessage{
+		Type:   model.NotificationStreamSessionsRevoked,
+		UserID: userID,
+	}
+	return s.broker.Publish(ctx, message)
+}
+
+// HeartbeatInterval mengembalikan jarak keep-alive pada stream
+func (s *notificationService) HeartbeatInterval() time.Duration {
+	if s.config.Notification.HeartbeatInterval <= 0 {
+		return notificationDefaultHeartbeat
+	}
+	return s.config.Notification.HeartbeatInterval
+}
+
+// Run berlangganan broker dan meneruskan setiap pesan ke stream user yang terbuka pada
+// instance ini. Langganan yang gagal atau terputus dicoba lagi setelah jeda.
+func (s *notificationService) Run(ctx context.Context) {
+	defer s.closeSubscribers()
+
+	for ctx.Err() == nil {
+		messages, err := s.broker.Subscribe(ctx)
+		if err != nil {
+			log.Printf("Failed to subscribe to notification broker: %v", err)
+		} else {
+			for message := range messages {
+				s.dispatch(message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(notificationResubscribeDelay):
+		}
+	}
+}
+
+// dispatch mengirim pesan ke semua stream milik user tanpa menunggu. Pesan untuk stream
+// yang buffernya penuh dibuang, client menyinkronkan ulang melalui daftar notifikasi.
+// Pesan sessions.revoked menutup semua stream user setelah pesan tersebut diantrikan.
+func (s *notificationService) dispatch(message *model.NotificationMessage) {
+	if message.Type == model.NotificationStreamSessionsRevoked {
+		s.closeUserSubscribers(message)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for subscriber := range s.subscribers[message.UserID] {
+		select {
+		case subscriber.messages <- message:
+		default:
+			log.Printf("Notification stream buffer full for user %s, dropping %s message", message.UserID, message.Type)
+		}
+	}
+}
+
+// closeUserSubscribers mengantrikan pesan penutup ke semua stream user jika buffer masih
+// cukup, lalu menutup dan melepas stream tersebut
+func (s *notificationService) closeUserSubscribers(message *model.NotificationMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscriber := range s.subscribers[message.UserID] {
+		select {
+		case subscriber.messages <- message:
+		default:
+		}
+		close(subscriber.messages)
+	}
+	delete(s.subscribers, message.UserID)
+}
+
+// closeSubscribers menutup semua stream agar koneksi SSE selesai saat server berhenti
+func (s *notificationService) closeSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for userID, subscribers := range s.subscribers {
+		for subscriber := range subscribers {
+			close(subscriber.messages)
+		}
+		delete(s.subscribers, userID)
+	}
+}
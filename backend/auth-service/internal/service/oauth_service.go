@@ -0,0 +1,1049 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// OAuth related errors
+var (
+	ErrOAuthClientNotFound  = errors.New("oauth client not found")
+	ErrInvalidClient        = errors.New("invalid client credentials")
+	ErrUnsupportedGrantType = errors.New("unsupported grant type")
+	ErrInvalidScope         = errors.New("invalid scope")
+	ErrUnauthorizedClient   = errors.New("client is not authorized for this token")
+	ErrInvalidGrant         = errors.New("invalid grant")
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("polling too frequently")
+	ErrAccessDenied         = errors.New("authorization request denied")
+	ErrExpiredDeviceCode    = errors.New("device code has expired")
+	ErrDeviceCodeNotFound   = errors.New("device authorization not found")
+	ErrDeviceAlreadyHandled = errors.New("device authorization already handled")
+	ErrInvalidRequest       = errors.New("invalid oauth request")
+	ErrInvalidTarget        = errors.New("requested audience is not allowed")
+	ErrInvalidAudience      = errors.New("audience must not contain whitespace")
+)
+
+// Pengaturan device authorization grant (RFC 8628)
+const (
+	userCodeLength          = 8
+	userCodeGenerateRetries = 3
+	slowDownIncrement       = 5 // detik, RFC 8628 section 3.5
+)
+
+// OAuthService interface untuk layanan OAuth 2.0 authorization server
+type OAuthService interface {
+	// Service account management
+	CreateClient(ctx context.Context, req *model.CreateOAuthClientRequest, createdBy uuid.UUID) (*model.OAuthClientCredentialsResponse, error)
+	GetAllClients(ctx context.Context, page, limit int, search string) (*model.OAuthClientsListResponse, error)
+	GetClientByID(ctx context.Context, id uuid.UUID) (*model.OAuthClientResponse, error)
+	UpdateClient(ctx context.Context, id uuid.UUID, req *model.UpdateOAuthClientRequest) (*model.OAuthClientResponse, error)
+	DeleteClient(ctx context.Context, id uuid.UUID) error
+	RotateClientSecret(ctx context.Context, id uuid.UUID) (*model.OAuthClientCredentialsResponse, error)
+
+	// Token endpoint
+	AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*model.OAuthClient, error)
+	ClientCredentialsGrant(ctx context.Context, client *model.OAuthClient, scope string) (*model.OAuthTokenResponse, error)
+	TokenExchangeGrant(ctx context.Context, client *model.OAuthClient, req *model.OAuthTokenRequest) (*model.OAuthTokenResponse, error)
+
+	// Introspection (RFC 7662) dan revocation (RFC 7009)
+	IntrospectToken(ctx context.Context, client *model.OAuthClient, token, tokenTypeHint string) (*model.OAuthIntrospectionResponse, error)
+	RevokeToken(ctx context.Context, client *model.OAuthClient, token, tokenTypeHint string, actor *model.AuditActor) error
+
+	// Device authorization grant (RFC 8628)
+	DeviceAuthorization(ctx context.Context, clientID, scope string) (*model.OAuthDeviceAuthorizationResponse, error)
+	GetDeviceVerification(ctx context.Context, userCode string) (*model.DeviceVerificationResponse, error)
+	VerifyDevice(ctx context.Context, userID uuid.UUID, userCode string, approve bool) error
+	DeviceCodeGrant(ctx context.Context, clientID, deviceCode string, clientInfo *ClientInfo) (*model.OAuthTokenResponse, error)
+}
+
+// oauthService implementasi OAuthService
+type oauthService struct {
+	clientRepo   repository.OAuthClientRepository
+	roleRepo     repository.RoleRepository
+	tokenRepo    repository.TokenRepository
+	authService  AuthService
+	roleService  RoleService
+	auditService AuditService
+	config       *config.Config
+}
+
+// NewOAuthService membuat instance baru OAuthService
+func NewOAuthService(clientRepo repository.OAuthClientRepository, roleRepo repository.RoleRepository, tokenRepo repository.TokenRepository, authService AuthService, roleService RoleService, auditService AuditService, cfg *config.Config) OAuthService {
+	return &oauthService{
+		clientRepo:   clientRepo,
+		roleRepo:     roleRepo,
+		tokenRepo:    tokenRepo,
+		authService:  authService,
+		roleService:  roleService,
+		auditService: auditService,
+		config:       cfg,
+	}
+}
+
+// CreateClient membuat service account baru beserta client secret-nya
+func (s *oauthService) CreateClient(ctx context.Context, req *model.CreateOAuthClientRequest, createdBy uuid.UUID) (*model.OAuthClientCredentialsResponse, error) {
+	// Validasi role dan scope
+	role, err := s.getActiveRole(ctx, req.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := validateScopes(req.Scopes, rolePermissionScopes(role))
+	if err != nil {
+		return nil, err
+	}
+	audiences, err := normalizeAudiences(req.Audiences)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate credentials
+	clientID, err := utils.GenerateSecureToken(16)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	clientSecret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	client := &model.OAuthClient{
+		ClientID:    "sa_" + clientID,
+		SecretHash:  utils.HashSecret(clientSecret),
+		Name:        req.Name,
+		Description: req.Description,
+		RoleID:      &role.ID,
+		Scopes:      strings.Join(scopes, " "),
+		Audiences:   strings.Join(audiences, " "),
+		Active:      true,
+		CreatedBy:   &createdBy,
+	}
+
+	if err := s.clientRepo.Create(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	client.Role = role
+	return &model.OAuthClientCredentialsResponse{
+		Client:       client.ToOAuthClientResponse(),
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+// GetAllClients mendapatkan semua service account dengan pagination
+func (s *oauthService) GetAllClients(ctx context.Context, page, limit int, search string) (*model.OAuthClientsListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	clients, total, err := s.clientRepo.GetAll(ctx, offset, limit, search)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clients: %w", err)
+	}
+
+	clientResponses := make([]model.OAuthClientResponse, len(clients))
+	for i, client := range clients {
+		clientResponses[i] = client.ToOAuthClientResponse()
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &model.OAuthClientsListResponse{
+		Clients:    clientResponses,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetClientByID mendapatkan service account berdasarkan ID
+func (s *oauthService) GetClientByID(ctx context.Context, id uuid.UUID) (*model.OAuthClientResponse, error) {
+	client, err := s.findClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	clientResponse := client.ToOAuthClientResponse()
+	return &clientResponse, nil
+}
+
+// UpdateClient mengupdate service account
+func (s *oauthService) UpdateClient(ctx context.Context, id uuid.UUID, req *model.UpdateOAuthClientRequest) (*model.OAuthClientResponse, error) {
+	client, err := s.findClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Perubahan role, scope, atau status mencabut token yang sudah diterbitkan
+	revoke := false
+
+	if req.Name != nil {
+		client.Name = *req.Name
+	}
+	if req.Description != nil {
+		client.Description = *req.Description
+	}
+	if req.RoleID != nil && (client.RoleID == nil || *client.RoleID != *req.RoleID) {
+		role, err := s.getActiveRole(ctx, *req.RoleID)
+		if err != nil {
+			return nil, err
+		}
+		client.RoleID = &role.ID
+		client.Role = role
+		revoke = true
+	}
+	if req.Scopes != nil || revoke {
+		requested := req.Scopes
+		if requested == nil {
+			requested = strings.Fields(client.Scopes)
+		}
+		scopes, err := validateScopes(requested, rolePermissionScopes(client.Role))
+		if err != nil {
+			return nil, err
+		}
+		client.Scopes = strings.Join(scopes, " ")
+		revoke = true
+	}
+	if req.Audiences != nil {
+		audiences, err := normalizeAudiences(req.Audiences)
+		if err != nil {
+			return nil, err
+		}
+		client.Audiences = strings.Join(audiences, " ")
+	}
+	if req.Active != nil && *req.Active != client.Active {
+		client.Active = *req.Active
+		revoke = revoke || !client.Active
+	}
+
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to update client: %w", err)
+	}
+
+	if revoke {
+		if err := s.revokeClientTokens(ctx, client.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	clientResponse := client.ToOAuthClientResponse()
+	return &clientResponse, nil
+}
+
+// DeleteClient menghapus service account dan mencabut token-nya
+func (s *oauthService) DeleteClient(ctx context.Context, id uuid.UUID) error {
+	err := s.clientRepo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return ErrOAuthClientNotFound
+		}
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+
+	return s.revokeClientTokens(ctx, id)
+}
+
+// RotateClientSecret membuat client secret baru dan mencabut token lama
+func (s *oauthService) RotateClientSecret(ctx context.Context, id uuid.UUID) (*model.OAuthClientCredentialsResponse, error) {
+	client, err := s.findClient(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	clientSecret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	client.SecretHash = utils.HashSecret(clientSecret)
+	if err := s.clientRepo.Update(ctx, client); err != nil {
+		return nil, fmt.Errorf("failed to update client: %w", err)
+	}
+
+	if err := s.revokeClientTokens(ctx, client.ID); err != nil {
+		return nil, err
+	}
+
+	return &model.OAuthClientCredentialsResponse{
+		Client:       client.ToOAuthClientResponse(),
+		ClientSecret: clientSecret,
+	}, nil
+}
+
+// AuthenticateClient memverifikasi client_id dan client_secret
+func (s *oauthService) AuthenticateClient(ctx context.Context, clientID, clientSecret string) (*model.OAuthClient, error) {
+	if clientID == "" || clientSecret == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.clientRepo.FindByClientID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if !utils.CheckSecretHash(clientSecret, client.SecretHash) || !client.Active {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// ClientCredentialsGrant menerbitkan access token untuk service account (RFC 6749 section 4.4)
+func (s *oauthService) ClientCredentialsGrant(ctx context.Context, client *model.OAuthClient, scope string) (*model.OAuthTokenResponse, error) {
+	if client.Role == nil || !client.Role.Active {
+		return nil, ErrInvalidClient
+	}
+
+	// Scope yang diminta harus merupakan subset dari scope client
+	allowed := clientScopes(client)
+	granted := allowed
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		var err error
+		granted, err = validateScopes(requested, allowed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	generation, err := s.tokenRepo.GetClientTokenGeneration(ctx, client.ID)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	grantedScope := strings.Join(granted, " ")
+	accessToken, err := utils.GenerateClientAccessToken(client.ID, client.ClientID, client.Role.Name, grantedScope, generation, s.config.JWT.SecretKey, s.config.OAuth.ClientTokenExpiry)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.clientRepo.UpdateLastUsed(ctx, client.ID, time.Now())
+
+	return &model.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.OAuth.ClientTokenExpiry / time.Second),
+		Scope:       grantedScope,
+	}, nil
+}
+
+// TokenExchangeGrant menukar access token subjek dengan token baru yang dibatasi audience dan
+// scope-nya, dengan client yang meminta dicatat sebagai aktor (RFC 8693)
+func (s *oauthService) TokenExchangeGrant(ctx context.Context, client *model.OAuthClient, req *model.OAuthTokenRequest) (*model.OAuthTokenResponse, error) {
+	if client.Role == nil || !client.Role.Active {
+		return nil, ErrInvalidClient
+	}
+	if req.SubjectToken == "" {
+		return nil, ErrInvalidRequest
+	}
+	if req.SubjectTokenType != model.TokenTypeAccessToken && req.SubjectTokenType != model.TokenTypeJWT {
+		return nil, ErrInvalidRequest
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != model.TokenTypeAccessToken {
+		return nil, ErrInvalidRequest
+	}
+
+	// Client hanya boleh meminta audience yang terdaftar untuknya
+	allowedAudiences := strings.Fields(client.Audiences)
+	if len(allowedAudiences) == 0 {
+		return nil, ErrUnauthorizedClient
+	}
+	audiences, err := normalizeAudiences(req.Audience)
+	if err != nil || len(audiences) == 0 {
+		return nil, ErrInvalidTarget
+	}
+	for _, audience := range audiences {
+		if !containsString(allowedAudiences, audience) {
+			return nil, ErrInvalidTarget
+		}
+	}
+
+	// Validasi subject token
+	subject, err := s.authService.ValidateToken(ctx, req.SubjectToken)
+	if err != nil {
+		return nil, ErrInvalidGrant
+	}
+
+	var subjectScopes []string
+	if subject.Scope != "" {
+		subjectScopes = strings.Fields(subject.Scope)
+	} else if !subject.IsClient() {
+		user, err := s.authService.GetUserByID(ctx, subject.UserID)
+		if err != nil || !user.Active {
+			return nil, ErrInvalidGrant
+		}
+		subjectScopes, err = s.roleService.GetUserPermissions(ctx, subject.UserID)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+	}
+
+	// Token baru tidak boleh melebihi hak subjek maupun hak client
+	allowed := commonScopes(subjectScopes, clientScopes(client))
+	granted := allowed
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		granted, err = validateScopes(requested, allowed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(granted) == 0 {
+		return nil, ErrInvalidScope
+	}
+
+	// Token hasil exchange tidak boleh berlaku lebih lama dari subject token
+	expiry := s.config.OAuth.TokenExchangeExpiry
+	if subject.ExpiresAt != nil {
+		if remaining := time.Until(subject.ExpiresAt.Time); remaining < expiry {
+			expiry = remaining
+		}
+	}
+
+	if subject.Subject == "" {
+		subject.Subject = subject.UserID.String()
+	}
+	actor := &utils.ActorClaim{
+		Subject:  client.ClientID,
+		ClientID: client.ClientID,
+		Act:      subject.Act,
+	}
+
+	grantedScope := strings.Join(granted, " ")
+	accessToken, err := utils.GenerateExchangedAccessToken(subject, audiences, grantedScope, actor, s.config.JWT.SecretKey, expiry)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.clientRepo.UpdateLastUsed(ctx, client.ID, time.Now())
+
+	return &model.OAuthTokenResponse{
+		AccessToken:     accessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(expiry / time.Second),
+		Scope:           grantedScope,
+		IssuedTokenType: model.TokenTypeAccessToken,
+	}, nil
+}
+
+// IntrospectToken mengembalikan status dan metadata token (RFC 7662).
+// Token yang tidak valid, kedaluwarsa, atau dicabut dilaporkan sebagai tidak aktif, bukan sebagai error.
+func (s *oauthService) IntrospectToken(ctx context.Context, client *model.OAuthClient, token, tokenTypeHint string) (*model.OAuthIntrospectionResponse, error) {
+	// Hint hanya menentukan urutan pengecekan (RFC 7662 section 2.1)
+	if tokenTypeHint == model.TokenTypeHintRefreshToken {
+		if response, ok := s.introspectRefreshToken(ctx, token); ok {
+			return response, nil
+		}
+		if response, ok := s.introspectAccessToken(ctx, token); ok {
+			return response, nil
+		}
+	} else {
+		if response, ok := s.introspectAccessToken(ctx, token); ok {
+			return response, nil
+		}
+		if response, ok := s.introspectRefreshToken(ctx, token); ok {
+			return response, nil
+		}
+	}
+
+	return &model.OAuthIntrospectionResponse{Active: false}, nil
+}
+
+// RevokeToken mencabut access token atau refresh token (RFC 7009).
+// Token yang tidak valid atau sudah dicabut tidak dianggap sebagai error.
+func (s *oauthService) RevokeToken(ctx context.Context, client *model.OAuthClient, token, tokenTypeHint string, actor *model.AuditActor) error {
+	if tokenTypeHint == model.TokenTypeHintRefreshToken {
+		if handled, err := s.revokeRefreshToken(ctx, client, token, actor); handled {
+			return err
+		}
+		_, err := s.revokeAccessToken(ctx, client, token, actor)
+		return err
+	}
+
+	if handled, err := s.revokeAccessToken(ctx, client, token, actor); handled {
+		return err
+	}
+	_, err := s.revokeRefreshToken(ctx, client, token, actor)
+	return err
+}
+
+// DeviceAuthorization memulai device authorization grant dan menerbitkan device code serta user code
+func (s *oauthService) DeviceAuthorization(ctx context.Context, clientID, scope string) (*model.OAuthDeviceAuthorizationResponse, error) {
+	if !s.isDeviceClient(clientID) {
+		return nil, ErrInvalidClient
+	}
+
+	deviceCode, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	expiry := s.config.OAuth.DeviceCodeExpiry
+	auth := &model.DeviceAuthorization{
+		DeviceCodeHash: utils.HashSecret(deviceCode),
+		ClientID:       clientID,
+		Scope:          strings.Join(strings.Fields(scope), " "),
+		Status:         model.DeviceAuthorizationPending,
+		Interval:       int(s.config.OAuth.DevicePollInterval / time.Second),
+		ExpiresAt:      time.Now().Add(expiry),
+	}
+
+	// User code pendek sehingga bentrokan dengan otorisasi lain yang masih aktif mungkin terjadi
+	stored := false
+	for i := 0; i < userCodeGenerateRetries && !stored; i++ {
+		auth.UserCode, err = utils.GenerateUserCode(userCodeLength)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+
+		err = s.tokenRepo.StoreDeviceAuthorization(ctx, auth, s.deviceRecordTTL(auth))
+		if err == nil {
+			stored = true
+		} else if !errors.Is(err, repository.ErrUserCodeConflict) {
+			return nil, ErrInternalServerError
+		}
+	}
+	if !stored {
+		return nil, ErrInternalServerError
+	}
+
+	userCode := formatUserCode(auth.UserCode)
+	return &model.OAuthDeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         s.config.OAuth.DeviceVerificationURI,
+		VerificationURIComplete: s.config.OAuth.DeviceVerificationURI + "?user_code=" + url.QueryEscape(userCode),
+		ExpiresIn:               int64(expiry / time.Second),
+		Interval:                auth.Interval,
+	}, nil
+}
+
+// GetDeviceVerification mendapatkan detail device authorization yang menunggu persetujuan user
+func (s *oauthService) GetDeviceVerification(ctx context.Context, userCode string) (*model.DeviceVerificationResponse, error) {
+	auth, err := s.findPendingDevice(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.DeviceVerificationResponse{
+		UserCode:  formatUserCode(auth.UserCode),
+		ClientID:  auth.ClientID,
+		Scope:     auth.Scope,
+		ExpiresAt: auth.ExpiresAt,
+	}, nil
+}
+
+// VerifyDevice menyetujui atau menolak device authorization atas nama user yang sedang login
+func (s *oauthService) VerifyDevice(ctx context.Context, userID uuid.UUID, userCode string, approve bool) error {
+	auth, err := s.findPendingDevice(ctx, userCode)
+	if err != nil {
+		return err
+	}
+
+	status := model.DeviceAuthorizationDenied
+	if approve {
+		status = model.DeviceAuthorizationApproved
+	}
+
+	// Status hanya diubah jika masih pending, sehingga persetujuan dan penolakan bersamaan
+	// tidak saling menimpa
+	if err := s.tokenRepo.SetDeviceAuthorizationStatus(ctx, auth.DeviceCodeHash, userID, model.DeviceAuthorizationPending, status); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTokenNotFound):
+			return ErrDeviceCodeNotFound
+		case errors.Is(err, repository.ErrDeviceAuthorizationHandled):
+			return ErrDeviceAlreadyHandled
+		}
+		return ErrInternalServerError
+	}
+
+	return nil
+}
+
+// DeviceCodeGrant menukar device code dengan token setelah user menyetujui perangkat (RFC 8628 section 3.4)
+func (s *oauthService) DeviceCodeGrant(ctx context.Context, clientID, deviceCode string, clientInfo *ClientInfo) (*model.OAuthTokenResponse, error) {
+	if !s.isDeviceClient(clientID) {
+		return nil, ErrInvalidClient
+	}
+	if deviceCode == "" {
+		return nil, ErrInvalidGrant
+	}
+
+	auth, err := s.tokenRepo.GetDeviceAuthorization(ctx, utils.HashSecret(deviceCode))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidGrant
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Device code hanya dapat ditukar oleh client yang memintanya
+	if auth.ClientID != clientID {
+		return nil, ErrInvalidGrant
+	}
+
+	now := time.Now()
+	if now.After(auth.ExpiresAt) {
+		s.tokenRepo.DeleteDeviceAuthorization(ctx, auth)
+		return nil, ErrExpiredDeviceCode
+	}
+
+	switch auth.Status {
+	case model.DeviceAuthorizationApproved:
+		return s.issueDeviceTokens(ctx, auth, clientInfo)
+	case model.DeviceAuthorizationIssuing:
+		// Polling lain sedang menukar otorisasi ini; jika gagal sementara, otorisasi dikembalikan
+		// ke approved sehingga client tetap menunggu
+		return nil, ErrAuthorizationPending
+	case model.DeviceAuthorizationDenied:
+		s.tokenRepo.DeleteDeviceAuthorization(ctx, auth)
+		return nil, ErrAccessDenied
+	}
+
+	// Masih menunggu persetujuan; client yang polling terlalu cepat diminta memperlambat
+	slow, err := s.tokenRepo.RecordDevicePoll(ctx, auth.DeviceCodeHash, time.Duration(auth.Interval)*time.Second, slowDownIncrement*time.Second, s.deviceRecordTTL(auth))
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if slow {
+		return nil, ErrSlowDown
+	}
+
+	return nil, ErrAuthorizationPending
+}
+
+// issueDeviceTokens menukar device authorization yang disetujui menjadi token. Status diubah ke
+// issuing secara atomik sehingga polling bersamaan tidak mendapatkan token dua kali. Otorisasi
+// dihapus setelah token diterbitkan atau ditolak permanen, dan dikembalikan ke approved jika
+// gagal sementara atau memerlukan step-up sehingga polling berikutnya dapat mencoba lagi.
+func (s *oauthService) issueDeviceTokens(ctx context.Context, auth *model.DeviceAuthorization, clientInfo *ClientInfo) (*model.OAuthTokenResponse, error) {
+	if err := s.tokenRepo.SetDeviceAuthorizationStatus(ctx, auth.DeviceCodeHash, *auth.UserID, model.DeviceAuthorizationApproved, model.DeviceAuthorizationIssuing); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrTokenNotFound):
+			return nil, ErrInvalidGrant
+		case errors.Is(err, repository.ErrDeviceAuthorizationHandled):
+			return nil, ErrAuthorizationPending
+		}
+		return nil, ErrInternalServerError
+	}
+
+	tokenResponse, err := s.issueApprovedDeviceTokens(ctx, auth, clientInfo)
+	if err != nil {
+		var stepUp *StepUpRequiredError
+		if errors.As(err, &stepUp) || errors.Is(err, ErrInternalServerError) {
+			if restoreErr := s.tokenRepo.SetDeviceAuthorizationStatus(ctx, auth.DeviceCodeHash, *auth.UserID, model.DeviceAuthorizationIssuing, model.DeviceAuthorizationApproved); restoreErr != nil {
+				log.Printf("Failed to restore device authorization: %v", restoreErr)
+			}
+			return nil, err
+		}
+	}
+
+	if deleteErr := s.tokenRepo.DeleteDeviceAuthorization(ctx, auth); deleteErr != nil && !errors.Is(deleteErr, repository.ErrTokenNotFound) {
+		log.Printf("Failed to delete device authorization: %v", deleteErr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokenResponse, nil
+}
+
+// issueApprovedDeviceTokens memeriksa scope yang disetujui lalu menerbitkan token untuk user
+func (s *oauthService) issueApprovedDeviceTokens(ctx context.Context, auth *model.DeviceAuthorization, clientInfo *ClientInfo) (*model.OAuthTokenResponse, error) {
+	// Scope yang disetujui user tidak boleh melebihi permission user saat token diterbitkan
+	if auth.Scope != "" {
+		permissions, err := s.roleService.GetUserPermissions(ctx, *auth.UserID)
+		if err != nil {
+			return nil, ErrInternalServerError
+		}
+		if _, err := validateScopes(strings.Fields(auth.Scope), permissions); err != nil {
+			return nil, ErrInvalidScope
+		}
+	}
+
+	tokenResponse, err := s.authService.IssueTokensForUser(ctx, *auth.UserID, "device", auth.Scope, clientInfo)
+	if err != nil {
+		var stepUp *StepUpRequiredError
+		switch {
+		case errors.As(err, &stepUp):
+			return nil, err
+		case errors.Is(err, ErrUserInactive), errors.Is(err, ErrUserNotFound),
+			errors.Is(err, ErrAccountLocked), errors.Is(err, ErrPasswordResetRequired):
+			return nil, ErrAccessDenied
+		}
+		return nil, ErrInternalServerError
+	}
+
+	return &model.OAuthTokenResponse{
+		AccessToken:  tokenResponse.AccessToken,
+		TokenType:    tokenResponse.TokenType,
+		ExpiresIn:    tokenResponse.ExpiresIn,
+		RefreshToken: tokenResponse.RefreshToken,
+		Scope:        auth.Scope,
+	}, nil
+}
+
+// findPendingDevice mencari device authorization yang masih menunggu persetujuan berdasarkan user code
+func (s *oauthService) findPendingDevice(ctx context.Context, userCode string) (*model.DeviceAuthorization, error) {
+	auth, err := s.tokenRepo.GetDeviceAuthorizationByUserCode(ctx, utils.NormalizeUserCode(userCode))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrDeviceCodeNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if time.Now().After(auth.ExpiresAt) {
+		return nil, ErrDeviceCodeNotFound
+	}
+	if auth.Status != model.DeviceAuthorizationPending {
+		return nil, ErrDeviceAlreadyHandled
+	}
+
+	return auth, nil
+}
+
+// deviceRecordTTL menghitung sisa masa simpan device authorization di Redis. Record disimpan
+// lebih lama dari masa berlaku device code agar client yang terlambat polling menerima expired_token.
+func (s *oauthService) deviceRecordTTL(auth *model.DeviceAuthorization) time.Duration {
+	ttl := time.Until(auth.ExpiresAt) + s.config.OAuth.DeviceCodeExpiry
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// isDeviceClient memeriksa apakah client terdaftar untuk device authorization grant
+func (s *oauthService) isDeviceClient(clientID string) bool {
+	if clientID == "" {
+		return false
+	}
+	for _, id := range s.config.OAuth.DeviceClientIDs {
+		if strings.TrimSpace(id) == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// formatUserCode memformat user code menjadi dua kelompok agar mudah dibaca, misalnya "BCDF-GHJK"
+func formatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+// introspectAccessToken memeriksa access token user maupun service account
+func (s *oauthService) introspectAccessToken(ctx context.Context, token string) (*model.OAuthIntrospectionResponse, bool) {
+	claims, err := s.authService.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, false
+	}
+
+	response := &model.OAuthIntrospectionResponse{
+		Active:    true,
+		Scope:     claims.Scope,
+		TokenType: "Bearer",
+		Iss:       claims.Issuer,
+		Jti:       claims.ID,
+		Role:      claims.Role,
+		Aud:       claims.Audience,
+	}
+	if claims.Act != nil {
+		response.Act = claims.Act
+	}
+	setIntrospectionTimes(response, claims)
+
+	if claims.IsClient() {
+		response.ClientID = claims.ClientID
+		response.Sub = claims.ClientID
+		response.SubType = utils.SubjectTypeClient
+		return response, true
+	}
+
+	// Token user hanya aktif selama akun user masih aktif
+	user, err := s.authService.GetUserByID(ctx, claims.UserID)
+	if err != nil || !user.Active {
+		return nil, false
+	}
+
+	response.Sub = claims.UserID.String()
+	response.Username = user.Email
+	response.SubType = utils.SubjectTypeUser
+	return response, true
+}
+
+// introspectRefreshToken memeriksa refresh token terhadap status di Redis
+func (s *oauthService) introspectRefreshToken(ctx context.Context, token string) (*model.OAuthIntrospectionResponse, bool) {
+	claims, err := utils.ParseRefreshToken(token, s.config.JWT.SecretKey)
+	if err != nil {
+		return nil, false
+	}
+
+	if err := s.tokenRepo.ValidateRefreshToken(ctx, claims.UserID, claims.TokenID); err != nil {
+		return nil, false
+	}
+
+	user, err := s.authService.GetUserByID(ctx, claims.UserID)
+	if err != nil || !user.Active {
+		return nil, false
+	}
+
+	response := &model.OAuthIntrospectionResponse{
+		Active:    true,
+		Username:  user.Email,
+		TokenType: model.TokenTypeHintRefreshToken,
+		Sub:       claims.UserID.String(),
+		Iss:       claims.Issuer,
+		Jti:       claims.TokenID,
+		SubType:   utils.SubjectTypeUser,
+		Role:      user.Role,
+	}
+	setIntrospectionTimes(response, claims)
+
+	return response, true
+}
+
+// revokeAccessToken mencabut access token berdasarkan jti. Nilai handled bernilai false
+// jika token bukan access token yang valid.
+func (s *oauthService) revokeAccessToken(ctx context.Context, client *model.OAuthClient, token string, actor *model.AuditActor) (bool, error) {
+	claims, err := utils.ParseAccessToken(token, s.config.JWT.SecretKey)
+	if err != nil {
+		return false, nil
+	}
+
+	// Token hanya dapat dicabut oleh client yang menerbitkannya (RFC 7009 section 2.1)
+	if !issuedToClient(client, claims) && !canRevokeAnyToken(client) {
+		return true, ErrUnauthorizedClient
+	}
+
+	// Token lama tanpa jti tidak dapat dicabut satu per satu dan akan kedaluwarsa dengan sendirinya
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return true, nil
+	}
+
+	expiresIn := time.Until(claims.ExpiresAt.Time)
+	if expiresIn <= 0 {
+		return true, nil
+	}
+
+	if err := s.tokenRepo.RevokeAccessToken(ctx, claims.ID, expiresIn); err != nil {
+		return true, ErrInternalServerError
+	}
+
+	// Token service account tidak memiliki user terdampak
+	var userID *uuid.UUID
+	if !claims.IsClient() {
+		userID = &claims.UserID
+	}
+	s.auditService.Record(ctx, actor, userID, model.AuditActionTokenRevoked, model.AuditResourceToken, claims.ID, map[string]interface{}{
+		"token_type": model.TokenTypeHintAccessToken,
+		"subject":    claims.Subject,
+	})
+
+	return true, nil
+}
+
+// revokeRefreshToken mencabut refresh token di Redis. Nilai handled bernilai false
+// jika token bukan refresh token yang valid.
+func (s *oauthService) revokeRefreshToken(ctx context.Context, client *model.OAuthClient, token string, actor *model.AuditActor) (bool, error) {
+	claims, err := utils.ParseRefreshToken(token, s.config.JWT.SecretKey)
+	if err != nil {
+		return false, nil
+	}
+
+	// Refresh token diterbitkan untuk sesi user, bukan untuk client tertentu
+	if !canRevokeAnyToken(client) {
+		return true, ErrUnauthorizedClient
+	}
+
+	err = s.tokenRepo.RevokeRefreshToken(ctx, claims.UserID, claims.TokenID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			// Token sudah dicabut sebelumnya
+			return true, nil
+		}
+		return true, ErrInternalServerError
+	}
+
+	s.auditService.Record(ctx, actor, &claims.UserID, model.AuditActionTokenRevoked, model.AuditResourceToken, claims.TokenID, map[string]interface{}{
+		"token_type": model.TokenTypeHintRefreshToken,
+	})
+
+	return true, nil
+}
+
+// issuedToClient memeriksa apakah access token diterbitkan untuk client: token service account
+// milik client itu sendiri, atau token hasil token exchange yang diminta oleh client tersebut
+func issuedToClient(client *model.OAuthClient, claims *utils.JWTClaims) bool {
+	if claims.Act != nil {
+		return claims.Act.ClientID == client.ClientID
+	}
+	return claims.IsClient() && claims.ClientID == client.ClientID
+}
+
+// canRevokeAnyToken memeriksa apakah client memiliki scope tokens:revoke untuk mencabut
+// token yang tidak diterbitkan untuknya, misalnya token sesi user
+func canRevokeAnyToken(client *model.OAuthClient) bool {
+	if client.Role == nil {
+		return false
+	}
+	_, err := validateScopes([]string{"tokens:revoke"}, clientScopes(client))
+	return err == nil
+}
+
+// setIntrospectionTimes mengisi klaim waktu pada response introspection
+func setIntrospectionTimes(response *model.OAuthIntrospectionResponse, claims *utils.JWTClaims) {
+	if claims.ExpiresAt != nil {
+		response.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		response.Iat = claims.IssuedAt.Unix()
+	}
+}
+
+// findClient mencari service account dan memetakan error repository
+func (s *oauthService) findClient(ctx context.Context, id uuid.UUID) (*model.OAuthClient, error) {
+	client, err := s.clientRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrOAuthClientNotFound) {
+			return nil, ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return client, nil
+}
+
+// getActiveRole mendapatkan role aktif untuk di-assign ke service account
+func (s *oauthService) getActiveRole(ctx context.Context, roleID uuid.UUID) (*model.Role, error) {
+	role, err := s.roleRepo.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if !role.Active {
+		return nil, ErrInvalidRole
+	}
+	return role, nil
+}
+
+// revokeClientTokens mencabut semua access token yang sudah diterbitkan untuk service account.
+// Kegagalan dikembalikan agar perubahan client tidak dilaporkan berhasil selama token lama masih berlaku.
+func (s *oauthService) revokeClientTokens(ctx context.Context, id uuid.UUID) error {
+	if err := s.tokenRepo.RevokeClientTokens(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke client tokens: %w", err)
+	}
+	return nil
+}
+
+// clientScopes mengembalikan scope maksimum yang dapat diberikan kepada service account.
+// Scope yang tersimpan dibatasi permission role saat ini, sama seperti scope API key.
+func clientScopes(client *model.OAuthClient) []string {
+	permissions := rolePermissionScopes(client.Role)
+	if scopes := strings.Fields(client.Scopes); len(scopes) > 0 {
+		return intersectScopes(scopes, permissions)
+	}
+	return permissions
+}
+
+// commonScopes mengembalikan scope yang tercakup oleh kedua daftar scope
+func commonScopes(a, b []string) []string {
+	result := make([]string, 0, len(a))
+	for _, scope := range append(intersectScopes(a, b), intersectScopes(b, a)...) {
+		if !containsString(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+// normalizeAudiences membersihkan dan menghapus duplikasi daftar audience
+func normalizeAudiences(audiences []string) ([]string, error) {
+	result := make([]string, 0, len(audiences))
+	for _, audience := range audiences {
+		audience = strings.TrimSpace(audience)
+		if audience == "" {
+			continue
+		}
+		if strings.ContainsAny(audience, " \t\r\n") {
+			return nil, ErrInvalidAudience
+		}
+		if !containsString(result, audience) {
+			result = append(result, audience)
+		}
+	}
+	return result, nil
+}
+
+// containsString memeriksa apakah slice berisi nilai tertentu
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// rolePermissionScopes mengembalikan permission aktif role dalam format "resource:action"
+func rolePermissionScopes(role *model.Role) []string {
+	if role == nil {
+		return nil
+	}
+
+	scopes := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		if permission.Active {
+			scopes = append(scopes, permission.Resource+":"+permission.Action)
+		}
+	}
+	return scopes
+}
+
+// validateScopes memastikan setiap scope yang diminta ada di daftar scope yang diizinkan.
+// Sebuah scope juga diizinkan jika daftar berisi "resource:manage" untuk resource yang sama.
+func validateScopes(requested, allowed []string) ([]string, error) {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, scope := range allowed {
+		allowedSet[scope] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+
+		resource, _, _ := strings.Cut(scope, ":")
+		if !allowedSet[scope] && !allowedSet[resource+":manage"] {
+			return nil, ErrInvalidScope
+		}
+
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
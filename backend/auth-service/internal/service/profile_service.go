@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Profile errors
+var (
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+	ErrEmailChangeNotFound     = errors.New("no pending email change")
+	ErrEmailDeliveryFailed     = errors.New("failed to send confirmation email")
+)
+
+// ProfileService interface untuk pengelolaan profil oleh user sendiri
+type ProfileService interface {
+	UpdateProfile(ctx context.Context, userID uuid.UUID, req *model.UpdateProfileRequest) (*model.ProfileResponse, error)
+	ConfirmEmailChange(ctx context.Context, token string) (*model.EmailChangeStatus, error)
+	CancelEmailChange(ctx context.Context, userID uuid.UUID) error
+}
+
+// profileService implementasi ProfileService
+type profileService struct {
+	userRepo  repository.UserRepository
+	tokenRepo repository.TokenRepository
+	emails    EmailService
+	config    *config.Config
+}
+
+// NewProfileService membuat instance baru ProfileService
+func NewProfileService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, emails EmailService, cfg *config.Config) ProfileService {
+	return &profileService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		emails:    emails,
+		config:    cfg,
+	}
+}
+
+// UpdateProfile memperbarui field profil yang dikirim. Jika email diubah, email baru
+// baru diterapkan setelah dikonfirmasi dari alamat lama dan alamat baru.
+func (s *profileService) UpdateProfile(ctx context.Context, userID uuid.UUID, req *model.UpdateProfileRequest) (*model.ProfileResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	changed := false
+	if req.Name != nil && *req.Name != user.Name {
+		user.Name = *req.Name
+		changed = true
+	}
+	if req.ProfilePicture != nil && *req.ProfilePicture != user.ProfilePicture {
+		user.ProfilePicture = *req.ProfilePicture
+		changed = true
+	}
+	if req.Phone != nil && *req.Phone != user.Phone {
+		user.Phone = *req.Phone
+		changed = true
+	}
+	if req.Locale != nil && *req.Locale != user.Locale {
+		user.Locale = *req.Locale
+		changed = true
+	}
+	if req.Timezone != nil && *req.Timezone != user.Timezone {
+		user.Timezone = *req.Timezone
+		changed = true
+	}
+
+	if changed {
+		user.UpdatedAt = time.Now()
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, ErrInternalServerError
+		}
+
+		// Hapus cache user
+		s.tokenRepo.InvalidateUserCache(ctx, userID)
+	}
+
+	if req.Email != nil && !strings.EqualFold(*req.Email, user.Email) {
+		if err := s.requestEmailChange(ctx, user, *req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.profileResponse(ctx, user), nil
+}
+
+// requestEmailChange membuat permintaan perubahan email dan mengirim link konfirmasi
+// ke alamat lama dan alamat baru. Permintaan sebelumnya yang belum selesai dibatalkan.
+func (s *profileService) requestEmailChange(ctx context.Context, user *model.User, newEmail string) error {
+	// Email baru tidak boleh dipakai user lain, termasuk user di trash
+	existing, err := s.userRepo.FindExistingEmails(ctx, []string{newEmail})
+	if err != nil {
+		return ErrInternalServerError
+	}
+	if len(existing) > 0 {
+		return ErrUserAlreadyExists
+	}
+
+	// Cek rate limit agar link konfirmasi tidak dipakai untuk mengirim email massal
+	key := fmt.Sprintf("email_change:%s", user.ID)
+	allowed, err := s.tokenRepo.CheckRateLimit(ctx, key, 5, time.Hour)
+	if err != nil {
+		return ErrInternalServerError
+	}
+	if !allowed {
+		return ErrRateLimitExceeded
+	}
+
+	oldToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return ErrInternalServerError
+	}
+	newToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return ErrInternalServerError
+	}
+
+	change := &model.EmailChange{
+		UserID:       user.ID,
+		OldEmail:     user.Email,
+		NewEmail:     newEmail,
+		OldTokenHash: utils.HashSecret(oldToken),
+		NewTokenHash: utils.HashSecret(newToken),
+		ExpiresAt:    time.Now().Add(s.config.Security.EmailChangeExpiry),
+	}
+
+	if err := s.tokenRepo.StoreEmailChange(ctx, change, s.config.Security.EmailChangeExpiry); err != nil {
+		return ErrInternalServerError
+	}
+
+	approve := &model.EmailRequest{
+		To:       change.OldEmail,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplateEmailChangeApprove,
+		Data: map[string]interface{}{
+			"OldEmail": change.OldEmail,
+			"NewEmail": change.NewEmail,
+			"Link":     s.emailChangeLink(oldToken),
+		},
+	}
+	confirm := &model.EmailRequest{
+		To:       change.NewEmail,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplateEmailChangeConfirm,
+		Data: map[string]interface{}{
+			"NewEmail": change.NewEmail,
+			"Link":     s.emailChangeLink(newToken),
+		},
+	}
+
+	for _, req := range []*model.EmailRequest{approve, confirm} {
+		if err := s.emails.Send(ctx, req); err != nil {
+			s.tokenRepo.DeleteEmailChange(ctx, change)
+			return ErrEmailDeliveryFailed
+		}
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange menandai salah satu link konfirmasi sebagai dibuka. Setelah kedua
+// alamat mengkonfirmasi, email user diperbarui dan cache user dihapus.
+func (s *profileService) ConfirmEmailChange(ctx context.Context, token string) (*model.EmailChangeStatus, error) {
+	tokenHash := utils.HashSecret(token)
+
+	pending, err := s.tokenRepo.GetEmailChangeByToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidEmailChangeToken
+		}
+		return nil, ErrInternalServerError
+	}
+
+	// Flag konfirmasi diubah dan diperiksa secara atomik di Redis; hanya pemanggilan yang
+	// melengkapi kedua konfirmasi yang menerapkan perubahan email
+	change, completed, err := s.tokenRepo.ConfirmEmailChange(ctx, pending.UserID, tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, ErrInvalidEmailChangeToken
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if !completed {
+		status := change.ToEmailChangeStatus()
+		return &status, nil
+	}
+
+	if err := s.applyEmailChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	status := change.ToEmailChangeStatus()
+	return &status, nil
+}
+
+// applyEmailChange menerapkan email baru setelah kedua alamat mengkonfirmasi
+func (s *profileService) applyEmailChange(ctx context.Context, change *model.EmailChange) error {
+	// Permintaan selesai atau gagal, token tidak boleh dipakai lagi
+	defer s.tokenRepo.DeleteEmailChange(ctx, change)
+
+	user, err := s.userRepo.FindByID(ctx, change.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidEmailChangeToken
+		}
+		return ErrInternalServerError
+	}
+
+	// Email sudah berubah melalui jalur lain sejak permintaan dibuat
+	if user.Email != change.OldEmail {
+		return ErrInvalidEmailChangeToken
+	}
+
+	existing, err := s.userRepo.FindExistingEmails(ctx, []string{change.NewEmail})
+	if err != nil {
+		return ErrInternalServerError
+	}
+	if len(existing) > 0 {
+		return ErrUserAlreadyExists
+	}
+
+	// Link konfirmasi ke alamat baru membuktikan kepemilikan email
+	user.Email = change.NewEmail
+	user.Verified = true
+	user.UpdatedAt = time.Now()
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return ErrInternalServerError
+	}
+
+	// Hapus cache user
+	s.tokenRepo.InvalidateUserCache(ctx, user.ID)
+
+	notice := &model.EmailRequest{
+		To:       change.OldEmail,
+		Name:     user.Name,
+		Locale:   user.Locale,
+		Template: model.EmailTemplateEmailChanged,
+		Data:     map[string]interface{}{"NewEmail": change.NewEmail},
+	}
+	if err := s.emails.Send(ctx, notice); err != nil {
+		log.Printf("Failed to send email change notice to %s: %v", change.OldEmail, err)
+	}
+
+	return nil
+}
+
+// CancelEmailChange membatalkan perubahan email yang masih menunggu konfirmasi
+func (s *profileService) CancelEmailChange(ctx context.Context, userID uuid.UUID) error {
+	change, err := s.tokenRepo.GetEmailChange(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenNotFound) {
+			return ErrEmailChangeNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	if err := s.tokenRepo.DeleteEmailChange(ctx, change); err != nil {
+		return ErrInternalServerError
+	}
+
+	return nil
+}
+
+// profileResponse membuat ProfileResponse dari user beserta perubahan email yang masih berjalan
+func (s *profileService) profileResponse(ctx context.Context, user *model.User) *model.ProfileResponse {
+	response := &model.ProfileResponse{
+		UserResponse: user.ToUserResponse(),
+	}
+
+	if change, err := s.tokenRepo.GetEmailChange(ctx, user.ID); err == nil {
+		status := change.ToEmailChangeStatus()
+		response.PendingEmailChange = &status
+	}
+
+	return response
+}
+
+// emailChangeLink membuat link konfirmasi perubahan email ke halaman frontend
+func (s *profileService) emailChangeLink(token string) string {
+	return tokenLink(s.config.Security.EmailChangeURL, token)
+}
@@ -26,20 +26,20 @@ type RoleService interface {
 	GetAllRoles(ctx context.Context, page, limit int, search string) (*model.RolesListResponse, error)
 	GetRoleByID(ctx context.Context, roleID uuid.UUID) (*model.RoleResponse, error)
 	GetRoleByName(ctx context.Context, name string) (*model.RoleResponse, error)
-	CreateRole(ctx context.Context, req *model.CreateRoleRequest) (*model.RoleResponse, error)
-	UpdateRole(ctx context.Context, roleID uuid.UUID, req *model.UpdateRoleRequest) (*model.RoleResponse, error)
-	DeleteRole(ctx context.Context, roleID uuid.UUID) error
+	CreateRole(ctx context.Context, req *model.CreateRoleRequest, actor *model.AuditActor) (*model.RoleResponse, error)
+	UpdateRole(ctx context.Context, roleID uuid.UUID, req *model.UpdateRoleRequest, actor *model.AuditActor) (*model.RoleResponse, error)
+	DeleteRole(ctx context.Context, roleID uuid.UUID, actor *model.AuditActor) error
 	
 	// Permission management
 	GetAllPermissions(ctx context.Context, page, limit int, search, resource string) (*model.PermissionsListResponse, error)
 	GetPermissionByID(ctx context.Context, permissionID uuid.UUID) (*model.PermissionResponse, error)
-	CreatePermission(ctx context.Context, req *model.CreatePermissionRequest) (*model.PermissionResponse, error)
-	UpdatePermission(ctx context.Context, permissionID uuid.UUID, req *model.UpdatePermissionRequest) (*model.PermissionResponse, error)
-	DeletePermission(ctx context.Context, permissionID uuid.UUID) error
+	CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, actor *model.AuditActor) (*model.PermissionResponse, error)
+	UpdatePermission(ctx context.Context, permissionID uuid.UUID, req *model.UpdatePermissionRequest, actor *model.AuditActor) (*model.PermissionResponse, error)
+	DeletePermission(ctx context.Context, permissionID uuid.UUID, actor *model.AuditActor) error
 	
 	// Role-Permission management
-	AssignPermissionsToRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error
-	RemovePermissionsFromRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error
+	AssignPermissionsToRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID, actor *model.AuditActor) error
+	RemovePermissionsFromRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID, actor *model.AuditActor) error
 	GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]model.PermissionResponse, error)
 	
 	// User permission checking
@@ -55,14 +55,20 @@ type roleService struct {
 	roleRepo       repository.RoleRepository
 	permissionRepo repository.PermissionRepository
 	userRepo       repository.UserRepository
+	txManager      repository.TransactionManager
+	auditService   AuditService
+	eventBus       EventBus
 }
 
 // NewRoleService membuat instance baru RoleService
-func NewRoleService(roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository, userRepo repository.UserRepository) RoleService {
+func NewRoleService(roleRepo repository.RoleRepository, permissionRepo repository.PermissionRepository, userRepo repository.UserRepository, txManager repository.TransactionManager, auditService AuditService, eventBus EventBus) RoleService {
 	return &roleService{
 		roleRepo:       roleRepo,
 		permissionRepo: permissionRepo,
 		userRepo:       userRepo,
+		txManager:      txManager,
+		auditService:   auditService,
+		eventBus:       eventBus,
 	}
 }
 
@@ -127,7 +133,7 @@ func (s *roleService) GetRoleByName(ctx context.Context, name string) (*model.Ro
 }
 
 // CreateRole membuat role baru
-func (s *roleService) CreateRole(ctx context.Context, req *model.CreateRoleRequest) (*model.RoleResponse, error) {
+func (s *roleService) CreateRole(ctx context.Context, req *model.CreateRoleRequest, actor *model.AuditActor) (*model.RoleResponse, error) {
 	// Cek apakah role sudah ada
 	existingRole, err := s.roleRepo.GetRoleByName(ctx, req.Name)
 	if err == nil && existingRole != nil {
@@ -152,32 +158,55 @@ func (s *roleService) CreateRole(ctx context.Context, req *model.CreateRoleReque
 		Active:      true,
 	}
 
-	// Simpan role
-	createdRole, err := s.roleRepo.CreateRole(ctx, role)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create role: %w", err)
-	}
-
-	// Assign permissions jika ada
-	if len(req.Permissions) > 0 {
-		err = s.roleRepo.AssignPermissionsToRole(ctx, createdRole.ID, req.Permissions)
+	// Simpan role, permissions, dan event dalam satu transaksi
+	var createdRole *model.Role
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		createdRole, err = s.roleRepo.CreateRole(ctx, role)
 		if err != nil {
-			return nil, fmt.Errorf("failed to assign permissions: %w", err)
+			return fmt.Errorf("failed to create role: %w", err)
 		}
-		
-		// Reload role dengan permissions
-		createdRole, err = s.roleRepo.GetRoleByID(ctx, createdRole.ID)
+
+		// Assign permissions jika ada
+		if len(req.Permissions) > 0 {
+			err = s.roleRepo.AssignPermissionsToRole(ctx, createdRole.ID, req.Permissions)
+			if err != nil {
+				return fmt.Errorf("failed to assign permissions: %w", err)
+			}
+
+			// Reload role dengan permissions
+			createdRole, err = s.roleRepo.GetRoleByID(ctx, createdRole.ID)
+			if err != nil {
+				return fmt.Errorf("failed to reload role: %w", err)
+			}
+		}
+
+		err = s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventRoleCreated, model.EventAggregateRole, createdRole.ID.String(), map[string]interface{}{
+			"role_id":      createdRole.ID,
+			"name":         createdRole.Name,
+			"display_name": createdRole.DisplayName,
+			"permissions":  rolePermissionNames(createdRole),
+		}))
 		if err != nil {
-			return nil, fmt.Errorf("failed to reload role: %w", err)
+			return fmt.Errorf("failed to publish role event: %w", err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionRoleCreated, model.AuditResourceRole, createdRole.ID.String(), map[string]interface{}{
+		"name":        createdRole.Name,
+		"permissions": req.Permissions,
+	})
+
 	roleResponse := createdRole.ToRoleResponse()
 	return &roleResponse, nil
 }
 
 // UpdateRole mengupdate role
-func (s *roleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *model.UpdateRoleRequest) (*model.RoleResponse, error) {
+func (s *roleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *model.UpdateRoleRequest, actor *model.AuditActor) (*model.RoleResponse, error) {
 	// Cek apakah role ada
 	role, err := s.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
@@ -187,44 +216,82 @@ func (s *roleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *mod
 		return nil, fmt.Errorf("failed to get role: %w", err)
 	}
 
-	// Update fields yang diberikan
-	if req.DisplayName != nil {
+	// Update fields yang diberikan, perubahan dicatat untuk audit log
+	changes := make(map[string]interface{})
+	if req.DisplayName != nil && *req.DisplayName != role.DisplayName {
+		changes["display_name"] = map[string]interface{}{"from": role.DisplayName, "to": *req.DisplayName}
 		role.DisplayName = *req.DisplayName
 	}
-	if req.Description != nil {
+	if req.Description != nil && *req.Description != role.Description {
+		changes["description"] = map[string]interface{}{"from": role.Description, "to": *req.Description}
 		role.Description = *req.Description
 	}
-	if req.Active != nil {
+	if req.Active != nil && *req.Active != role.Active {
+		changes["active"] = map[string]interface{}{"from": role.Active, "to": *req.Active}
 		role.Active = *req.Active
 	}
+	previousPermissions := rolePermissionIDs(role)
 
-	// Update role
-	updatedRole, err := s.roleRepo.UpdateRole(ctx, role)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update role: %w", err)
+	// Validasi permissions
+	for _, permissionID := range req.Permissions {
+		_, err := s.permissionRepo.GetPermissionByID(ctx, permissionID)
+		if err != nil {
+			return nil, ErrInvalidPermissions
+		}
 	}
 
-	// Update permissions jika diberikan
-	if len(req.Permissions) > 0 {
-		// Validasi permissions
-		for _, permissionID := range req.Permissions {
-			_, err := s.permissionRepo.GetPermissionByID(ctx, permissionID)
+	// Update role, permissions, dan event dalam satu transaksi
+	var updatedRole *model.Role
+	var added, removed []uuid.UUID
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		updatedRole, err = s.roleRepo.UpdateRole(ctx, role)
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+
+		// Replace permissions jika diberikan
+		if len(req.Permissions) > 0 {
+			err = s.roleRepo.ReplaceRolePermissions(ctx, roleID, req.Permissions)
 			if err != nil {
-				return nil, ErrInvalidPermissions
+				return fmt.Errorf("failed to update permissions: %w", err)
 			}
+
+			// Reload role dengan permissions
+			updatedRole, err = s.roleRepo.GetRoleByID(ctx, roleID)
+			if err != nil {
+				return fmt.Errorf("failed to reload role: %w", err)
+			}
+			added, removed = diffPermissionIDs(previousPermissions, rolePermissionIDs(updatedRole))
 		}
 
-		// Replace permissions
-		err = s.roleRepo.ReplaceRolePermissions(ctx, roleID, req.Permissions)
-		if err != nil {
-			return nil, fmt.Errorf("failed to update permissions: %w", err)
+		if len(changes) > 0 {
+			err = s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventRoleUpdated, model.EventAggregateRole, roleID.String(), map[string]interface{}{
+				"role_id": roleID,
+				"name":    role.Name,
+				"changes": changes,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to publish role event: %w", err)
+			}
 		}
-		
-		// Reload role dengan permissions
-		updatedRole, err = s.roleRepo.GetRoleByID(ctx, roleID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to reload role: %w", err)
+		if len(added) > 0 || len(removed) > 0 {
+			return s.publishPermissionChange(ctx, role.Name, roleID, added, removed)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, actor, nil, model.AuditActionRoleUpdated, model.AuditResourceRole, roleID.String(), map[string]interface{}{
+			"name":    role.Name,
+			"changes": changes,
+		})
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		s.recordPermissionChange(ctx, actor, role.Name, roleID, added, removed)
 	}
 
 	roleResponse := updatedRole.ToRoleResponse()
@@ -232,9 +299,9 @@ func (s *roleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *mod
 }
 
 // DeleteRole menghapus role
-func (s *roleService) DeleteRole(ctx context.Context, roleID uuid.UUID) error {
+func (s *roleService) DeleteRole(ctx context.Context, roleID uuid.UUID, actor *model.AuditActor) error {
 	// Cek apakah role ada
-	_, err := s.roleRepo.GetRoleByID(ctx, roleID)
+	role, err := s.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
 		if err == repository.ErrRoleNotFound {
 			return ErrRoleNotFound
@@ -252,12 +319,29 @@ func (s *roleService) DeleteRole(ctx context.Context, roleID uuid.UUID) error {
 		return ErrRoleInUse
 	}
 
-	// Hapus role
-	err = s.roleRepo.DeleteRole(ctx, roleID)
+	// Hapus role beserta event dalam satu transaksi
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepo.DeleteRole(ctx, roleID); err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+
+		err := s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventRoleDeleted, model.EventAggregateRole, roleID.String(), map[string]interface{}{
+			"role_id": roleID,
+			"name":    role.Name,
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to publish role event: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete role: %w", err)
+		return err
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionRoleDeleted, model.AuditResourceRole, roleID.String(), map[string]interface{}{
+		"name": role.Name,
+	})
+
 	return nil
 }
 
@@ -308,7 +392,7 @@ func (s *roleService) GetPermissionByID(ctx context.Context, permissionID uuid.U
 }
 
 // CreatePermission membuat permission baru
-func (s *roleService) CreatePermission(ctx context.Context, req *model.CreatePermissionRequest) (*model.PermissionResponse, error) {
+func (s *roleService) CreatePermission(ctx context.Context, req *model.CreatePermissionRequest, actor *model.AuditActor) (*model.PermissionResponse, error) {
 	// Cek apakah permission sudah ada
 	existingPermission, err := s.permissionRepo.GetPermissionByName(ctx, req.Name)
 	if err == nil && existingPermission != nil {
@@ -331,12 +415,18 @@ func (s *roleService) CreatePermission(ctx context.Context, req *model.CreatePer
 		return nil, fmt.Errorf("failed to create permission: %w", err)
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionPermissionCreated, model.AuditResourcePermission, createdPermission.ID.String(), map[string]interface{}{
+		"name":     createdPermission.Name,
+		"resource": createdPermission.Resource,
+		"action":   createdPermission.Action,
+	})
+
 	permissionResponse := createdPermission.ToPermissionResponse()
 	return &permissionResponse, nil
 }
 
 // UpdatePermission mengupdate permission
-func (s *roleService) UpdatePermission(ctx context.Context, permissionID uuid.UUID, req *model.UpdatePermissionRequest) (*model.PermissionResponse, error) {
+func (s *roleService) UpdatePermission(ctx context.Context, permissionID uuid.UUID, req *model.UpdatePermissionRequest, actor *model.AuditActor) (*model.PermissionResponse, error) {
 	// Cek apakah permission ada
 	permission, err := s.permissionRepo.GetPermissionByID(ctx, permissionID)
 	if err != nil {
@@ -346,7 +436,14 @@ func (s *roleService) UpdatePermission(ctx context.Context, permissionID uuid.UU
 		return nil, fmt.Errorf("failed to get permission: %w", err)
 	}
 
-	// Update fields yang diberikan
+	// Update fields yang diberikan, nilai sebelumnya dicatat untuk audit log
+	previous := map[string]interface{}{
+		"display_name": permission.DisplayName,
+		"description":  permission.Description,
+		"resource":     permission.Resource,
+		"action":       permission.Action,
+		"active":       permission.Active,
+	}
 	if req.DisplayName != nil {
 		permission.DisplayName = *req.DisplayName
 	}
@@ -369,14 +466,19 @@ func (s *roleService) UpdatePermission(ctx context.Context, permissionID uuid.UU
 		return nil, fmt.Errorf("failed to update permission: %w", err)
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionPermissionUpdated, model.AuditResourcePermission, permissionID.String(), map[string]interface{}{
+		"name":     updatedPermission.Name,
+		"previous": previous,
+	})
+
 	permissionResponse := updatedPermission.ToPermissionResponse()
 	return &permissionResponse, nil
 }
 
 // DeletePermission menghapus permission
-func (s *roleService) DeletePermission(ctx context.Context, permissionID uuid.UUID) error {
+func (s *roleService) DeletePermission(ctx context.Context, permissionID uuid.UUID, actor *model.AuditActor) error {
 	// Cek apakah permission ada
-	_, err := s.permissionRepo.GetPermissionByID(ctx, permissionID)
+	permission, err := s.permissionRepo.GetPermissionByID(ctx, permissionID)
 	if err != nil {
 		if err == repository.ErrPermissionNotFound {
 			return ErrPermissionNotFound
@@ -390,13 +492,17 @@ func (s *roleService) DeletePermission(ctx context.Context, permissionID uuid.UU
 		return fmt.Errorf("failed to delete permission: %w", err)
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionPermissionDeleted, model.AuditResourcePermission, permissionID.String(), map[string]interface{}{
+		"name": permission.Name,
+	})
+
 	return nil
 }
 
 // AssignPermissionsToRole menambahkan permissions ke role
-func (s *roleService) AssignPermissionsToRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+func (s *roleService) AssignPermissionsToRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID, actor *model.AuditActor) error {
 	// Validasi role
-	_, err := s.roleRepo.GetRoleByID(ctx, roleID)
+	role, err := s.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
 		if err == repository.ErrRoleNotFound {
 			return ErrRoleNotFound
@@ -412,19 +518,30 @@ func (s *roleService) AssignPermissionsToRole(ctx context.Context, roleID uuid.U
 		}
 	}
 
-	// Assign permissions
-	err = s.roleRepo.AssignPermissionsToRole(ctx, roleID, permissionIDs)
+	// Assign permissions beserta event dalam satu transaksi
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepo.AssignPermissionsToRole(ctx, roleID, permissionIDs); err != nil {
+			return fmt.Errorf("failed to assign permissions: %w", err)
+		}
+		return s.publishPermissionChange(ctx, role.Name, roleID, permissionIDs, []uuid.UUID{})
+	})
 	if err != nil {
-		return fmt.Errorf("failed to assign permissions: %w", err)
+		return err
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionRolePermissionsChanged, model.AuditResourceRole, roleID.String(), map[string]interface{}{
+		"name":    role.Name,
+		"added":   permissionIDs,
+		"removed": []uuid.UUID{},
+	})
+
 	return nil
 }
 
 // RemovePermissionsFromRole menghapus permissions dari role
-func (s *roleService) RemovePermissionsFromRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID) error {
+func (s *roleService) RemovePermissionsFromRole(ctx context.Context, roleID uuid.UUID, permissionIDs []uuid.UUID, actor *model.AuditActor) error {
 	// Validasi role
-	_, err := s.roleRepo.GetRoleByID(ctx, roleID)
+	role, err := s.roleRepo.GetRoleByID(ctx, roleID)
 	if err != nil {
 		if err == repository.ErrRoleNotFound {
 			return ErrRoleNotFound
@@ -432,12 +549,23 @@ func (s *roleService) RemovePermissionsFromRole(ctx context.Context, roleID uuid
 		return fmt.Errorf("failed to get role: %w", err)
 	}
 
-	// Remove permissions
-	err = s.roleRepo.RemovePermissionsFromRole(ctx, roleID, permissionIDs)
+	// Remove permissions beserta event dalam satu transaksi
+	err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.roleRepo.RemovePermissionsFromRole(ctx, roleID, permissionIDs); err != nil {
+			return fmt.Errorf("failed to remove permissions: %w", err)
+		}
+		return s.publishPermissionChange(ctx, role.Name, roleID, []uuid.UUID{}, permissionIDs)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to remove permissions: %w", err)
+		return err
 	}
 
+	s.auditService.Record(ctx, actor, nil, model.AuditActionRolePermissionsChanged, model.AuditResourceRole, roleID.String(), map[string]interface{}{
+		"name":    role.Name,
+		"added":   []uuid.UUID{},
+		"removed": permissionIDs,
+	})
+
 	return nil
 }
 
@@ -467,12 +595,18 @@ func (s *roleService) GetUserPermissions(ctx context.Context, userID uuid.UUID)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Jika user tidak memiliki role baru, return empty
-	if user.RoleID == nil {
+	// Jika user belum memiliki role baru, gunakan role dengan nama yang sama dengan field legacy
+	var role *model.Role
+	if user.RoleID != nil {
+		role, err = s.roleRepo.GetRoleByID(ctx, *user.RoleID)
+	} else if user.Role != "" {
+		role, err = s.roleRepo.GetRoleByName(ctx, user.Role)
+		if err == repository.ErrRoleNotFound {
+			return []string{}, nil
+		}
+	} else {
 		return []string{}, nil
 	}
-
-	role, err := s.roleRepo.GetRoleByID(ctx, *user.RoleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user role: %w", err)
 	}
@@ -535,10 +669,31 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 		// Dashboard permissions
 		{Name: "dashboard:read", DisplayName: "View Dashboard", Description: "Access dashboard", Resource: "dashboard", Action: "read"},
 		{Name: "dashboard:stats", DisplayName: "View Statistics", Description: "View dashboard statistics", Resource: "dashboard", Action: "stats"},
+
+		// File storage permissions
+		{Name: "files:read", DisplayName: "Read Files", Description: "View, search and download own files", Resource: "files", Action: "read"},
+		{Name: "files:create", DisplayName: "Upload Files", Description: "Upload files and create folders", Resource: "files", Action: "create"},
+		{Name: "files:update", DisplayName: "Update Files", Description: "Rename and move own files and folders", Resource: "files", Action: "update"},
+		{Name: "files:delete", DisplayName: "Delete Files", Description: "Delete own files and folders", Resource: "files", Action: "delete"},
+		{Name: "files:manage", DisplayName: "Manage Files", Description: "Full access to files of all users and storage quotas", Resource: "files", Action: "manage"},
+
+		// Audit log permissions
+		{Name: "audit:read", DisplayName: "Read Audit Log", Description: "Query and export the security and admin audit log", Resource: "audit", Action: "read"},
+
+		// Webhook permissions
+		{Name: "webhooks:manage", DisplayName: "Manage Webhooks", Description: "Manage webhook subscriptions and view delivery logs", Resource: "webhooks", Action: "manage"},
+
+		// Job permissions
+		{Name: "jobs:manage", DisplayName: "Manage Jobs", Description: "List maintenance jobs, view run history and trigger jobs manually", Resource: "jobs", Action: "manage"},
+
+		// OAuth client permissions
+		{Name: "clients:manage", DisplayName: "Manage OAuth Clients", Description: "Create, rotate and delete service accounts", Resource: "clients", Action: "manage"},
+		{Name: "tokens:revoke", DisplayName: "Revoke Tokens", Description: "Revoke tokens that were not issued to the service account itself", Resource: "tokens", Action: "revoke"},
 	}
 
 	// Create permissions
 	permissionIDs := make(map[string]uuid.UUID)
+	createdPermissions := make(map[string]bool)
 	for _, permReq := range defaultPermissions {
 		// Check if permission already exists
 		existing, err := s.permissionRepo.GetPermissionByName(ctx, permReq.Name)
@@ -563,6 +718,7 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 		}
 
 		permissionIDs[permReq.Name] = createdPermission.ID
+		createdPermissions[permReq.Name] = true
 	}
 
 	// Default roles
@@ -578,6 +734,7 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 			description: "Full system access",
 			permissions: []string{
 				"users:manage", "roles:manage", "permissions:manage", "dashboard:read", "dashboard:stats",
+				"files:manage", "audit:read", "webhooks:manage", "jobs:manage", "clients:manage",
 			},
 		},
 		{
@@ -586,6 +743,7 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 			description: "Basic user access",
 			permissions: []string{
 				"dashboard:read",
+				"files:read", "files:create", "files:update", "files:delete",
 			},
 		},
 		{
@@ -594,6 +752,7 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 			description: "User management access",
 			permissions: []string{
 				"users:list", "users:read", "users:update", "dashboard:read", "dashboard:stats",
+				"files:read", "files:create", "files:update", "files:delete",
 			},
 		},
 	}
@@ -603,6 +762,18 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 		// Check if role already exists
 		existing, err := s.roleRepo.GetRoleByName(ctx, roleData.name)
 		if err == nil && existing != nil {
+			// Permission default yang baru ditambahkan juga diberikan ke role yang sudah ada
+			var newPermissionIDs []uuid.UUID
+			for _, permName := range roleData.permissions {
+				if createdPermissions[permName] {
+					newPermissionIDs = append(newPermissionIDs, permissionIDs[permName])
+				}
+			}
+			if len(newPermissionIDs) > 0 {
+				if err := s.roleRepo.AssignPermissionsToRole(ctx, existing.ID, newPermissionIDs); err != nil {
+					return fmt.Errorf("failed to assign permissions to role %s: %w", roleData.name, err)
+				}
+			}
 			continue
 		}
 
@@ -636,4 +807,71 @@ func (s *roleService) InitializeDefaultRolesAndPermissions(ctx context.Context)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// rolePermissionIDs mengembalikan ID permission yang dimiliki role
+func rolePermissionIDs(role *model.Role) []uuid.UUID {
+	ids := make([]uuid.UUID, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		ids[i] = permission.ID
+	}
+	return ids
+}
+
+// diffPermissionIDs mengembalikan permission yang ditambahkan dan dihapus dari previous ke current
+func diffPermissionIDs(previous, current []uuid.UUID) ([]uuid.UUID, []uuid.UUID) {
+	before := make(map[uuid.UUID]bool, len(previous))
+	for _, id := range previous {
+		before[id] = true
+	}
+	after := make(map[uuid.UUID]bool, len(current))
+	for _, id := range current {
+		after[id] = true
+	}
+
+	added := []uuid.UUID{}
+	for _, id := range current {
+		if !before[id] {
+			added = append(added, id)
+		}
+	}
+	removed := []uuid.UUID{}
+	for _, id := range previous {
+		if !after[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+// recordPermissionChange mencatat audit log perubahan permission role sebagai daftar
+// permission yang ditambahkan dan dihapus
+func (s *roleService) recordPermissionChange(ctx context.Context, actor *model.AuditActor, roleName string, roleID uuid.UUID, added, removed []uuid.UUID) {
+	s.auditService.Record(ctx, actor, nil, model.AuditActionRolePermissionsChanged, model.AuditResourceRole, roleID.String(), map[string]interface{}{
+		"name":    roleName,
+		"added":   added,
+		"removed": removed,
+	})
+}
+
+// publishPermissionChange menulis event perubahan permission role ke outbox
+func (s *roleService) publishPermissionChange(ctx context.Context, roleName string, roleID uuid.UUID, added, removed []uuid.UUID) error {
+	err := s.eventBus.Publish(ctx, model.NewDomainEvent(model.EventRolePermissionsChanged, model.EventAggregateRole, roleID.String(), map[string]interface{}{
+		"role_id": roleID,
+		"name":    roleName,
+		"added":   added,
+		"removed": removed,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to publish role event: %w", err)
+	}
+	return nil
+}
+
+// rolePermissionNames mengembalikan nama permission yang dimiliki role
+func rolePermissionNames(role *model.Role) []string {
+	names := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		names[i] = permission.Name
+	}
+	return names
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+)
+
+// Storage errors
+var (
+	ErrInvalidSignedURL = errors.New("invalid or expired signed URL")
+	ErrObjectNotFound   = errors.New("object not found")
+)
+
+// StorageService interface untuk menyajikan object dari file storage melalui signed URL
+type StorageService interface {
+	OpenSignedObject(ctx context.Context, key string, expires int64, signature string) (io.ReadCloser, error)
+}
+
+// storageService implementasi StorageService
+type storageService struct {
+	fileStorage repository.FileStorage
+	config      *config.Config
+}
+
+// NewStorageService membuat instance baru StorageService
+func NewStorageService(fileStorage repository.FileStorage, cfg *config.Config) StorageService {
+	return &storageService{
+		fileStorage: fileStorage,
+		config:      cfg,
+	}
+}
+
+// OpenSignedObject memverifikasi signature dan masa berlaku signed URL lalu membuka object
+func (s *storageService) OpenSignedObject(ctx context.Context, key string, expires int64, signature string) (io.ReadCloser, error) {
+	if repository.ValidateObjectKey(key) != nil {
+		return nil, ErrInvalidSignedURL
+	}
+	if !utils.VerifyObjectSignature(key, expires, signature, s.config.Storage.SigningKey, time.Now()) {
+		return nil, ErrInvalidSignedURL
+	}
+
+	object, err := s.fileStorage.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, repository.ErrObjectNotFound) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	return object, nil
+}
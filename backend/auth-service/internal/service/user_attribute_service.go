@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// maxAttributeStringLength sama dengan panjang kolom user_attribute_values.string_value
+const maxAttributeStringLength = 255
+
+var (
+	// attributeNamePattern membatasi nama attribute agar aman dipakai sebagai key JSON dan query parameter
+	attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	// attributeClaimPattern membatasi nama klaim JWT untuk attribute
+	attributeClaimPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,63}$`)
+)
+
+// User attribute errors
+var (
+	ErrAttributeDefinitionNotFound = errors.New("attribute definition not found")
+	ErrAttributeDefinitionExists   = errors.New("attribute definition already exists")
+	ErrInvalidAttributeDefinition  = errors.New("invalid attribute definition")
+	ErrUnknownAttribute            = errors.New("unknown attribute")
+	ErrInvalidAttributeValue       = errors.New("invalid attribute value")
+	ErrAttributeRequired           = errors.New("required attribute is missing")
+	ErrAttributeNotEditable        = errors.New("attribute cannot be changed by the user")
+)
+
+// UserAttributeService interface untuk pengelolaan definisi dan nilai custom attribute user
+type UserAttributeService interface {
+	GetDefinitions(ctx context.Context, admin bool) ([]model.AttributeDefinitionResponse, error)
+	CreateDefinition(ctx context.Context, req *model.CreateAttributeDefinitionRequest) (*model.AttributeDefinitionResponse, error)
+	UpdateDefinition(ctx context.Context, definitionID uuid.UUID, req *model.UpdateAttributeDefinitionRequest) (*model.AttributeDefinitionResponse, error)
+	DeleteDefinition(ctx context.Context, definitionID uuid.UUID) error
+	GetUserAttributes(ctx context.Context, userID uuid.UUID, admin bool) (map[string]interface{}, error)
+	SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]interface{}, admin bool) (map[string]interface{}, error)
+}
+
+// userAttributeService implementasi UserAttributeService
+type userAttributeService struct {
+	attributeRepo repository.UserAttributeRepository
+	userRepo      repository.UserRepository
+	tokenRepo     repository.TokenRepository
+	config        *config.Config
+}
+
+// NewUserAttributeService membuat instance baru UserAttributeService
+func NewUserAttributeService(attributeRepo repository.UserAttributeRepository, userRepo repository.UserRepository, tokenRepo repository.TokenRepository, cfg *config.Config) UserAttributeService {
+	return &userAttributeService{
+		attributeRepo: attributeRepo,
+		userRepo:      userRepo,
+		tokenRepo:     tokenRepo,
+		config:        cfg,
+	}
+}
+
+// GetDefinitions mendapatkan definisi attribute. Untuk user biasa hanya definisi
+// yang terlihat oleh user sendiri yang dikembalikan.
+func (s *userAttributeService) GetDefinitions(ctx context.Context, admin bool) ([]model.AttributeDefinitionResponse, error) {
+	definitions, err := s.attributeRepo.ListDefinitions(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses := make([]model.AttributeDefinitionResponse, 0, len(definitions))
+	for i := range definitions {
+		if !admin && !definitions[i].VisibleToSelf() {
+			continue
+		}
+		responses = append(responses, definitions[i].ToAttributeDefinitionResponse())
+	}
+
+	return responses, nil
+}
+
+// CreateDefinition membuat definisi attribute baru
+func (s *userAttributeService) CreateDefinition(ctx context.Context, req *model.CreateAttributeDefinitionRequest) (*model.AttributeDefinitionResponse, error) {
+	definition := &model.AttributeDefinition{
+		Name:        strings.TrimSpace(req.Name),
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Description: strings.TrimSpace(req.Description),
+		Type:        req.Type,
+		Required:    req.Required,
+		Visibility:  req.Visibility,
+		Pattern:     req.Pattern,
+		MinValue:    req.MinValue,
+		MaxValue:    req.MaxValue,
+		JWTClaim:    strings.TrimSpace(req.JWTClaim),
+	}
+	if definition.Visibility == "" {
+		definition.Visibility = model.AttributeVisibilityAdmin
+	}
+	definition.SetOptionList(req.Options)
+
+	if err := s.validateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+
+	if err := s.attributeRepo.CreateDefinition(ctx, definition); err != nil {
+		if errors.Is(err, repository.ErrAttributeDefinitionExists) {
+			return nil, ErrAttributeDefinitionExists
+		}
+		return nil, ErrInternalServerError
+	}
+
+	response := definition.ToAttributeDefinitionResponse()
+	return &response, nil
+}
+
+// UpdateDefinition mengubah definisi attribute. Perubahan visibilitas dan klaim JWT
+// berlaku untuk token baru dan cache data user yang sudah kedaluwarsa.
+func (s *userAttributeService) UpdateDefinition(ctx context.Context, definitionID uuid.UUID, req *model.UpdateAttributeDefinitionRequest) (*model.AttributeDefinitionResponse, error) {
+	definition, err := s.attributeRepo.FindDefinitionByID(ctx, definitionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttributeDefinitionNotFound) {
+			return nil, ErrAttributeDefinitionNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	if req.DisplayName != nil {
+		definition.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Description != nil {
+		definition.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Required != nil {
+		definition.Required = *req.Required
+	}
+	if req.Visibility != nil {
+		definition.Visibility = *req.Visibility
+	}
+	if req.Pattern != nil {
+		definition.Pattern = *req.Pattern
+	}
+	if req.MinValue != nil {
+		definition.MinValue = req.MinValue
+	}
+	if req.MaxValue != nil {
+		definition.MaxValue = req.MaxValue
+	}
+	if req.Options != nil {
+		definition.SetOptionList(*req.Options)
+	}
+	if req.JWTClaim != nil {
+		definition.JWTClaim = strings.TrimSpace(*req.JWTClaim)
+	}
+
+	if err := s.validateDefinition(ctx, definition); err != nil {
+		return nil, err
+	}
+
+	definition.UpdatedAt = time.Now()
+	if err := s.attributeRepo.UpdateDefinition(ctx, definition); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	response := definition.ToAttributeDefinitionResponse()
+	return &response, nil
+}
+
+// DeleteDefinition menghapus definisi attribute beserta semua nilainya
+func (s *userAttributeService) DeleteDefinition(ctx context.Context, definitionID uuid.UUID) error {
+	userIDs, err := s.attributeRepo.DeleteDefinition(ctx, definitionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAttributeDefinitionNotFound) {
+			return ErrAttributeDefinitionNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	for _, userID := range userIDs {
+		s.tokenRepo.InvalidateUserCache(ctx, userID)
+	}
+
+	return nil
+}
+
+// GetUserAttributes mendapatkan nilai attribute user. Untuk user sendiri (admin false)
+// attribute dengan visibilitas admin tidak disertakan.
+func (s *userAttributeService) GetUserAttributes(ctx context.Context, userID uuid.UUID, admin bool) (map[string]interface{}, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	values, err := s.attributeRepo.FindValuesByUserIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return model.AttributeMap(values, admin), nil
+}
+
+// SetUserAttributes mengubah sebagian nilai attribute user; nilai nil menghapus attribute.
+// User sendiri (admin false) hanya dapat mengubah attribute dengan visibilitas self_edit.
+// Attribute wajib yang dapat diubah pemanggil harus tetap memiliki nilai setelah perubahan.
+func (s *userAttributeService) SetUserAttributes(ctx context.Context, userID uuid.UUID, attributes map[string]interface{}, admin bool) (map[string]interface{}, error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	definitions, err := s.attributeRepo.ListDefinitions(ctx)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	definitionsByName := make(map[string]*model.AttributeDefinition, len(definitions))
+	for i := range definitions {
+		definitionsByName[definitions[i].Name] = &definitions[i]
+	}
+
+	current, err := s.attributeRepo.FindValuesByUserIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	hasValue := make(map[uuid.UUID]bool, len(current))
+	for _, value := range current {
+		hasValue[value.DefinitionID] = true
+	}
+
+	var values []model.UserAttributeValue
+	var removed []uuid.UUID
+	for name, raw := range attributes {
+		definition, exists := definitionsByName[name]
+		// Attribute khusus admin diperlakukan seolah tidak ada bagi user biasa
+		if !exists || (!admin && !definition.VisibleToSelf()) {
+			return nil, ErrUnknownAttribute
+		}
+		if !admin && definition.Visibility != model.AttributeVisibilitySelfEdit {
+			return nil, ErrAttributeNotEditable
+		}
+
+		if raw == nil {
+			removed = append(removed, definition.ID)
+			hasValue[definition.ID] = false
+			continue
+		}
+
+		value, err := parseAttributeValue(definition, raw)
+		if err != nil {
+			return nil, err
+		}
+		value.UserID = userID
+		values = append(values, *value)
+		hasValue[definition.ID] = true
+	}
+
+	for i := range definitions {
+		editable := admin || definitions[i].Visibility == model.AttributeVisibilitySelfEdit
+		if definitions[i].Required && editable && !hasValue[definitions[i].ID] {
+			return nil, ErrAttributeRequired
+		}
+	}
+
+	if err := s.attributeRepo.SaveUserValues(ctx, userID, values, removed); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	// Hapus cache agar UserResponse dan token berikutnya memakai nilai terbaru
+	s.tokenRepo.InvalidateUserCache(ctx, userID)
+
+	return s.GetUserAttributes(ctx, userID, admin)
+}
+
+// validateDefinition memeriksa konsistensi aturan validasi definisi attribute
+func (s *userAttributeService) validateDefinition(ctx context.Context, definition *model.AttributeDefinition) error {
+	if !attributeNamePattern.MatchString(definition.Name) || definition.DisplayName == "" {
+		return ErrInvalidAttributeDefinition
+	}
+
+	options := definition.OptionList()
+	if (definition.Type == model.AttributeTypeEnum) != (len(options) > 0) {
+		return ErrInvalidAttributeDefinition
+	}
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		if seen[option] {
+			return ErrInvalidAttributeDefinition
+		}
+		seen[option] = true
+	}
+
+	if definition.Pattern != "" {
+		if definition.Type != model.AttributeTypeString {
+			return ErrInvalidAttributeDefinition
+		}
+		if _, err := regexp.Compile(definition.Pattern); err != nil {
+			return ErrInvalidAttributeDefinition
+		}
+	}
+
+	if definition.MinValue != nil || definition.MaxValue != nil {
+		if definition.Type != model.AttributeTypeString && definition.Type != model.AttributeTypeNumber {
+			return ErrInvalidAttributeDefinition
+		}
+		if definition.MinValue != nil && definition.MaxValue != nil && *definition.MinValue > *definition.MaxValue {
+			return ErrInvalidAttributeDefinition
+		}
+		if definition.Type == model.AttributeTypeString && definition.MinValue != nil && *definition.MinValue < 0 {
+			return ErrInvalidAttributeDefinition
+		}
+	}
+
+	if definition.JWTClaim != "" {
+		// Access token dapat dibaca user, sehingga attribute khusus admin tidak boleh dipetakan
+		if !attributeClaimPattern.MatchString(definition.JWTClaim) || definition.Visibility == model.AttributeVisibilityAdmin {
+			return ErrInvalidAttributeDefinition
+		}
+
+		definitions, err := s.attributeRepo.ListDefinitions(ctx)
+		if err != nil {
+			return ErrInternalServerError
+		}
+		for _, other := range definitions {
+			if other.ID != definition.ID && other.JWTClaim == definition.JWTClaim {
+				return ErrInvalidAttributeDefinition
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseAttributeValue memvalidasi nilai JSON terhadap definisi attribute dan
+// menyimpannya di kolom sesuai tipenya
+func parseAttributeValue(definition *model.AttributeDefinition, raw interface{}) (*model.UserAttributeValue, error) {
+	value := &model.UserAttributeValue{
+		DefinitionID: definition.ID,
+		Definition:   *definition,
+		UpdatedAt:    time.Now(),
+	}
+
+	switch definition.Type {
+	case model.AttributeTypeString:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, ErrInvalidAttributeValue
+		}
+		text = strings.TrimSpace(text)
+		length := float64(utf8.RuneCountInString(text))
+		if length > maxAttributeStringLength ||
+			(definition.MinValue != nil && length < *definition.MinValue) ||
+			(definition.MaxValue != nil && length > *definition.MaxValue) {
+			return nil, ErrInvalidAttributeValue
+		}
+		if definition.Pattern != "" {
+			pattern, err := regexp.Compile(definition.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return nil, ErrInvalidAttributeValue
+			}
+		}
+		value.StringValue = &text
+
+	case model.AttributeTypeEnum:
+		text, ok := raw.(string)
+		if !ok || !containsString(definition.OptionList(), text) {
+			return nil, ErrInvalidAttributeValue
+		}
+		value.StringValue = &text
+
+	case model.AttributeTypeNumber:
+		number, ok := raw.(float64)
+		if !ok ||
+			(definition.MinValue != nil && number < *definition.MinValue) ||
+			(definition.MaxValue != nil && number > *definition.MaxValue) {
+			return nil, ErrInvalidAttributeValue
+		}
+		value.NumberValue = &number
+
+	case model.AttributeTypeBoolean:
+		flag, ok := raw.(bool)
+		if !ok {
+			return nil, ErrInvalidAttributeValue
+		}
+		value.BoolValue = &flag
+
+	case model.AttributeTypeDate:
+		text, ok := raw.(string)
+		if !ok {
+			return nil, ErrInvalidAttributeValue
+		}
+		date, err := time.Parse("2006-01-02", text)
+		if err != nil {
+			return nil, ErrInvalidAttributeValue
+		}
+		value.DateValue = &date
+
+	default:
+		return nil, ErrInvalidAttributeValue
+	}
+
+	return value, nil
+}
+
+// resolveAttributeFilters mengkonversi filter attr.<name>=<value> dari query string ke
+// filter bertipe sesuai definisi attribute
+func resolveAttributeFilters(ctx context.Context, attributeRepo repository.UserAttributeRepository, query map[string]string) ([]model.AttributeFilter, error) {
+	if len(query) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+
+	definitions, err := attributeRepo.FindDefinitionsByNames(ctx, names)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	if len(definitions) != len(names) {
+		return nil, ErrUnknownAttribute
+	}
+
+	filters := make([]model.AttributeFilter, 0, len(definitions))
+	for _, definition := range definitions {
+		raw := query[definition.Name]
+		filter := model.AttributeFilter{DefinitionID: definition.ID, Type: definition.Type}
+
+		switch definition.Type {
+		case model.AttributeTypeNumber:
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, ErrInvalidAttributeValue
+			}
+			filter.Value = number
+		case model.AttributeTypeBoolean:
+			flag, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, ErrInvalidAttributeValue
+			}
+			filter.Value = flag
+		case model.AttributeTypeDate:
+			date, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				return nil, ErrInvalidAttributeValue
+			}
+			filter.Value = date
+		default:
+			filter.Value = raw
+		}
+
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// groupAttributeValues mengelompokkan nilai attribute berdasarkan user
+func groupAttributeValues(values []model.UserAttributeValue) map[uuid.UUID][]model.UserAttributeValue {
+	grouped := make(map[uuid.UUID][]model.UserAttributeValue)
+	for _, value := range values {
+		grouped[value.UserID] = append(grouped[value.UserID], value)
+	}
+	return grouped
+}
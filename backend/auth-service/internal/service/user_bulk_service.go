@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Bulk action related errors
+var (
+	ErrBulkNoTarget       = errors.New("either user_ids or filter must be provided")
+	ErrBulkAmbiguous      = errors.New("user_ids and filter cannot be combined")
+	ErrBulkTooManyUsers   = errors.New("bulk action exceeds the maximum number of users")
+	ErrBulkRoleRequired   = errors.New("role or role_id is required for change_role")
+	ErrBulkUnknownAction  = errors.New("unknown bulk action")
+	ErrBulkInvalidRoleRef = errors.New("role and role_id refer to different roles")
+)
+
+// bulkBatchSize adalah jumlah user yang diproses per batch
+const bulkBatchSize = 100
+
+// UserBulkService interface untuk layanan bulk action user oleh admin
+type UserBulkService interface {
+	ExecuteBulkAction(ctx context.Context, req *model.BulkUserActionRequest, actor *model.AuditActor) (*model.BulkUserActionResponse, error)
+}
+
+// userBulkService implementasi UserBulkService
+type userBulkService struct {
+	userRepo    repository.UserRepository
+	tokenRepo   repository.TokenRepository
+	auditRepo   repository.AuditRepository
+	authService AuthService
+	roleService RoleService
+	txManager   repository.TransactionManager
+	eventBus    EventBus
+	config      *config.Config
+}
+
+// NewUserBulkService membuat instance baru UserBulkService
+func NewUserBulkService(userRepo repository.UserRepository, tokenRepo repository.TokenRepository, auditRepo repository.AuditRepository, authService AuthService, roleService RoleService, txManager repository.TransactionManager, eventBus EventBus, cfg *config.Config) UserBulkService {
+	return &userBulkService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		auditRepo:   auditRepo,
+		authService: authService,
+		roleService: roleService,
+		txManager:   txManager,
+		eventBus:    eventBus,
+		config:      cfg,
+	}
+}
+
+// bulkOperation menyimpan parameter satu bulk action yang sedang berjalan
+type bulkOperation struct {
+	id         uuid.UUID
+	action     string
+	legacyRole string     // role legacy tujuan untuk change_role
+	roleID     *uuid.UUID // role RBAC tujuan untuk change_role
+	roleName   string
+	actor      *model.AuditActor
+	response   *model.BulkUserActionResponse
+}
+
+// ExecuteBulkAction menjalankan aksi terhadap user yang dipilih dengan ID atau filter, per batch
+func (s *userBulkService) ExecuteBulkAction(ctx context.Context, req *model.BulkUserActionRequest, actor *model.AuditActor) (*model.BulkUserActionResponse, error) {
+	if len(req.UserIDs) == 0 && req.Filter == nil {
+		return nil, ErrBulkNoTarget
+	}
+	if len(req.UserIDs) > 0 && req.Filter != nil {
+		return nil, ErrBulkAmbiguous
+	}
+
+	op := &bulkOperation{
+		id:     uuid.New(),
+		action: req.Action,
+		actor:  actor,
+		response: &model.BulkUserActionResponse{
+			Action:  req.Action,
+			Results: []model.BulkUserActionResult{},
+		},
+	}
+
+	switch req.Action {
+	case model.BulkActionDeactivate, model.BulkActionActivate, model.BulkActionForcePasswordReset, model.BulkActionRevokeSessions:
+	case model.BulkActionChangeRole:
+		if err := s.resolveTargetRole(ctx, req, op); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrBulkUnknownAction
+	}
+
+	if len(req.UserIDs) > 0 {
+		if err := s.runByIDs(ctx, req.UserIDs, op); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := s.runByFilter(ctx, req.Filter, op); err != nil {
+			return nil, err
+		}
+	}
+
+	op.response.Total = len(op.response.Results)
+	return op.response, nil
+}
+
+// runByIDs memproses user berdasarkan daftar ID per batch
+func (s *userBulkService) runByIDs(ctx context.Context, userIDs []uuid.UUID, op *bulkOperation) error {
+	// Hapus ID duplikat dengan tetap mempertahankan urutan
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	ids := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if s.config.Security.BulkActionMaxUsers > 0 && len(ids) > s.config.Security.BulkActionMaxUsers {
+		return ErrBulkTooManyUsers
+	}
+
+	for start := 0; start < len(ids); start += bulkBatchSize {
+		end := start + bulkBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+
+		users, err := s.userRepo.FindByIDs(ctx, batch)
+		if err != nil {
+			return ErrInternalServerError
+		}
+
+		found := make(map[uuid.UUID]bool, len(users))
+		for _, user := range users {
+			found[user.ID] = true
+		}
+		for _, id := range batch {
+			if !found[id] {
+				op.addResult(model.BulkUserActionResult{UserID: id, Status: model.BulkResultFailed, Error: "user not found"})
+			}
+		}
+
+		s.processBatch(ctx, users, op)
+	}
+
+	return nil
+}
+
+// runByFilter memproses semua user yang cocok dengan filter menggunakan cursor pagination.
+// Cursor berbasis kolom pengurutan dan ID sehingga perubahan status oleh aksi ini tidak
+// menggeser halaman berikutnya.
+func (s *userBulkService) runByFilter(ctx context.Context, filter *model.UserFilter, op *bulkOperation) error {
+	batch := *filter
+	batch.SortBy = model.UserSortCreatedAt
+	batch.SortOrder = "asc"
+	batch.Limit = bulkBatchSize
+	batch.UseCursor = true
+	batch.Cursor = ""
+
+	for first := true; ; first = false {
+		users, total, nextCursor, err := s.userRepo.GetAllUsers(ctx, &batch)
+		if err != nil {
+			return ErrInternalServerError
+		}
+
+		// Batas jumlah user diperiksa sebelum ada perubahan yang dijalankan
+		if first && s.config.Security.BulkActionMaxUsers > 0 && total > int64(s.config.Security.BulkActionMaxUsers) {
+			return ErrBulkTooManyUsers
+		}
+
+		s.processBatch(ctx, users, op)
+
+		if nextCursor == "" {
+			return nil
+		}
+		batch.Cursor = nextCursor
+	}
+}
+
+// processBatch menjalankan aksi untuk satu batch user dan mencatat hasil serta audit per user
+func (s *userBulkService) processBatch(ctx context.Context, users []model.User, op *bulkOperation) {
+	eligible := make([]model.User, 0, len(users))
+	for _, user := range users {
+		if reason := op.skipReason(&user); reason != "" {
+			op.addResult(model.BulkUserActionResult{UserID: user.ID, Email: user.Email, Status: model.BulkResultSkipped, Error: reason})
+			continue
+		}
+		eligible = append(eligible, user)
+	}
+	if len(eligible) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(eligible))
+	for i, user := range eligible {
+		ids[i] = user.ID
+	}
+
+	// Perubahan kolom dijalankan sekaligus untuk satu batch
+	var err error
+	switch op.action {
+	case model.BulkActionDeactivate:
+		err = s.userRepo.UpdateStatusBatch(ctx, ids, false)
+	case model.BulkActionActivate:
+		err = s.userRepo.UpdateStatusBatch(ctx, ids, true)
+	case model.BulkActionChangeRole:
+		// Event user.role_changed ditulis ke outbox dalam transaksi yang sama dengan perubahan role
+		err = s.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := s.userRepo.UpdateRoleBatch(ctx, ids, op.legacyRole, op.roleID); err != nil {
+				return err
+			}
+
+			events := make([]*model.DomainEvent, len(eligible))
+			for i := range eligible {
+				data := userEventData(&eligible[i])
+				data["previous_role"] = eligible[i].Role
+				data["previous_role_id"] = eligible[i].RoleID
+				data["role"] = op.roleName
+				data["role_id"] = op.roleID
+				events[i] = model.NewDomainEvent(model.EventUserRoleChanged, model.EventAggregateUser, eligible[i].ID.String(), data)
+			}
+			return s.eventBus.Publish(ctx, events...)
+		})
+	case model.BulkActionForcePasswordReset:
+		err = s.userRepo.SetPasswordResetRequired(ctx, ids, true)
+	}
+	if err != nil {
+		for _, user := range eligible {
+			op.addResult(model.BulkUserActionResult{UserID: user.ID, Email: user.Email, Status: model.BulkResultFailed, Error: "failed to update user"})
+		}
+		return
+	}
+
+	auditEntries := make([]model.AuditLog, 0, len(eligible))
+	for _, user := range eligible {
+		result := model.BulkUserActionResult{UserID: user.ID, Email: user.Email, Status: model.BulkResultSucceeded}
+		details := map[string]interface{}{
+			"bulk":         true,
+			"operation_id": op.id,
+		}
+
+		switch op.action {
+		case model.BulkActionActivate:
+			s.tokenRepo.InvalidateUserCache(ctx, user.ID)
+		case model.BulkActionChangeRole:
+			details["previous_role"] = user.Role
+			details["previous_role_id"] = user.RoleID
+			details["role"] = op.roleName
+			details["role_id"] = op.roleID
+			s.tokenRepo.InvalidateUserCache(ctx, user.ID)
+		case model.BulkActionForcePasswordReset:
+			// Link reset dikirim ke email user; token hanya dikembalikan untuk driver mail "log"
+			token, expiresAt, err := s.authService.SendPasswordSetupLink(ctx, &user)
+			if err != nil {
+				result.Status = model.BulkResultFailed
+				result.Error = "password reset is required but the reset token could not be issued"
+			} else {
+				result.ResetToken = token
+				details["reset_token_expires_at"] = expiresAt
+			}
+			s.authService.RevokeUserSessions(ctx, user.ID)
+		default:
+			// deactivate dan revoke_sessions
+			s.authService.RevokeUserSessions(ctx, user.ID)
+		}
+
+		op.addResult(result)
+		auditEntries = append(auditEntries, op.auditEntry(&user, details))
+	}
+
+	if err := s.auditRepo.CreateBatch(ctx, auditEntries); err != nil {
+		log.Printf("Failed to write audit log for bulk action %s: %v", op.id, err)
+	}
+}
+
+// resolveTargetRole menentukan role tujuan change_role dari nama role atau ID role
+func (s *userBulkService) resolveTargetRole(ctx context.Context, req *model.BulkUserActionRequest, op *bulkOperation) error {
+	var role *model.RoleResponse
+	var err error
+
+	switch {
+	case req.RoleID != nil:
+		role, err = s.roleService.GetRoleByID(ctx, *req.RoleID)
+	case req.Role != "":
+		role, err = s.roleService.GetRoleByName(ctx, req.Role)
+	default:
+		return ErrBulkRoleRequired
+	}
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return ErrRoleNotFound
+		}
+		return ErrInternalServerError
+	}
+	if req.RoleID != nil && req.Role != "" && req.Role != role.Name {
+		return ErrBulkInvalidRoleRef
+	}
+
+	op.roleID = &role.ID
+	op.roleName = role.Name
+	// Field role legacy hanya mengenal user dan admin
+	op.legacyRole = "user"
+	if role.Name == "admin" {
+		op.legacyRole = "admin"
+	}
+
+	return nil
+}
+
+// skipReason mengembalikan alasan user dilewati, atau string kosong jika aksi dapat dijalankan
+func (op *bulkOperation) skipReason(user *model.User) string {
+	if op.actor != nil && op.actor.ID != nil && *op.actor.ID == user.ID && op.action != model.BulkActionActivate {
+		return "cannot apply this action to your own account"
+	}
+
+	switch op.action {
+	case model.BulkActionDeactivate:
+		if !user.Active {
+			return "user is already inactive"
+		}
+	case model.BulkActionActivate:
+		if user.Active {
+			return "user is already active"
+		}
+	case model.BulkActionChangeRole:
+		if user.RoleID != nil && *user.RoleID == *op.roleID && user.Role == op.legacyRole {
+			return "user already has this role"
+		}
+	case model.BulkActionForcePasswordReset:
+		if user.Provider != "" && user.Provider != "local" {
+			return "user signs in with " + user.Provider
+		}
+	}
+
+	return ""
+}
+
+// addResult menambahkan hasil per user dan memperbarui ringkasan
+func (op *bulkOperation) addResult(result model.BulkUserActionResult) {
+	switch result.Status {
+	case model.BulkResultSucceeded:
+		op.response.Succeeded++
+	case model.BulkResultSkipped:
+		op.response.Skipped++
+	default:
+		op.response.Failed++
+	}
+	op.response.Results = append(op.response.Results, result)
+}
+
+// auditEntry membuat audit log untuk satu user yang terdampak bulk action
+func (op *bulkOperation) auditEntry(user *model.User, details map[string]interface{}) model.AuditLog {
+	action := map[string]string{
+		model.BulkActionDeactivate:         model.AuditActionUserDeactivated,
+		model.BulkActionActivate:           model.AuditActionUserActivated,
+		model.BulkActionChangeRole:         model.AuditActionUserRoleChanged,
+		model.BulkActionForcePasswordReset: model.AuditActionUserPasswordReset,
+		model.BulkActionRevokeSessions:     model.AuditActionUserSessionsRevoked,
+	}[op.action]
+
+	userID := user.ID
+	return model.NewAuditLog(op.actor, &userID, action, model.AuditResourceUser, user.ID.String(), details)
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// User import related errors
+var (
+	ErrImportEmpty       = errors.New("import file contains no rows")
+	ErrImportTooManyRows = errors.New("import file exceeds the maximum number of rows")
+)
+
+// userExportBatchSize adalah jumlah user yang dibaca per batch saat export
+const userExportBatchSize = 500
+
+// UserImportService interface untuk layanan bulk import dan export user
+type UserImportService interface {
+	ImportUsers(ctx context.Context, rows []model.ImportUserRow, dryRun bool, actor *model.AuditActor) (*model.ImportUsersResponse, error)
+	ExportUsers(ctx context.Context, filter *model.UserFilter, write func(users []model.UserResponse) error) error
+}
+
+// userImportService implementasi UserImportService
+type userImportService struct {
+	userRepo    repository.UserRepository
+	authService AuthService
+	roleService RoleService
+	config      *config.Config
+	validator   *validator.Validate
+}
+
+// NewUserImportService membuat instance baru UserImportService
+func NewUserImportService(userRepo repository.UserRepository, authService AuthService, roleService RoleService, cfg *config.Config) UserImportService {
+	return &userImportService{
+		userRepo:    userRepo,
+		authService: authService,
+		roleService: roleService,
+		config:      cfg,
+		validator:   validator.New(),
+	}
+}
+
+// ImportUsers memvalidasi semua baris lalu membuat user untuk baris yang valid.
+// Pada dry-run tidak ada user yang dibuat, hanya hasil validasi per baris yang dikembalikan.
+func (s *userImportService) ImportUsers(ctx context.Context, rows []model.ImportUserRow, dryRun bool, actor *model.AuditActor) (*model.ImportUsersResponse, error) {
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if s.config.Security.UserImportMaxRows > 0 && len(rows) > s.config.Security.UserImportMaxRows {
+		return nil, ErrImportTooManyRows
+	}
+
+	// Email yang sudah terdaftar dicek sekaligus agar dry-run akurat tanpa query per baris
+	emails := make([]string, len(rows))
+	for i := range rows {
+		rows[i].Email = strings.ToLower(strings.TrimSpace(rows[i].Email))
+		rows[i].Name = utils.SanitizeInput(strings.TrimSpace(rows[i].Name))
+		rows[i].Role = strings.TrimSpace(rows[i].Role)
+		emails[i] = rows[i].Email
+	}
+
+	existing, err := s.userRepo.FindExistingEmails(ctx, emails)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+	registered := make(map[string]bool, len(existing))
+	for _, email := range existing {
+		registered[strings.ToLower(email)] = true
+	}
+
+	response := &model.ImportUsersResponse{
+		DryRun:  dryRun,
+		Total:   len(rows),
+		Results: make([]model.ImportUserResult, 0, len(rows)),
+	}
+
+	roleIDs := make(map[string]*uuid.UUID)
+	seen := make(map[string]int, len(rows))
+
+	for i, row := range rows {
+		result := model.ImportUserResult{
+			Row:   i + 1,
+			Email: row.Email,
+		}
+
+		req, rowErrors := s.validateRow(ctx, &row, roleIDs)
+		if firstRow, ok := seen[row.Email]; ok && row.Email != "" {
+			rowErrors = append(rowErrors, fmt.Sprintf("duplicate email, first seen on row %d", firstRow))
+		} else {
+			seen[row.Email] = i + 1
+		}
+		if registered[row.Email] {
+			rowErrors = append(rowErrors, "email already registered")
+		}
+
+		if len(rowErrors) > 0 {
+			result.Status = model.ImportRowStatusFailed
+			result.Errors = rowErrors
+			response.Failed++
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		if dryRun {
+			result.Status = model.ImportRowStatusValid
+			response.Valid++
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		// User dibuat melalui jalur yang sama dengan pembuatan user oleh admin
+		created, err := s.authService.CreateUser(ctx, req, actor)
+		if err != nil {
+			result.Status = model.ImportRowStatusFailed
+			result.Errors = []string{importErrorMessage(err)}
+			response.Failed++
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		result.Status = model.ImportRowStatusCreated
+		result.UserID = &created.User.ID
+		result.InviteToken = created.InviteToken
+		response.Created++
+		response.Results = append(response.Results, result)
+	}
+
+	return response, nil
+}
+
+// ExportUsers membaca semua user yang cocok dengan filter per batch menggunakan cursor
+// pagination dan meneruskannya ke fungsi write, sehingga export tidak memuat semua user sekaligus
+func (s *userImportService) ExportUsers(ctx context.Context, filter *model.UserFilter, write func(users []model.UserResponse) error) error {
+	batch := *filter
+	batch.Limit = userExportBatchSize
+	batch.UseCursor = true
+	batch.Cursor = ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := s.authService.GetAllUsers(ctx, &batch)
+		if err != nil {
+			return err
+		}
+
+		if len(page.Users) > 0 {
+			if err := write(page.Users); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+		batch.Cursor = page.NextCursor
+	}
+}
+
+// validateRow memvalidasi satu baris import dan mengubahnya menjadi CreateUserRequest
+func (s *userImportService) validateRow(ctx context.Context, row *model.ImportUserRow, roleIDs map[string]*uuid.UUID) (*model.CreateUserRequest, []string) {
+	var rowErrors []string
+
+	if err := s.validator.Struct(row); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, fieldErr := range validationErrors {
+				rowErrors = append(rowErrors, strings.ToLower(fieldErr.Field())+": failed on '"+fieldErr.Tag()+"'")
+			}
+		} else {
+			rowErrors = append(rowErrors, err.Error())
+		}
+	}
+
+	if row.Password == "" && !row.SendInvite {
+		rowErrors = append(rowErrors, "password is required when send_invite is false")
+	}
+	if row.Password != "" && !utils.IsStrongPassword(row.Password, s.config.Security.PasswordMinLength) {
+		rowErrors = append(rowErrors, "password must include uppercase, lowercase, number, and special character")
+	}
+
+	req := &model.CreateUserRequest{
+		Email:      row.Email,
+		Name:       row.Name,
+		Password:   row.Password,
+		Verified:   row.Verified,
+		SendInvite: row.SendInvite,
+	}
+
+	// Role dapat berupa role legacy (user/admin) maupun nama role RBAC
+	if row.Role != "" {
+		roleID, err := s.resolveRole(ctx, row.Role, roleIDs)
+		switch {
+		case errors.Is(err, ErrRoleNotFound):
+			rowErrors = append(rowErrors, "role not found: "+row.Role)
+		case err != nil:
+			rowErrors = append(rowErrors, "failed to resolve role")
+		default:
+			req.RoleID = roleID
+			if row.Role == "admin" || row.Role == "user" {
+				req.Role = row.Role
+			}
+		}
+	}
+
+	return req, rowErrors
+}
+
+// resolveRole mencari ID role berdasarkan nama dengan cache per import
+func (s *userImportService) resolveRole(ctx context.Context, name string, cache map[string]*uuid.UUID) (*uuid.UUID, error) {
+	if roleID, ok := cache[name]; ok {
+		if roleID == nil {
+			return nil, ErrRoleNotFound
+		}
+		return roleID, nil
+	}
+
+	role, err := s.roleService.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			cache[name] = nil
+		}
+		return nil, err
+	}
+
+	cache[name] = &role.ID
+	return &role.ID, nil
+}
+
+// importErrorMessage mengubah error pembuatan user menjadi pesan per baris
+func importErrorMessage(err error) string {
+	switch err {
+	case ErrUserAlreadyExists:
+		return "email already registered"
+	case ErrPasswordTooWeak:
+		return "password is too weak"
+	case ErrPasswordRequired:
+		return "password is required when send_invite is false"
+	default:
+		return "failed to create user"
+	}
+}
@@ -0,0 +1,677 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/auth-service/config"
+	"github.com/auth-service/internal/model"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Webhook related errors
+var (
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEvent         = errors.New("invalid webhook event type")
+	ErrInvalidWebhookURL           = errors.New("webhook url must be an absolute http or https url")
+	ErrWebhookURLNotAllowed        = errors.New("webhook url targets a disallowed address")
+	ErrWebhookDeliveryInProgress   = errors.New("webhook delivery is still being retried")
+)
+
+// webhookResponseBodyLimit membatasi ukuran body respons yang disimpan di log percobaan
+const webhookResponseBodyLimit = 1024
+
+// webhookClaimLease adalah tambahan waktu klaim di atas timeout request, agar delivery
+// yang sedang dikirim tidak diambil instance lain
+const webhookClaimLease = time.Minute
+
+// WebhookService interface untuk layanan subscription dan pengiriman webhook
+type WebhookService interface {
+	// Subscription
+	GetAllSubscriptions(ctx context.Context, page, limit int, search string) (*model.WebhookSubscriptionsListResponse, error)
+	GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*model.WebhookSubscriptionResponse, error)
+	CreateSubscription(ctx context.Context, req *model.CreateWebhookSubscriptionRequest, actor *model.AuditActor) (*model.WebhookSubscriptionSecretResponse, error)
+	UpdateSubscription(ctx context.Context, id uuid.UUID, req *model.UpdateWebhookSubscriptionRequest, actor *model.AuditActor) (*model.WebhookSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, id uuid.UUID, actor *model.AuditActor) error
+	RotateSubscriptionSecret(ctx context.Context, id uuid.UUID, actor *model.AuditActor) (*model.WebhookSubscriptionSecretResponse, error)
+	SendTestEvent(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+
+	// Delivery
+	GetDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter) (*model.WebhookDeliveriesListResponse, error)
+	GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+
+	// Publish membuat delivery untuk setiap subscription aktif yang berlangganan tipe event,
+	// dipanggil oleh relay outbox melalui webhook sink
+	Publish(ctx context.Context, event *model.DomainEvent) error
+	// ProcessDueDeliveries mengirim delivery yang sudah jatuh tempo, dipanggil berkala oleh dispatcher
+	ProcessDueDeliveries(ctx context.Context) (int, error)
+}
+
+// webhookService implementasi WebhookService
+type webhookService struct {
+	webhookRepo  repository.WebhookRepository
+	auditService AuditService
+	httpClient   *http.Client
+	config       *config.Config
+}
+
+// NewWebhookService membuat instance baru WebhookService
+func NewWebhookService(webhookRepo repository.WebhookRepository, auditService AuditService, cfg *config.Config) WebhookService {
+	return &webhookService{
+		webhookRepo:  webhookRepo,
+		auditService: auditService,
+		httpClient: &http.Client{
+			Timeout:   cfg.Webhook.Timeout,
+			Transport: newWebhookTransport(cfg.Webhook.AllowPrivate),
+			// Redirect tidak diikuti agar payload bertanda tangan tidak diteruskan ke host lain
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: cfg,
+	}
+}
+
+// GetAllSubscriptions mendapatkan semua subscription webhook dengan pagination
+func (s *webhookService) GetAllSubscriptions(ctx context.Context, page, limit int, search string) (*model.WebhookSubscriptionsListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+
+	subscriptions, total, err := s.webhookRepo.GetAllSubscriptions(ctx, offset, limit, search)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	responses := make([]model.WebhookSubscriptionResponse, len(subscriptions))
+	for i := range subscriptions {
+		responses[i] = subscriptions[i].ToWebhookSubscriptionResponse()
+	}
+
+	totalPages := int((total + int64(limit) - 1) / int64(limit))
+
+	return &model.WebhookSubscriptionsListResponse{
+		Subscriptions: responses,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+		TotalPages:    totalPages,
+	}, nil
+}
+
+// GetSubscriptionByID mendapatkan subscription webhook berdasarkan ID
+func (s *webhookService) GetSubscriptionByID(ctx context.Context, id uuid.UUID) (*model.WebhookSubscriptionResponse, error) {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := subscription.ToWebhookSubscriptionResponse()
+	return &response, nil
+}
+
+// CreateSubscription membuat subscription webhook baru beserta signing secret-nya
+func (s *webhookService) CreateSubscription(ctx context.Context, req *model.CreateWebhookSubscriptionRequest, actor *model.AuditActor) (*model.WebhookSubscriptionSecretResponse, error) {
+	if err := s.validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	subscription := &model.WebhookSubscription{
+		Name:        req.Name,
+		Description: req.Description,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      strings.Join(events, " "),
+		Active:      true,
+	}
+	if actor != nil {
+		subscription.CreatedBy = actor.ID
+	}
+
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.auditService.Record(ctx, actor, nil, model.AuditActionWebhookCreated, model.AuditResourceWebhook, subscription.ID.String(), map[string]interface{}{
+		"name":   subscription.Name,
+		"url":    subscription.URL,
+		"events": events,
+	})
+
+	return &model.WebhookSubscriptionSecretResponse{
+		Subscription: subscription.ToWebhookSubscriptionResponse(),
+		Secret:       secret,
+	}, nil
+}
+
+// UpdateSubscription mengupdate subscription webhook. Field yang tidak dikirim tidak diubah.
+func (s *webhookService) UpdateSubscription(ctx context.Context, id uuid.UUID, req *model.UpdateWebhookSubscriptionRequest, actor *model.AuditActor) (*model.WebhookSubscriptionResponse, error) {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Name != nil && *req.Name != subscription.Name {
+		changes["name"] = map[string]interface{}{"from": subscription.Name, "to": *req.Name}
+		subscription.Name = *req.Name
+	}
+	if req.Description != nil {
+		subscription.Description = *req.Description
+	}
+	if req.URL != nil && *req.URL != subscription.URL {
+		if err := s.validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		changes["url"] = map[string]interface{}{"from": subscription.URL, "to": *req.URL}
+		subscription.URL = *req.URL
+	}
+	if req.Events != nil {
+		events, err := normalizeWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		if joined := strings.Join(events, " "); joined != subscription.Events {
+			changes["events"] = map[string]interface{}{"from": strings.Fields(subscription.Events), "to": events}
+			subscription.Events = joined
+		}
+	}
+	if req.Active != nil && *req.Active != subscription.Active {
+		changes["active"] = map[string]interface{}{"from": subscription.Active, "to": *req.Active}
+		subscription.Active = *req.Active
+	}
+
+	subscription.UpdatedAt = time.Now()
+	if err := s.webhookRepo.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	if len(changes) > 0 {
+		s.auditService.Record(ctx, actor, nil, model.AuditActionWebhookUpdated, model.AuditResourceWebhook, subscription.ID.String(), map[string]interface{}{
+			"changes": changes,
+		})
+	}
+
+	response := subscription.ToWebhookSubscriptionResponse()
+	return &response, nil
+}
+
+// DeleteSubscription menghapus subscription webhook. Delivery yang belum terkirim
+// ditutup sebagai dead oleh dispatcher.
+func (s *webhookService) DeleteSubscription(ctx context.Context, id uuid.UUID, actor *model.AuditActor) error {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.webhookRepo.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			return ErrWebhookSubscriptionNotFound
+		}
+		return ErrInternalServerError
+	}
+
+	s.auditService.Record(ctx, actor, nil, model.AuditActionWebhookDeleted, model.AuditResourceWebhook, id.String(), map[string]interface{}{
+		"name": subscription.Name,
+		"url":  subscription.URL,
+	})
+
+	return nil
+}
+
+// RotateSubscriptionSecret membuat signing secret baru; secret lama langsung tidak berlaku
+func (s *webhookService) RotateSubscriptionSecret(ctx context.Context, id uuid.UUID, actor *model.AuditActor) (*model.WebhookSubscriptionSecretResponse, error) {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	subscription.Secret = secret
+	subscription.UpdatedAt = time.Now()
+	if err := s.webhookRepo.UpdateSubscription(ctx, subscription); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	s.auditService.Record(ctx, actor, nil, model.AuditActionWebhookSecretRotated, model.AuditResourceWebhook, subscription.ID.String(), nil)
+
+	return &model.WebhookSubscriptionSecretResponse{
+		Subscription: subscription.ToWebhookSubscriptionResponse(),
+		Secret:       secret,
+	}, nil
+}
+
+// SendTestEvent mengantrekan event webhook.ping ke satu subscription, termasuk yang nonaktif
+func (s *webhookService) SendTestEvent(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newWebhookEvent(model.WebhookEventPing, map[string]interface{}{
+		"subscription_id": subscription.ID,
+		"name":            subscription.Name,
+	})
+	delivery, err := newWebhookDelivery(subscription.ID, event)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	deliveries := []model.WebhookDelivery{delivery}
+	if err := s.webhookRepo.CreateDeliveries(ctx, deliveries); err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	return &deliveries[0], nil
+}
+
+// GetDeliveries mendapatkan log pengiriman webhook yang cocok dengan filter
+func (s *webhookService) GetDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter) (*model.WebhookDeliveriesListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	offset := (filter.Page - 1) * filter.Limit
+
+	deliveries, total, err := s.webhookRepo.ListDeliveries(ctx, filter, offset, filter.Limit)
+	if err != nil {
+		return nil, ErrInternalServerError
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+
+	return &model.WebhookDeliveriesListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       filter.Page,
+		Limit:      filter.Limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetDelivery mendapatkan satu pengiriman webhook beserta log setiap percobaannya
+func (s *webhookService) GetDelivery(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDeliveryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+	return delivery, nil
+}
+
+// Redeliver menjadwalkan ulang delivery yang sudah selesai (succeeded atau dead)
+// dengan jatah percobaan penuh. Delivery yang masih dalam retry tidak dapat dikirim ulang.
+func (s *webhookService) Redeliver(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	delivery, err := s.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == model.WebhookDeliveryPending || delivery.Status == model.WebhookDeliveryFailed {
+		return nil, ErrWebhookDeliveryInProgress
+	}
+
+	if err := s.webhookRepo.ResetDelivery(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+
+	return s.GetDelivery(ctx, id)
+}
+
+// Publish membuat delivery untuk setiap subscription aktif yang berlangganan tipe event.
+// ID domain event dipakai sebagai ID event webhook, dan delivery yang sudah ada untuk event
+// yang sama dilewati sehingga aman dipanggil ulang oleh relay.
+func (s *webhookService) Publish(ctx context.Context, event *model.DomainEvent) error {
+	subscriptions, err := s.webhookRepo.GetActiveSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	webhookEvent := &model.WebhookEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.OccurredAt,
+		Data:      event.Data,
+	}
+	var deliveries []model.WebhookDelivery
+	for i := range subscriptions {
+		if !subscriptions[i].Subscribes(event.Type) {
+			continue
+		}
+		delivery, err := newWebhookDelivery(subscriptions[i].ID, webhookEvent)
+		if err != nil {
+			return fmt.Errorf("failed to encode webhook event %s: %w", event.ID, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return s.webhookRepo.CreateDeliveries(ctx, deliveries)
+}
+
+// ProcessDueDeliveries mengklaim delivery yang jatuh tempo lalu mengirimnya secara paralel
+func (s *webhookService) ProcessDueDeliveries(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDueDeliveries(ctx, time.Now(), s.config.Webhook.Timeout+webhookClaimLease, s.config.Webhook.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// deliver melakukan satu percobaan pengiriman lalu menyimpan hasil dan jadwal berikutnya
+func (s *webhookService) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	now := time.Now()
+	attempt := &model.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts + 1,
+	}
+
+	subscription := delivery.Subscription
+	switch {
+	case subscription == nil:
+		attempt.Error = "subscription has been deleted"
+	case !subscription.Active && delivery.EventType != model.WebhookEventPing:
+		attempt.Error = "subscription is inactive"
+	default:
+		attempt.ResponseStatus, attempt.ResponseBody, attempt.Error = s.send(ctx, subscription, delivery, now)
+	}
+	attempt.DurationMs = time.Since(now).Milliseconds()
+
+	delivery.Attempts = attempt.Attempt
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = attempt.ResponseStatus
+	delivery.LastError = attempt.Error
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case subscription == nil || !subscription.Active || delivery.Attempts >= s.config.Webhook.MaxAttempts:
+		delivery.Status = model.WebhookDeliveryDead
+		delivery.NextAttemptAt = nil
+	default:
+		delivery.Status = model.WebhookDeliveryFailed
+		next := now.Add(s.retryDelay(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := s.webhookRepo.SaveAttempt(ctx, delivery, attempt); err != nil {
+		log.Printf("Failed to save webhook delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// send mengirim payload bertanda tangan ke URL subscription. Error kosong berarti
+// endpoint merespons dengan status 2xx.
+func (s *webhookService) send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery, now time.Time) (int, string, string) {
+	body := []byte(delivery.Payload)
+	timestamp := now.Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", truncateString(err.Error(), 1000)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "auth-service-webhooks/1.0")
+	req.Header.Set(model.WebhookHeaderID, delivery.ID.String())
+	req.Header.Set(model.WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(model.WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(model.WebhookHeaderSignature, utils.SignWebhookPayload(timestamp, body, subscription.Secret))
+	req.Header.Set(model.WebhookHeaderIdempotencyKey, delivery.EventID.String())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, "", truncateString(err.Error(), 1000)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseBodyLimit))
+	responseBody := truncateString(string(data), webhookResponseBodyLimit)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, responseBody, fmt.Sprintf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, responseBody, ""
+}
+
+// retryDelay menghitung jeda exponential backoff setelah percobaan ke-attempts
+func (s *webhookService) retryDelay(attempts int) time.Duration {
+	delay := s.config.Webhook.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.config.Webhook.RetryMaxDelay {
+			return s.config.Webhook.RetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// findSubscription mencari subscription dan memetakan error repository ke error service
+func (s *webhookService) findSubscription(ctx context.Context, id uuid.UUID) (*model.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.FindSubscriptionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookSubscriptionNotFound) {
+			return nil, ErrWebhookSubscriptionNotFound
+		}
+		return nil, ErrInternalServerError
+	}
+	return subscription, nil
+}
+
+// newWebhookEvent membuat envelope event dengan ID unik yang sama untuk semua subscription
+func newWebhookEvent(eventType string, data map[string]interface{}) *model.WebhookEvent {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &model.WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// newWebhookDelivery membuat delivery pending untuk event ke satu subscription
+func newWebhookDelivery(subscriptionID uuid.UUID, event *model.WebhookEvent) (model.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return model.WebhookDelivery{}, err
+	}
+
+	now := time.Now()
+	return model.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        string(payload),
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+	}, nil
+}
+
+// normalizeWebhookEvents memvalidasi dan menghapus duplikasi tipe event subscription
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	known := map[string]bool{model.WebhookEventAll: true}
+	for _, event := range model.WebhookEventTypes {
+		known[event] = true
+	}
+
+	seen := make(map[string]bool, len(events))
+	normalized := make([]string, 0, len(events))
+	for _, event := range events {
+		event = strings.TrimSpace(event)
+		if !known[event] {
+			return nil, ErrInvalidWebhookEvent
+		}
+		if seen[event] {
+			continue
+		}
+		seen[event] = true
+		normalized = append(normalized, event)
+	}
+	if len(normalized) == 0 {
+		return nil, ErrInvalidWebhookEvent
+	}
+	return normalized, nil
+}
+
+// validateWebhookURL memastikan URL webhook absolut dengan skema https (atau http jika
+// WEBHOOK_REQUIRE_HTTPS dimatikan), memiliki host, dan tidak menunjuk alamat internal.
+// Nama host yang di-resolve ke alamat internal ditolak saat koneksi oleh newWebhookTransport.
+func (s *webhookService) validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || u.User != nil {
+		return ErrInvalidWebhookURL
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if s.config.Webhook.RequireHTTPS {
+			return ErrInvalidWebhookURL
+		}
+	default:
+		return ErrInvalidWebhookURL
+	}
+
+	if s.config.Webhook.AllowPrivate {
+		return nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURLNotAllowed
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return ErrWebhookURLNotAllowed
+	}
+	return nil
+}
+
+// blockedWebhookNetworks adalah rentang alamat khusus yang tidak tercakup pemeriksaan net.IP
+// bawaan: "this network", CGNAT, IETF protocol assignment, benchmarking, reserved, dan NAT64
+var blockedWebhookNetworks = mustParseCIDRs("0.0.0.0/8", "100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "240.0.0.0/4", "64:ff9b::/96")
+
+// isPublicIP memeriksa apakah alamat IP boleh menjadi target webhook
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedWebhookNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookTransport membuat transport HTTP untuk pengiriman webhook. Alamat tujuan diperiksa
+// setelah DNS di-resolve, tepat sebelum koneksi dibuat, sehingga nama host yang menunjuk atau
+// berpindah (DNS rebinding) ke alamat loopback, private, atau link-local ikut ditolak.
+func newWebhookTransport(allowPrivate bool) *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return ErrWebhookURLNotAllowed
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxy dari environment dimatikan karena koneksi ke proxy melewati pemeriksaan alamat tujuan
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// mustParseCIDRs mem-parse daftar CIDR yang ditulis di kode
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// generateWebhookSecret membuat signing secret baru untuk subscription
+func generateWebhookSecret() (string, error) {
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+// truncateString memotong value menjadi maksimal limit byte tanpa memotong karakter UTF-8
+func truncateString(value string, limit int) string {
+	value = strings.ToValidUTF8(value, "")
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ResizeImageToFill memotong bagian tengah gambar sesuai rasio target lalu
+// mengubah ukurannya menjadi tepat width x height pixel. Pengecilan memakai
+// rata-rata area (box filter) sehingga hasilnya tidak pecah.
+func ResizeImageToFill(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	// Tentukan area crop di tengah gambar dengan rasio yang sama dengan target
+	cropWidth, cropHeight := srcWidth, srcHeight
+	if srcWidth*height > srcHeight*width {
+		cropWidth = srcHeight * width / height
+	} else {
+		cropHeight = srcWidth * height / width
+	}
+	if cropWidth < 1 {
+		cropWidth = 1
+	}
+	if cropHeight < 1 {
+		cropHeight = 1
+	}
+	cropX := bounds.Min.X + (srcWidth-cropWidth)/2
+	cropY := bounds.Min.Y + (srcHeight-cropHeight)/2
+
+	// Konversi ke RGBA agar pixel dapat dibaca langsung tanpa konversi warna per pixel
+	cropped := image.NewRGBA(image.Rect(0, 0, cropWidth, cropHeight))
+	draw.Draw(cropped, cropped.Bounds(), src, image.Point{X: cropX, Y: cropY}, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * cropHeight / height
+		y1 := (y + 1) * cropHeight / height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < width; x++ {
+			x0 := x * cropWidth / width
+			x1 := (x + 1) * cropWidth / width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint32
+			for sy := y0; sy < y1; sy++ {
+				offset := cropped.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint32(cropped.Pix[offset])
+					g += uint32(cropped.Pix[offset+1])
+					b += uint32(cropped.Pix[offset+2])
+					a += uint32(cropped.Pix[offset+3])
+					offset += 4
+					count++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
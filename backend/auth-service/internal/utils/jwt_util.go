@@ -15,27 +15,93 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenIssuer adalah nilai klaim iss untuk token yang diterbitkan layanan ini,
+// sekaligus audience yang mewakili layanan ini pada token exchange
+const TokenIssuer = "auth-service"
+
+// Jenis subjek yang direpresentasikan oleh access token
+const (
+	SubjectTypeUser   = "user"   // pengguna manusia
+	SubjectTypeClient = "client" // service account (client credentials)
+)
+
 // JWTClaims adalah struktur untuk klaim JWT
 type JWTClaims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	TokenID   string    `json:"token_id,omitempty"` // Hanya untuk refresh token
-	TokenType string    `json:"token_type"`         // "access" atau "refresh"
+	UserID      uuid.UUID              `json:"user_id"` // ID user, atau ID internal service account jika SubjectType "client"
+	Email       string                 `json:"email"`
+	Role        string                 `json:"role"`
+	TokenID     string                 `json:"token_id,omitempty"`   // Hanya untuk refresh token
+	TokenType   string                 `json:"token_type"`           // "access" atau "refresh"
+	SubjectType string                 `json:"sub_type,omitempty"`   // "user" atau "client", kosong dianggap "user"
+	ClientID    string                 `json:"client_id,omitempty"`  // Hanya untuk token service account
+	ClientGen   int64                  `json:"client_gen,omitempty"` // Generasi token service account saat token diterbitkan
+	UserGen     int64                  `json:"user_gen,omitempty"`   // Generasi token user saat token diterbitkan
+	Scope       string                 `json:"scope,omitempty"`      // Scope yang diberikan, dipisah spasi
+	Act         *ActorClaim            `json:"act,omitempty"`        // Rantai aktor untuk token hasil token exchange
+	Attributes  map[string]interface{} `json:"attributes,omitempty"` // Custom attribute user yang dipetakan ke klaim JWT
 	jwt.RegisteredClaims
 }
 
-// GenerateAccessToken menghasilkan token JWT untuk akses
-func GenerateAccessToken(userID uuid.UUID, email, role, secretKey string, expiry time.Duration) (string, error) {
+// ActorClaim merepresentasikan pihak yang bertindak atas nama subjek token (RFC 8693 section 4.1).
+// Aktor sebelumnya dalam rantai delegasi disimpan secara bersarang pada field Act.
+type ActorClaim struct {
+	Subject  string      `json:"sub"`
+	ClientID string      `json:"client_id,omitempty"`
+	Act      *ActorClaim `json:"act,omitempty"`
+}
+
+// IsClient mengembalikan true jika token diterbitkan untuk service account
+func (c *JWTClaims) IsClient() bool {
+	return c.SubjectType == SubjectTypeClient
+}
+
+// GenerateAccessToken menghasilkan token JWT untuk akses. scope kosong berarti sesi login penuh.
+// attributes berisi custom attribute user yang dipetakan ke klaim JWT (boleh nil). generation
+// adalah generasi token user saat ini; token dengan generasi lebih lama dianggap dicabut.
+func GenerateAccessToken(userID uuid.UUID, email, role, scope string, attributes map[string]interface{}, generation int64, secretKey string, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
-		UserID:    userID,
-		Email:     email,
-		Role:      role,
-		TokenType: "access",
+		UserID:      userID,
+		Email:       email,
+		Role:        role,
+		TokenType:   "access",
+		SubjectType: SubjectTypeUser,
+		UserGen:     generation,
+		Scope:       scope,
+		Attributes:  attributes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    TokenIssuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secretKey))
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// GenerateClientAccessToken menghasilkan token JWT akses untuk service account. generation
+// adalah generasi token client saat ini; token dengan generasi lebih lama dianggap dicabut.
+func GenerateClientAccessToken(id uuid.UUID, clientID, role, scope string, generation int64, secretKey string, expiry time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:      id,
+		Role:        role,
+		TokenType:   "access",
+		SubjectType: SubjectTypeClient,
+		ClientID:    clientID,
+		ClientGen:   generation,
+		Scope:       scope,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   clientID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "auth-service",
+			Issuer:    TokenIssuer,
 		},
 	}
 
@@ -48,16 +114,52 @@ func GenerateAccessToken(userID uuid.UUID, email, role, secretKey string, expiry
 	return tokenString, nil
 }
 
-// GenerateRefreshToken menghasilkan token JWT untuk refresh
-func GenerateRefreshToken(userID uuid.UUID, tokenID, secretKey string, expiry time.Duration) (string, error) {
+// GenerateExchangedAccessToken menghasilkan access token hasil token exchange yang mewarisi
+// subjek dari token asal, dibatasi untuk audience dan scope tertentu, serta mencatat aktornya
+func GenerateExchangedAccessToken(subject *JWTClaims, audience []string, scope string, actor *ActorClaim, secretKey string, expiry time.Duration) (string, error) {
+	claims := JWTClaims{
+		UserID:      subject.UserID,
+		Email:       subject.Email,
+		Role:        subject.Role,
+		TokenType:   "access",
+		SubjectType: subject.SubjectType,
+		ClientID:    subject.ClientID,
+		ClientGen:   subject.ClientGen,
+		UserGen:     subject.UserGen,
+		Scope:       scope,
+		Act:         actor,
+		Attributes:  subject.Attributes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject.Subject,
+			Audience:  audience,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    TokenIssuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secretKey))
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// GenerateRefreshToken menghasilkan token JWT untuk refresh. Scope disimpan agar access token
+// hasil refresh tetap dibatasi ke scope yang sama.
+func GenerateRefreshToken(userID uuid.UUID, tokenID, scope, secretKey string, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
 		UserID:    userID,
 		TokenID:   tokenID,
 		TokenType: "refresh",
+		Scope:     scope,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "auth-service",
+			Issuer:    TokenIssuer,
 		},
 	}
 
@@ -154,3 +256,64 @@ func GenerateRandomString(length int) string {
 	}
 	return string(b)
 }
+
+// InvitationClaims adalah klaim untuk token undangan user.
+// ID token (jti) berisi ID undangan yang tersimpan di database.
+type InvitationClaims struct {
+	Email     string `json:"email"`
+	TokenType string `json:"token_type"` // selalu "invitation"
+	jwt.RegisteredClaims
+}
+
+// GenerateInvitationToken menghasilkan token undangan bertanda tangan yang berlaku sampai expiresAt
+func GenerateInvitationToken(invitationID uuid.UUID, email, secretKey string, expiresAt time.Time) (string, error) {
+	claims := InvitationClaims{
+		Email:     email,
+		TokenType: "invitation",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        invitationID.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    TokenIssuer,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secretKey))
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ParseInvitationToken memvalidasi tanda tangan dan masa berlaku token undangan
+func ParseInvitationToken(tokenString, secretKey string) (*InvitationClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &InvitationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return []byte(secretKey), nil
+	})
+
+	if err != nil {
+		if ve, ok := err.(*jwt.ValidationError); ok {
+			if ve.Errors&jwt.ValidationErrorExpired != 0 {
+				return nil, ErrExpiredToken
+			}
+		}
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*InvitationClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	// Verifikasi jenis token
+	if claims.TokenType != "invitation" || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
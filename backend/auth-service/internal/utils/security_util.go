@@ -1,11 +1,16 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"html"
-	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -37,30 +42,131 @@ func GenerateCSRFToken() (string, error) {
 	return base64.StdEncoding.EncodeToString(b), nil
 }
 
-// GetClientIP mendapatkan alamat IP klien dari request
-func GetClientIP(c *gin.Context) string {
-	// Cek header X-Forwarded-For
-	forwardedFor := c.Request.Header.Get("X-Forwarded-For")
-	if forwardedFor != "" {
-		// X-Forwarded-For bisa berisi beberapa IP, ambil yang pertama
-		parts := strings.Split(forwardedFor, ",")
-		ip := strings.TrimSpace(parts[0])
-		return ip
+// GenerateSecureToken menghasilkan token acak yang aman secara kriptografi
+// dengan panjang byteLength byte, dienkode base64 URL-safe tanpa padding
+func GenerateSecureToken(byteLength int) (string, error) {
+	b := make([]byte, byteLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
 	}
 
-	// Cek header X-Real-IP
-	realIP := c.Request.Header.Get("X-Real-IP")
-	if realIP != "" {
-		return realIP
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// userCodeCharset berisi huruf konsonan tanpa vokal agar user code mudah diketik
+// dan tidak membentuk kata (RFC 8628 section 6.1)
+const userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+
+// GenerateUserCode menghasilkan user code acak untuk device authorization grant
+func GenerateUserCode(length int) (string, error) {
+	// Byte di atas kelipatan panjang charset dibuang agar distribusi karakter merata
+	maxByte := 256 - 256%len(userCodeCharset)
+	code := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, v := range buf {
+			if int(v) < maxByte && len(code) < length {
+				code = append(code, userCodeCharset[int(v)%len(userCodeCharset)])
+			}
+		}
 	}
 
-	// Dapatkan IP dari RemoteAddr
-	ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
-	if err != nil {
-		return c.Request.RemoteAddr
+	return string(code), nil
+}
+
+// GenerateNumericCode menghasilkan kode angka acak, misalnya kode verifikasi yang dikirim melalui email
+func GenerateNumericCode(length int) (string, error) {
+	// Byte di atas kelipatan 10 dibuang agar distribusi angka merata
+	maxByte := 250
+	code := make([]byte, 0, length)
+	buf := make([]byte, length)
+	for len(code) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, v := range buf {
+			if int(v) < maxByte && len(code) < length {
+				code = append(code, '0'+v%10)
+			}
+		}
+	}
+
+	return string(code), nil
+}
+
+// NormalizeUserCode menghapus pemisah dan mengubah user code menjadi huruf besar
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(userCode)))
+}
+
+// HashSecret menghasilkan hash SHA-256 (hex) dari secret yang dibangkitkan mesin.
+// Secret acak berentropi tinggi tidak memerlukan bcrypt, dan hash deterministik
+// memungkinkan pencarian langsung berdasarkan nilai hash.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckSecretHash membandingkan secret dengan hash secara constant-time
+func CheckSecretHash(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
+
+// SignObjectURL menghasilkan signature HMAC-SHA256 (hex) untuk signed URL file,
+// mengikat key object dengan waktu kedaluwarsa (unix timestamp)
+func SignObjectURL(key string, expires int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyObjectSignature memeriksa signature signed URL file secara constant-time
+// dan menolak URL yang sudah kedaluwarsa
+func VerifyObjectSignature(key string, expires int64, signature, secret string, now time.Time) bool {
+	if now.Unix() > expires {
+		return false
 	}
+	expected := SignObjectURL(key, expires, secret)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
 
-	return ip
+// SignAuditCheckpoint menghasilkan signature HMAC-SHA256 (hex) untuk checkpoint audit log,
+// mengikat sequence dan hash ujung chain dengan waktu checkpoint (unix timestamp)
+func SignAuditCheckpoint(sequence uint64, hash string, createdAt int64, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatUint(sequence, 10) + "\n" + hash + "\n" + strconv.FormatInt(createdAt, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAuditCheckpoint memeriksa signature checkpoint audit log secara constant-time
+func VerifyAuditCheckpoint(sequence uint64, hash string, createdAt int64, signature, secret string) bool {
+	expected := SignAuditCheckpoint(sequence, hash, createdAt, secret)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// SignWebhookPayload menghasilkan signature HMAC-SHA256 (hex) untuk payload webhook,
+// dihitung atas "<timestamp>.<body>" agar penerima dapat menolak request yang diputar ulang
+func SignWebhookPayload(timestamp int64, body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetClientIP mendapatkan alamat IP klien dari request. Header X-Forwarded-For dan X-Real-IP
+// hanya dipakai jika koneksi berasal dari proxy tepercaya (lihat gin SetTrustedProxies),
+// sehingga klien tidak dapat memalsukan IP-nya.
+func GetClientIP(c *gin.Context) string {
+	return c.ClientIP()
 }
 
 // GetUserAgent mendapatkan user agent dari request
@@ -98,32 +204,6 @@ func SetSecureHeaders(c *gin.Context) {
 	c.Header("Feature-Policy", "camera 'none'; microphone 'none'; geolocation 'none'")
 }
 
-// DetectSuspiciousLogin mendeteksi login yang mencurigakan
-func DetectSuspiciousLogin(c *gin.Context, knownIP, knownUserAgent string) bool {
-	// Dapatkan IP dan user agent saat ini
-	currentIP := GetClientIP(c)
-	currentUserAgent := GetUserAgent(c)
-
-	// Jika ini adalah login pertama, tidak ada yang mencurigakan
-	if knownIP == "" || knownUserAgent == "" {
-		return false
-	}
-
-	// Jika IP dan user agent sama dengan yang diketahui, tidak mencurigakan
-	if currentIP == knownIP && currentUserAgent == knownUserAgent {
-		return false
-	}
-
-	// Jika IP berbeda dan user agent berbeda, sangat mencurigakan
-	if currentIP != knownIP && currentUserAgent != knownUserAgent {
-		return true
-	}
-
-	// Jika hanya IP yang berbeda, mungkin mencurigakan (tergantung kebijakan)
-	// Untuk implementasi sederhana, kita anggap mencurigakan
-	return true
-}
-
 // ValidateRequestOrigin memvalidasi origin request untuk mencegah CSRF
 func ValidateRequestOrigin(c *gin.Context, allowedOrigins []string) bool {
 	origin := c.Request.Header.Get("Origin")
@@ -53,6 +53,10 @@ CREATE TABLE IF NOT EXISTS users (
     password VARCHAR(255),
     name VARCHAR(255) NOT NULL,
     profile_picture VARCHAR(255),
+    avatar_key VARCHAR(255),
+    phone VARCHAR(30),
+    locale VARCHAR(35),
+    timezone VARCHAR(64),
     provider VARCHAR(50) DEFAULT 'local',
     provider_id VARCHAR(255),
     role VARCHAR(50) DEFAULT 'user', -- Legacy field for backward compatibility
@@ -62,13 +66,18 @@ CREATE TABLE IF NOT EXISTS users (
     last_login DATETIME,
     login_attempts INT DEFAULT 0,
     locked_until DATETIME,
+    password_reset_required BOOLEAN DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP DEFAULT NULL,
     FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE SET NULL,
     INDEX idx_email (email),
     INDEX idx_provider_id (provider, provider_id),
-    INDEX idx_role_id (role_id)
+    INDEX idx_role_id (role_id),
+    INDEX idx_name (name),
+    INDEX idx_last_login (last_login),
+    INDEX idx_created_at (created_at),
+    INDEX idx_deleted_at (deleted_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- Membuat tabel login_histories
@@ -78,25 +87,38 @@ CREATE TABLE IF NOT EXISTS login_histories (
     ip VARCHAR(50),
     user_agent VARCHAR(255),
     device_info VARCHAR(255),
+    device_fingerprint VARCHAR(64), -- hash keluarga browser, OS dan model perangkat
     browser VARCHAR(100),
     os VARCHAR(100),
     country VARCHAR(100),
     city VARCHAR(100),
+    latitude DOUBLE,
+    longitude DOUBLE,
     success BOOLEAN DEFAULT TRUE,
     failure_reason VARCHAR(255),
+    risk_score INT DEFAULT 0,
+    risk_factors VARCHAR(255), -- faktor risiko dipisahkan koma
+    risk_action VARCHAR(20), -- allow, notify atau step_up
     login_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP DEFAULT NULL,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
     INDEX idx_user_id (user_id),
-    INDEX idx_login_time (login_time)
+    INDEX idx_login_time (login_time),
+    INDEX idx_login_histories_outcome_time (success, login_time), -- dipakai job retensi riwayat login
+    INDEX idx_login_histories_user_device (user_id, device_fingerprint) -- dipakai deteksi perangkat baru
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
--- Membuat tabel user_activities
+-- Membuat tabel user_activities (audit log append-only dengan hash chain).
+-- Tanpa foreign key ke users agar purge user tidak mengubah baris yang sudah di-hash.
 CREATE TABLE IF NOT EXISTS user_activities (
     id CHAR(36) PRIMARY KEY,
-    user_id CHAR(36) NOT NULL,
+    sequence BIGINT UNSIGNED, -- posisi pada hash chain, NULL untuk entry sebelum hash chain diaktifkan
+    user_id CHAR(36), -- user yang terdampak, NULL untuk aksi pada role/permission atau user yang sudah di-purge
+    actor_id CHAR(36), -- pelaku aksi, NULL untuk service account atau proses sistem
+    actor_type VARCHAR(20),
+    actor VARCHAR(255),
     action VARCHAR(100) NOT NULL,
     resource VARCHAR(100),
     resource_id VARCHAR(100),
@@ -104,13 +126,329 @@ CREATE TABLE IF NOT EXISTS user_activities (
     ip_address VARCHAR(50),
     user_agent VARCHAR(255),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    prev_hash CHAR(64),
+    hash CHAR(64),
+    UNIQUE INDEX idx_sequence (sequence),
     INDEX idx_user_id (user_id),
+    INDEX idx_actor_id (actor_id),
     INDEX idx_action (action),
     INDEX idx_resource (resource),
     INDEX idx_created_at (created_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- Membuat tabel audit_chain_head (ujung hash chain audit log, satu baris)
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+    id INT UNSIGNED PRIMARY KEY,
+    sequence BIGINT UNSIGNED NOT NULL DEFAULT 0,
+    hash CHAR(64),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel audit_checkpoints (checkpoint hash chain yang ditandatangani HMAC)
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id CHAR(36) PRIMARY KEY,
+    sequence BIGINT UNSIGNED NOT NULL,
+    hash CHAR(64) NOT NULL,
+    signature CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_sequence (sequence)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel oauth_clients (service account untuk client credentials grant)
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id CHAR(36) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_hash VARCHAR(255) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    role_id CHAR(36),
+    scopes VARCHAR(1000),
+    audiences VARCHAR(1000),
+    active BOOLEAN DEFAULT TRUE,
+    created_by CHAR(36),
+    last_used_at DATETIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE SET NULL,
+    INDEX idx_role_id (role_id),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel api_keys (personal access token)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes VARCHAR(1000),
+    expires_at DATETIME,
+    last_used_at DATETIME,
+    last_used_ip VARCHAR(50),
+    revoked_at DATETIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_user_id (user_id),
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel invitations (undangan user oleh admin)
+CREATE TABLE IF NOT EXISTS invitations (
+    id CHAR(36) PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    name VARCHAR(255),
+    role VARCHAR(50) DEFAULT 'user',
+    role_id CHAR(36),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, revoked; expired dihitung dari expires_at
+    invited_by CHAR(36),
+    accepted_user_id CHAR(36),
+    expires_at DATETIME NOT NULL,
+    accepted_at DATETIME,
+    revoked_at DATETIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE SET NULL,
+    INDEX idx_email (email),
+    INDEX idx_status (status),
+    INDEX idx_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel folders (path lengkap disimpan agar file dapat difilter berdasarkan path)
+CREATE TABLE IF NOT EXISTS folders (
+    id CHAR(36) PRIMARY KEY,
+    owner_id CHAR(36) NOT NULL,
+    parent_id CHAR(36),
+    name VARCHAR(255) NOT NULL,
+    path VARCHAR(512) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_folder_owner_path (owner_id, path),
+    INDEX idx_parent_id (parent_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel files (metadata; isi file disimpan di file storage)
+CREATE TABLE IF NOT EXISTS files (
+    id CHAR(36) PRIMARY KEY,
+    owner_id CHAR(36) NOT NULL,
+    folder_id CHAR(36),
+    name VARCHAR(255) NOT NULL,
+    original_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(127) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    width INT,
+    height INT,
+    download_count INT DEFAULT 0,
+    last_download_at DATETIME,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE,
+    INDEX idx_owner_id (owner_id),
+    INDEX idx_folder_id (folder_id),
+    INDEX idx_name (name),
+    INDEX idx_mime_type (mime_type),
+    INDEX idx_size (size),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel storage_quotas (quota_bytes NULL berarti memakai STORAGE_DEFAULT_QUOTA)
+CREATE TABLE IF NOT EXISTS storage_quotas (
+    user_id CHAR(36) PRIMARY KEY,
+    quota_bytes BIGINT,
+    used_bytes BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel attribute_definitions (definisi custom attribute user)
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id CHAR(36) PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    display_name VARCHAR(100),
+    description VARCHAR(255),
+    type VARCHAR(20) NOT NULL,
+    required BOOLEAN DEFAULT FALSE,
+    visibility VARCHAR(20) DEFAULT 'admin',
+    pattern VARCHAR(255),
+    min_value DOUBLE,
+    max_value DOUBLE,
+    options TEXT,
+    jwt_claim VARCHAR(64),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel user_attribute_values (nilai disimpan di kolom sesuai tipe agar dapat di-index)
+CREATE TABLE IF NOT EXISTS user_attribute_values (
+    user_id CHAR(36) NOT NULL,
+    definition_id CHAR(36) NOT NULL,
+    string_value VARCHAR(255),
+    number_value DOUBLE,
+    bool_value BOOLEAN,
+    date_value DATE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, definition_id),
+    INDEX idx_attr_string (definition_id, string_value),
+    INDEX idx_attr_number (definition_id, number_value),
+    INDEX idx_attr_date (definition_id, date_value),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (definition_id) REFERENCES attribute_definitions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel webhook_subscriptions (endpoint penerima event identitas)
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id CHAR(36) PRIMARY KEY,
+    name VARCHAR(100),
+    description TEXT,
+    url VARCHAR(2048),
+    secret VARCHAR(255), -- kunci HMAC payload
+    events VARCHAR(1000), -- daftar event dipisah spasi, "*" untuk semua event
+    active BOOLEAN DEFAULT TRUE,
+    created_by CHAR(36),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel webhook_deliveries (pengiriman satu event ke satu subscription)
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id CHAR(36) PRIMARY KEY,
+    subscription_id CHAR(36) NOT NULL,
+    event_id CHAR(36) NOT NULL,
+    event_type VARCHAR(100),
+    payload MEDIUMTEXT,
+    status VARCHAR(20), -- pending, failed, succeeded, dead
+    attempts INT DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL,
+    last_attempt_at TIMESTAMP NULL,
+    response_status INT,
+    last_error VARCHAR(1000),
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_webhook_deliveries_event (event_id, subscription_id), -- satu delivery per event dan subscription
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    INDEX idx_subscription_id (subscription_id),
+    INDEX idx_event_type (event_type),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel webhook_delivery_attempts (log setiap percobaan pengiriman)
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id CHAR(36) PRIMARY KEY,
+    delivery_id CHAR(36) NOT NULL,
+    attempt INT NOT NULL,
+    response_status INT, -- 0 jika request gagal sebelum ada respons
+    response_body TEXT,
+    error VARCHAR(1000),
+    duration_ms BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_delivery_id (delivery_id),
+    FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel outbox_events (domain event yang ditulis dalam transaksi yang sama dengan perubahan state)
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id CHAR(36) PRIMARY KEY, -- juga idempotency key untuk consumer
+    aggregate_type VARCHAR(50),
+    aggregate_id VARCHAR(100),
+    event_type VARCHAR(100),
+    payload MEDIUMTEXT,
+    status VARCHAR(20), -- pending, failed, published
+    attempts INT DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL,
+    published_sinks VARCHAR(255), -- sink yang sudah menerima event, dipisah spasi
+    last_error VARCHAR(1000),
+    occurred_at TIMESTAMP NULL,
+    published_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_outbox_due (status, next_attempt_at),
+    INDEX idx_outbox_aggregate (aggregate_type, aggregate_id),
+    INDEX idx_event_type (event_type),
+    INDEX idx_published_at (published_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel notifications (notifikasi per user dengan status dibaca)
+CREATE TABLE IF NOT EXISTS notifications (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    type VARCHAR(20) NOT NULL, -- info, success, warning, error
+    category VARCHAR(50), -- security, account, system
+    event VARCHAR(100),
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    data JSON,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_notifications_user (user_id, created_at),
+    INDEX idx_category (category),
+    INDEX idx_read_at (read_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel chat_conversations (percakapan 1:1, user_a_id selalu lebih kecil dari user_b_id)
+CREATE TABLE IF NOT EXISTS chat_conversations (
+    id CHAR(36) PRIMARY KEY,
+    user_a_id CHAR(36) NOT NULL,
+    user_b_id CHAR(36) NOT NULL,
+    last_message_id CHAR(36),
+    last_message_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_chat_conversations_pair (user_a_id, user_b_id),
+    INDEX idx_user_b_id (user_b_id),
+    INDEX idx_last_message_at (last_message_at),
+    FOREIGN KEY (user_a_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_b_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel chat_messages (pesan chat dengan delivery dan read receipt)
+CREATE TABLE IF NOT EXISTS chat_messages (
+    id CHAR(36) PRIMARY KEY,
+    conversation_id CHAR(36) NOT NULL,
+    sender_id CHAR(36) NOT NULL,
+    recipient_id CHAR(36) NOT NULL,
+    client_message_id VARCHAR(64), -- ID dari client untuk mencegah pesan ganda saat kirim ulang
+    type VARCHAR(20) NOT NULL, -- text, image, file
+    body TEXT,
+    delivered_at TIMESTAMP NULL,
+    read_at TIMESTAMP NULL,
+    created_at TIMESTAMP(3) DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_chat_messages_conversation (conversation_id, created_at),
+    INDEX idx_chat_messages_recipient (recipient_id, read_at),
+    UNIQUE INDEX idx_chat_messages_client (sender_id, client_message_id),
+    FOREIGN KEY (conversation_id) REFERENCES chat_conversations(id) ON DELETE CASCADE,
+    FOREIGN KEY (sender_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (recipient_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Membuat tabel job_runs (riwayat run job pemeliharaan di latar belakang)
+CREATE TABLE IF NOT EXISTS job_runs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    trigger_type VARCHAR(20) NOT NULL, -- schedule, manual
+    triggered_by CHAR(36), -- admin yang memicu run manual
+    instance VARCHAR(255), -- host dan PID instance yang menjalankan job
+    status VARCHAR(20) NOT NULL, -- running, succeeded, failed
+    affected BIGINT NOT NULL DEFAULT 0,
+    error VARCHAR(1000),
+    started_at TIMESTAMP(3) NOT NULL,
+    finished_at TIMESTAMP(3) NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    INDEX idx_job_runs_job_started (job_name, started_at),
+    INDEX idx_job_runs_started_at (started_at),
+    INDEX idx_job_runs_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- Membuat tabel refresh_tokens (opsional, jika tidak menggunakan Redis)
 -- CREATE TABLE IF NOT EXISTS refresh_tokens (
 --     id CHAR(36) PRIMARY KEY,